@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/config"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/database"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// Runs spending anomaly detection for every workspace with receipts, or for the workspaces of one
+// user. Meant to be scheduled, e.g. nightly from cron; runs are idempotent.
+func main() {
+	var email string
+
+	flag.StringVar(&email, "user", "", "Email of a single user whose workspaces to check; defaults to every workspace")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Connect to database
+	db, err := database.NewPostgresDB(database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  cfg.DBSSLMode,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	anomalyRepo := repository.NewAnomalyRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	anomalyService := service.NewAnomalyService(anomalyRepo, workspaceRepo, utils.NewValidator())
+
+	var workspaceIDs []int
+	if email != "" {
+		user, err := repository.NewUserRepository(db).FindByEmail(email)
+		if err != nil {
+			log.Fatalf("Failed to find user %s: %v", email, err)
+		}
+		workspaces, err := workspaceRepo.FindByUserID(user.ID)
+		if err != nil {
+			log.Fatalf("Failed to list workspaces of %s: %v", email, err)
+		}
+		for _, workspace := range workspaces {
+			workspaceIDs = append(workspaceIDs, workspace.ID)
+		}
+	} else {
+		workspaceIDs, err = anomalyRepo.FindWorkspaceIDs()
+		if err != nil {
+			log.Fatalf("Failed to list workspaces: %v", err)
+		}
+	}
+
+	// One workspace's failure is logged and the rest are still checked
+	var checked, created, failed int
+	for _, workspaceID := range workspaceIDs {
+		result, err := anomalyService.DetectWorkspaceAnomalies(workspaceID)
+		if err != nil {
+			log.Printf("Failed to detect anomalies for workspace %d: %v", workspaceID, err)
+			failed++
+			continue
+		}
+		checked += result.Checked
+		created += result.Created
+	}
+
+	fmt.Printf("✅ Checked %d receipts of %d workspaces, %d new anomalies\n", checked, len(workspaceIDs)-failed, created)
+	if failed > 0 {
+		log.Fatalf("Anomaly detection failed for %d workspaces", failed)
+	}
+}
@@ -6,6 +6,13 @@ import (
 
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/config"
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/database"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/document"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/handler"
+	appMiddleware "github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/storage"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -33,6 +40,73 @@ func main() {
 	}
 	defer db.Close()
 
+	// Repositories
+	receiptRepo := repository.NewReceiptRepository(db)
+	itemRepo := repository.NewItemRepository(db)
+	adjustmentRepo := repository.NewAdjustmentRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	rateRepo := repository.NewExchangeRateRepository(db)
+	cardRepo := repository.NewCardRepository(db)
+	bankTransactionRepo := repository.NewBankTransactionRepository(db)
+	merchantRepo := repository.NewMerchantRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	splitRepo := repository.NewSplitRepository(db)
+	expenseRepo := repository.NewExpenseReportRepository(db)
+	recurringRepo := repository.NewRecurringRepository(db)
+	anomalyRepo := repository.NewAnomalyRepository(db)
+	warrantyRepo := repository.NewWarrantyRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+
+	// Services
+	receiptService := service.NewReceiptService(receiptRepo, itemRepo, adjustmentRepo, userRepo, rateRepo, cardRepo, merchantRepo, productRepo, workspaceRepo, expenseRepo)
+	exportService := service.NewExportService(receiptRepo, workspaceRepo)
+	importService := service.NewImportService(receiptRepo, userRepo, cardRepo, merchantRepo, productRepo, workspaceRepo, utils.NewValidator())
+	cardService := service.NewCardService(cardRepo, utils.NewValidator())
+	reconciliationService := service.NewReconciliationService(bankTransactionRepo, receiptRepo, userRepo, workspaceRepo)
+	merchantService := service.NewMerchantService(merchantRepo, userRepo, workspaceRepo, utils.NewValidator())
+	productService := service.NewProductService(productRepo, userRepo, rateRepo, workspaceRepo, utils.NewValidator())
+	workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo, utils.NewValidator())
+	splitService := service.NewSplitService(splitRepo, receiptRepo, itemRepo, workspaceRepo, utils.NewValidator())
+	expenseService := service.NewExpenseReportService(expenseRepo, receiptRepo, userRepo, rateRepo, workspaceRepo, utils.NewValidator())
+	reportService := service.NewReportService(receiptRepo, itemRepo, expenseRepo, userRepo, rateRepo, workspaceRepo, storage.NewImageStore(cfg.StorageType, cfg.StoragePath))
+	recurringService := service.NewRecurringService(recurringRepo, workspaceRepo)
+	anomalyService := service.NewAnomalyService(anomalyRepo, workspaceRepo, utils.NewValidator())
+	warrantyService := service.NewWarrantyService(warrantyRepo, receiptRepo, itemRepo, workspaceRepo, utils.NewValidator())
+	notificationService := service.NewNotificationService(notificationRepo)
+	tagService := service.NewTagService(tagRepo, receiptRepo, workspaceRepo, utils.NewValidator())
+	fileStore := storage.NewFileStore(cfg.StorageType, cfg.StoragePath, storage.MinioConfig{
+		Endpoint:  cfg.MinioEndpoint,
+		AccessKey: cfg.MinioAccessKey,
+		SecretKey: cfg.MinioSecretKey,
+		Bucket:    cfg.MinioBucket,
+		UseSSL:    cfg.MinioUseSSL,
+	})
+	attachmentService := service.NewAttachmentService(attachmentRepo, receiptRepo, expenseRepo, workspaceRepo, fileStore)
+	documentService := service.NewDocumentService(receiptService, attachmentService, receiptRepo, fileStore, document.NewRasterizer(cfg.PDFRasterizer))
+
+	// Handlers
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+	exportHandler := handler.NewExportHandler(exportService)
+	importHandler := handler.NewImportHandler(importService)
+	cardHandler := handler.NewCardHandler(cardService)
+	reconciliationHandler := handler.NewReconciliationHandler(reconciliationService)
+	merchantHandler := handler.NewMerchantHandler(merchantService)
+	productHandler := handler.NewProductHandler(productService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
+	splitHandler := handler.NewSplitHandler(splitService)
+	expenseHandler := handler.NewExpenseReportHandler(expenseService)
+	reportHandler := handler.NewReportHandler(reportService)
+	recurringHandler := handler.NewRecurringHandler(recurringService)
+	anomalyHandler := handler.NewAnomalyHandler(anomalyService)
+	warrantyHandler := handler.NewWarrantyHandler(warrantyService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	tagHandler := handler.NewTagHandler(tagService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService)
+	documentHandler := handler.NewDocumentHandler(documentService)
+
 	// Create Echo instance
 	e := echo.New()
 
@@ -58,6 +132,198 @@ func main() {
 		})
 	}
 
+	// Receipt routes (authenticated)
+	receipts := v1.Group("/receipts", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		receipts.GET("", receiptHandler.GetReceipts)
+		receipts.GET("/stats", receiptHandler.GetStats)
+		receipts.GET("/search", receiptHandler.SearchReceipts)
+		receipts.GET("/export", exportHandler.ExportReceipts)
+		receipts.POST("/import", importHandler.ImportReceipts)
+		receipts.POST("/documents", documentHandler.IngestDocument)
+		receipts.GET("/duplicates", receiptHandler.GetDuplicates)
+		receipts.POST("/:id/duplicate/confirm", receiptHandler.ConfirmDuplicate)
+		receipts.POST("/:id/duplicate/dismiss", receiptHandler.DismissDuplicate)
+		receipts.GET("/:id/split", splitHandler.GetSplit)
+		receipts.PUT("/:id/split", splitHandler.SplitReceipt)
+		receipts.DELETE("/:id/split", splitHandler.DeleteSplit)
+		receipts.PUT("/:id/notes", receiptHandler.UpdateReceiptNotes)
+		receipts.POST("/:id/extraction", receiptHandler.ApplyPageExtraction)
+		receipts.GET("/:id/attachments", attachmentHandler.GetAttachments)
+		receipts.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+		receipts.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+		receipts.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+		receipts.GET("/:id/tags", tagHandler.GetReceiptTags)
+		receipts.PUT("/:id/tags", tagHandler.SetReceiptTags)
+	}
+
+	// Card routes (authenticated)
+	cards := v1.Group("/cards", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		cards.GET("", cardHandler.GetCards)
+		cards.POST("", cardHandler.CreateCard)
+		cards.DELETE("/:id", cardHandler.DeleteCard)
+	}
+
+	// Bank reconciliation routes (authenticated)
+	bankTransactions := v1.Group("/bank-transactions", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		bankTransactions.POST("/import", reconciliationHandler.ImportStatement)
+		bankTransactions.POST("/:id/match", reconciliationHandler.MatchTransaction)
+		bankTransactions.DELETE("/:id/match", reconciliationHandler.UnmatchTransaction)
+	}
+
+	reconciliation := v1.Group("/reconciliation", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		reconciliation.GET("", reconciliationHandler.GetReport)
+		reconciliation.POST("", reconciliationHandler.Reconcile)
+	}
+
+	// Merchant routes (authenticated)
+	merchants := v1.Group("/merchants", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		merchants.GET("", merchantHandler.GetMerchants)
+		merchants.POST("/match", merchantHandler.MatchReceipts)
+		merchants.POST("/merge", merchantHandler.MergeMerchants)
+		merchants.GET("/:id", merchantHandler.GetMerchant)
+		merchants.PUT("/:id", merchantHandler.UpdateMerchant)
+	}
+
+	// Product routes (authenticated)
+	products := v1.Group("/products", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		products.GET("", productHandler.GetProducts)
+		products.GET("/top", productHandler.GetTopProducts)
+		products.POST("/match", productHandler.MatchItems)
+		products.POST("/merge", productHandler.MergeProducts)
+		products.GET("/:id", productHandler.GetProduct)
+		products.PUT("/:id", productHandler.UpdateProduct)
+		products.GET("/:id/prices", productHandler.GetPriceHistory)
+	}
+
+	// Workspace routes (authenticated). Receipt routes act on the workspace in the
+	// X-Workspace-ID header or workspace_id parameter, else the personal workspace.
+	workspaces := v1.Group("/workspaces", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		workspaces.GET("", workspaceHandler.GetWorkspaces)
+		workspaces.POST("", workspaceHandler.CreateWorkspace)
+		workspaces.GET("/invitations", workspaceHandler.GetMyInvitations)
+		workspaces.POST("/invitations/accept", workspaceHandler.AcceptInvitation)
+		workspaces.GET("/:id", workspaceHandler.GetWorkspace)
+		workspaces.PUT("/:id", workspaceHandler.UpdateWorkspace)
+		workspaces.GET("/:id/invitations", workspaceHandler.GetPendingInvitations)
+		workspaces.POST("/:id/invitations", workspaceHandler.InviteMember)
+		workspaces.DELETE("/:id/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+		workspaces.PUT("/:id/members/:userId", workspaceHandler.UpdateMember)
+		workspaces.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
+	}
+
+	// Bill splitting routes (authenticated), for the workspace chosen like receipt routes
+	splits := v1.Group("/splits", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		splits.GET("/balances", splitHandler.GetBalances)
+		splits.GET("/ledger", splitHandler.GetLedger)
+		splits.POST("/settlements", splitHandler.CreateSettlement)
+	}
+
+	// Expense report routes (authenticated), for the workspace chosen like receipt routes
+	expenseReports := v1.Group("/expense-reports", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		expenseReports.GET("", expenseHandler.GetReports)
+		expenseReports.POST("", expenseHandler.CreateReport)
+		expenseReports.GET("/:id", expenseHandler.GetReport)
+		expenseReports.PUT("/:id", expenseHandler.UpdateReport)
+		expenseReports.DELETE("/:id", expenseHandler.DeleteReport)
+		expenseReports.GET("/:id/summary", expenseHandler.GetSummary)
+		expenseReports.GET("/:id/pdf", reportHandler.ExpenseReportPDF)
+		expenseReports.POST("/:id/receipts", expenseHandler.AddReceipts)
+		expenseReports.DELETE("/:id/receipts/:receiptId", expenseHandler.RemoveReceipt)
+		expenseReports.PUT("/:id/approvers", expenseHandler.SetApprovers)
+		expenseReports.POST("/:id/submit", expenseHandler.SubmitReport)
+		expenseReports.POST("/:id/approve", expenseHandler.ApproveReport)
+		expenseReports.POST("/:id/reject", expenseHandler.RejectReport)
+		expenseReports.POST("/:id/pay", expenseHandler.MarkPaid)
+		expenseReports.POST("/:id/comments", expenseHandler.AddComment)
+	}
+
+	// Report routes (authenticated), for the workspace chosen like receipt routes
+	reports := v1.Group("/reports", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		reports.GET("/monthly", reportHandler.MonthlyReport)
+	}
+
+	// Recurring purchase routes (authenticated), for the workspace chosen like receipt routes
+	recurringPurchases := v1.Group("/recurring", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		recurringPurchases.GET("", recurringHandler.GetRecurring)
+		recurringPurchases.POST("/detect", recurringHandler.DetectRecurring)
+		recurringPurchases.PUT("/:id", recurringHandler.UpdateRecurring)
+	}
+
+	// Anomaly feed routes (authenticated), for the workspace chosen like receipt routes
+	anomalies := v1.Group("/anomalies", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		anomalies.GET("", anomalyHandler.GetAnomalies)
+		anomalies.POST("/detect", anomalyHandler.DetectAnomalies)
+		anomalies.PUT("/:id", anomalyHandler.UpdateAnomaly)
+	}
+
+	// Item routes (authenticated)
+	items := v1.Group("/items", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		items.GET("/:id/warranty", warrantyHandler.GetWarranty)
+		items.PUT("/:id/warranty", warrantyHandler.SetWarranty)
+		items.DELETE("/:id/warranty", warrantyHandler.DeleteWarranty)
+		items.PUT("/:id/notes", receiptHandler.UpdateItemNotes)
+	}
+
+	// Warranty routes (authenticated), for the workspace chosen like receipt routes
+	warranties := v1.Group("/warranties", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		warranties.GET("/upcoming", warrantyHandler.GetUpcoming)
+	}
+
+	// Tag routes (authenticated)
+	tags := v1.Group("/tags", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		tags.GET("", tagHandler.GetTags)
+		tags.POST("", tagHandler.CreateTag)
+		tags.PUT("/:id", tagHandler.RenameTag)
+		tags.DELETE("/:id", tagHandler.DeleteTag)
+	}
+
+	// Notification routes (authenticated)
+	notifications := v1.Group("/notifications", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		notifications.GET("", notificationHandler.GetNotifications)
+		notifications.POST("/read", notificationHandler.MarkAllRead)
+		notifications.POST("/:id/read", notificationHandler.MarkRead)
+	}
+
+	// Admin routes (authenticated, admin role checked by the services)
+	admin := v1.Group("/admin", appMiddleware.JWTMiddleware(cfg.JWTSecret))
+
+	{
+		admin.GET("/merchants", merchantHandler.AdminGetMerchants)
+		admin.POST("/merchants/merge", merchantHandler.AdminMergeMerchants)
+	}
+
 	// Start server
 	address := fmt.Sprintf(":%s", cfg.ServerPort)
 	log.Printf("🚀 Server starting on %s", address)
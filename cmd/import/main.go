@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/config"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/database"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/importer"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+func main() {
+	var filePath string
+	var format string
+	var email string
+	var dryRun bool
+	var batchSize int
+	var workspaceID int
+
+	flag.StringVar(&filePath, "file", "", "Path to the CSV or JSON file to import")
+	flag.StringVar(&format, "format", "", "File format (csv, json); defaults to the file extension")
+	flag.StringVar(&email, "user", "", "Email of the user who will own the imported receipts")
+	flag.BoolVar(&dryRun, "dry-run", false, "Validate and check the import without saving")
+	flag.IntVar(&batchSize, "batch-size", service.DefaultImportBatchSize, "Receipts inserted per transaction")
+	flag.IntVar(&workspaceID, "workspace", 0, "ID of the workspace receiving the receipts; defaults to the user's personal workspace")
+	flag.Parse()
+
+	if filePath == "" || email == "" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Connect to database
+	db, err := database.NewPostgresDB(database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  cfg.DBSSLMode,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db)
+	user, err := userRepo.FindByEmail(email)
+	if err != nil {
+		log.Fatalf("Failed to find user %s: %v", email, err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Fatalf("Failed to open import file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := importer.ParseReceipts(importer.Format(format), file)
+	if err != nil {
+		log.Fatalf("Failed to parse import file: %v", err)
+	}
+
+	importService := service.NewImportService(repository.NewReceiptRepository(db), userRepo, repository.NewCardRepository(db), repository.NewMerchantRepository(db), repository.NewProductRepository(db), repository.NewWorkspaceRepository(db), utils.NewValidator())
+	report, err := importService.ImportReceipts(user.ID, workspaceID, records, dryRun, batchSize)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	for _, row := range report.Rows {
+		if !row.Success {
+			fmt.Printf("Row %d: %s\n", row.Row, row.Error)
+		}
+	}
+
+	summary, _ := json.MarshalIndent(map[string]interface{}{
+		"dry_run":   report.DryRun,
+		"total":     report.Total,
+		"succeeded": report.Succeeded,
+		"failed":    report.Failed,
+	}, "", "  ")
+	fmt.Println(string(summary))
+
+	if report.Failed > 0 {
+		os.Exit(2)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Receipt Importer")
+	fmt.Println("")
+	fmt.Println("Usage:")
+	fmt.Println("  go run cmd/import/main.go -file=<path> -user=<email> [options]")
+	fmt.Println("")
+	fmt.Println("Options:")
+	fmt.Println("  -format=csv|json   File format, defaults to the file extension")
+	fmt.Println("  -dry-run           Validate without saving")
+	fmt.Println("  -batch-size=N      Receipts per transaction (default 100)")
+	fmt.Println("  -workspace=ID      Workspace receiving the receipts (default: personal)")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  go run cmd/import/main.go -file=receipts.csv -user=me@example.com -dry-run")
+	fmt.Println("  go run cmd/import/main.go -file=receipts.json -user=me@example.com")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/config"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/database"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/importer"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+)
+
+func main() {
+	var filePath string
+	var format string
+
+	flag.StringVar(&filePath, "file", "", "Path to the exchange rates file")
+	flag.StringVar(&format, "format", "csv", "File format (csv, ecb)")
+	flag.Parse()
+
+	if filePath == "" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Fatalf("Failed to open rates file: %v", err)
+	}
+	defer file.Close()
+
+	rates, err := importer.ParseRates(importer.Format(format), file)
+	if err != nil {
+		log.Fatalf("Failed to parse rates file: %v", err)
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Connect to database
+	db, err := database.NewPostgresDB(database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  cfg.DBSSLMode,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := repository.NewExchangeRateRepository(db).UpsertBatch(rates); err != nil {
+		log.Fatalf("Failed to import rates: %v", err)
+	}
+
+	fmt.Printf("✅ Imported %d exchange rates\n", len(rates))
+}
+
+func printUsage() {
+	fmt.Println("Exchange Rate Importer")
+	fmt.Println("")
+	fmt.Println("Usage:")
+	fmt.Println("  go run cmd/rates/main.go -file=<path> [-format=csv|ecb]")
+	fmt.Println("")
+	fmt.Println("Formats:")
+	fmt.Println("  csv   Columns date,base,quote,rate (1 base = rate quote)")
+	fmt.Println("  ecb   ECB euro reference rates XML (eurofxref-daily.xml, eurofxref-hist.xml)")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  go run cmd/rates/main.go -file=rates.csv")
+	fmt.Println("  go run cmd/rates/main.go -file=eurofxref-hist.xml -format=ecb")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/config"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/database"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// Sends reminders for item return windows that are about to close. Meant to be scheduled
+// daily, e.g. from cron; each window is reminded once.
+func main() {
+	var days int
+
+	flag.IntVar(&days, "days", service.ReturnReminderDays, "Remind about return windows closing within this many days")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Connect to database
+	db, err := database.NewPostgresDB(database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  cfg.DBSSLMode,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	warrantyService := service.NewWarrantyService(
+		repository.NewWarrantyRepository(db),
+		repository.NewReceiptRepository(db),
+		repository.NewItemRepository(db),
+		repository.NewWorkspaceRepository(db),
+		utils.NewValidator(),
+	)
+
+	sent, err := warrantyService.SendReturnReminders(time.Now(), days)
+	if err != nil {
+		log.Fatalf("Failed to send return reminders after %d sent: %v", sent, err)
+	}
+
+	fmt.Printf("✅ Sent %d return reminders\n", sent)
+}
@@ -0,0 +1,69 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+)
+
+// Baseline summarizes a history of values by its median and spread. It uses the median absolute
+// deviation rather than the standard deviation, so the outliers being looked for do not inflate
+// the spread and hide themselves.
+type Baseline struct {
+	Median float64
+	// Spread is the median absolute deviation scaled to estimate a standard deviation, or the
+	// scaled mean absolute deviation when more than half of the values are equal
+	Spread float64
+	Count  int
+}
+
+// Scale factors turning the median and mean absolute deviations into estimates of the standard
+// deviation of normally distributed values
+const (
+	madScale      = 1.4826
+	meanDeviation = 1.2533
+)
+
+// NewBaseline computes the baseline of values. It returns false when there are fewer than
+// MinSamples values or they are all equal, since nothing can then stand out.
+func NewBaseline(values []float64) (Baseline, bool) {
+	if len(values) < MinSamples {
+		return Baseline{}, false
+	}
+
+	middle := median(values)
+
+	deviations := make([]float64, len(values))
+	sum := 0.0
+	for i, value := range values {
+		deviations[i] = math.Abs(value - middle)
+		sum += deviations[i]
+	}
+
+	spread := madScale * median(deviations)
+	if spread == 0 {
+		spread = meanDeviation * sum / float64(len(values))
+	}
+	if spread == 0 {
+		return Baseline{}, false
+	}
+
+	return Baseline{Median: middle, Spread: spread, Count: len(values)}, true
+}
+
+// Score returns the robust z-score of a value: how many estimated standard deviations it lies
+// above (positive) or below (negative) the median
+func (b Baseline) Score(value float64) float64 {
+	return (value - b.Median) / b.Spread
+}
+
+// median returns the middle value, or the mean of the two middle values
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
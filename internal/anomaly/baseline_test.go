@@ -0,0 +1,57 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewBaseline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		ok     bool
+		median float64
+		spread float64
+	}{
+		{name: "too few values", values: []float64{1, 2, 3, 4, 5, 6, 7}},
+		{name: "all equal", values: []float64{10, 10, 10, 10, 10, 10, 10, 10}},
+		{name: "median absolute deviation", values: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9}, ok: true, median: 5, spread: 2 * madScale},
+		{name: "outlier does not widen the spread", values: []float64{1, 2, 3, 4, 5, 6, 7, 8, 1000}, ok: true, median: 5, spread: 2 * madScale},
+		{name: "mostly equal falls back to mean deviation", values: []float64{10, 10, 10, 10, 10, 10, 10, 20}, ok: true, median: 10, spread: meanDeviation * 10 / 8},
+		{name: "even count averages the middle values", values: []float64{1, 2, 3, 4, 6, 7, 8, 9}, ok: true, median: 5, spread: 2.5 * madScale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewBaseline(tt.values)
+			if ok != tt.ok {
+				t.Fatalf("NewBaseline(%v) ok = %v, want %v", tt.values, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got.Median != tt.median || math.Abs(got.Spread-tt.spread) > 1e-9 || got.Count != len(tt.values) {
+				t.Errorf("NewBaseline(%v) = %+v, want median %v spread %v count %d", tt.values, got, tt.median, tt.spread, len(tt.values))
+			}
+		})
+	}
+}
+
+func TestBaselineScore(t *testing.T) {
+	baseline := Baseline{Median: 100, Spread: 10}
+
+	tests := []struct {
+		value float64
+		want  float64
+	}{
+		{value: 100, want: 0},
+		{value: 135, want: 3.5},
+		{value: 80, want: -2},
+	}
+
+	for _, tt := range tests {
+		if got := baseline.Score(tt.value); got != tt.want {
+			t.Errorf("Score(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package anomaly
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Threshold is the robust z-score above which a value is an outlier. 3.5 is the usual cut-off
+// for the modified z-score.
+const Threshold = 3.5
+
+// MinSamples is the fewest values a baseline is computed from
+const MinSamples = 8
+
+// Detect compares every receipt in a workspace's history against baselines computed from that
+// history and returns the outliers:
+//   - receipt totals (paid after discounts) against the workspace's receipts in the same currency
+//   - item counts against the workspace's receipts
+//   - item unit prices against the workspace's items of the same category and currency
+//
+// Only values above the baseline are flagged, since an unusually cheap receipt is rarely a
+// concern. Baselines with fewer than MinSamples values are skipped.
+func Detect(history []domain.ReceiptWithItems) []domain.Anomaly {
+	totals := map[string][]float64{}
+	var counts []float64
+	prices := map[string][]float64{}
+
+	for i := range history {
+		receipt := &history[i]
+		totals[receipt.Currency] = append(totals[receipt.Currency], float64(paid(receipt)))
+		if len(receipt.Items) > 0 {
+			counts = append(counts, float64(len(receipt.Items)))
+		}
+		for _, item := range receipt.Items {
+			if item.Category.Valid {
+				key := priceKey(item.Category.String, receipt.Currency)
+				prices[key] = append(prices[key], float64(item.UnitPrice.Amount))
+			}
+		}
+	}
+
+	totalBaselines := map[string]Baseline{}
+	for currency, values := range totals {
+		if baseline, ok := NewBaseline(values); ok {
+			totalBaselines[currency] = baseline
+		}
+	}
+	countBaseline, hasCountBaseline := NewBaseline(counts)
+	priceBaselines := map[string]Baseline{}
+	for key, values := range prices {
+		if baseline, ok := NewBaseline(values); ok {
+			priceBaselines[key] = baseline
+		}
+	}
+
+	var anomalies []domain.Anomaly
+	for i := range history {
+		receipt := &history[i]
+		currency := sql.NullString{String: receipt.Currency, Valid: true}
+
+		if baseline, ok := totalBaselines[receipt.Currency]; ok {
+			total := domain.NewMoney(paid(receipt), receipt.Currency)
+			if score := baseline.Score(float64(total.Amount)); score > Threshold {
+				typical := domain.NewMoney(int64(math.Round(baseline.Median)), receipt.Currency)
+				anomalies = append(anomalies, domain.Anomaly{
+					WorkspaceID: receipt.WorkspaceID,
+					ReceiptID:   receipt.ID,
+					Kind:        domain.AnomalyTotal,
+					Currency:    currency,
+					Value:       domain.Decimal(total.String()),
+					Baseline:    domain.Decimal(typical.String()),
+					Score:       roundScore(score),
+					Message: fmt.Sprintf("Total of %s %s is well above your typical receipt of %s %s",
+						total, receipt.Currency, typical, receipt.Currency),
+				})
+			}
+		}
+
+		if hasCountBaseline && len(receipt.Items) > 0 {
+			count := len(receipt.Items)
+			if score := countBaseline.Score(float64(count)); score > Threshold {
+				typical := strconv.FormatFloat(countBaseline.Median, 'f', -1, 64)
+				anomalies = append(anomalies, domain.Anomaly{
+					WorkspaceID: receipt.WorkspaceID,
+					ReceiptID:   receipt.ID,
+					Kind:        domain.AnomalyItemCount,
+					Value:       domain.Decimal(strconv.Itoa(count)),
+					Baseline:    domain.Decimal(typical),
+					Score:       roundScore(score),
+					Message:     fmt.Sprintf("%d items is well above your typical %s items per receipt", count, typical),
+				})
+			}
+		}
+
+		for _, item := range receipt.Items {
+			if !item.Category.Valid {
+				continue
+			}
+			baseline, ok := priceBaselines[priceKey(item.Category.String, receipt.Currency)]
+			if !ok {
+				continue
+			}
+			price := domain.NewMoney(item.UnitPrice.Amount, receipt.Currency)
+			if score := baseline.Score(float64(price.Amount)); score > Threshold {
+				typical := domain.NewMoney(int64(math.Round(baseline.Median)), receipt.Currency)
+				anomalies = append(anomalies, domain.Anomaly{
+					WorkspaceID: receipt.WorkspaceID,
+					ReceiptID:   receipt.ID,
+					ItemID:      sql.NullInt64{Int64: int64(item.ID), Valid: true},
+					Kind:        domain.AnomalyItemPrice,
+					Category:    item.Category,
+					Currency:    currency,
+					Value:       domain.Decimal(price.String()),
+					Baseline:    domain.Decimal(typical.String()),
+					Score:       roundScore(score),
+					Message: fmt.Sprintf("%s at %s %s is well above the typical %s %s for %s",
+						item.Name, price, receipt.Currency, typical, receipt.Currency, item.Category.String),
+				})
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// paid returns the amount paid for a receipt in minor units
+func paid(receipt *domain.ReceiptWithItems) int64 {
+	return receipt.TotalSpending.Sub(receipt.TotalDiscount).Amount
+}
+
+// priceKey identifies the item price baseline of a category in a currency
+func priceKey(category, currency string) string {
+	return category + "/" + currency
+}
+
+// roundScore rounds a score to two decimals for display
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,42 @@
+package anomaly
+
+import (
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func receipt(id int, currency string, total, discount int64) domain.ReceiptWithItems {
+	var r domain.ReceiptWithItems
+	r.ID = id
+	r.WorkspaceID = 3
+	r.Currency = currency
+	r.TotalSpending = domain.NewMoney(total, currency)
+	r.TotalDiscount = domain.NewMoney(discount, currency)
+	return r
+}
+
+func TestDetect(t *testing.T) {
+	history := []domain.ReceiptWithItems{
+		receipt(1, "IDR", 50000, 0),
+		receipt(2, "IDR", 52000, 0),
+		receipt(3, "IDR", 48000, 0),
+		receipt(4, "IDR", 51000, 0),
+		receipt(5, "IDR", 49000, 0),
+		receipt(6, "IDR", 53000, 0),
+		receipt(7, "IDR", 47000, 0),
+		// Large before discounts but paid a typical amount
+		receipt(8, "IDR", 500000, 450000),
+		receipt(9, "IDR", 500000, 0),
+		// Alone in its currency, so there is no baseline to compare with
+		receipt(10, "USD", 50000, 0),
+	}
+
+	got := Detect(history)
+	if len(got) != 1 {
+		t.Fatalf("Detect() = %+v, want one anomaly", got)
+	}
+	if got[0].ReceiptID != 9 || got[0].WorkspaceID != 3 || got[0].Kind != domain.AnomalyTotal || got[0].Score <= Threshold {
+		t.Errorf("Detect() = %+v, want a total anomaly on receipt 9 of workspace 3", got[0])
+	}
+}
@@ -32,6 +32,10 @@ type Config struct {
 	MinioSecretKey string
 	MinioBucket    string
 	MinioUseSSL    bool
+	StoragePath    string
+
+	// Document Ingestion
+	PDFRasterizer string
 }
 
 // LoadConfig loads configuration from environment variables
@@ -66,6 +70,10 @@ func LoadConfig() (*Config, error) {
 		MinioSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
 		MinioBucket:    getEnv("MINIO_BUCKET", "receipts"),
 		MinioUseSSL:    minioUseSSL,
+		StoragePath:    getEnv("STORAGE_PATH", "./uploads"),
+
+		// Document Ingestion
+		PDFRasterizer: getEnv("PDF_RASTERIZER", "pdftoppm"),
 	}
 
 	return config, nil
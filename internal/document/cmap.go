@@ -0,0 +1,136 @@
+package document
+
+import (
+	"unicode/utf16"
+)
+
+// cmap is a font's ToUnicode map from character codes to text
+type cmap struct {
+	codeLength int
+	chars      map[uint32]string
+}
+
+// parseCMap reads the code space and the bfchar and bfrange mappings of a ToUnicode CMap
+func parseCMap(data []byte) (*cmap, error) {
+	m := &cmap{chars: map[uint32]string{}}
+	l := &lexer{data: data}
+
+	var operands []interface{}
+	for {
+		token := l.value()
+		if token == nil && l.pos >= len(l.data) {
+			break
+		}
+
+		keyword, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+
+		switch keyword {
+		case "endcodespacerange":
+			if len(operands) >= 1 && m.codeLength == 0 {
+				if low, ok := operands[0].(pdfString); ok {
+					m.codeLength = len(low)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				code, ok1 := operands[i].(pdfString)
+				text, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					m.setLength(len(code))
+					m.chars[codeValue(code)] = utf16Text(text)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].(pdfString)
+				high, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				m.setLength(len(low))
+				m.addRange(codeValue(low), codeValue(high), operands[i+2])
+			}
+		}
+
+		operands = operands[:0]
+	}
+
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	if m.codeLength == 0 {
+		m.codeLength = 1
+	}
+	return m, nil
+}
+
+// setLength takes the code length from the first mapping when the code space is not declared
+func (m *cmap) setLength(length int) {
+	if m.codeLength == 0 {
+		m.codeLength = length
+	}
+}
+
+// addRange maps the codes from low to high either to consecutive text starting at a string,
+// or to the strings of an array
+func (m *cmap) addRange(low, high uint32, destination interface{}) {
+	if high < low || high-low > 0xFFFF {
+		return
+	}
+
+	switch dst := destination.(type) {
+	case pdfString:
+		runes := []rune(utf16Text(dst))
+		if len(runes) == 0 {
+			return
+		}
+		for code := low; code <= high; code++ {
+			shifted := append([]rune(nil), runes...)
+			shifted[len(shifted)-1] += rune(code - low)
+			m.chars[code] = string(shifted)
+		}
+	case pdfArray:
+		for i, item := range dst {
+			if text, ok := item.(pdfString); ok && low+uint32(i) <= high {
+				m.chars[low+uint32(i)] = utf16Text(text)
+			}
+		}
+	}
+}
+
+// decode converts the codes of a shown string to text; unmapped codes are dropped
+func (m *cmap) decode(raw []byte) string {
+	var text []rune
+	for i := 0; i+m.codeLength <= len(raw); i += m.codeLength {
+		if s, ok := m.chars[codeValue(raw[i:i+m.codeLength])]; ok {
+			text = append(text, []rune(s)...)
+		}
+	}
+	return string(text)
+}
+
+// codeValue reads a big-endian character code
+func codeValue(code []byte) uint32 {
+	var value uint32
+	for _, b := range code {
+		value = value<<8 | uint32(b)
+	}
+	return value
+}
+
+// utf16Text decodes big-endian UTF-16 text
+func utf16Text(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+	}
+	if len(data)%2 == 1 {
+		units = append(units, uint16(data[len(data)-1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,186 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// HTMLDocument is the visible content of an HTML document
+type HTMLDocument struct {
+	Title string
+	// Text has a line per block of text, with the cells of a table row on one line
+	Text string
+	// Tables holds the rows of every table without a table inside it, a string per cell, so
+	// that the layout tables around an e-mail receipt are left out
+	Tables [][][]string
+}
+
+// blockElements start a new line of text
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Br: true, atom.Caption: true,
+	atom.Center: true, atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Footer: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Table: true, atom.Tbody: true, atom.Tfoot: true,
+	atom.Thead: true, atom.Tr: true, atom.Ul: true,
+}
+
+// hiddenElements hold no visible text
+var hiddenElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Noscript: true, atom.Object: true, atom.Script: true, atom.Style: true,
+	atom.Svg: true, atom.Template: true,
+}
+
+// ParseHTML reads the visible text and tables of an HTML document. The character set is taken
+// from the document's meta tags, UTF-8 when it declares none.
+func ParseHTML(data []byte) (*HTMLDocument, error) {
+	reader, err := charset.NewReader(bytes.NewReader(data), "text/html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode html: %w", err)
+	}
+
+	root, err := html.Parse(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	doc := &HTMLDocument{}
+	if title := findElement(root, atom.Title); title != nil {
+		doc.Title = textContent(title)
+	}
+
+	w := &textWriter{}
+	renderHTML(root, w)
+	doc.Text = w.String()
+
+	collectTables(root, doc)
+	return doc, nil
+}
+
+// renderHTML writes the visible text under a node, breaking lines at block elements
+func renderHTML(node *html.Node, w *textWriter) {
+	switch node.Type {
+	case html.TextNode:
+		w.write(node.Data)
+		return
+	case html.ElementNode:
+		if hidden(node) {
+			return
+		}
+	}
+
+	block := node.Type == html.ElementNode && blockElements[node.DataAtom]
+	if block {
+		w.newline()
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		renderHTML(child, w)
+	}
+	switch {
+	case block:
+		w.newline()
+	case node.DataAtom == atom.Td || node.DataAtom == atom.Th:
+		w.space()
+	}
+}
+
+// hidden reports whether an element is not shown, such as scripts and the preheader text that
+// e-mails hide with inline styles
+func hidden(node *html.Node) bool {
+	if hiddenElements[node.DataAtom] {
+		return true
+	}
+	for _, attr := range node.Attr {
+		switch attr.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if attr.Val == "true" {
+				return true
+			}
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(attr.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectTables adds the rows of the innermost tables under a node to the document
+func collectTables(node *html.Node, doc *HTMLDocument) {
+	if node.Type == html.ElementNode && hidden(node) {
+		return
+	}
+
+	if node.DataAtom == atom.Table && !containsTable(node) {
+		var rows [][]string
+		for _, row := range findElements(node, atom.Tr) {
+			var cells []string
+			for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+					cells = append(cells, textContent(cell))
+				}
+			}
+			if len(cells) > 0 {
+				rows = append(rows, cells)
+			}
+		}
+		if len(rows) > 0 {
+			doc.Tables = append(doc.Tables, rows)
+		}
+		return
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		collectTables(child, doc)
+	}
+}
+
+// containsTable reports whether a table has another table inside it
+func containsTable(table *html.Node) bool {
+	for child := table.FirstChild; child != nil; child = child.NextSibling {
+		if findElement(child, atom.Table) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the visible text under a node on a single line
+func textContent(node *html.Node) string {
+	w := &textWriter{}
+	renderHTML(node, w)
+	return strings.ReplaceAll(w.String(), "\n", " ")
+}
+
+// findElement returns the first element of a kind at or under a node, or nil
+func findElement(node *html.Node, kind atom.Atom) *html.Node {
+	if node.Type == html.ElementNode && node.DataAtom == kind {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, kind); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findElements returns the elements of a kind under a node, in document order
+func findElements(node *html.Node, kind atom.Atom) []*html.Node {
+	var found []*html.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == kind {
+			found = append(found, child)
+		}
+		found = append(found, findElements(child, kind)...)
+	}
+	return found
+}
@@ -0,0 +1,63 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		title  string
+		text   string
+		tables [][][]string
+	}{
+		{
+			name:  "blocks and table rows are lines",
+			html:  `<html><head><title>Your receipt from Kopi Kenangan</title></head><body><h1>Kopi Kenangan</h1><p>Thanks for your order</p><table><tr><th>Item</th><th>Total</th></tr><tr><td>Latte</td><td>25.000</td></tr></table></body></html>`,
+			title: "Your receipt from Kopi Kenangan",
+			text:  "Kopi Kenangan\nThanks for your order\nItem Total\nLatte 25.000",
+			tables: [][][]string{
+				{{"Item", "Total"}, {"Latte", "25.000"}},
+			},
+		},
+		{
+			name: "hidden text is left out",
+			html: `<body><div style="display: none">Preheader</div><script>var x = 1</script><span hidden>secret</span><p>Visible</p></body>`,
+			text: "Visible",
+		},
+		{
+			name: "only the innermost tables",
+			html: `<table><tr><td><table><tr><td>Latte</td><td>25.000</td></tr></table></td></tr></table>`,
+			text: "Latte 25.000",
+			tables: [][][]string{
+				{{"Latte", "25.000"}},
+			},
+		},
+		{
+			name:  "declared character set",
+			html:  "<html><head><meta charset=\"iso-8859-1\"><title>Caf\xe9</title></head><body>Caf\xe9 au lait</body></html>",
+			title: "Café",
+			text:  "Café au lait",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHTML([]byte(tt.html))
+			if err != nil {
+				t.Fatalf("ParseHTML() error = %v", err)
+			}
+			if got.Title != tt.title {
+				t.Errorf("ParseHTML() title = %q, want %q", got.Title, tt.title)
+			}
+			if got.Text != tt.text {
+				t.Errorf("ParseHTML() text = %q, want %q", got.Text, tt.text)
+			}
+			if !reflect.DeepEqual(got.Tables, tt.tables) {
+				t.Errorf("ParseHTML() tables = %q, want %q", got.Tables, tt.tables)
+			}
+		})
+	}
+}
@@ -0,0 +1,631 @@
+// Package document reads the text of PDF and HTML e-receipts and renders scanned PDFs as images
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxFormDepth bounds how deeply form XObjects drawn by other forms are followed
+	maxFormDepth = 5
+	// maxStreamSize bounds the decoded size of one stream, far above any receipt's content, so
+	// that a small compressed stream cannot expand into gigabytes
+	maxStreamSize = 32 << 20
+	// maxDecodedSize bounds the decoded size of all the streams of a document together
+	maxDecodedSize = 128 << 20
+	// maxOperators bounds the content stream operators run for a document, counting forms each
+	// time they are drawn, so that forms drawing each other many times cannot run for hours
+	maxOperators = 1000000
+)
+
+// errStreamTooLarge is returned for streams decoding to more than the size limits
+var errStreamTooLarge = errors.New("stream decodes to too much data")
+
+// objectPattern matches the start of an indirect object, "12 0 obj"
+var objectPattern = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// pdfObject is an indirect object and, for streams, its raw undecoded data
+type pdfObject struct {
+	value  interface{}
+	stream []byte
+}
+
+// pdfFile is the indirect objects of a PDF, found by scanning the file rather than through
+// its cross-reference table so that damaged files can still be read
+type pdfFile struct {
+	objects map[int]*pdfObject
+	order   []int
+	cmaps   map[pdfRef]*cmap
+	// streams caches decoded stream data by object number
+	streams map[int][]byte
+	// decoded is the size of the stream data decoded so far, limited to maxDecodedSize
+	decoded int
+	// rendering holds the forms being rendered, which may not draw themselves again
+	rendering map[int]bool
+	// operators counts the content stream operators run so far, limited to maxOperators
+	operators int
+}
+
+// PDFText extracts the text layer of a PDF, one string per page with a line per text line.
+// Pages drawn only as images have no text. Encrypted PDFs are not supported.
+func PDFText(data []byte) ([]string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF document")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return nil, fmt.Errorf("encrypted PDF documents are not supported")
+	}
+
+	file, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	pages := file.pages()
+	texts := make([]string, 0, len(pages))
+	for i, page := range pages {
+		w := &textWriter{}
+		content, err := file.pageContent(page.dict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", i+1, err)
+		}
+		if err := file.renderContent(content, page.resources, w, 0); err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", i+1, err)
+		}
+		texts = append(texts, w.String())
+	}
+
+	return texts, nil
+}
+
+// parsePDF collects the indirect objects of a PDF, including those packed in object streams.
+// Later definitions of an object replace earlier ones, as incremental updates do.
+func parsePDF(data []byte) (*pdfFile, error) {
+	file := &pdfFile{
+		objects:   map[int]*pdfObject{},
+		cmaps:     map[pdfRef]*cmap{},
+		streams:   map[int][]byte{},
+		rendering: map[int]bool{},
+	}
+
+	for _, match := range objectPattern.FindAllSubmatchIndex(data, -1) {
+		var num int
+		fmt.Sscanf(string(data[match[2]:match[3]]), "%d", &num)
+
+		l := &lexer{data: data, pos: match[1]}
+		object := &pdfObject{value: l.value()}
+		if l.err != nil {
+			return nil, fmt.Errorf("object %d: %w", num, l.err)
+		}
+
+		save := l.pos
+		if keyword, ok := l.next().(pdfKeyword); ok && keyword == "stream" {
+			object.stream = streamData(data, l.pos)
+		} else {
+			l.pos = save
+		}
+
+		if _, seen := file.objects[num]; !seen {
+			file.order = append(file.order, num)
+		}
+		file.objects[num] = object
+	}
+
+	for _, num := range append([]int(nil), file.order...) {
+		object := file.objects[num]
+		dict, ok := object.value.(pdfDict)
+		if !ok || dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		if err := file.unpackObjectStream(dict, object.stream); err != nil {
+			return nil, fmt.Errorf("object stream %d: %w", num, err)
+		}
+	}
+
+	return file, nil
+}
+
+// streamData returns the data of a stream starting after its "stream" keyword at pos, up to
+// its "endstream" keyword
+func streamData(data []byte, pos int) []byte {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+// unpackObjectStream adds the objects packed in an object stream that are not defined directly.
+// A stream that cannot be decoded is skipped, but offsets outside its data are an error, as is
+// a stream decoding to more than the size limits.
+func (f *pdfFile) unpackObjectStream(dict pdfDict, raw []byte) error {
+	data, err := f.decode(dict, raw)
+	if errors.Is(err, errStreamTooLarge) {
+		return err
+	}
+	if err != nil {
+		return nil
+	}
+
+	// Offsets are checked as floats, since NaN and infinities do not convert to int
+	count := int(number(dict["N"]))
+	firstOffset := number(dict["First"])
+	if !(firstOffset >= 0 && firstOffset <= float64(len(data))) {
+		return fmt.Errorf("invalid first object offset %v", firstOffset)
+	}
+	first := int(firstOffset)
+
+	header := &lexer{data: data[:first]}
+	for i := 0; i < count; i++ {
+		num, ok1 := header.next().(float64)
+		offset, ok2 := header.next().(float64)
+		if !ok1 || !ok2 {
+			return nil
+		}
+		if !(offset >= 0 && offset <= float64(len(data)-first)) {
+			return fmt.Errorf("invalid offset %v of object %v", offset, num)
+		}
+		if _, seen := f.objects[int(num)]; seen || first+int(offset) == len(data) {
+			continue
+		}
+
+		l := &lexer{data: data, pos: first + int(offset)}
+		f.objects[int(num)] = &pdfObject{value: l.value()}
+		if l.err != nil {
+			return fmt.Errorf("object %d: %w", int(num), l.err)
+		}
+		f.order = append(f.order, int(num))
+	}
+
+	return nil
+}
+
+// decode decodes stream data, counting it against the document's decoded size limit
+func (f *pdfFile) decode(dict pdfDict, raw []byte) ([]byte, error) {
+	data, err := decodeStream(dict, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	f.decoded += len(data)
+	if f.decoded > maxDecodedSize {
+		return nil, errStreamTooLarge
+	}
+	return data, nil
+}
+
+// decodeStream decodes stream data with its filters. Only FlateDecode is supported, which is
+// what text content is compressed with; a truncated stream yields what could be decoded. Data
+// decoding to more than maxStreamSize is rejected as a compression bomb.
+func decodeStream(dict pdfDict, raw []byte) ([]byte, error) {
+	var filters []interface{}
+	switch filter := dict["Filter"].(type) {
+	case pdfName:
+		filters = []interface{}{filter}
+	case pdfArray:
+		filters = filter
+	}
+
+	data := raw
+	for _, filter := range filters {
+		switch filter {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			reader, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode stream: %w", err)
+			}
+			decoded, err := io.ReadAll(io.LimitReader(reader, maxStreamSize+1))
+			if len(decoded) > maxStreamSize {
+				return nil, errStreamTooLarge
+			}
+			if err != nil && len(decoded) == 0 {
+				return nil, fmt.Errorf("failed to decode stream: %w", err)
+			}
+			data = decoded
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %v", filter)
+		}
+	}
+
+	return data, nil
+}
+
+// resolve follows an indirect reference to its value
+func (f *pdfFile) resolve(value interface{}) interface{} {
+	for i := 0; i < 10; i++ {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		object, ok := f.objects[ref.num]
+		if !ok {
+			return nil
+		}
+		value = object.value
+	}
+	return nil
+}
+
+// dict resolves a value that should be a dictionary
+func (f *pdfFile) dict(value interface{}) pdfDict {
+	dict, _ := f.resolve(value).(pdfDict)
+	return dict
+}
+
+// stream returns the decoded data of a referenced stream, or nil when there is no such stream
+// or it cannot be decoded. Only exceeding the size limits is an error. Streams are decoded once.
+func (f *pdfFile) stream(value interface{}) ([]byte, error) {
+	ref, ok := value.(pdfRef)
+	if !ok {
+		return nil, nil
+	}
+	if data, ok := f.streams[ref.num]; ok {
+		return data, nil
+	}
+	object, ok := f.objects[ref.num]
+	if !ok || object.stream == nil {
+		return nil, nil
+	}
+	dict, _ := object.value.(pdfDict)
+	data, err := f.decode(dict, object.stream)
+	if errors.Is(err, errStreamTooLarge) {
+		return nil, err
+	}
+	f.streams[ref.num] = data
+	return data, nil
+}
+
+// pdfPage is a page dictionary with the resources it inherits
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages lists the pages in document order by walking the page tree from the catalog, falling
+// back to every page object in file order when there is no usable page tree
+func (f *pdfFile) pages() []pdfPage {
+	var pages []pdfPage
+
+	for _, num := range f.order {
+		if dict, ok := f.objects[num].value.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+			f.walkPages(f.dict(dict["Pages"]), nil, &pages, 0)
+			break
+		}
+	}
+
+	if len(pages) > 0 {
+		return pages
+	}
+
+	nums := append([]int(nil), f.order...)
+	sort.Ints(nums)
+	for _, num := range nums {
+		if dict, ok := f.objects[num].value.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: f.dict(dict["Resources"])})
+		}
+	}
+	return pages
+}
+
+// walkPages appends the pages under a page tree node
+func (f *pdfFile) walkPages(node pdfDict, inherited pdfDict, pages *[]pdfPage, depth int) {
+	if node == nil || depth > 32 {
+		return
+	}
+
+	resources := inherited
+	if own := f.dict(node["Resources"]); own != nil {
+		resources = own
+	}
+
+	if node["Type"] == pdfName("Page") {
+		*pages = append(*pages, pdfPage{dict: node, resources: resources})
+		return
+	}
+
+	kids, _ := f.resolve(node["Kids"]).(pdfArray)
+	for _, kid := range kids {
+		f.walkPages(f.dict(kid), resources, pages, depth+1)
+	}
+}
+
+// pageContent returns the decoded content streams of a page, concatenated
+func (f *pdfFile) pageContent(page pdfDict) ([]byte, error) {
+	var parts []interface{}
+	switch contents := page["Contents"].(type) {
+	case pdfRef:
+		if array, ok := f.resolve(contents).(pdfArray); ok {
+			parts = array
+		} else {
+			return f.stream(contents)
+		}
+	case pdfArray:
+		parts = contents
+	}
+
+	var content []byte
+	for _, part := range parts {
+		data, err := f.stream(part)
+		if err != nil {
+			return nil, err
+		}
+		content = append(append(content, data...), '\n')
+	}
+
+	return content, nil
+}
+
+// fontCMap returns the ToUnicode map of a font in the resources, or nil when it has none
+func (f *pdfFile) fontCMap(resources pdfDict, name pdfName) (*cmap, error) {
+	fonts := f.dict(resources["Font"])
+	ref, ok := fonts[name].(pdfRef)
+	if !ok {
+		return nil, nil
+	}
+
+	if cached, ok := f.cmaps[ref]; ok {
+		return cached, nil
+	}
+
+	var m *cmap
+	if font := f.dict(ref); font != nil {
+		data, err := f.stream(font["ToUnicode"])
+		if err != nil {
+			return nil, fmt.Errorf("font %s: %w", name, err)
+		}
+		if data != nil {
+			if m, err = parseCMap(data); err != nil {
+				return nil, fmt.Errorf("font %s: %w", name, err)
+			}
+		}
+	}
+	f.cmaps[ref] = m
+	return m, nil
+}
+
+// renderContent interprets the text operators of a content stream, writing the text shown.
+// Lines are broken where the text position moves to another line; text placed on the same
+// line, such as the columns of a table, is separated by spaces.
+func (f *pdfFile) renderContent(content []byte, resources pdfDict, w *textWriter, depth int) error {
+	l := &lexer{data: content}
+	var operands []interface{}
+	var font *cmap
+	var lineY, lastY, leading float64
+	placed := false
+
+	// moveTo starts a line at y, or continues the current one when y is the same
+	moveTo := func(y float64) {
+		if placed && math.Abs(y-lastY) > 1 {
+			w.newline()
+		} else {
+			w.space()
+		}
+		lineY, lastY, placed = y, y, true
+	}
+	nextLine := func() {
+		w.newline()
+		lineY -= leading
+		lastY = lineY
+	}
+
+	for {
+		token := l.value()
+		if token == nil && l.pos >= len(l.data) {
+			return l.err
+		}
+
+		keyword, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+
+		f.operators++
+		if f.operators > maxOperators {
+			return fmt.Errorf("more than %d content operators", maxOperators)
+		}
+
+		switch keyword {
+		case "BT":
+			lineY = 0
+		case "ET":
+			w.space()
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					var err error
+					if font, err = f.fontCMap(resources, name); err != nil {
+						return err
+					}
+				}
+			}
+		case "TL":
+			if len(operands) >= 1 {
+				leading = number(operands[len(operands)-1])
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				ty := number(operands[len(operands)-1])
+				if keyword == "TD" {
+					leading = -ty
+				}
+				moveTo(lineY + ty)
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				moveTo(number(operands[5]))
+			}
+		case "T*":
+			nextLine()
+		case "Tj":
+			if len(operands) >= 1 {
+				w.write(decodeText(operands[len(operands)-1], font))
+			}
+		case "'", `"`:
+			nextLine()
+			if len(operands) >= 1 {
+				w.write(decodeText(operands[len(operands)-1], font))
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				array, _ := operands[len(operands)-1].(pdfArray)
+				for _, part := range array {
+					// A large negative adjustment is a gap between words
+					if n, ok := part.(float64); ok && n < -180 {
+						w.space()
+						continue
+					}
+					w.write(decodeText(part, font))
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 && depth < maxFormDepth {
+				if err := f.renderForm(resources, operands[len(operands)-1], w, depth); err != nil {
+					return err
+				}
+				placed = false
+			}
+		case "BI":
+			skipInlineImage(l)
+		}
+
+		operands = operands[:0]
+	}
+}
+
+// renderForm renders the text of a form XObject drawn with Do. A form drawing itself, directly
+// or through other forms, is skipped.
+func (f *pdfFile) renderForm(resources pdfDict, name interface{}, w *textWriter, depth int) error {
+	xobjects := f.dict(resources["XObject"])
+	ref, ok := xobjects[pdfName(fmt.Sprint(name))].(pdfRef)
+	if !ok {
+		return nil
+	}
+
+	form := f.dict(ref)
+	if form == nil || form["Subtype"] != pdfName("Form") || f.rendering[ref.num] {
+		return nil
+	}
+	f.rendering[ref.num] = true
+	defer delete(f.rendering, ref.num)
+
+	formResources := resources
+	if own := f.dict(form["Resources"]); own != nil {
+		formResources = own
+	}
+
+	content, err := f.stream(ref)
+	if err != nil {
+		return fmt.Errorf("form %s: %w", name, err)
+	}
+
+	w.newline()
+	if err := f.renderContent(content, formResources, w, depth+1); err != nil {
+		return fmt.Errorf("form %s: %w", name, err)
+	}
+	w.newline()
+	return nil
+}
+
+// skipInlineImage moves past the data of an inline image, up to its EI operator
+func skipInlineImage(l *lexer) {
+	start := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if start < 0 {
+		l.pos = len(l.data)
+		return
+	}
+
+	pos := l.pos + start + 2
+	for pos < len(l.data) {
+		end := bytes.Index(l.data[pos:], []byte("EI"))
+		if end < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		pos += end
+		if isSpace(l.data[pos-1]) && (pos+2 >= len(l.data) || isSpace(l.data[pos+2])) {
+			l.pos = pos + 2
+			return
+		}
+		pos += 2
+	}
+	l.pos = len(l.data)
+}
+
+// decodeText converts a shown string to text with the font's ToUnicode map, or as Latin-1
+// when the font has none
+func decodeText(value interface{}, font *cmap) string {
+	raw, ok := value.(pdfString)
+	if !ok {
+		return ""
+	}
+	if font != nil {
+		return font.decode(raw)
+	}
+
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// textWriter assembles extracted text into lines, dropping control characters and runs of
+// spaces
+type textWriter struct {
+	lines   []string
+	current strings.Builder
+	gap     bool
+}
+
+// write appends text to the current line
+func (w *textWriter) write(text string) {
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			w.gap = true
+		case unicode.IsPrint(r):
+			if w.gap && w.current.Len() > 0 {
+				w.current.WriteByte(' ')
+			}
+			w.gap = false
+			w.current.WriteRune(r)
+		}
+	}
+}
+
+// space separates the next text from the current line
+func (w *textWriter) space() {
+	w.gap = true
+}
+
+// newline ends the current line
+func (w *textWriter) newline() {
+	if w.current.Len() > 0 {
+		w.lines = append(w.lines, w.current.String())
+		w.current.Reset()
+	}
+	w.gap = false
+}
+
+// String returns the text written so far
+func (w *textWriter) String() string {
+	w.newline()
+	return strings.Join(w.lines, "\n")
+}
@@ -0,0 +1,211 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"strings"
+	"testing"
+)
+
+// testPDF assembles a PDF from object definitions; objects are found by scanning, so it needs
+// no cross-reference table
+func testPDF(objects ...string) []byte {
+	return []byte("%PDF-1.4\n" + strings.Join(objects, "\n") + "\n%%EOF\n")
+}
+
+// testStream defines a stream object with the given dictionary entries
+func testStream(num, entries, data string) string {
+	return num + " 0 obj\n<< " + entries + " >>\nstream\n" + data + "\nendstream\nendobj"
+}
+
+// testFlate compresses stream data with FlateDecode
+func testFlate(data string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.String()
+}
+
+const (
+	testCatalog = "1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj"
+	testPages   = "2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj"
+	testPage    = "3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj"
+	testContent = "BT 10 700 Td (Kopi Kenangan) Tj 0 -14 Td (Total 25.000) Tj ET"
+)
+
+func TestPDFText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{
+			name: "page tree",
+			data: testPDF(testCatalog, testPages, testPage, testStream("4", "", testContent)),
+			want: []string{"Kopi Kenangan\nTotal 25.000"},
+		},
+		{
+			name: "page in an object stream",
+			data: testPDF(
+				testCatalog, testPages,
+				testStream("5", "/Type /ObjStm /N 1 /First 4", "3 0 << /Type /Page /Parent 2 0 R /Contents 4 0 R >>"),
+				testStream("4", "", testContent),
+			),
+			want: []string{"Kopi Kenangan\nTotal 25.000"},
+		},
+		{
+			name: "text in a form",
+			data: testPDF(
+				testCatalog, testPages,
+				"3 0 obj\n<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X1 5 0 R >> >> /Contents 4 0 R >>\nendobj",
+				testStream("4", "", "/X1 Do"),
+				testStream("5", "/Type /XObject /Subtype /Form", testContent),
+			),
+			want: []string{"Kopi Kenangan\nTotal 25.000"},
+		},
+		{
+			name: "compressed content",
+			data: testPDF(testCatalog, testPages, testPage, testStream("4", "/Filter /FlateDecode", testFlate(testContent))),
+			want: []string{"Kopi Kenangan\nTotal 25.000"},
+		},
+		{
+			name: "form drawing itself",
+			data: testPDF(
+				testCatalog, testPages,
+				"3 0 obj\n<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X1 5 0 R >> >> /Contents 4 0 R >>\nendobj",
+				testStream("4", "", "/X1 Do"),
+				testStream("5", "/Type /XObject /Subtype /Form", testContent+strings.Repeat(" /X1 Do", 30)),
+			),
+			want: []string{"Kopi Kenangan\nTotal 25.000"},
+		},
+		{
+			name: "scanned page without text",
+			data: testPDF(testCatalog, testPages, testPage, testStream("4", "", "q 595 0 0 842 0 0 cm /Im1 Do Q")),
+			want: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PDFText(tt.data)
+			if err != nil {
+				t.Fatalf("PDFText() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("PDFText() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("PDFText() page %d = %q, want %q", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPDFTextMalformed(t *testing.T) {
+	deep := strings.Repeat("[", maxNesting+1)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "not a PDF", data: []byte("<html></html>")},
+		{name: "encrypted", data: testPDF("1 0 obj\n<< /Encrypt 2 0 R >>\nendobj")},
+		{
+			name: "negative first offset",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First -4", "3 0 << /Type /Page >>")),
+		},
+		{
+			name: "first offset past the data",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First 400", "3 0 << /Type /Page >>")),
+		},
+		{
+			name: "negative object offset",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First 5", "3 -5 << /Type /Page >>")),
+		},
+		{
+			name: "object offset past the data",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First 6", "3 500 << /Type /Page >>")),
+		},
+		{
+			name: "object offset not a number",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First 6", "3 NaN << /Type /Page >>")),
+		},
+		{
+			name: "compression bomb",
+			data: testPDF(testCatalog, testPages, testPage, testStream("4", "/Filter /FlateDecode", testFlate(strings.Repeat(" ", maxStreamSize+1)))),
+		},
+		{
+			name: "compression bomb in an object stream",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First 4 /Filter /FlateDecode", testFlate("3 0 "+strings.Repeat(" ", maxStreamSize)))),
+		},
+		{
+			name: "forms drawing each other many times",
+			data: testPDF(
+				testCatalog, testPages,
+				"3 0 obj\n<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X1 5 0 R /X2 6 0 R /X3 7 0 R /X4 8 0 R /X5 9 0 R >> >> /Contents 4 0 R >>\nendobj",
+				testStream("4", "", "/X1 Do"),
+				testStream("5", "/Type /XObject /Subtype /Form", strings.Repeat("/X2 Do ", 30)),
+				testStream("6", "/Type /XObject /Subtype /Form", strings.Repeat("/X3 Do ", 30)),
+				testStream("7", "/Type /XObject /Subtype /Form", strings.Repeat("/X4 Do ", 30)),
+				testStream("8", "/Type /XObject /Subtype /Form", strings.Repeat("/X5 Do ", 30)),
+				testStream("9", "/Type /XObject /Subtype /Form", strings.Repeat("(x) Tj ", 30)),
+			),
+		},
+		{
+			name: "deeply nested object",
+			data: testPDF("1 0 obj\n" + deep + "\nendobj"),
+		},
+		{
+			name: "deeply nested object in an object stream",
+			data: testPDF(testStream("5", "/Type /ObjStm /N 1 /First 4", "3 0 "+deep)),
+		},
+		{
+			name: "deeply nested content",
+			data: testPDF(testCatalog, testPages, testPage, testStream("4", "", "BT "+deep+" TJ ET")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := PDFText(tt.data); err == nil {
+				t.Errorf("PDFText() = %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestLexerNesting(t *testing.T) {
+	tests := []struct {
+		depth   int
+		wantErr bool
+	}{
+		{depth: 1},
+		{depth: maxNesting},
+		{depth: maxNesting + 1, wantErr: true},
+		{depth: 100000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		data := strings.Repeat("[", tt.depth) + strings.Repeat("]", tt.depth)
+		l := &lexer{data: []byte(data)}
+		l.value()
+		if (l.err != nil) != tt.wantErr {
+			t.Errorf("value() of %d nested arrays error = %v, want error %v", tt.depth, l.err, tt.wantErr)
+		}
+	}
+}
+
+func TestPDFTextStrayDelimiters(t *testing.T) {
+	data := []byte("%PDF-1.4\n1 0 obj\n" + strings.Repeat(")", 15<<20) + strings.Repeat("> ", 1<<20))
+
+	got, err := PDFText(data)
+	if err != nil {
+		t.Fatalf("PDFText() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("PDFText() = %q, want no pages", got)
+	}
+}
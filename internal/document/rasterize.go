@@ -0,0 +1,79 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// rasterDPI is the resolution pages are rendered at, enough for text to be read from them
+	rasterDPI = 150
+	// rasterTimeout bounds how long rendering a document may take
+	rasterTimeout = 60 * time.Second
+)
+
+// Rasterizer renders the pages of a PDF as PNG images
+type Rasterizer interface {
+	Rasterize(data []byte, maxPages int) ([][]byte, error)
+}
+
+// NewRasterizer creates a rasterizer running poppler's pdftoppm at the given command, or
+// returns nil when the command is not installed
+func NewRasterizer(command string) Rasterizer {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil
+	}
+	return &pdftoppm{path: path}
+}
+
+type pdftoppm struct {
+	path string
+}
+
+// Rasterize renders up to maxPages pages, in page order
+func (r *pdftoppm) Rasterize(data []byte, maxPages int) ([][]byte, error) {
+	dir, err := os.MkdirTemp("", "rasterize-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "document.pdf")
+	if err := os.WriteFile(input, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write document: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rasterTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, r.path, "-png", "-r", strconv.Itoa(rasterDPI), "-l", strconv.Itoa(maxPages), input, filepath.Join(dir, "page"))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to render pages: %w: %s", err, output)
+	}
+
+	// Pages are written as page-1.png, or page-01.png and so on for longer documents, so the
+	// names sort in page order
+	names, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	sort.Strings(names)
+
+	pages := make([][]byte, 0, len(names))
+	for _, name := range names {
+		page, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page: %w", err)
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
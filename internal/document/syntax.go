@@ -0,0 +1,291 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// maxNesting bounds how deeply arrays and dictionaries may nest, so that a hostile file cannot
+// exhaust the stack
+const maxNesting = 100
+
+// PDF objects are represented by these types, numbers as float64, booleans as bool and null
+// as nil
+type (
+	pdfName   string
+	pdfString []byte
+	pdfArray  []interface{}
+	pdfDict   map[pdfName]interface{}
+	pdfRef    struct{ num, gen int }
+	// pdfKeyword is a bare word: an operator in a content stream, or obj, R and the like
+	pdfKeyword string
+)
+
+// pdfDelimiter is a delimiter token such as "<<" or "]"
+type pdfDelimiter string
+
+// lexer reads the tokens of PDF object syntax and content streams. After a syntax error it
+// stops at the end of the data with err set.
+type lexer struct {
+	data  []byte
+	pos   int
+	depth int
+	err   error
+}
+
+// isSpace reports whether c is PDF white space
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+// isDelimiter reports whether c ends a bare word
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips white space and comments
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token, or nil at the end of the data. Tokens are numbers, names,
+// strings, keywords and delimiters; arrays and dictionaries are assembled by value.
+func (l *lexer) next() interface{} {
+	// Stray closing delimiters in a damaged file are skipped
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return nil
+		}
+		c := l.data[l.pos]
+		if c != ')' && (c != '>' || l.pos+1 < len(l.data) && l.data[l.pos+1] == '>') {
+			break
+		}
+		l.pos++
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodeName(l.data[start:l.pos]))
+	case c == '(':
+		return l.literalString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return pdfDelimiter("<<")
+	case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return pdfDelimiter(">>")
+	case c == '<':
+		return l.hexString()
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return pdfDelimiter(string(c))
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if number, err := strconv.ParseFloat(word, 64); err == nil {
+		return number
+	}
+	switch word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return pdfKeyword(word)
+}
+
+// value reads a complete value, assembling arrays, dictionaries and indirect references
+func (l *lexer) value() interface{} {
+	token := l.next()
+
+	switch t := token.(type) {
+	case pdfDelimiter:
+		if t == "[" || t == "<<" {
+			if l.depth == maxNesting {
+				l.err = fmt.Errorf("objects nested more than %d deep", maxNesting)
+				l.pos = len(l.data)
+				return nil
+			}
+			l.depth++
+			defer func() { l.depth-- }()
+		}
+
+		switch t {
+		case "[":
+			var array pdfArray
+			for l.pos < len(l.data) {
+				save := l.pos
+				if d, ok := l.next().(pdfDelimiter); ok && d == "]" {
+					break
+				}
+				l.pos = save
+				array = append(array, l.value())
+			}
+			return array
+		case "<<":
+			dict := pdfDict{}
+			for l.pos < len(l.data) {
+				key := l.next()
+				if d, ok := key.(pdfDelimiter); ok && d == ">>" {
+					break
+				}
+				name, ok := key.(pdfName)
+				if !ok {
+					continue
+				}
+				dict[name] = l.value()
+			}
+			return dict
+		}
+	case float64:
+		// "num gen R" is a reference
+		save := l.pos
+		if gen, ok := l.next().(float64); ok {
+			if keyword, ok := l.next().(pdfKeyword); ok && keyword == "R" {
+				return pdfRef{num: int(t), gen: int(gen)}
+			}
+		}
+		l.pos = save
+	}
+
+	return token
+}
+
+// literalString reads a parenthesized string, resolving escapes
+func (l *lexer) literalString() pdfString {
+	l.pos++
+	var out []byte
+	depth := 1
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					value := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						value = value*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(value)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// hexString reads a string of hex digit pairs in angle brackets
+func (l *lexer) hexString() pdfString {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		value, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		out = append(out, byte(value))
+	}
+	return out
+}
+
+// decodeName resolves #xx escapes in a name
+func decodeName(raw []byte) string {
+	if bytes.IndexByte(raw, '#') < 0 {
+		return string(raw)
+	}
+
+	var out []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if value, err := strconv.ParseUint(string(raw[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(value))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return string(out)
+}
+
+// number returns a numeric value, or 0 when the value is not a number
+func number(value interface{}) float64 {
+	n, _ := value.(float64)
+	return n
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdjustmentType is the kind of a receipt-level charge or correction
+type AdjustmentType string
+
+const (
+	AdjustmentTax         AdjustmentType = "tax"
+	AdjustmentService     AdjustmentType = "service"
+	AdjustmentTip         AdjustmentType = "tip"
+	AdjustmentRounding    AdjustmentType = "rounding"
+	AdjustmentDeliveryFee AdjustmentType = "delivery_fee"
+)
+
+// AdjustmentTypes lists every adjustment type in display order
+var AdjustmentTypes = []AdjustmentType{
+	AdjustmentTax, AdjustmentService, AdjustmentTip, AdjustmentRounding, AdjustmentDeliveryFee,
+}
+
+// Adjustment is a receipt line that is not an item, such as PPN/VAT, a service charge or
+// rounding. Amounts are in the currency of the receipt; rounding may be negative.
+type Adjustment struct {
+	ID            int            `json:"id" db:"id"`
+	UUID          uuid.UUID      `json:"uuid" db:"uuid"`
+	ReceiptID     int            `json:"receipt_id" db:"receipt_id"`
+	Type          AdjustmentType `json:"type" db:"type"`
+	Label         string         `json:"label" db:"label"`
+	Rate          Decimal        `json:"rate" db:"rate"`
+	Amount        Money          `json:"amount" db:"amount"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64          `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// CreateAdjustmentRequest represents adjustment creation request.
+// Rate is the percentage printed on the receipt, e.g. 11 for PPN 11%.
+type CreateAdjustmentRequest struct {
+	Type   AdjustmentType `json:"type" validate:"required,oneof=tax service tip rounding delivery_fee"`
+	Label  string         `json:"label"`
+	Rate   Decimal        `json:"rate"`
+	Amount Decimal        `json:"amount" validate:"required"`
+}
+
+// AdjustmentTotal is the sum of one adjustment type in one currency on one day
+type AdjustmentTotal struct {
+	Currency string
+	Day      time.Time
+	Type     AdjustmentType
+	Amount   Money
+}
+
+// Consistency checks that the items and adjustments add up to the receipt total, and that each
+// percentage adjustment matches its rate, and returns a description of every mismatch.
+// A rate may apply to the item subtotal or to the subtotal plus the adjustments listed before it
+// (e.g. tax charged on top of the service charge); one major currency unit of slack is allowed.
+// Receipts without items are not checked.
+func (r *ReceiptWithItems) Consistency() []string {
+	var warnings []string
+	if len(r.Items) == 0 {
+		return warnings
+	}
+
+	subtotal := NewMoney(0, r.Currency)
+	for _, item := range r.Items {
+		subtotal = subtotal.Add(item.Total)
+	}
+
+	running := subtotal
+	for _, adjustment := range r.Adjustments {
+		if rate, ok := adjustment.Rate.Rat(); ok && adjustment.Rate != "" && adjustment.Type != AdjustmentRounding {
+			percent := new(big.Rat).Quo(rate, big.NewRat(100, 1))
+			if !nearAmount(subtotal.Convert(percent, r.Currency), adjustment.Amount) &&
+				!nearAmount(running.Convert(percent, r.Currency), adjustment.Amount) {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s of %s%% should be about %s but is %s",
+					adjustment.Type, adjustment.Rate, subtotal.Convert(percent, r.Currency), adjustment.Amount,
+				))
+			}
+		}
+		running = running.Add(adjustment.Amount)
+	}
+
+	if running.Amount != r.TotalSpending.Amount {
+		warnings = append(warnings, fmt.Sprintf(
+			"items and adjustments add up to %s %s but the receipt total is %s",
+			running, r.Currency, r.TotalSpending,
+		))
+	}
+
+	return warnings
+}
+
+// nearAmount reports whether two amounts differ by at most one major currency unit
+func nearAmount(a, b Money) bool {
+	slack := exponentScale(CurrencyExponent(a.Currency)).Num().Int64()
+	diff := a.Amount - b.Amount
+	return diff <= slack && -diff <= slack
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnomalyKind is the receipt measure found to be an outlier
+type AnomalyKind string
+
+const (
+	// AnomalyTotal is a receipt total far above the workspace's usual receipts
+	AnomalyTotal AnomalyKind = "total"
+	// AnomalyItemCount is a receipt with far more items than usual
+	AnomalyItemCount AnomalyKind = "item_count"
+	// AnomalyItemPrice is an item priced far above the usual items of its category
+	AnomalyItemPrice AnomalyKind = "item_price"
+)
+
+// AnomalyStatus tracks whether the user has seen an anomaly in their feed
+type AnomalyStatus string
+
+const (
+	AnomalyNew       AnomalyStatus = "new"
+	AnomalySeen      AnomalyStatus = "seen"
+	AnomalyDismissed AnomalyStatus = "dismissed"
+)
+
+// Anomaly flags a receipt, or an item of it, whose value is a statistical outlier against the
+// workspace's history. Value and Baseline are decimal amounts in Currency, or counts when Currency is
+// null; Score is the robust z-score of the value. StoreName and ReceiptDate are read from the
+// receipt for the feed.
+type Anomaly struct {
+	ID            int            `json:"id" db:"id"`
+	UUID          uuid.UUID      `json:"uuid" db:"uuid"`
+	WorkspaceID   int            `json:"workspace_id" db:"workspace_id"`
+	ReceiptID     int            `json:"receipt_id" db:"receipt_id"`
+	ItemID        sql.NullInt64  `json:"item_id" db:"item_id"`
+	Kind          AnomalyKind    `json:"kind" db:"kind"`
+	Category      sql.NullString `json:"category" db:"category"`
+	Currency      sql.NullString `json:"currency" db:"currency"`
+	Value         Decimal        `json:"value" db:"value"`
+	Baseline      Decimal        `json:"baseline" db:"baseline"`
+	Score         float64        `json:"score" db:"score"`
+	Message       string         `json:"message" db:"message"`
+	Status        AnomalyStatus  `json:"status" db:"status"`
+	StoreName     sql.NullString `json:"store_name" db:"-"`
+	ReceiptDate   sql.NullTime   `json:"receipt_date" db:"-"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64          `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64          `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// AnomalyDetectionResult reports a detection run: the receipts checked, the anomalies found and
+// how many of them were new
+type AnomalyDetectionResult struct {
+	Checked int `json:"checked"`
+	Found   int `json:"found"`
+	Created int `json:"created"`
+}
+
+// UpdateAnomalyRequest marks an anomaly as seen or dismisses it from the feed
+type UpdateAnomalyRequest struct {
+	Status AnomalyStatus `json:"status" validate:"required,oneof=new seen dismissed"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttachmentKind distinguishes further pages of a receipt from supporting documents such as
+// invoices
+type AttachmentKind string
+
+const (
+	AttachmentPage     AttachmentKind = "page"
+	AttachmentDocument AttachmentKind = "document"
+)
+
+// Attachment is a file added to a receipt besides its image. Pages are ordered by position
+// after the receipt image; documents are ordered among themselves.
+type Attachment struct {
+	ID            int            `json:"id" db:"id"`
+	UUID          uuid.UUID      `json:"uuid" db:"uuid"`
+	ReceiptID     int            `json:"receipt_id" db:"receipt_id"`
+	UserID        int            `json:"user_id" db:"user_id"`
+	Kind          AttachmentKind `json:"kind" db:"kind"`
+	Position      int            `json:"position" db:"position"`
+	Filename      string         `json:"filename" db:"filename"`
+	ContentType   string         `json:"content_type" db:"content_type"`
+	Size          int            `json:"size" db:"size"`
+	Checksum      string         `json:"checksum" db:"checksum"`
+	StorageKey    string         `json:"-" db:"storage_key"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64          `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// PageExtractionRequest is the extraction result of each page of a receipt, in page order,
+// starting with the receipt image
+type PageExtractionRequest struct {
+	Pages []CreateReceiptRequest `json:"pages" validate:"required,min=1,max=20"`
+}
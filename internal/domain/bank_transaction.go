@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BankTransaction is one line of an imported bank or card statement.
+// Amount is signed as on the statement: money leaving the account is negative.
+type BankTransaction struct {
+	ID            int             `json:"id" db:"id"`
+	UUID          uuid.UUID       `json:"uuid" db:"uuid"`
+	UserID        int             `json:"user_id" db:"user_id"`
+	Account       string          `json:"account" db:"account"`
+	ExternalID    string          `json:"external_id" db:"external_id"`
+	Date          time.Time       `json:"date" db:"date"`
+	Description   string          `json:"description" db:"description"`
+	Amount        Money           `json:"amount" db:"amount"`
+	Currency      string          `json:"currency" db:"currency"`
+	ReceiptID     sql.NullInt64   `json:"receipt_id" db:"receipt_id"`
+	MatchScore    sql.NullFloat64 `json:"match_score" db:"match_score"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64           `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// MatchTransactionRequest pairs a bank transaction with a receipt by hand
+type MatchTransactionRequest struct {
+	ReceiptID int `json:"receipt_id" validate:"required,min=1"`
+}
+
+// StatementImportReport summarizes a bank statement import. Transactions imported before
+// are skipped, so the same statement can be imported again safely.
+type StatementImportReport struct {
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	Skipped  int               `json:"skipped"`
+	Failed   int               `json:"failed"`
+	Rows     []ImportRowResult `json:"rows"`
+}
+
+// ReconciliationMatch is a bank transaction paired with the receipt it paid for
+type ReconciliationMatch struct {
+	Transaction BankTransaction `json:"transaction"`
+	Receipt     Receipt         `json:"receipt"`
+}
+
+// ReconciliationReport lists matched pairs and what is left unmatched on either side
+type ReconciliationReport struct {
+	Matched               []ReconciliationMatch `json:"matched"`
+	UnmatchedReceipts     []Receipt             `json:"unmatched_receipts"`
+	UnmatchedTransactions []BankTransaction     `json:"unmatched_transactions"`
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"math/big"
+	"sort"
+	"time"
+)
+
+// DefaultCurrency is used when neither the receipt nor the user specify a currency
+const DefaultCurrency = "IDR"
+
+type ExchangeRate struct {
+	ID            int       `json:"id" db:"id"`
+	BaseCurrency  string    `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency"`
+	Rate          Decimal   `json:"rate" db:"rate"`
+	RateDate      time.Time `json:"rate_date" db:"rate_date"`
+	Source        string    `json:"source" db:"source"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// CurrencyTotal is the spending of one currency and payment method on one day.
+// PaymentMethod is "" for receipts without a known payment method.
+type CurrencyTotal struct {
+	Currency      string
+	PaymentMethod string
+	Day           time.Time
+	Receipts      int
+	TotalSpending Money
+	TotalDiscount Money
+}
+
+// datedRate is an exact rate valid from its day
+type datedRate struct {
+	day  time.Time
+	rate *big.Rat
+}
+
+// RateTable looks up historical exchange rates
+type RateTable struct {
+	pairs map[[2]string][]datedRate
+}
+
+// NewRateTable indexes rates by currency pair and date. Rates that are not positive decimals are ignored.
+func NewRateTable(rates []ExchangeRate) *RateTable {
+	t := &RateTable{pairs: map[[2]string][]datedRate{}}
+	for _, rate := range rates {
+		value, ok := rate.Rate.Rat()
+		if !ok || value.Sign() <= 0 {
+			continue
+		}
+		key := [2]string{rate.BaseCurrency, rate.QuoteCurrency}
+		t.pairs[key] = append(t.pairs[key], datedRate{day: rate.RateDate, rate: value})
+	}
+	for _, list := range t.pairs {
+		sort.Slice(list, func(i, j int) bool { return list[i].day.Before(list[j].day) })
+	}
+	return t
+}
+
+// Rate returns how many units of to one unit of from was worth on day, using the latest rate
+// published on or before that day. Direct, inverse and cross rates through a shared base
+// currency (e.g. EUR for ECB rates) are tried in that order.
+func (t *RateTable) Rate(from, to string, day time.Time) (*big.Rat, bool) {
+	if from == to {
+		return big.NewRat(1, 1), true
+	}
+
+	if rate, ok := t.lookup(from, to, day); ok {
+		return rate, true
+	}
+	if rate, ok := t.lookup(to, from, day); ok {
+		return new(big.Rat).Inv(rate), true
+	}
+
+	for key := range t.pairs {
+		base := key[0]
+		if key[1] != from {
+			continue
+		}
+		fromRate, okFrom := t.lookup(base, from, day)
+		toRate, okTo := t.lookup(base, to, day)
+		if okFrom && okTo {
+			return new(big.Rat).Quo(toRate, fromRate), true
+		}
+	}
+
+	return nil, false
+}
+
+// Convert converts an amount to another currency at the rate of day
+func (t *RateTable) Convert(amount Money, to string, day time.Time) (Money, bool) {
+	rate, ok := t.Rate(amount.Currency, to, day)
+	if !ok {
+		return Money{}, false
+	}
+	return amount.Convert(rate, to), true
+}
+
+// lookup finds the latest direct rate on or before day
+func (t *RateTable) lookup(base, quote string, day time.Time) (*big.Rat, bool) {
+	list := t.pairs[[2]string{base, quote}]
+	i := sort.Search(len(list), func(i int) bool { return list[i].day.After(day) })
+	if i == 0 {
+		return nil, false
+	}
+	return list[i-1].rate, true
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExpenseReportStatus is the stage of an expense report in the reimbursement workflow
+type ExpenseReportStatus string
+
+const (
+	// ExpenseDraft reports are being put together by their submitter
+	ExpenseDraft ExpenseReportStatus = "draft"
+	// ExpenseSubmitted reports await every approver's decision
+	ExpenseSubmitted ExpenseReportStatus = "submitted"
+	// ExpenseApproved reports were approved by every approver and await payment
+	ExpenseApproved ExpenseReportStatus = "approved"
+	// ExpenseRejected reports were rejected by an approver and may be changed and resubmitted
+	ExpenseRejected ExpenseReportStatus = "rejected"
+	// ExpensePaid reports have been reimbursed
+	ExpensePaid ExpenseReportStatus = "paid"
+)
+
+// Editable reports whether the submitter may still change the report and its receipts
+func (s ExpenseReportStatus) Editable() bool {
+	return s == ExpenseDraft || s == ExpenseRejected
+}
+
+// ApprovalDecision is an approver's decision on a submitted report
+type ApprovalDecision string
+
+const (
+	ApprovalPending  ApprovalDecision = "pending"
+	ApprovalApproved ApprovalDecision = "approved"
+	ApprovalRejected ApprovalDecision = "rejected"
+)
+
+// ExpenseReport groups business receipts a member asks to be reimbursed for. Once submitted,
+// its receipts are locked from edits until the report is rejected.
+type ExpenseReport struct {
+	ID            int                 `json:"id" db:"id"`
+	UUID          uuid.UUID           `json:"uuid" db:"uuid"`
+	WorkspaceID   int                 `json:"workspace_id" db:"workspace_id"`
+	UserID        int                 `json:"user_id" db:"user_id"`
+	Title         string              `json:"title" db:"title"`
+	Description   string              `json:"description" db:"description"`
+	Status        ExpenseReportStatus `json:"status" db:"status"`
+	ReceiptCount  int                 `json:"receipt_count" db:"-"`
+	Approvers     []ExpenseApprover   `json:"approvers" db:"-"`
+	SubmittedAt   sql.NullTime        `json:"submitted_at" db:"submitted_at"`
+	DecidedAt     sql.NullTime        `json:"decided_at" db:"decided_at"`
+	PaidAt        sql.NullTime        `json:"paid_at" db:"paid_at"`
+	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64               `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64               `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// Approver returns the report's assignment of the user, or nil if they are not an approver
+func (r *ExpenseReport) Approver(userID int) *ExpenseApprover {
+	for i := range r.Approvers {
+		if r.Approvers[i].UserID == userID {
+			return &r.Approvers[i]
+		}
+	}
+	return nil
+}
+
+// ExpenseApprover is a member assigned to approve an expense report
+type ExpenseApprover struct {
+	UserID    int              `json:"user_id" db:"user_id"`
+	FullName  string           `json:"full_name" db:"-"`
+	Decision  ApprovalDecision `json:"decision" db:"decision"`
+	DecidedAt sql.NullTime     `json:"decided_at" db:"decided_at"`
+}
+
+// ExpenseComment is a comment on an expense report by its submitter or an approver
+type ExpenseComment struct {
+	ID            int       `json:"id" db:"id"`
+	UUID          uuid.UUID `json:"uuid" db:"uuid"`
+	ReportID      int       `json:"report_id" db:"report_id"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	FullName      string    `json:"full_name" db:"-"`
+	Body          string    `json:"body" db:"body"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// ExpenseReportWithReceipts is an expense report with its receipts and comments
+type ExpenseReportWithReceipts struct {
+	ExpenseReport
+	Receipts []Receipt        `json:"receipts"`
+	Comments []ExpenseComment `json:"comments"`
+}
+
+// ExpenseReportLine is one receipt of an expense report summary. Amount is what was paid in the
+// receipt currency; Converted is the same amount in the submitter's home currency, or null when
+// there is no exchange rate for the purchase day.
+type ExpenseReportLine struct {
+	ReceiptID int       `json:"receipt_id"`
+	Date      time.Time `json:"date"`
+	StoreName string    `json:"store_name"`
+	Amount    Money     `json:"amount"`
+	Converted *Money    `json:"converted"`
+}
+
+// ExpenseReportSummary totals an expense report for reimbursement. Total is in the submitter's
+// home currency; receipts without an exchange rate are left out of it and listed per currency
+// under Unconverted.
+type ExpenseReportSummary struct {
+	ReportID     int                 `json:"report_id"`
+	Title        string              `json:"title"`
+	Status       ExpenseReportStatus `json:"status"`
+	Submitter    string              `json:"submitter"`
+	ReceiptCount int                 `json:"receipt_count"`
+	From         *time.Time          `json:"from"`
+	To           *time.Time          `json:"to"`
+	ByCurrency   []Money             `json:"by_currency"`
+	Total        Money               `json:"total"`
+	Unconverted  []Money             `json:"unconverted"`
+	Lines        []ExpenseReportLine `json:"lines"`
+}
+
+// CreateExpenseReportRequest represents expense report creation or update request
+type CreateExpenseReportRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+// ExpenseReceiptsRequest adds receipts to an expense report
+type ExpenseReceiptsRequest struct {
+	ReceiptIDs []int `json:"receipt_ids" validate:"required,min=1,dive,min=1"`
+}
+
+// ExpenseApproversRequest replaces the approvers of an expense report
+type ExpenseApproversRequest struct {
+	UserIDs []int `json:"user_ids" validate:"required,min=1,dive,min=1"`
+}
+
+// ReviewExpenseReportRequest approves or rejects a report, optionally with a comment
+type ReviewExpenseReportRequest struct {
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+// CreateExpenseCommentRequest represents comment creation request
+type CreateExpenseCommentRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+// ImportRowResult is the outcome of importing one receipt
+type ImportRowResult struct {
+	Row         int    `json:"row"`
+	Success     bool   `json:"success"`
+	ReceiptID   int    `json:"receipt_id,omitempty"`
+	ReceiptUUID string `json:"receipt_uuid,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ImportReport summarizes a bulk receipt import
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
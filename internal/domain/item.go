@@ -1,29 +1,47 @@
 package domain
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Item is a line of a receipt. Its amounts are in the currency of the receipt.
 type Item struct {
-	ID            int       `json:"id" db:"id"`
-	UUID          uuid.UUID `json:"uuid" db:"uuid"`
-	ReceiptID     int       `json:"receipt_id" db:"receipt_id"`
-	Name          string    `json:"name" db:"name"`
-	UnitPrice     int       `json:"unit_price" db:"unit_price"`
-	Quantity      int       `json:"quantity" db:"quantity"`
-	Price         int       `json:"price" db:"price"`
-	Total         int       `json:"total" db:"total"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
+	ID            int            `json:"id" db:"id"`
+	UUID          uuid.UUID      `json:"uuid" db:"uuid"`
+	ReceiptID     int            `json:"receipt_id" db:"receipt_id"`
+	ProductID     sql.NullInt64  `json:"product_id" db:"product_id"`
+	Name          string         `json:"name" db:"name"`
+	UnitPrice     Money          `json:"unit_price" db:"unit_price"`
+	Quantity      int            `json:"quantity" db:"quantity"`
+	Price         Money          `json:"price" db:"price"`
+	Total         Money          `json:"total" db:"total"`
+	Category      sql.NullString `json:"category" db:"category"`
+	Notes         sql.NullString `json:"notes" db:"notes"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64          `json:"created_at_unix" db:"created_at_unix"`
 }
 
 // CreateItemRequest represents item creation request
 type CreateItemRequest struct {
-	Name      string `json:"name" validate:"required"`
-	UnitPrice int    `json:"unit_price" validate:"required,min=0"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
-	Price     int    `json:"price" validate:"required,min=0"`
-	Total     int    `json:"total" validate:"required,min=0"`
+	Name      string  `json:"name" validate:"required"`
+	UnitPrice Decimal `json:"unit_price" validate:"required"`
+	Quantity  int     `json:"quantity" validate:"required,min=1"`
+	Price     Decimal `json:"price" validate:"required"`
+	Total     Decimal `json:"total" validate:"required"`
+	Category  string  `json:"category"`
+	Notes     string  `json:"notes" validate:"max=5000"`
+}
+
+// UpdateNotesRequest replaces the notes of a receipt or item; an empty string clears them
+type UpdateNotesRequest struct {
+	Notes string `json:"notes" validate:"max=5000"`
+}
+
+// MatchedItem is an item matched by search; Highlight wraps the matching terms in <mark> tags
+type MatchedItem struct {
+	Item
+	Highlight string `json:"highlight"`
 }
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merchant is the canonical store behind the free-text store names of a workspace's receipts.
+// UserID is the member whose receipt created it.
+// Aliases are the normalized store names that resolve to the merchant.
+type Merchant struct {
+	ID            int            `json:"id" db:"id"`
+	UUID          uuid.UUID      `json:"uuid" db:"uuid"`
+	UserID        int            `json:"user_id" db:"user_id"`
+	WorkspaceID   int            `json:"workspace_id" db:"workspace_id"`
+	Name          string         `json:"name" db:"name"`
+	Address       sql.NullString `json:"address" db:"address"`
+	Phone         sql.NullInt64  `json:"phone" db:"phone"`
+	Aliases       []string       `json:"aliases" db:"-"`
+	ReceiptCount  int            `json:"receipt_count" db:"-"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64          `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64          `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// UpdateMerchantRequest represents merchant update request
+type UpdateMerchantRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Address string `json:"address" validate:"max=255"`
+	Phone   *int64 `json:"phone"`
+}
+
+// MergeMerchantsRequest merges the source merchants into the target merchant
+type MergeMerchantsRequest struct {
+	TargetID  int   `json:"target_id" validate:"required,min=1"`
+	SourceIDs []int `json:"source_ids" validate:"required,min=1,dive,min=1"`
+}
@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not 1/100.
+// Keep in sync with the currency_exponent SQL function.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns the number of decimal places of a currency's minor unit
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// Money is an exact amount in the minor units of its currency, e.g. {1050, "USD"} is $10.50.
+// It is stored as BIGINT and encoded in JSON as a decimal number.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney creates an amount of minor units
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal string such as "10.50" into minor units of the currency.
+// It fails rather than rounds when the value has more decimals than the currency allows.
+func ParseMoney(value, currency string) (Money, error) {
+	negative, whole, fraction, err := splitDecimal(value)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent := CurrencyExponent(currency)
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%s has more than %d decimal places for %s", value, exponent, currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	digits := strings.TrimLeft(whole+fraction, "0")
+	if digits == "" {
+		return Money{Currency: currency}, nil
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("amount out of range: %s", value)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// String formats the amount as a decimal, e.g. "10.50"
+func (m Money) String() string {
+	digits := strconv.FormatUint(absInt64(m.Amount), 10)
+
+	exponent := CurrencyExponent(m.Currency)
+	if exponent > 0 {
+		if len(digits) <= exponent {
+			digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+	}
+
+	if m.Amount < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + other. It panics if both have a currency and they differ; an amount
+// without a currency, such as the zero Money, takes the other's.
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: sameCurrency(m, other)}
+}
+
+// Sub returns m - other. It panics if both have a currency and they differ; an amount
+// without a currency, such as the zero Money, takes the other's.
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: sameCurrency(m, other)}
+}
+
+// sameCurrency returns the currency of the result of adding a and b
+func sameCurrency(a, b Money) string {
+	switch {
+	case a.Currency == "":
+		return b.Currency
+	case b.Currency == "" || strings.EqualFold(a.Currency, b.Currency):
+		return a.Currency
+	}
+	panic(fmt.Sprintf("money: cannot combine %s and %s amounts", a.Currency, b.Currency))
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Div divides the amount into n parts, rounding half away from zero
+func (m Money) Div(n int64) Money {
+	if n == 0 {
+		return Money{Currency: m.Currency}
+	}
+	return Money{Amount: roundRat(new(big.Rat).SetFrac64(m.Amount, n)), Currency: m.Currency}
+}
+
+// Convert converts the amount to currency at rate (units of currency per unit of m.Currency),
+// rounding half away from zero to the target minor unit
+func (m Money) Convert(rate *big.Rat, currency string) Money {
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	value.Mul(value, exponentScale(CurrencyExponent(currency)-CurrencyExponent(m.Currency)))
+	return Money{Amount: roundRat(value), Currency: currency}
+}
+
+// WithCurrency re-expresses the same value in another currency's minor units, rounding when
+// the new currency has fewer decimals
+func (m Money) WithCurrency(currency string) Money {
+	return m.Convert(big.NewRat(1, 1), currency)
+}
+
+// MarshalJSON encodes the amount as a JSON number without going through float64
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Scan reads the minor units from a BIGINT (or NUMERIC aggregate) column.
+// The currency is not part of the column and must be set by the caller.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		m.Amount = 0
+	case int64:
+		m.Amount = v
+	case []byte:
+		return m.scanText(string(v))
+	case string:
+		return m.scanText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+// scanText parses an integer amount returned as text
+func (m *Money) scanText(text string) error {
+	amount, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid money amount: %s", text)
+	}
+	m.Amount = amount
+	return nil
+}
+
+// Value stores the minor units
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+// Decimal is an exact decimal number as written in a request or file, e.g. "10.50".
+// It decodes from a JSON number or string without passing through float64.
+type Decimal string
+
+// ParseDecimal validates a decimal string
+func ParseDecimal(value string) (Decimal, error) {
+	value = strings.TrimSpace(value)
+	if _, _, _, err := splitDecimal(value); err != nil {
+		return "", err
+	}
+	return Decimal(value), nil
+}
+
+// UnmarshalJSON accepts a JSON number, a numeric string or null
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		*d = ""
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			*d = ""
+			return nil
+		}
+	}
+
+	value, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+// MarshalJSON encodes the decimal as a JSON number, or null when empty
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return []byte("null"), nil
+	}
+	return []byte(d), nil
+}
+
+// Rat returns the exact value of the decimal
+func (d Decimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+// splitDecimal splits a plain decimal such as "-12.50" into its sign, whole and fraction digits
+func splitDecimal(value string) (negative bool, whole, fraction string, err error) {
+	text := strings.TrimSpace(value)
+	if strings.HasPrefix(text, "-") {
+		negative = true
+		text = text[1:]
+	} else {
+		text = strings.TrimPrefix(text, "+")
+	}
+
+	whole, fraction, _ = strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return false, "", "", fmt.Errorf("invalid decimal: %q", value)
+	}
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return false, "", "", fmt.Errorf("invalid decimal: %q", value)
+		}
+	}
+
+	return negative, whole, fraction, nil
+}
+
+// exponentScale returns 10^exponent as a rational
+func exponentScale(exponent int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(exponent))), nil)
+	if exponent < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), scale)
+	}
+	return new(big.Rat).SetInt(scale)
+}
+
+// roundRat rounds a rational to the nearest integer, half away from zero
+func roundRat(value *big.Rat) int64 {
+	num := new(big.Int).Abs(value.Num())
+	quotient, remainder := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	return quotient.Int64()
+}
+
+func absInt64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{name: "two decimals", value: "10.50", currency: "USD", want: Money{1050, "USD"}},
+		{name: "short fraction", value: "10.5", currency: "USD", want: Money{1050, "USD"}},
+		{name: "no whole part", value: ".5", currency: "USD", want: Money{50, "USD"}},
+		{name: "plus sign", value: "+7", currency: "USD", want: Money{700, "USD"}},
+		{name: "negative", value: "-3.25", currency: "USD", want: Money{-325, "USD"}},
+		{name: "zero", value: "0.00", currency: "USD", want: Money{0, "USD"}},
+		{name: "surrounding space", value: " 1.99 ", currency: "EUR", want: Money{199, "EUR"}},
+		{name: "no decimals", value: "1000", currency: "JPY", want: Money{1000, "JPY"}},
+		{name: "trailing zero decimals", value: "1.0", currency: "JPY", want: Money{1, "JPY"}},
+		{name: "lowercase currency", value: "5", currency: "jpy", want: Money{5, "jpy"}},
+		{name: "too many decimals", value: "1.5", currency: "JPY", wantErr: true},
+		{name: "three decimals", value: "1.234", currency: "KWD", want: Money{1234, "KWD"}},
+		{name: "negative three decimals", value: "-0.005", currency: "KWD", want: Money{-5, "KWD"}},
+		{name: "more than three decimals", value: "1.2345", currency: "KWD", wantErr: true},
+		{name: "not rounded", value: "0.005", currency: "USD", wantErr: true},
+		{name: "not a number", value: "abc", currency: "USD", wantErr: true},
+		{name: "empty", value: "", currency: "USD", wantErr: true},
+		{name: "out of range", value: "99999999999999999999", currency: "USD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.value, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney(%q, %q) error = %v, wantErr %v", tt.value, tt.currency, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ParseMoney(%q, %q) = %+v, want %+v", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{1050, "USD"}, "10.50"},
+		{Money{5, "USD"}, "0.05"},
+		{Money{-5, "USD"}, "-0.05"},
+		{Money{0, "USD"}, "0.00"},
+		{Money{1000, "JPY"}, "1000"},
+		{Money{-1, "JPY"}, "-1"},
+		{Money{1234, "KWD"}, "1.234"},
+		{Money{7, "KWD"}, "0.007"},
+		{Money{-1000, "KWD"}, "-1.000"},
+		{Money{math.MinInt64, "USD"}, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		rate     *big.Rat
+		currency string
+		want     Money
+	}{
+		{name: "to fewer decimals", money: Money{1000, "USD"}, rate: big.NewRat(150, 1), currency: "JPY", want: Money{1500, "JPY"}},
+		{name: "to more decimals", money: Money{1000, "USD"}, rate: big.NewRat(3, 10), currency: "KWD", want: Money{3000, "KWD"}},
+		{name: "from fewer decimals", money: Money{1500, "JPY"}, rate: big.NewRat(1, 150), currency: "USD", want: Money{1000, "USD"}},
+		{name: "half rounds up", money: Money{1, "USD"}, rate: big.NewRat(1, 2), currency: "EUR", want: Money{1, "EUR"}},
+		{name: "negative half rounds down", money: Money{-1, "USD"}, rate: big.NewRat(1, 2), currency: "EUR", want: Money{-1, "EUR"}},
+		{name: "below half rounds to zero", money: Money{1, "USD"}, rate: big.NewRat(1, 3), currency: "EUR", want: Money{0, "EUR"}},
+		{name: "half across exponents", money: Money{1, "USD"}, rate: big.NewRat(150, 1), currency: "JPY", want: Money{2, "JPY"}},
+		{name: "negative half across exponents", money: Money{-1, "USD"}, rate: big.NewRat(150, 1), currency: "JPY", want: Money{-2, "JPY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Convert(tt.rate, tt.currency); got != tt.want {
+				t.Errorf("%+v.Convert(%s, %q) = %+v, want %+v", tt.money, tt.rate, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyWithCurrency(t *testing.T) {
+	tests := []struct {
+		money    Money
+		currency string
+		want     Money
+	}{
+		{Money{1050, "USD"}, "JPY", Money{11, "JPY"}},
+		{Money{1049, "USD"}, "JPY", Money{10, "JPY"}},
+		{Money{-1050, "USD"}, "JPY", Money{-11, "JPY"}},
+		{Money{1235, "KWD"}, "USD", Money{124, "USD"}},
+		{Money{1234, "KWD"}, "USD", Money{123, "USD"}},
+		{Money{5, "USD"}, "KWD", Money{50, "KWD"}},
+		{Money{12, "JPY"}, "EUR", Money{1200, "EUR"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.WithCurrency(tt.currency); got != tt.want {
+			t.Errorf("%+v.WithCurrency(%q) = %+v, want %+v", tt.money, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyDiv(t *testing.T) {
+	tests := []struct {
+		money Money
+		n     int64
+		want  Money
+	}{
+		{Money{100, "USD"}, 3, Money{33, "USD"}},
+		{Money{5, "USD"}, 2, Money{3, "USD"}},
+		{Money{-5, "USD"}, 2, Money{-3, "USD"}},
+		{Money{7, "JPY"}, 0, Money{0, "JPY"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Div(tt.n); got != tt.want {
+			t.Errorf("%+v.Div(%d) = %+v, want %+v", tt.money, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyAddSub(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Money
+		sum      Money
+		diff     Money
+		mismatch bool
+	}{
+		{name: "same currency", a: Money{150, "USD"}, b: Money{50, "USD"}, sum: Money{200, "USD"}, diff: Money{100, "USD"}},
+		{name: "currency case", a: Money{1, "USD"}, b: Money{1, "usd"}, sum: Money{2, "USD"}, diff: Money{0, "USD"}},
+		{name: "zero money takes the currency", a: Money{}, b: Money{5, "JPY"}, sum: Money{5, "JPY"}, diff: Money{-5, "JPY"}},
+		{name: "amount without currency", a: Money{5, "KWD"}, b: Money{1, ""}, sum: Money{6, "KWD"}, diff: Money{4, "KWD"}},
+		{name: "different currencies", a: Money{1, "USD"}, b: Money{1, "EUR"}, mismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mismatch {
+				for _, op := range []func(Money) Money{tt.a.Add, tt.a.Sub} {
+					func() {
+						defer func() {
+							if recover() == nil {
+								t.Errorf("combining %+v and %+v did not panic", tt.a, tt.b)
+							}
+						}()
+						op(tt.b)
+					}()
+				}
+				return
+			}
+			if got := tt.a.Add(tt.b); got != tt.sum {
+				t.Errorf("%+v.Add(%+v) = %+v, want %+v", tt.a, tt.b, got, tt.sum)
+			}
+			if got := tt.a.Sub(tt.b); got != tt.diff {
+				t.Errorf("%+v.Sub(%+v) = %+v, want %+v", tt.a, tt.b, got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    int64
+		wantErr bool
+	}{
+		{name: "null", src: nil, want: 0},
+		{name: "bigint", src: int64(42), want: 42},
+		{name: "numeric bytes", src: []byte("-17"), want: -17},
+		{name: "numeric string", src: "123", want: 123},
+		{name: "fraction", src: "1.5", wantErr: true},
+		{name: "float", src: 1.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money{Amount: 99, Currency: "USD"}
+			err := m.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan(%v) error = %v, wantErr %v", tt.src, err, tt.wantErr)
+			}
+			if err == nil && (m.Amount != tt.want || m.Currency != "USD") {
+				t.Errorf("Scan(%v) = %+v, want {Amount:%d Currency:USD}", tt.src, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyValue(t *testing.T) {
+	for _, m := range []Money{{1050, "USD"}, {-3, "JPY"}, {0, "KWD"}} {
+		got, err := m.Value()
+		if err != nil || got != m.Amount {
+			t.Errorf("%+v.Value() = %v, %v, want %d", m, got, err, m.Amount)
+		}
+	}
+}
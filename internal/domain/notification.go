@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationKind is the event a notification is about
+type NotificationKind string
+
+const (
+	// NotificationReturnReminder warns that the return window of an item is about to close
+	NotificationReturnReminder NotificationKind = "return_reminder"
+)
+
+// Notification is a message to a user, optionally about a receipt or one of its items
+type Notification struct {
+	ID            int              `json:"id" db:"id"`
+	UUID          uuid.UUID        `json:"uuid" db:"uuid"`
+	UserID        int              `json:"user_id" db:"user_id"`
+	Kind          NotificationKind `json:"kind" db:"kind"`
+	Title         string           `json:"title" db:"title"`
+	Message       string           `json:"message" db:"message"`
+	ReceiptID     sql.NullInt64    `json:"receipt_id" db:"receipt_id"`
+	ItemID        sql.NullInt64    `json:"item_id" db:"item_id"`
+	ReadAt        sql.NullTime     `json:"read_at" db:"read_at"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64            `json:"created_at_unix" db:"created_at_unix"`
+}
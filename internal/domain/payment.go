@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentMethod is how a receipt was paid
+type PaymentMethod string
+
+const (
+	PaymentCash         PaymentMethod = "cash"
+	PaymentDebitCard    PaymentMethod = "debit_card"
+	PaymentCreditCard   PaymentMethod = "credit_card"
+	PaymentEWallet      PaymentMethod = "e_wallet"
+	PaymentQRIS         PaymentMethod = "qris"
+	PaymentBankTransfer PaymentMethod = "bank_transfer"
+	PaymentOther        PaymentMethod = "other"
+)
+
+// IsCard reports whether the payment method uses a card
+func (m PaymentMethod) IsCard() bool {
+	return m == PaymentDebitCard || m == PaymentCreditCard
+}
+
+// Card is a payment card registered by a user. Only the brand and the last four digits are
+// kept, which is enough to link receipts to the card.
+type Card struct {
+	ID            int       `json:"id" db:"id"`
+	UUID          uuid.UUID `json:"uuid" db:"uuid"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	Nickname      string    `json:"nickname" db:"nickname"`
+	Brand         string    `json:"brand" db:"brand"`
+	Last4         string    `json:"last4" db:"last4"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// CreateCardRequest represents card registration request
+type CreateCardRequest struct {
+	Nickname string `json:"nickname" validate:"max=100"`
+	Brand    string `json:"brand" validate:"omitempty,max=30"`
+	Last4    string `json:"last4" validate:"required,len=4,numeric"`
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product is the canonical product behind the raw item names of a workspace's receipts.
+// UserID is the member whose item created it.
+// Aliases are the normalized item names that resolve to the product.
+type Product struct {
+	ID            int            `json:"id" db:"id"`
+	UUID          uuid.UUID      `json:"uuid" db:"uuid"`
+	UserID        int            `json:"user_id" db:"user_id"`
+	WorkspaceID   int            `json:"workspace_id" db:"workspace_id"`
+	Name          string         `json:"name" db:"name"`
+	Unit          sql.NullString `json:"unit" db:"unit"`
+	Barcode       sql.NullString `json:"barcode" db:"barcode"`
+	SKU           sql.NullString `json:"sku" db:"sku"`
+	Aliases       []string       `json:"aliases" db:"-"`
+	PurchaseCount int            `json:"purchase_count" db:"-"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64          `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64          `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// UpdateProductRequest represents product update request
+type UpdateProductRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Unit    string `json:"unit" validate:"max=20"`
+	Barcode string `json:"barcode" validate:"max=64"`
+	SKU     string `json:"sku" validate:"max=64"`
+}
+
+// MergeProductsRequest merges the source products into the target product
+type MergeProductsRequest struct {
+	TargetID  int   `json:"target_id" validate:"required,min=1"`
+	SourceIDs []int `json:"source_ids" validate:"required,min=1,dive,min=1"`
+}
+
+// ProductPrice is the price paid for a product on one receipt, in the receipt currency
+type ProductPrice struct {
+	ReceiptID int            `json:"receipt_id"`
+	ItemID    int            `json:"item_id"`
+	Date      time.Time      `json:"date"`
+	StoreName sql.NullString `json:"store_name"`
+	Name      string         `json:"name"`
+	Quantity  int            `json:"quantity"`
+	UnitPrice Money          `json:"unit_price"`
+}
+
+// ProductTotal is the spending on one product in one currency on one day
+type ProductTotal struct {
+	ProductID int
+	Name      string
+	Unit      sql.NullString
+	Currency  string
+	Day       time.Time
+	Quantity  int
+	Purchases int
+	Total     Money
+}
+
+// TopProduct is the spending on one product converted to the user's home currency.
+// Amounts without a known exchange rate are listed per currency under Unconverted.
+type TopProduct struct {
+	ProductID     int              `json:"product_id"`
+	Name          string           `json:"name"`
+	Unit          sql.NullString   `json:"unit"`
+	Quantity      int              `json:"quantity"`
+	Purchases     int              `json:"purchases"`
+	TotalSpending Money            `json:"total_spending"`
+	Unconverted   map[string]Money `json:"unconverted,omitempty"`
+}
@@ -2,6 +2,9 @@ package domain
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,42 +19,187 @@ const (
 	StatusFailed     ReceiptStatus = "failed"
 )
 
+// DuplicateStatus tracks the review state of a suspected duplicate receipt
+type DuplicateStatus string
+
+const (
+	DuplicateNone      DuplicateStatus = "none"
+	DuplicateSuspected DuplicateStatus = "suspected"
+	DuplicateConfirmed DuplicateStatus = "confirmed"
+	DuplicateDismissed DuplicateStatus = "dismissed"
+)
+
+// ReceiptSource is the format of the original file a receipt was extracted from
+type ReceiptSource string
+
+const (
+	SourceImage ReceiptSource = "image"
+	SourcePDF   ReceiptSource = "pdf"
+	SourceHTML  ReceiptSource = "html"
+)
+
 type Receipt struct {
-	ID               int            `json:"id" db:"id"`
-	UUID             uuid.UUID      `json:"uuid" db:"uuid"`
-	UserID           int            `json:"user_id" db:"user_id"`
-	StoreName        sql.NullString `json:"store_name" db:"store_name"`
-	Address          sql.NullString `json:"address" db:"address"`
-	Phone            sql.NullInt64  `json:"phone" db:"phone"`
-	Date             sql.NullTime   `json:"date" db:"date"`
-	ImageURL         string         `json:"image_url" db:"image_url"`
-	OriginalFilename string         `json:"original_filename" db:"original_filename"`
-	FileSize         int            `json:"file_size" db:"file_size"`
-	UploadDate       time.Time      `json:"upload_date" db:"upload_date"`
-	Status           ReceiptStatus  `json:"status" db:"status"`
-	TotalItems       int            `json:"total_items" db:"total_items"`
-	TotalSpending    float64        `json:"total_spending" db:"total_spending"`
-	TotalDiscount    float64        `json:"total_discount" db:"total_discount"`
-	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
-	CreatedAtUnix    int64          `json:"created_at_unix" db:"created_at_unix"`
-	UpdatedAtUnix    int64          `json:"updated_at_unix" db:"updated_at_unix"`
-}
-
-// ReceiptWithItems represents receipt with its items
+	ID               int             `json:"id" db:"id"`
+	UUID             uuid.UUID       `json:"uuid" db:"uuid"`
+	UserID           int             `json:"user_id" db:"user_id"`
+	WorkspaceID      int             `json:"workspace_id" db:"workspace_id"`
+	StoreName        sql.NullString  `json:"store_name" db:"store_name"`
+	MerchantID       sql.NullInt64   `json:"merchant_id" db:"merchant_id"`
+	Address          sql.NullString  `json:"address" db:"address"`
+	Phone            sql.NullInt64   `json:"phone" db:"phone"`
+	Date             sql.NullTime    `json:"date" db:"date"`
+	ImageURL         string          `json:"image_url" db:"image_url"`
+	OriginalFilename string          `json:"original_filename" db:"original_filename"`
+	FileSize         int             `json:"file_size" db:"file_size"`
+	SourceType       ReceiptSource   `json:"source_type" db:"source_type"`
+	UploadDate       time.Time       `json:"upload_date" db:"upload_date"`
+	Status           ReceiptStatus   `json:"status" db:"status"`
+	TotalItems       int             `json:"total_items" db:"total_items"`
+	TotalSpending    Money           `json:"total_spending" db:"total_spending"`
+	TotalDiscount    Money           `json:"total_discount" db:"total_discount"`
+	Currency         string          `json:"currency" db:"currency"`
+	PaymentMethod    sql.NullString  `json:"payment_method" db:"payment_method"`
+	CardBrand        sql.NullString  `json:"card_brand" db:"card_brand"`
+	CardLast4        sql.NullString  `json:"card_last4" db:"card_last4"`
+	CardID           sql.NullInt64   `json:"card_id" db:"card_id"`
+	ImageHash        sql.NullString  `json:"image_hash" db:"image_hash"`
+	PerceptualHash   sql.NullInt64   `json:"perceptual_hash" db:"perceptual_hash"`
+	DuplicateOf      sql.NullInt64   `json:"duplicate_of" db:"duplicate_of"`
+	DuplicateStatus  DuplicateStatus `json:"duplicate_status" db:"duplicate_status"`
+	Notes            sql.NullString  `json:"notes" db:"notes"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix    int64           `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix    int64           `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// ReceiptWithItems represents receipt with its items and adjustments.
+// Warnings lists consistency problems between the lines and the receipt total.
 type ReceiptWithItems struct {
 	Receipt
-	Items []Item `json:"items"`
+	Items       []Item       `json:"items"`
+	Adjustments []Adjustment `json:"adjustments"`
+	Warnings    []string     `json:"warnings,omitempty"`
 }
 
 // CreateReceiptRequest represents receipt creation request
 type CreateReceiptRequest struct {
-	StoreName     string              `json:"store_name"`
-	Address       string              `json:"address"`
-	Phone         *int64              `json:"phone"`
-	Date          *string             `json:"date"`
-	TotalItems    int                 `json:"total_items"`
-	TotalSpending float64             `json:"total_spending"`
-	TotalDiscount float64             `json:"total_discount"`
-	Items         []CreateItemRequest `json:"items"`
+	StoreName     string                    `json:"store_name"`
+	Address       string                    `json:"address"`
+	Phone         *int64                    `json:"phone"`
+	Date          *string                   `json:"date"`
+	TotalItems    int                       `json:"total_items" validate:"min=0"`
+	TotalSpending Decimal                   `json:"total_spending"`
+	TotalDiscount Decimal                   `json:"total_discount"`
+	Currency      string                    `json:"currency" validate:"omitempty,len=3"`
+	PaymentMethod PaymentMethod             `json:"payment_method" validate:"omitempty,oneof=cash debit_card credit_card e_wallet qris bank_transfer other"`
+	CardBrand     string                    `json:"card_brand" validate:"omitempty,max=30"`
+	CardLast4     string                    `json:"card_last4" validate:"omitempty,len=4,numeric"`
+	RawText       string                    `json:"raw_text"`
+	Notes         *string                   `json:"notes" validate:"omitempty,max=5000"`
+	Items         []CreateItemRequest       `json:"items" validate:"dive"`
+	Adjustments   []CreateAdjustmentRequest `json:"adjustments" validate:"dive"`
+}
+
+// ReceiptImage describes the uploaded image or document a receipt was extracted from
+type ReceiptImage struct {
+	URL      string
+	Filename string
+	Size     int
+	Hash     string
+	// Source is the format of the file, an image when empty
+	Source ReceiptSource
+	// PerceptualHash is the dHash of the image, stored as the signed bit pattern
+	PerceptualHash sql.NullInt64
+}
+
+// ReceiptSortField is a field receipts can be sorted by
+type ReceiptSortField string
+
+const (
+	SortByUploadDate ReceiptSortField = "upload_date"
+	SortByDate       ReceiptSortField = "date"
+	SortByTotal      ReceiptSortField = "total"
+	SortByStore      ReceiptSortField = "store"
+)
+
+// SortOrder is an ascending or descending sort direction
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// ReceiptFilter narrows and orders receipt queries; zero values are ignored.
+// The same filter is shared by listing, export and stats.
+type ReceiptFilter struct {
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	MinAmount  *Decimal
+	MaxAmount  *Decimal
+	StoreName  string
+	MerchantID int
+	Status     ReceiptStatus
+	Category   string
+	Tag        string
+	// UserID is the requesting user, whose tag Tag names since tags are per user
+	UserID        int
+	HasDiscount   *bool
+	PaymentMethod PaymentMethod
+	CardID        int
+	SortBy        ReceiptSortField
+	SortOrder     SortOrder
+}
+
+// ReceiptItemRow is a receipt header and its adjustments joined with one of its items.
+// Item is nil for receipts without items.
+type ReceiptItemRow struct {
+	Receipt     Receipt
+	Adjustments []Adjustment
+	Item        *Item
+}
+
+// ReceiptSearchResult is a receipt matched by search, with its matching items
+type ReceiptSearchResult struct {
+	Receipt
+	Rank         float64       `json:"rank"`
+	MatchedItems []MatchedItem `json:"matched_items"`
+}
+
+// ReceiptCursor is a keyset position in the receipt list ordered by upload date and id, newest first.
+// Backward cursors page towards newer receipts.
+type ReceiptCursor struct {
+	UploadDate time.Time `json:"t"`
+	ID         int       `json:"id"`
+	Backward   bool      `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c ReceiptCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeReceiptCursor parses a token produced by ReceiptCursor.Encode
+func DecodeReceiptCursor(token string) (*ReceiptCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	cursor := &ReceiptCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// ReceiptPage is a page of receipts read with keyset pagination. NextCursor leads to older
+// receipts and PrevCursor to newer ones; each is empty when there are no more receipts that way.
+type ReceiptPage struct {
+	Receipts   []Receipt
+	NextCursor string
+	PrevCursor string
 }
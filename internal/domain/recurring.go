@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cadence is how often a recurring purchase repeats
+type Cadence string
+
+const (
+	CadenceWeekly  Cadence = "weekly"
+	CadenceMonthly Cadence = "monthly"
+	CadenceYearly  Cadence = "yearly"
+)
+
+// RecurringStatus is the state of a recurring purchase relative to its predicted next occurrence
+type RecurringStatus string
+
+const (
+	// RecurringActive purchases are not yet overdue
+	RecurringActive RecurringStatus = "active"
+	// RecurringMissing purchases are overdue by more than the cadence tolerance
+	RecurringMissing RecurringStatus = "missing"
+	// RecurringEnded purchases have missed several occurrences and have probably stopped
+	RecurringEnded RecurringStatus = "ended"
+)
+
+// RecurringPurchase is a detected subscription or other regular purchase: receipts from the same
+// merchant for a similar amount at a regular cadence. Amount is the typical (median) amount.
+// UnusualAmount is set when the latest occurrence cost unusually more than that.
+type RecurringPurchase struct {
+	ID               int             `json:"id" db:"id"`
+	UUID             uuid.UUID       `json:"uuid" db:"uuid"`
+	WorkspaceID      int             `json:"workspace_id" db:"workspace_id"`
+	MerchantID       sql.NullInt64   `json:"merchant_id" db:"merchant_id"`
+	MerchantKey      string          `json:"-" db:"merchant_key"`
+	StoreName        string          `json:"store_name" db:"store_name"`
+	Cadence          Cadence         `json:"cadence" db:"cadence"`
+	Amount           Money           `json:"amount" db:"amount"`
+	Currency         string          `json:"currency" db:"currency"`
+	Occurrences      int             `json:"occurrences" db:"occurrences"`
+	FirstDate        time.Time       `json:"first_date" db:"first_date"`
+	LastDate         time.Time       `json:"last_date" db:"last_date"`
+	NextDate         time.Time       `json:"next_date" db:"next_date"`
+	Status           RecurringStatus `json:"status" db:"status"`
+	UnusualAmount    *Money          `json:"unusual_amount" db:"unusual_amount"`
+	UnusualReceiptID sql.NullInt64   `json:"unusual_receipt_id" db:"unusual_receipt_id"`
+	ReceiptIDs       []int64         `json:"receipt_ids" db:"receipt_ids"`
+	Dismissed        bool            `json:"dismissed" db:"dismissed"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix    int64           `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix    int64           `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// UpdateRecurringRequest dismisses a recurring purchase, hiding it from the list, or restores it
+type UpdateRecurringRequest struct {
+	Dismissed bool `json:"dismissed"`
+}
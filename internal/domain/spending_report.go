@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// SpendingReport is a printable summary of a set of receipts, such as a month of spending or an
+// expense report. Totals are in HomeCurrency; receipts without an exchange rate for their
+// purchase day are left out of them and listed per currency under Unconverted.
+type SpendingReport struct {
+	Title        string
+	Subtitle     string
+	From         *time.Time
+	To           *time.Time
+	HomeCurrency string
+	ReceiptCount int
+	Total        Money
+	Discount     Money
+	Unconverted  []Money
+	Categories   []CategoryTotal
+	TopStores    []StoreTotal
+	Receipts     []SpendingReportReceipt
+	GeneratedAt  time.Time
+}
+
+// CategoryTotal is the spending on one item category. Receipt adjustments and discounts are
+// shared between the categories of a receipt in proportion to its items.
+type CategoryTotal struct {
+	Category string
+	Total    Money
+}
+
+// StoreTotal is the spending at one store
+type StoreTotal struct {
+	Name     string
+	Receipts int
+	Total    Money
+}
+
+// SpendingReportReceipt is one receipt row of a spending report. Converted is nil when there is
+// no exchange rate for the purchase day.
+type SpendingReportReceipt struct {
+	ID            int
+	Date          time.Time
+	StoreName     string
+	PaymentMethod string
+	Amount        Money
+	Converted     *Money
+	ImageURL      string
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Split divides a receipt between workspace members. The payer paid the whole receipt, and
+// every other participant owes the payer their share.
+type Split struct {
+	ID            int               `json:"id" db:"id"`
+	UUID          uuid.UUID         `json:"uuid" db:"uuid"`
+	ReceiptID     int               `json:"receipt_id" db:"receipt_id"`
+	WorkspaceID   int               `json:"workspace_id" db:"workspace_id"`
+	PayerID       int               `json:"payer_id" db:"payer_id"`
+	Currency      string            `json:"currency" db:"currency"`
+	CreatedBy     int               `json:"created_by" db:"created_by"`
+	Allocations   []SplitAllocation `json:"allocations" db:"-"`
+	Shares        []SplitShare      `json:"shares" db:"-"`
+	CreatedAt     time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64             `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64             `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// SplitAllocation assigns part of an item to a participant. An item's total is divided
+// between its participants in proportion to their weights, so weights 1 and 2 give a third
+// and two thirds.
+type SplitAllocation struct {
+	ItemID int `json:"item_id" db:"item_id"`
+	UserID int `json:"user_id" db:"user_id"`
+	Weight int `json:"weight" db:"weight"`
+}
+
+// SplitShare is what one participant's part of a split receipt costs. Items is their part of
+// the item totals; Adjustments is their part of the tax, service, tip and other adjustments
+// less the discount, divided in proportion to Items.
+type SplitShare struct {
+	UserID      int   `json:"user_id" db:"user_id"`
+	Items       Money `json:"items" db:"items"`
+	Adjustments Money `json:"adjustments" db:"adjustments"`
+	Total       Money `json:"total" db:"total"`
+}
+
+// SplitRequest splits a receipt. Items listed in Items are divided between their participants;
+// other items are divided evenly between Participants, or go to the payer when Participants is
+// empty. PayerID defaults to the member who added the receipt.
+type SplitRequest struct {
+	PayerID      int                `json:"payer_id" validate:"omitempty,min=1"`
+	Participants []int              `json:"participants" validate:"dive,min=1"`
+	Items        []SplitItemRequest `json:"items" validate:"dive"`
+}
+
+// SplitItemRequest divides one item between participants
+type SplitItemRequest struct {
+	ItemID int                 `json:"item_id" validate:"required,min=1"`
+	Shares []SplitShareRequest `json:"shares" validate:"required,min=1,dive"`
+}
+
+// SplitShareRequest is one participant's weight in an item; the weight defaults to 1
+type SplitShareRequest struct {
+	UserID int `json:"user_id" validate:"required,min=1"`
+	Weight int `json:"weight" validate:"omitempty,min=1"`
+}
+
+// Settlement records a payment from one member to another that settles split debts
+type Settlement struct {
+	ID            int       `json:"id" db:"id"`
+	UUID          uuid.UUID `json:"uuid" db:"uuid"`
+	WorkspaceID   int       `json:"workspace_id" db:"workspace_id"`
+	FromUserID    int       `json:"from_user_id" db:"from_user_id"`
+	ToUserID      int       `json:"to_user_id" db:"to_user_id"`
+	Amount        Money     `json:"amount" db:"amount"`
+	Currency      string    `json:"currency" db:"currency"`
+	Note          string    `json:"note" db:"note"`
+	CreatedBy     int       `json:"created_by" db:"created_by"`
+	SettledAt     time.Time `json:"settled_at" db:"settled_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// CreateSettlementRequest records a settlement. FromUserID defaults to the current user, who
+// must be either the payer or the receiver.
+type CreateSettlementRequest struct {
+	FromUserID int     `json:"from_user_id" validate:"omitempty,min=1"`
+	ToUserID   int     `json:"to_user_id" validate:"required,min=1"`
+	Amount     Decimal `json:"amount" validate:"required"`
+	Currency   string  `json:"currency" validate:"required,len=3"`
+	Note       string  `json:"note" validate:"max=255"`
+	SettledAt  *string `json:"settled_at"`
+}
+
+// LedgerEntry is a debt between two members: a split share, From owing To, or a settlement,
+// From paying To
+type LedgerEntry struct {
+	Type       string    `json:"type"`
+	Date       time.Time `json:"date"`
+	ReceiptID  int       `json:"receipt_id,omitempty"`
+	StoreName  string    `json:"store_name,omitempty"`
+	FromUserID int       `json:"from_user_id"`
+	ToUserID   int       `json:"to_user_id"`
+	Amount     Money     `json:"amount"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// Ledger entry types
+const (
+	LedgerSplit      = "split"
+	LedgerSettlement = "settlement"
+)
+
+// Debt is an amount one member owes another
+type Debt struct {
+	FromUserID int   `json:"from_user_id"`
+	ToUserID   int   `json:"to_user_id"`
+	Amount     Money `json:"amount"`
+}
+
+// MemberBalance is a member's net balance: positive when others owe them
+type MemberBalance struct {
+	UserID   int    `json:"user_id"`
+	FullName string `json:"full_name"`
+	Balance  Money  `json:"balance"`
+}
+
+// BalanceSummary is who owes whom in one currency. Debts are the outstanding debts between
+// each pair of members; Transfers settle every balance with as few payments as possible.
+type BalanceSummary struct {
+	Currency  string          `json:"currency"`
+	Balances  []MemberBalance `json:"balances"`
+	Debts     []Debt          `json:"debts"`
+	Transfers []Debt          `json:"transfers"`
+}
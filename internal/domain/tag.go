@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a label a user attaches to receipts, such as "tax-deductible". ReceiptCount is the
+// number of receipts carrying the tag when tags are listed.
+type Tag struct {
+	ID            int       `json:"id" db:"id"`
+	UUID          uuid.UUID `json:"uuid" db:"uuid"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	Name          string    `json:"name" db:"name"`
+	ReceiptCount  int       `json:"receipt_count" db:"-"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64     `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// TagRequest creates or renames a tag
+type TagRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// SetReceiptTagsRequest replaces the user's tags on a receipt. Tags that do not exist yet are
+// created.
+type SetReceiptTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+// TagTotal is the spending of receipts carrying one tag in one currency on one day
+type TagTotal struct {
+	Tag string
+	CurrencyTotal
+}
+
+// NormalizeTagName lowercases a tag name and joins its words with dashes, so "Trip Bali 2026"
+// and "trip-bali-2026" are the same tag
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
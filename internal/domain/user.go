@@ -6,23 +6,37 @@ import (
 	"github.com/google/uuid"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID            int       `json:"id" db:"id"`
 	UUID          uuid.UUID `json:"uuid" db:"uuid"`
 	Email         string    `json:"email" db:"email"`
 	PasswordHash  string    `json:"-" db:"password_hash"`
 	FullName      string    `json:"full_name" db:"full_name"`
+	HomeCurrency  string    `json:"home_currency" db:"home_currency"`
+	Role          string    `json:"role" db:"role"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 	CreatedAtUnix int64     `json:"created_at_unix" db:"created_at_unix"`
 	UpdatedAtUnix int64     `json:"updated_at_unix" db:"updated_at_unix"`
 }
 
+// IsAdmin reports whether the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
 // CreateUserRequest represents user registration request
 type CreateUserRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
-	FullName string `json:"full_name" validate:"required"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required,min=8"`
+	FullName     string `json:"full_name" validate:"required"`
+	HomeCurrency string `json:"home_currency" validate:"omitempty,len=3"`
 }
 
 // LoginRequest represents login request
@@ -37,6 +51,8 @@ type UserResponse struct {
 	UUID          string    `json:"uuid"`
 	Email         string    `json:"email"`
 	FullName      string    `json:"full_name"`
+	HomeCurrency  string    `json:"home_currency"`
+	Role          string    `json:"role"`
 	CreatedAt     time.Time `json:"created_at"`
 	CreatedAtUnix int64     `json:"created_at_unix"`
 }
@@ -48,6 +64,8 @@ func (u *User) ToResponse() UserResponse {
 		UUID:          u.UUID.String(),
 		Email:         u.Email,
 		FullName:      u.FullName,
+		HomeCurrency:  u.HomeCurrency,
+		Role:          u.Role,
 		CreatedAt:     u.CreatedAt,
 		CreatedAtUnix: u.CreatedAtUnix,
 	}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ExpirationKind distinguishes a warranty from a return window
+type ExpirationKind string
+
+const (
+	ExpirationWarranty ExpirationKind = "warranty"
+	ExpirationReturn   ExpirationKind = "return"
+)
+
+// ItemWarranty is the warranty length and return window of an item. Expiry dates are computed
+// from the purchase date of the receipt, or its upload date when the purchase date is unknown,
+// so they follow corrections to the receipt date.
+type ItemWarranty struct {
+	ItemID            int           `json:"item_id" db:"item_id"`
+	ReceiptID         int           `json:"receipt_id" db:"receipt_id"`
+	WarrantyMonths    sql.NullInt64 `json:"warranty_months" db:"warranty_months"`
+	ReturnDays        sql.NullInt64 `json:"return_days" db:"return_days"`
+	WarrantyExpiresOn sql.NullTime  `json:"warranty_expires_on" db:"-"`
+	ReturnExpiresOn   sql.NullTime  `json:"return_expires_on" db:"-"`
+	ReturnRemindedAt  sql.NullTime  `json:"return_reminded_at" db:"return_reminded_at"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix     int64         `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix     int64         `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// SetWarrantyRequest sets the warranty length and return window of an item. A field left out
+// is not tracked; leaving both out is rejected.
+type SetWarrantyRequest struct {
+	WarrantyMonths *int `json:"warranty_months" validate:"omitempty,min=1,max=600"`
+	ReturnDays     *int `json:"return_days" validate:"omitempty,min=1,max=365"`
+}
+
+// Expiration is an upcoming end of a warranty or return window. UserID is the user who added
+// the receipt, who receives reminders.
+type Expiration struct {
+	Kind         ExpirationKind `json:"kind"`
+	ExpiresOn    time.Time      `json:"expires_on"`
+	DaysLeft     int            `json:"days_left"`
+	ItemID       int            `json:"item_id"`
+	ItemName     string         `json:"item_name"`
+	ReceiptID    int            `json:"receipt_id"`
+	WorkspaceID  int            `json:"workspace_id"`
+	UserID       int            `json:"user_id"`
+	StoreName    sql.NullString `json:"store_name"`
+	PurchaseDate time.Time      `json:"purchase_date"`
+}
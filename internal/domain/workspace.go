@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceRole is a member's role in a workspace
+type WorkspaceRole string
+
+const (
+	// WorkspaceOwner manages members and invitations and can edit receipts
+	WorkspaceOwner WorkspaceRole = "owner"
+	// WorkspaceEditor can add, edit and delete receipts
+	WorkspaceEditor WorkspaceRole = "editor"
+	// WorkspaceViewer can only read receipts
+	WorkspaceViewer WorkspaceRole = "viewer"
+)
+
+// CanEdit reports whether the role may add, change or delete receipts
+func (r WorkspaceRole) CanEdit() bool {
+	return r == WorkspaceOwner || r == WorkspaceEditor
+}
+
+// Workspace is a household or team whose members share receipts. Every user has a personal
+// workspace that cannot be shared; receipts are created there unless another workspace is chosen.
+type Workspace struct {
+	ID            int           `json:"id" db:"id"`
+	UUID          uuid.UUID     `json:"uuid" db:"uuid"`
+	Name          string        `json:"name" db:"name"`
+	OwnerID       int           `json:"owner_id" db:"owner_id"`
+	Personal      bool          `json:"personal" db:"personal"`
+	Role          WorkspaceRole `json:"role,omitempty" db:"-"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
+	CreatedAtUnix int64         `json:"created_at_unix" db:"created_at_unix"`
+	UpdatedAtUnix int64         `json:"updated_at_unix" db:"updated_at_unix"`
+}
+
+// WorkspaceMember is a user's membership of a workspace
+type WorkspaceMember struct {
+	WorkspaceID   int           `json:"workspace_id" db:"workspace_id"`
+	UserID        int           `json:"user_id" db:"user_id"`
+	Email         string        `json:"email" db:"-"`
+	FullName      string        `json:"full_name" db:"-"`
+	Role          WorkspaceRole `json:"role" db:"role"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64         `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// WorkspaceInvitation invites an email address to join a workspace. The token is only
+// returned when the invitation is created; the database keeps its hash.
+type WorkspaceInvitation struct {
+	ID            int           `json:"id" db:"id"`
+	UUID          uuid.UUID     `json:"uuid" db:"uuid"`
+	WorkspaceID   int           `json:"workspace_id" db:"workspace_id"`
+	WorkspaceName string        `json:"workspace_name,omitempty" db:"-"`
+	Email         string        `json:"email" db:"email"`
+	Role          WorkspaceRole `json:"role" db:"role"`
+	Token         string        `json:"token,omitempty" db:"-"`
+	TokenHash     string        `json:"-" db:"token_hash"`
+	InvitedBy     int           `json:"invited_by" db:"invited_by"`
+	ExpiresAt     time.Time     `json:"expires_at" db:"expires_at"`
+	AcceptedAt    sql.NullTime  `json:"accepted_at" db:"accepted_at"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	CreatedAtUnix int64         `json:"created_at_unix" db:"created_at_unix"`
+}
+
+// IsExpired checks if invitation has expired
+func (i *WorkspaceInvitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+// WorkspaceWithMembers is a workspace with its members
+type WorkspaceWithMembers struct {
+	Workspace
+	Members []WorkspaceMember `json:"members"`
+}
+
+// CreateWorkspaceRequest represents workspace creation or rename request
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// InviteMemberRequest invites an email address to a workspace with a role
+type InviteMemberRequest struct {
+	Email string        `json:"email" validate:"required,email"`
+	Role  WorkspaceRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+// AcceptInvitationRequest accepts an invitation with its token
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// UpdateMemberRequest changes a member's role
+type UpdateMemberRequest struct {
+	Role WorkspaceRole `json:"role" validate:"required,oneof=editor viewer"`
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// newCSVWriter creates a CSV export writer and writes the header row
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+// WriteRow writes a receipt item row
+func (c *csvWriter) WriteRow(row *domain.ReceiptItemRow) error {
+	cells := rowCells(row)
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cell.Value
+	}
+
+	if err := c.w.Write(record); err != nil {
+		return fmt.Errorf("failed to write csv row: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered rows
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
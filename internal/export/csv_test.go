@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/google/uuid"
+)
+
+// exportRows returns the item rows of a USD receipt with two items, a tax split over two lines
+// and a service charge, and a store name that needs quoting
+func exportRows() []*domain.ReceiptItemRow {
+	receipt := domain.Receipt{
+		ID:            7,
+		UUID:          uuid.MustParse("6f1c2a52-8d43-4c1e-9a57-0d6c8a4b9e21"),
+		StoreName:     sql.NullString{String: `Joe's "Diner", <Downtown> & Bar`, Valid: true},
+		Date:          sql.NullTime{Time: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Valid: true},
+		UploadDate:    time.Date(2026, 3, 15, 9, 30, 0, 0, time.UTC),
+		Status:        domain.StatusCompleted,
+		TotalItems:    2,
+		TotalSpending: domain.NewMoney(2750, "USD"),
+		TotalDiscount: domain.NewMoney(250, "USD"),
+		Currency:      "USD",
+		PaymentMethod: sql.NullString{String: "credit_card", Valid: true},
+		CardBrand:     sql.NullString{String: "visa", Valid: true},
+		CardLast4:     sql.NullString{String: "4242", Valid: true},
+	}
+	adjustments := []domain.Adjustment{
+		{Type: domain.AdjustmentTax, Amount: domain.NewMoney(100, "USD")},
+		{Type: domain.AdjustmentTax, Amount: domain.NewMoney(50, "USD")},
+		{Type: domain.AdjustmentService, Amount: domain.NewMoney(100, "USD")},
+	}
+	items := []domain.Item{
+		{
+			Name:      "Burger\nwith fries",
+			Category:  sql.NullString{String: "dining", Valid: true},
+			UnitPrice: domain.NewMoney(1000, "USD"),
+			Quantity:  2,
+			Price:     domain.NewMoney(2000, "USD"),
+			Total:     domain.NewMoney(2000, "USD"),
+		},
+		{
+			Name:      "Cola",
+			UnitPrice: domain.NewMoney(500, "USD"),
+			Quantity:  1,
+			Price:     domain.NewMoney(500, "USD"),
+			Total:     domain.NewMoney(500, "USD"),
+		},
+	}
+
+	rows := make([]*domain.ReceiptItemRow, len(items))
+	for i := range items {
+		rows[i] = &domain.ReceiptItemRow{Receipt: receipt, Adjustments: adjustments, Item: &items[i]}
+	}
+	return rows
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := exportRows()
+	rows = append(rows, &domain.ReceiptItemRow{Receipt: rows[0].Receipt})
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want a header and 3 rows", len(records))
+	}
+	if !reflect.DeepEqual(records[0], columns) {
+		t.Errorf("header = %v, want %v", records[0], columns)
+	}
+
+	want := []string{
+		"6f1c2a52-8d43-4c1e-9a57-0d6c8a4b9e21", `Joe's "Diner", <Downtown> & Bar`, "", "2026-03-14", "2026-03-15 09:30:00",
+		"completed", "2", "27.50", "2.50", "USD", "credit_card", "visa", "4242",
+		"1.50", "1.00", "0.00", "0.00", "0.00",
+		"Burger\nwith fries", "dining", "10.00", "2", "20.00", "20.00",
+	}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("first row = %q, want %q", records[1], want)
+	}
+
+	// A receipt without items has empty item columns
+	noItem := records[3]
+	if len(noItem) != len(columns) {
+		t.Fatalf("row without an item has %d columns, want %d", len(noItem), len(columns))
+	}
+	for i, value := range noItem[len(columns)-6:] {
+		if value != "" {
+			t.Errorf("row without an item has %q in %s", value, columns[len(columns)-6+i])
+		}
+	}
+}
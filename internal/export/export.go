@@ -0,0 +1,192 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV       Format = "csv"
+	FormatXLSX      Format = "xlsx"
+	FormatOFX       Format = "ofx"
+	FormatQIF       Format = "qif"
+	FormatLedger    Format = "ledger"
+	FormatBeancount Format = "beancount"
+)
+
+// Writer writes receipt item rows to an export file
+type Writer interface {
+	WriteRow(row *domain.ReceiptItemRow) error
+	Close() error
+}
+
+// transactionWriter renders whole receipts, as needed by the personal-finance formats
+type transactionWriter interface {
+	WriteTransaction(t *transaction) error
+	Close() error
+}
+
+// cell is a single exported value
+type cell struct {
+	Value   string
+	Numeric bool
+}
+
+// columns are the header names of a receipt item export, in rowCells order
+var columns = []string{
+	"receipt_uuid", "store_name", "address", "date", "upload_date", "status",
+	"receipt_total_items", "receipt_total_spending", "receipt_total_discount", "currency",
+	"payment_method", "card_brand", "card_last4",
+	"receipt_tax", "receipt_service", "receipt_tip", "receipt_rounding", "receipt_delivery_fee",
+	"item_name", "item_category", "item_unit_price", "item_quantity", "item_price", "item_total",
+}
+
+// NewWriter creates an export writer for the format
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	case FormatOFX:
+		return newGroupingWriter(newOFXWriter(w)), nil
+	case FormatQIF:
+		return newGroupingWriter(newQIFWriter(w)), nil
+	case FormatLedger:
+		return newGroupingWriter(newLedgerWriter(w, false)), nil
+	case FormatBeancount:
+		return newGroupingWriter(newLedgerWriter(w, true)), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// IsSupported reports whether the format can be exported
+func IsSupported(format Format) bool {
+	switch format {
+	case FormatCSV, FormatXLSX, FormatOFX, FormatQIF, FormatLedger, FormatBeancount:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentType returns the MIME type of the format
+func ContentType(format Format) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatQIF:
+		return "application/qif"
+	case FormatLedger, FormatBeancount:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// rowCells flattens a receipt item row into export cells
+func rowCells(row *domain.ReceiptItemRow) []cell {
+	receipt := row.Receipt
+
+	date := ""
+	if receipt.Date.Valid {
+		date = receipt.Date.Time.Format("2006-01-02")
+	}
+
+	cells := []cell{
+		{Value: receipt.UUID.String()},
+		{Value: receipt.StoreName.String},
+		{Value: receipt.Address.String},
+		{Value: date},
+		{Value: receipt.UploadDate.Format("2006-01-02 15:04:05")},
+		{Value: string(receipt.Status)},
+		{Value: strconv.Itoa(receipt.TotalItems), Numeric: true},
+		{Value: receipt.TotalSpending.String(), Numeric: true},
+		{Value: receipt.TotalDiscount.String(), Numeric: true},
+		{Value: receipt.Currency},
+		{Value: receipt.PaymentMethod.String},
+		{Value: receipt.CardBrand.String},
+		{Value: receipt.CardLast4.String},
+	}
+
+	for _, adjustmentType := range domain.AdjustmentTypes {
+		total := domain.NewMoney(0, receipt.Currency)
+		for _, adjustment := range row.Adjustments {
+			if adjustment.Type == adjustmentType {
+				total = total.Add(adjustment.Amount)
+			}
+		}
+		cells = append(cells, cell{Value: total.String(), Numeric: true})
+	}
+
+	if row.Item == nil {
+		return append(cells, cell{}, cell{}, cell{}, cell{}, cell{}, cell{})
+	}
+
+	item := row.Item
+	return append(cells,
+		cell{Value: item.Name},
+		cell{Value: item.Category.String},
+		cell{Value: item.UnitPrice.String(), Numeric: true},
+		cell{Value: strconv.Itoa(item.Quantity), Numeric: true},
+		cell{Value: item.Price.String(), Numeric: true},
+		cell{Value: item.Total.String(), Numeric: true},
+	)
+}
+
+// groupingWriter collects consecutive item rows of the same receipt and hands each
+// complete receipt to a transaction writer
+type groupingWriter struct {
+	tw      transactionWriter
+	current *domain.ReceiptWithItems
+}
+
+// newGroupingWriter wraps a transaction writer as a row Writer
+func newGroupingWriter(tw transactionWriter) *groupingWriter {
+	return &groupingWriter{tw: tw}
+}
+
+// WriteRow adds a row to the current receipt, flushing the previous receipt when it changes
+func (g *groupingWriter) WriteRow(row *domain.ReceiptItemRow) error {
+	if g.current == nil || g.current.ID != row.Receipt.ID {
+		if err := g.flush(); err != nil {
+			return err
+		}
+		g.current = &domain.ReceiptWithItems{Receipt: row.Receipt, Adjustments: row.Adjustments}
+	}
+
+	if row.Item != nil {
+		g.current.Items = append(g.current.Items, *row.Item)
+	}
+
+	return nil
+}
+
+// Close flushes the last receipt and closes the transaction writer
+func (g *groupingWriter) Close() error {
+	if err := g.flush(); err != nil {
+		return err
+	}
+	return g.tw.Close()
+}
+
+// flush writes the current receipt, if any
+func (g *groupingWriter) flush() error {
+	if g.current == nil {
+		return nil
+	}
+	err := g.tw.WriteTransaction(newTransaction(g.current))
+	g.current = nil
+	return err
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// ledgerWriter renders transactions as plain-text ledger or beancount entries
+type ledgerWriter struct {
+	w         *bufio.Writer
+	beancount bool
+	// firstPosting holds the earliest posting date of each account, which beancount needs an
+	// open directive for
+	firstPosting map[string]string
+}
+
+// newLedgerWriter creates a ledger (or beancount) writer
+func newLedgerWriter(w io.Writer, beancount bool) *ledgerWriter {
+	return &ledgerWriter{w: bufio.NewWriter(w), beancount: beancount, firstPosting: map[string]string{}}
+}
+
+// WriteTransaction writes one receipt entry
+func (l *ledgerWriter) WriteTransaction(t *transaction) error {
+	if l.beancount {
+		fmt.Fprintf(l.w, "%s * %s \"\"\n", t.date(), quote(t.payee()))
+		fmt.Fprintf(l.w, "  receipt: %s\n", quote(t.Receipt.UUID.String()))
+	} else {
+		fmt.Fprintf(l.w, "%s %s\n", t.date(), t.payee())
+		fmt.Fprintf(l.w, "    ; receipt: %s\n", t.Receipt.UUID.String())
+	}
+
+	date, currency := t.date(), t.currency()
+	for _, p := range t.Expenses {
+		l.writePosting(date, p.Account, p.Amount, currency)
+	}
+	if !t.Discount.IsZero() {
+		l.writePosting(date, discountAccount, t.Discount.Neg(), currency)
+	}
+	l.writePosting(date, paymentAccount, t.Paid.Neg(), currency)
+
+	_, err := l.w.WriteString("\n")
+	return err
+}
+
+// writePosting writes an indented posting line, remembering the first day the account is used
+func (l *ledgerWriter) writePosting(date string, account string, amount domain.Money, currency string) {
+	indent := "    "
+	if l.beancount {
+		indent = "  "
+		if first, ok := l.firstPosting[account]; !ok || date < first {
+			l.firstPosting[account] = date
+		}
+	}
+	fmt.Fprintf(l.w, "%s%-40s %12s %s\n", indent, account, amount, currency)
+}
+
+// Close writes beancount's open directives and flushes buffered output. Transactions are
+// streamed in the export's sort order, so the accounts are only known at the end; beancount
+// reads directives in date order wherever they are in the file.
+func (l *ledgerWriter) Close() error {
+	if l.beancount && len(l.firstPosting) > 0 {
+		accounts := make([]string, 0, len(l.firstPosting))
+		for account := range l.firstPosting {
+			accounts = append(accounts, account)
+		}
+		sort.Strings(accounts)
+
+		l.w.WriteString("; Accounts, opened on the day of their first posting\n")
+		for _, account := range accounts {
+			fmt.Fprintf(l.w, "%s open %s\n", l.firstPosting[account], account)
+		}
+	}
+	return l.w.Flush()
+}
+
+// quote returns s as a double-quoted beancount string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
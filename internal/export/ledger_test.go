@@ -0,0 +1,80 @@
+package export
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func ledgerReceipt(date string, category string, total, discount int64) *domain.ReceiptWithItems {
+	day, _ := time.Parse("2006-01-02", date)
+	return &domain.ReceiptWithItems{
+		Receipt: domain.Receipt{
+			StoreName:     sql.NullString{String: "Toko", Valid: true},
+			Date:          sql.NullTime{Time: day, Valid: true},
+			Currency:      "IDR",
+			TotalSpending: domain.NewMoney(total, "IDR"),
+			TotalDiscount: domain.NewMoney(discount, "IDR"),
+		},
+		Items: []domain.Item{{
+			Name:     "Item",
+			Quantity: 1,
+			Category: sql.NullString{String: category, Valid: true},
+			Total:    domain.NewMoney(total, "IDR"),
+		}},
+	}
+}
+
+func TestBeancountOpensEveryAccountOnItsFirstPosting(t *testing.T) {
+	var buf bytes.Buffer
+	w := newLedgerWriter(&buf, true)
+
+	// Streamed out of date order, as exports sorted by amount or store are
+	receipts := []*domain.ReceiptWithItems{
+		ledgerReceipt("2026-03-10", "groceries", 50000, 0),
+		ledgerReceipt("2026-01-05", "dining", 30000, 5000),
+		ledgerReceipt("2026-02-01", "groceries", 20000, 0),
+	}
+	for _, receipt := range receipts {
+		if err := w.WriteTransaction(newTransaction(receipt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"2026-01-05 open Assets:Cash\n",
+		"2026-01-05 open Expenses:Dining\n",
+		"2026-02-01 open Expenses:Groceries\n",
+		"2026-01-05 open Income:Discounts\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+	if count := strings.Count(out, " open "); count != 4 {
+		t.Errorf("got %d open directives, want 4:\n%s", count, out)
+	}
+}
+
+func TestLedgerHasNoOpenDirectives(t *testing.T) {
+	var buf bytes.Buffer
+	w := newLedgerWriter(&buf, false)
+	if err := w.WriteTransaction(newTransaction(ledgerReceipt("2026-01-05", "dining", 30000, 0))); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), " open ") {
+		t.Errorf("ledger output has open directives:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// ofxWriter renders transactions as an OFX 2 bank statement. The statement header carries
+// the date range of all transactions, so entries are buffered until Close.
+// The statement currency is that of the first transaction. Transactions in other currencies
+// keep their own amounts and are marked with a CURRENCY aggregate, whose CURRATE is 1 as the
+// export has no exchange rates; importers read TRNAMT in CURSYM rather than the statement
+// currency.
+type ofxWriter struct {
+	w            io.Writer
+	transactions bytes.Buffer
+	start, end   time.Time
+	currency     string
+}
+
+// newOFXWriter creates an OFX writer
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{w: w}
+}
+
+// WriteTransaction buffers one receipt as a statement transaction
+func (o *ofxWriter) WriteTransaction(t *transaction) error {
+	date := t.Receipt.UploadDate
+	if t.Receipt.Date.Valid {
+		date = t.Receipt.Date.Time
+	}
+	if o.start.IsZero() || date.Before(o.start) {
+		o.start = date
+	}
+	if date.After(o.end) {
+		o.end = date
+	}
+
+	var memo []string
+	for _, p := range t.Expenses {
+		memo = append(memo, fmt.Sprintf("%s %s", p.Category, p.Amount))
+	}
+	if !t.Discount.IsZero() {
+		memo = append(memo, fmt.Sprintf("%s %s", discountCategory, t.Discount.Neg()))
+	}
+
+	currency := t.currency()
+	if o.currency == "" {
+		o.currency = currency
+	}
+
+	b := &o.transactions
+	b.WriteString("<STMTTRN><TRNTYPE>POS</TRNTYPE>")
+	fmt.Fprintf(b, "<DTPOSTED>%s</DTPOSTED>", date.Format("20060102"))
+	fmt.Fprintf(b, "<TRNAMT>%s</TRNAMT>", t.Paid.Neg())
+	fmt.Fprintf(b, "<FITID>%s</FITID>", t.Receipt.UUID.String())
+	b.WriteString("<NAME>")
+	xml.EscapeText(b, []byte(truncate(t.payee(), 32)))
+	b.WriteString("</NAME><MEMO>")
+	xml.EscapeText(b, []byte(truncate(strings.Join(memo, "; "), 255)))
+	b.WriteString("</MEMO>")
+	if currency != o.currency {
+		fmt.Fprintf(b, "<CURRENCY><CURRATE>1</CURRATE><CURSYM>%s</CURSYM></CURRENCY>", currency)
+	}
+	b.WriteString("</STMTTRN>\n")
+
+	return nil
+}
+
+// Close writes the complete statement
+func (o *ofxWriter) Close() error {
+	now := time.Now().Format("20060102150405")
+	if o.start.IsZero() {
+		o.start = time.Now()
+		o.end = o.start
+	}
+	if o.currency == "" {
+		o.currency = domain.DefaultCurrency
+	}
+
+	status := "<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>"
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	b.WriteString("<OFX>\n")
+	fmt.Fprintf(&b, "<SIGNONMSGSRSV1><SONRS>%s<DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", status, now)
+	fmt.Fprintf(&b, "<BANKMSGSRSV1><STMTTRNRS><TRNUID>1</TRNUID>%s<STMTRS><CURDEF>%s</CURDEF>\n", status, o.currency)
+	b.WriteString("<BANKACCTFROM><BANKID>RECEIPTS</BANKID><ACCTID>RECEIPTS</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n")
+	fmt.Fprintf(&b, "<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n", o.start.Format("20060102"), o.end.Format("20060102"))
+
+	if _, err := b.WriteTo(o.w); err != nil {
+		return fmt.Errorf("failed to write ofx: %w", err)
+	}
+	if _, err := o.transactions.WriteTo(o.w); err != nil {
+		return fmt.Errorf("failed to write ofx: %w", err)
+	}
+
+	footer := fmt.Sprintf("</BANKTRANLIST><LEDGERBAL><BALAMT>0.00</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL></STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n", now)
+	if _, err := io.WriteString(o.w, footer); err != nil {
+		return fmt.Errorf("failed to write ofx: %w", err)
+	}
+
+	return nil
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
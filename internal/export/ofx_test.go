@@ -0,0 +1,80 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// ofxStatement is the part of an OFX bank statement the export writes
+type ofxStatement struct {
+	Currency     string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>CURDEF"`
+	Start        string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTSTART"`
+	End          string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>DTEND"`
+	Transactions []struct {
+		Posted       string `xml:"DTPOSTED"`
+		Amount       string `xml:"TRNAMT"`
+		ID           string `xml:"FITID"`
+		Name         string `xml:"NAME"`
+		Memo         string `xml:"MEMO"`
+		Currency     string `xml:"CURRENCY>CURSYM"`
+		OrigCurrency string `xml:"ORIGCURRENCY>CURSYM"`
+	} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+}
+
+func TestOFXWriter(t *testing.T) {
+	rows := exportRows()
+
+	euro := rows[0].Receipt
+	euro.ID = 8
+	euro.StoreName = sql.NullString{String: "Boulangerie du Marché Saint-Germain-des-Prés", Valid: true}
+	euro.Date = sql.NullTime{Time: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Valid: true}
+	euro.Currency = "EUR"
+	euro.TotalSpending = domain.NewMoney(480, "EUR")
+	euro.TotalDiscount = domain.NewMoney(0, "EUR")
+	rows = append(rows, &domain.ReceiptItemRow{Receipt: euro})
+
+	out := writeTransactions(t, FormatOFX, rows)
+	if !strings.Contains(out, `<?OFX OFXHEADER="200" VERSION="220"`) {
+		t.Errorf("output has no OFX header:\n%s", out)
+	}
+
+	var statement ofxStatement
+	if err := xml.Unmarshal([]byte(out), &statement); err != nil {
+		t.Fatalf("output is not well-formed XML: %v\n%s", err, out)
+	}
+
+	if statement.Currency != "USD" || statement.Start != "20260302" || statement.End != "20260314" {
+		t.Errorf("statement is in %s from %s to %s, want USD from 20260302 to 20260314", statement.Currency, statement.Start, statement.End)
+	}
+	if len(statement.Transactions) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(statement.Transactions))
+	}
+
+	usd := statement.Transactions[0]
+	if usd.Posted != "20260314" || usd.Amount != "-25.00" || usd.ID != "6f1c2a52-8d43-4c1e-9a57-0d6c8a4b9e21" {
+		t.Errorf("first transaction = %+v", usd)
+	}
+	if usd.Name != `Joe's "Diner", <Downtown> & Bar` {
+		t.Errorf("first transaction name = %q", usd.Name)
+	}
+	if want := "dining 20.00; Uncategorized 5.00; Taxes 1.50; Service Charges 1.00; Discounts -2.50"; usd.Memo != want {
+		t.Errorf("first transaction memo = %q, want %q", usd.Memo, want)
+	}
+	if usd.Currency != "" || usd.OrigCurrency != "" {
+		t.Errorf("transaction in the statement currency is marked as %q/%q", usd.Currency, usd.OrigCurrency)
+	}
+
+	// The euro amount is kept and marked as being in euros
+	eur := statement.Transactions[1]
+	if eur.Amount != "-4.80" || eur.Currency != "EUR" || eur.OrigCurrency != "" {
+		t.Errorf("euro transaction = %+v, want -4.80 in the EUR currency", eur)
+	}
+	if eur.Name != "Boulangerie du Marché Saint-Germ" {
+		t.Errorf("euro transaction name = %q, want it cut to 32 characters", eur.Name)
+	}
+}
@@ -0,0 +1,130 @@
+package export
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Accounts used by the double-entry exporters
+const (
+	uncategorizedCategory = "Uncategorized"
+	discountCategory      = "Discounts"
+	expenseAccountPrefix  = "Expenses:"
+	discountAccount       = "Income:Discounts"
+	paymentAccount        = "Assets:Cash"
+)
+
+// adjustmentCategories maps receipt adjustments to expense categories
+var adjustmentCategories = map[domain.AdjustmentType]string{
+	domain.AdjustmentTax:         "Taxes",
+	domain.AdjustmentService:     "Service Charges",
+	domain.AdjustmentTip:         "Tips",
+	domain.AdjustmentRounding:    "Rounding",
+	domain.AdjustmentDeliveryFee: "Delivery Fees",
+}
+
+// posting is one leg of a receipt transaction
+type posting struct {
+	Category string
+	Account  string
+	Amount   domain.Money
+}
+
+// transaction is a receipt split into category and discount postings.
+// Expenses and the discount sum to Paid.
+type transaction struct {
+	Receipt  *domain.ReceiptWithItems
+	Expenses []posting
+	Discount domain.Money
+	Paid     domain.Money
+}
+
+// newTransaction groups the receipt's items by category and books each adjustment to its own
+// category. Any difference between the lines and the receipt total is booked as uncategorized
+// so the transaction always balances.
+func newTransaction(receipt *domain.ReceiptWithItems) *transaction {
+	t := &transaction{
+		Receipt:  receipt,
+		Discount: receipt.TotalDiscount,
+		Paid:     receipt.TotalSpending.Sub(receipt.TotalDiscount),
+	}
+
+	index := map[string]int{}
+	linesTotal := domain.NewMoney(0, receipt.Currency)
+
+	addExpense := func(category string, amount domain.Money) {
+		if category == "" {
+			category = uncategorizedCategory
+		}
+		i, ok := index[category]
+		if !ok {
+			i = len(t.Expenses)
+			index[category] = i
+			t.Expenses = append(t.Expenses, posting{
+				Category: category,
+				Account:  expenseAccountPrefix + accountName(category),
+				Amount:   domain.NewMoney(0, receipt.Currency),
+			})
+		}
+		t.Expenses[i].Amount = t.Expenses[i].Amount.Add(amount)
+	}
+
+	for _, item := range receipt.Items {
+		addExpense(item.Category.String, item.Total)
+		linesTotal = linesTotal.Add(item.Total)
+	}
+	for _, adjustment := range receipt.Adjustments {
+		addExpense(adjustmentCategories[adjustment.Type], adjustment.Amount)
+		linesTotal = linesTotal.Add(adjustment.Amount)
+	}
+
+	if diff := receipt.TotalSpending.Sub(linesTotal); !diff.IsZero() {
+		addExpense(uncategorizedCategory, diff)
+	}
+
+	return t
+}
+
+// payee returns the store name of the receipt, or a placeholder
+func (t *transaction) payee() string {
+	if t.Receipt.StoreName.Valid && t.Receipt.StoreName.String != "" {
+		return t.Receipt.StoreName.String
+	}
+	return "Unknown store"
+}
+
+// currency returns the receipt currency, falling back to the default currency
+func (t *transaction) currency() string {
+	if t.Receipt.Currency != "" {
+		return t.Receipt.Currency
+	}
+	return domain.DefaultCurrency
+}
+
+// date returns the purchase date, falling back to the upload date
+func (t *transaction) date() string {
+	if t.Receipt.Date.Valid {
+		return t.Receipt.Date.Time.Format("2006-01-02")
+	}
+	return t.Receipt.UploadDate.Format("2006-01-02")
+}
+
+// accountName turns a free-text category into an account component, e.g. "dining out" -> "Dining-Out"
+func accountName(category string) string {
+	words := strings.FieldsFunc(category, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return uncategorizedCategory
+	}
+
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func TestNewTransaction(t *testing.T) {
+	item := func(category string, total int64) domain.Item {
+		return domain.Item{Category: sql.NullString{String: category, Valid: category != ""}, Total: domain.NewMoney(total, "USD")}
+	}
+	adjustment := func(adjustmentType domain.AdjustmentType, amount int64) domain.Adjustment {
+		return domain.Adjustment{Type: adjustmentType, Amount: domain.NewMoney(amount, "USD")}
+	}
+
+	tests := []struct {
+		name        string
+		items       []domain.Item
+		adjustments []domain.Adjustment
+		total       int64
+		discount    int64
+		want        map[string]int64
+	}{
+		{
+			name:  "items grouped by category",
+			items: []domain.Item{item("groceries", 1000), item("dining", 500), item("groceries", 250)},
+			total: 1750,
+			want:  map[string]int64{"groceries": 1250, "dining": 500},
+		},
+		{
+			name:        "adjustments in their own categories",
+			items:       []domain.Item{item("dining", 2000)},
+			adjustments: []domain.Adjustment{adjustment(domain.AdjustmentTax, 200), adjustment(domain.AdjustmentTip, 300), adjustment(domain.AdjustmentRounding, -5)},
+			total:       2495,
+			discount:    100,
+			want:        map[string]int64{"dining": 2000, "Taxes": 200, "Tips": 300, "Rounding": -5},
+		},
+		{
+			name:  "lines short of the total",
+			items: []domain.Item{item("", 700), item("dining", 200)},
+			total: 1000,
+			want:  map[string]int64{uncategorizedCategory: 800, "dining": 200},
+		},
+		{
+			name:  "lines over the total",
+			items: []domain.Item{item("dining", 1200)},
+			total: 1000,
+			want:  map[string]int64{"dining": 1200, uncategorizedCategory: -200},
+		},
+		{
+			name:  "receipt without items",
+			total: 999,
+			want:  map[string]int64{uncategorizedCategory: 999},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt := &domain.ReceiptWithItems{
+				Receipt: domain.Receipt{
+					Currency:      "USD",
+					TotalSpending: domain.NewMoney(tt.total, "USD"),
+					TotalDiscount: domain.NewMoney(tt.discount, "USD"),
+				},
+				Items:       tt.items,
+				Adjustments: tt.adjustments,
+			}
+
+			tr := newTransaction(receipt)
+			got := map[string]int64{}
+			sum := domain.NewMoney(0, "USD")
+			for _, p := range tr.Expenses {
+				got[p.Category] = p.Amount.Amount
+				sum = sum.Add(p.Amount)
+				if p.Account != expenseAccountPrefix+accountName(p.Category) {
+					t.Errorf("posting %s is booked to %s", p.Category, p.Account)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newTransaction() expenses = %v, want %v", got, tt.want)
+			}
+
+			// Expenses less the discount are what was paid
+			if paid := sum.Sub(tr.Discount); paid != tr.Paid || tr.Paid.Amount != tt.total-tt.discount {
+				t.Errorf("expenses %s less discount %s = %s, want paid %s", sum, tr.Discount, paid, tr.Paid)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// qifWriter renders transactions as QIF cash records with category splits
+type qifWriter struct {
+	w *bufio.Writer
+}
+
+// newQIFWriter creates a QIF writer and writes the account type header
+func newQIFWriter(w io.Writer) *qifWriter {
+	q := &qifWriter{w: bufio.NewWriter(w)}
+	q.w.WriteString("!Type:Cash\n")
+	return q
+}
+
+// WriteTransaction writes one receipt record
+func (q *qifWriter) WriteTransaction(t *transaction) error {
+	date := t.Receipt.UploadDate
+	if t.Receipt.Date.Valid {
+		date = t.Receipt.Date.Time
+	}
+
+	fmt.Fprintf(q.w, "D%s\n", date.Format("01/02/2006"))
+	fmt.Fprintf(q.w, "T%s\n", t.Paid.Neg())
+	fmt.Fprintf(q.w, "P%s\n", t.payee())
+	fmt.Fprintf(q.w, "MReceipt %s\n", t.Receipt.UUID.String())
+
+	for _, p := range t.Expenses {
+		fmt.Fprintf(q.w, "S%s\n$%s\n", p.Category, p.Amount.Neg())
+	}
+	if !t.Discount.IsZero() {
+		fmt.Fprintf(q.w, "S%s\n$%s\n", discountCategory, t.Discount)
+	}
+
+	_, err := q.w.WriteString("^\n")
+	return err
+}
+
+// Close flushes buffered output
+func (q *qifWriter) Close() error {
+	return q.w.Flush()
+}
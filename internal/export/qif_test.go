@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// writeTransactions exports rows with a grouping writer of the format
+func writeTransactions(t *testing.T, format Format, rows []*domain.ReceiptItemRow) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestQIFWriter(t *testing.T) {
+	rows := exportRows()
+	other := ledgerReceipt("2026-03-20", "groceries", 5000, 0)
+	other.ID = 8
+	rows = append(rows, &domain.ReceiptItemRow{Receipt: other.Receipt, Item: &other.Items[0]})
+
+	out := writeTransactions(t, FormatQIF, rows)
+	if !strings.HasPrefix(out, "!Type:Cash\n") {
+		t.Fatalf("output has no account type header:\n%s", out)
+	}
+
+	records := strings.Split(strings.TrimSuffix(strings.TrimPrefix(out, "!Type:Cash\n"), "^\n"), "^\n")
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(records), out)
+	}
+
+	want := "D03/14/2026\n" +
+		"T-25.00\n" +
+		"PJoe's \"Diner\", <Downtown> & Bar\n" +
+		"MReceipt 6f1c2a52-8d43-4c1e-9a57-0d6c8a4b9e21\n" +
+		"Sdining\n$-20.00\n" +
+		"SUncategorized\n$-5.00\n" +
+		"STaxes\n$-1.50\n" +
+		"SService Charges\n$-1.00\n" +
+		"SDiscounts\n$2.50\n"
+	if records[0] != want {
+		t.Errorf("first record =\n%s\nwant\n%s", records[0], want)
+	}
+
+	// The splits of every record add up to its total
+	for i, record := range records {
+		var total, splits domain.Money
+		for _, line := range strings.Split(strings.TrimSuffix(record, "\n"), "\n") {
+			switch line[0] {
+			case 'T':
+				total = parseQIFAmount(t, line[1:])
+			case '$':
+				splits = splits.Add(parseQIFAmount(t, line[1:]))
+			}
+		}
+		if splits != total {
+			t.Errorf("record %d splits add up to %s, want %s", i+1, splits, total)
+		}
+	}
+}
+
+func parseQIFAmount(t *testing.T, value string) domain.Money {
+	t.Helper()
+	amount, err := domain.ParseMoney(value, "")
+	if err != nil {
+		t.Fatalf("invalid QIF amount %q: %v", value, err)
+	}
+	return amount
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Static parts of a single-sheet workbook. Cells use inline strings, so no shared strings part is needed.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Receipts" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rowNo int
+}
+
+// newXLSXWriter creates an XLSX export writer. The worksheet is the last zip entry,
+// so rows are compressed and written out as they arrive.
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create xlsx part: %w", err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, fmt.Errorf("failed to write xlsx part: %w", err)
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create xlsx sheet: %w", err)
+	}
+
+	x := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(f)}
+	x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]cell, len(columns))
+	for i, name := range columns {
+		header[i] = cell{Value: name}
+	}
+	if err := x.writeCells(header); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// WriteRow writes a receipt item row
+func (x *xlsxWriter) WriteRow(row *domain.ReceiptItemRow) error {
+	return x.writeCells(rowCells(row))
+}
+
+// writeCells writes one worksheet row
+func (x *xlsxWriter) writeCells(cells []cell) error {
+	x.rowNo++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.rowNo)
+
+	for _, c := range cells {
+		switch {
+		case c.Value == "":
+			x.sheet.WriteString(`<c/>`)
+		case c.Numeric:
+			x.sheet.WriteString(`<c><v>`)
+			xml.EscapeText(x.sheet, []byte(c.Value))
+			x.sheet.WriteString(`</v></c>`)
+		default:
+			x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(x.sheet, []byte(c.Value))
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+
+	if _, err := x.sheet.WriteString(`</row>`); err != nil {
+		return fmt.Errorf("failed to write xlsx row: %w", err)
+	}
+	return nil
+}
+
+// Close finishes the worksheet and the zip archive
+func (x *xlsxWriter) Close() error {
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return fmt.Errorf("failed to write xlsx sheet: %w", err)
+	}
+	if err := x.zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish xlsx: %w", err)
+	}
+	return nil
+}
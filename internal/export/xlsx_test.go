@@ -0,0 +1,122 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"testing"
+)
+
+// xlsxSheet is the part of a worksheet the export writes
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range exportRows() {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("export is not a zip archive: %v", err)
+	}
+
+	parts := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = data
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		data, ok := parts[name]
+		if !ok {
+			t.Errorf("archive has no %s", name)
+			continue
+		}
+		if err := xml.Unmarshal(data, new(struct{})); err != nil {
+			t.Errorf("%s is not well-formed XML: %v", name, err)
+		}
+	}
+
+	sheetData := parts["xl/worksheets/sheet1.xml"]
+	if !bytes.Contains(sheetData, []byte(`Joe&#39;s &#34;Diner&#34;, &lt;Downtown&gt; &amp; Bar`)) {
+		t.Errorf("store name is not escaped in the sheet:\n%s", sheetData)
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(sheetData, &sheet); err != nil {
+		t.Fatalf("sheet is not well-formed XML: %v", err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("sheet has %d rows, want a header and 2 rows", len(sheet.Rows))
+	}
+	for i, row := range sheet.Rows {
+		if row.R != i+1 || len(row.Cells) != len(columns) {
+			t.Errorf("row %d is numbered %d with %d cells, want %d cells", i+1, row.R, len(row.Cells), len(columns))
+		}
+	}
+
+	header := sheet.Rows[0].Cells
+	if header[0].Type != "inlineStr" || header[0].Inline != "receipt_uuid" {
+		t.Errorf("first header cell = %+v, want the inline string receipt_uuid", header[0])
+	}
+
+	cells := sheet.Rows[1].Cells
+	tests := []struct {
+		column  string
+		text    string
+		numeric bool
+	}{
+		{column: "store_name", text: `Joe's "Diner", <Downtown> & Bar`},
+		{column: "address"},
+		{column: "receipt_total_spending", text: "27.50", numeric: true},
+		{column: "receipt_tax", text: "1.50", numeric: true},
+		{column: "item_name", text: "Burger\nwith fries"},
+		{column: "item_quantity", text: "2", numeric: true},
+	}
+	for _, tt := range tests {
+		i := slices.Index(columns, tt.column)
+		c := cells[i]
+		switch {
+		case tt.text == "":
+			if c.Type != "" || c.Value != "" || c.Inline != "" {
+				t.Errorf("%s = %+v, want an empty cell", tt.column, c)
+			}
+		case tt.numeric:
+			if c.Type != "" || c.Value != tt.text {
+				t.Errorf("%s = %+v, want the number %s", tt.column, c, tt.text)
+			}
+		default:
+			if c.Type != "inlineStr" || c.Inline != tt.text {
+				t.Errorf("%s = %+v, want the inline string %q", tt.column, c, tt.text)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package extraction
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// adjustmentPatterns recognize adjustment lines by their label, in English and Indonesian
+var adjustmentPatterns = []struct {
+	pattern *regexp.Regexp
+	kind    domain.AdjustmentType
+}{
+	{regexp.MustCompile(`(?i)\b(pembulatan|rounding)\b`), domain.AdjustmentRounding},
+	{regexp.MustCompile(`(?i)\b(service|servis|svc|biaya layanan)\b`), domain.AdjustmentService},
+	{regexp.MustCompile(`(?i)\b(ppn|pb1|pajak|tax|vat|gst)\b`), domain.AdjustmentTax},
+	{regexp.MustCompile(`(?i)\b(tips?|gratuity)\b`), domain.AdjustmentTip},
+	{regexp.MustCompile(`(?i)\b(ongkir|ongkos kirim|delivery|biaya (antar|pengiriman))\b`), domain.AdjustmentDeliveryFee},
+}
+
+// summaryPattern matches total lines that mention an adjustment without being one,
+// e.g. "Grand Total (incl. PPN)"
+var summaryPattern = regexp.MustCompile(`(?i)\b(sub\s*total|grand\s*total|incl\.?|including|termasuk)\b`)
+
+// headerPattern matches header and footer lines that name an adjustment without being one,
+// e.g. "Tax Invoice No 0123", "Customer Service 0800 1234 567" or "Delivery Address: Jl. Melati 5"
+var headerPattern = regexp.MustCompile(`(?i)\b(invoice|faktur|npwp|no\.|nomor|number|customer|pelanggan|hotline|call|telp|phone|address|alamat|www|e-?mail)\b`)
+
+// ratePattern matches a percentage such as "11%" or "2,5 %"
+var ratePattern = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*%`)
+
+// amountPattern matches a printed amount, optionally negative or in parentheses.
+// The number must not be part of a word such as "PB1".
+var amountPattern = regexp.MustCompile(`(?i)(?:^|[^\pL\d.,])(-|\()?\s*(?:rp\.?|\$|€|£)?\s*(\d[\d.,]*)\)?`)
+
+// DetectAdjustments finds tax, service charge, tip, rounding and delivery fee lines in receipt
+// text. The amount is the number ending the line and the rate any percentage before it; a line
+// that does not end in an amount, such as a phone or invoice number in a header, is skipped.
+func DetectAdjustments(text string) []domain.CreateAdjustmentRequest {
+	var adjustments []domain.CreateAdjustmentRequest
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || summaryPattern.MatchString(line) || headerPattern.MatchString(line) {
+			continue
+		}
+
+		kind, ok := adjustmentType(line)
+		if !ok {
+			continue
+		}
+
+		adjustment := domain.CreateAdjustmentRequest{Type: kind}
+
+		// Blank out the rate so its digits are not read as the amount
+		masked := line
+		if match := ratePattern.FindStringSubmatchIndex(line); match != nil {
+			adjustment.Rate = parseNumber(line[match[2]:match[3]])
+			masked = line[:match[0]] + strings.Repeat(" ", match[1]-match[0]) + line[match[1]:]
+		}
+
+		amounts := amountPattern.FindAllStringSubmatchIndex(masked, -1)
+		if len(amounts) == 0 {
+			continue
+		}
+		last := amounts[len(amounts)-1]
+		if strings.TrimSpace(masked[last[1]:]) != "" {
+			continue
+		}
+
+		// A number with a leading zero such as "0800", or joined to the number before it as in
+		// "0800-123-456" or "2026/000123", is a phone or reference number
+		digits := masked[last[4]:last[5]]
+		if len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+			continue
+		}
+		if start := last[0]; start > 0 && strings.IndexByte("-/", masked[start]) >= 0 && isDigit(masked[start-1]) {
+			continue
+		}
+
+		amount := parseNumber(digits)
+		if amount == "" {
+			continue
+		}
+		if last[2] >= 0 {
+			amount = "-" + amount
+		}
+
+		adjustment.Label = strings.TrimRight(strings.TrimSpace(line[:last[0]]), " :")
+		adjustment.Amount = amount
+		adjustments = append(adjustments, adjustment)
+	}
+
+	return adjustments
+}
+
+// adjustmentType returns the type of the first pattern matching the line
+func adjustmentType(line string) (domain.AdjustmentType, bool) {
+	for _, p := range adjustmentPatterns {
+		if p.pattern.MatchString(line) {
+			return p.kind, true
+		}
+	}
+	return "", false
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseNumber normalizes a printed number to a decimal, telling thousands separators from the
+// decimal point: "12.500" and "12,500" are twelve thousand five hundred, "12,50" is twelve and a half.
+// It returns "" when the text is not a number.
+func parseNumber(text string) domain.Decimal {
+	text = strings.Trim(text, ".,")
+	if text == "" {
+		return ""
+	}
+
+	lastDot := strings.LastIndex(text, ".")
+	lastComma := strings.LastIndex(text, ",")
+	separator := lastDot
+	if lastComma > separator {
+		separator = lastComma
+	}
+
+	whole, fraction := text, ""
+	if separator >= 0 {
+		mark := text[separator]
+		digitsAfter := len(text) - separator - 1
+		mixed := lastDot >= 0 && lastComma >= 0
+		repeated := strings.Count(text, string(mark)) > 1
+		if mixed || (!repeated && digitsAfter != 3) {
+			whole, fraction = text[:separator], text[separator+1:]
+		}
+	}
+
+	whole = strings.NewReplacer(".", "", ",", "").Replace(whole)
+	if fraction != "" {
+		whole += "." + fraction
+	}
+
+	value, err := domain.ParseDecimal(whole)
+	if err != nil {
+		return ""
+	}
+	return value
+}
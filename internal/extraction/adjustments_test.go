@@ -0,0 +1,48 @@
+package extraction
+
+import (
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func TestDetectAdjustments(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   domain.AdjustmentType
+		amount domain.Decimal
+		rate   domain.Decimal
+	}{
+		{line: "PPN 11% 3.542", want: domain.AdjustmentTax, amount: "3542", rate: "11"},
+		{line: "Service Charge 5%: 1.750", want: domain.AdjustmentService, amount: "1750", rate: "5"},
+		{line: "Pembulatan (200)", want: domain.AdjustmentRounding, amount: "-200"},
+		{line: "Tip $2.50", want: domain.AdjustmentTip, amount: "2.50"},
+		{line: "Ongkos Kirim Rp 10.000", want: domain.AdjustmentDeliveryFee, amount: "10000"},
+		{line: "Grand Total (incl. PPN) 35.742"},
+		{line: "Tax Invoice No 0012345"},
+		{line: "Tax Invoice 2026-000123"},
+		{line: "Customer Service 0800 1234 567"},
+		{line: "Service 0800-123-456"},
+		{line: "Delivery Address: Jl. Melati 5"},
+		{line: "Delivery to Jl. Melati 5 Jakarta"},
+		{line: "Tax"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got := DetectAdjustments(tt.line)
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Fatalf("DetectAdjustments(%q) = %+v, want none", tt.line, got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("DetectAdjustments(%q) = %+v, want one adjustment", tt.line, got)
+			}
+			if got[0].Type != tt.want || got[0].Amount != tt.amount || got[0].Rate != tt.rate {
+				t.Errorf("DetectAdjustments(%q) = %s %s at %s%%, want %s %s at %s%%", tt.line, got[0].Type, got[0].Amount, got[0].Rate, tt.want, tt.amount, tt.rate)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package extraction
+
+import (
+	"regexp"
+)
+
+// currencyCodes are ISO 4217 codes recognized when they appear verbatim in receipt text
+var currencyCodes = []string{
+	"IDR", "USD", "EUR", "GBP", "JPY", "SGD", "MYR", "AUD", "THB", "PHP", "VND", "KRW", "CNY", "INR", "HKD",
+}
+
+// currencySymbols maps printed symbols to currency codes. Longer symbols come first so that
+// "S$" is not read as "$"; letter symbols must be followed by an amount.
+var currencySymbols = []struct {
+	pattern *regexp.Regexp
+	code    string
+}{
+	{regexp.MustCompile(`(?i)\brp\.?\s?\d`), "IDR"},
+	{regexp.MustCompile(`\bRM\s?\d`), "MYR"},
+	{regexp.MustCompile(`US\$`), "USD"},
+	{regexp.MustCompile(`HK\$`), "HKD"},
+	{regexp.MustCompile(`S\$`), "SGD"},
+	{regexp.MustCompile(`A\$`), "AUD"},
+	{regexp.MustCompile(`€`), "EUR"},
+	{regexp.MustCompile(`£`), "GBP"},
+	{regexp.MustCompile(`¥`), "JPY"},
+	{regexp.MustCompile(`₩`), "KRW"},
+	{regexp.MustCompile(`฿`), "THB"},
+	{regexp.MustCompile(`₱`), "PHP"},
+	{regexp.MustCompile(`₫`), "VND"},
+	{regexp.MustCompile(`₹`), "INR"},
+	{regexp.MustCompile(`\$`), "USD"},
+}
+
+// codePattern matches a standalone three letter code
+var codePattern = regexp.MustCompile(`\b[A-Z]{3}\b`)
+
+// DetectCurrency guesses the currency of receipt text from ISO codes and currency symbols.
+// It returns "" when no currency can be recognized.
+func DetectCurrency(text string) string {
+	for _, match := range codePattern.FindAllString(text, -1) {
+		for _, code := range currencyCodes {
+			if match == code {
+				return code
+			}
+		}
+	}
+
+	for _, s := range currencySymbols {
+		if s.pattern.MatchString(text) {
+			return s.code
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,143 @@
+package extraction
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/document"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Column headers of item tables in HTML receipts
+var (
+	nameHeaderPattern     = regexp.MustCompile(`(?i)\b(items?|description|product|produk|nama|menu|barang|name)\b`)
+	quantityHeaderPattern = regexp.MustCompile(`(?i)\b(qty|quantity|kuantitas|jml|pcs)\b`)
+	priceHeaderPattern    = regexp.MustCompile(`(?i)\b(unit|price|harga|@)\b`)
+	totalHeaderPattern    = regexp.MustCompile(`(?i)\b(total|amount|subtotal|jumlah)\b`)
+)
+
+// itemColumns holds the column indexes of an item table, -1 for columns it does not have
+type itemColumns struct {
+	name, quantity, price, total int
+}
+
+// ExtractPDF extracts a receipt from the text layer of a PDF, one string per page. It reports
+// false when the pages have no text, as for scanned documents.
+func ExtractPDF(pages []string) (domain.CreateReceiptRequest, bool) {
+	text := strings.TrimSpace(strings.Join(pages, "\n"))
+	if !letterPattern.MatchString(text) {
+		return domain.CreateReceiptRequest{}, false
+	}
+	return ParseText(text), true
+}
+
+// ExtractHTML extracts a receipt from an HTML e-receipt. Items come from a table with item and
+// amount column headers when there is one, read by column, and from the text lines otherwise.
+// A title naming the store, as in "Your receipt from Kopi Kenangan", names the receipt.
+func ExtractHTML(doc *document.HTMLDocument) domain.CreateReceiptRequest {
+	req := ParseText(doc.Text)
+
+	if match := receiptFromPattern.FindStringSubmatch(doc.Title); match != nil {
+		req.StoreName = clipName(match[1])
+	}
+
+	for _, table := range doc.Tables {
+		if items := tableItems(table); len(items) > 0 {
+			req.Items = items
+			req.TotalItems = len(items)
+			break
+		}
+	}
+
+	return req
+}
+
+// tableItems reads the items of a table below its header row, stopping at the totals. A table
+// without an item header has no items.
+func tableItems(rows [][]string) []domain.CreateItemRequest {
+	for i, row := range rows {
+		columns, ok := headerColumns(row)
+		if !ok {
+			continue
+		}
+
+		var items []domain.CreateItemRequest
+		for _, row := range rows[i+1:] {
+			if label := strings.TrimSpace(strings.Join(row, " ")); subtotalPattern.MatchString(label) || isTotalLine(label) {
+				break
+			}
+			if item, ok := tableItem(row, columns); ok {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// headerColumns finds the item columns of a header row, which needs a name and an amount column
+func headerColumns(row []string) (itemColumns, bool) {
+	columns := itemColumns{name: -1, quantity: -1, price: -1, total: -1}
+	for i, cell := range row {
+		switch {
+		case quantityHeaderPattern.MatchString(cell) && columns.quantity < 0:
+			columns.quantity = i
+		case priceHeaderPattern.MatchString(cell) && !totalHeaderPattern.MatchString(cell) && columns.price < 0:
+			columns.price = i
+		case totalHeaderPattern.MatchString(cell) && columns.total < 0 && columns.name >= 0:
+			columns.total = i
+		case nameHeaderPattern.MatchString(cell) && columns.name < 0:
+			columns.name = i
+		}
+	}
+	return columns, columns.name >= 0 && (columns.price >= 0 || columns.total >= 0)
+}
+
+// tableItem reads an item from a table row. Without a total column the price is the line total
+// unless a quantity column says otherwise.
+func tableItem(row []string, columns itemColumns) (domain.CreateItemRequest, bool) {
+	cell := func(index int) string {
+		if index < 0 || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+
+	name := cell(columns.name)
+	if !letterPattern.MatchString(name) {
+		return domain.CreateItemRequest{}, false
+	}
+
+	quantity := int64(1)
+	if text := strings.Trim(cell(columns.quantity), "xX× "); text != "" {
+		parsed, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || parsed <= 0 {
+			return domain.CreateItemRequest{}, false
+		}
+		quantity = parsed
+	}
+
+	unit, hasUnit := lastAmount(cell(columns.price))
+	total, hasTotal := lastAmount(cell(columns.total))
+	switch {
+	case !hasUnit && !hasTotal:
+		return domain.CreateItemRequest{}, false
+	case !hasTotal:
+		total = new(big.Rat).Mul(unit, big.NewRat(quantity, 1))
+	case !hasUnit:
+		quantity, unit = unitPrice(total, quantity)
+	}
+	if total.Sign() <= 0 {
+		return domain.CreateItemRequest{}, false
+	}
+
+	return domain.CreateItemRequest{
+		Name:      clipName(name),
+		UnitPrice: ratDecimal(unit),
+		Quantity:  int(quantity),
+		Price:     ratDecimal(total),
+		Total:     ratDecimal(total),
+	}, true
+}
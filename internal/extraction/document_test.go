@@ -0,0 +1,96 @@
+package extraction
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/document"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func TestExtractPDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []string
+		ok    bool
+		store string
+	}{
+		{name: "text layer", pages: []string{"Kopi Kenangan\nLatte 25.000", "Total 25.000"}, ok: true, store: "Kopi Kenangan"},
+		{name: "scanned pages", pages: []string{"", " "}},
+		{name: "no pages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractPDF(tt.pages)
+			if ok != tt.ok || got.StoreName != tt.store {
+				t.Errorf("ExtractPDF() = %q, %v, want %q, %v", got.StoreName, ok, tt.store, tt.ok)
+			}
+		})
+	}
+}
+
+func TestExtractHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   document.HTMLDocument
+		store string
+		items []domain.CreateItemRequest
+	}{
+		{
+			name: "item table read by column",
+			doc: document.HTMLDocument{
+				Title: "Your receipt from Kopi Kenangan",
+				Text:  "Thanks for your order\nItem Qty Price Total\nKopi Susu 2 20.000 40.000\nCroissant 1 18.000 18.000\nTotal 58.000",
+				Tables: [][][]string{{
+					{"Item", "Qty", "Price", "Total"},
+					{"Kopi Susu", "2", "20.000", "40.000"},
+					{"Croissant", "1", "18.000", "18.000"},
+					{"Total", "", "", "58.000"},
+				}},
+			},
+			store: "Kopi Kenangan",
+			items: []domain.CreateItemRequest{
+				{Name: "Kopi Susu", UnitPrice: "20000", Quantity: 2, Price: "40000", Total: "40000"},
+				{Name: "Croissant", UnitPrice: "18000", Quantity: 1, Price: "18000", Total: "18000"},
+			},
+		},
+		{
+			name: "price column without totals",
+			doc: document.HTMLDocument{
+				Text: "Toko Buku\nProduct Qty Harga\nNovel 3 50.000\nTotal 150.000",
+				Tables: [][][]string{{
+					{"Product", "Qty", "Harga"},
+					{"Novel", "3", "50.000"},
+				}},
+			},
+			store: "Toko Buku",
+			items: []domain.CreateItemRequest{
+				{Name: "Novel", UnitPrice: "50000", Quantity: 3, Price: "150000", Total: "150000"},
+			},
+		},
+		{
+			name: "layout table without an item header falls back to the text",
+			doc: document.HTMLDocument{
+				Text:   "Warung Makan\nNasi Goreng 30.000\nTotal 30.000",
+				Tables: [][][]string{{{"Warung Makan"}, {"Thank you"}}},
+			},
+			store: "Warung Makan",
+			items: []domain.CreateItemRequest{
+				{Name: "Nasi Goreng", UnitPrice: "30000", Quantity: 1, Price: "30000", Total: "30000"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractHTML(&tt.doc)
+			if got.StoreName != tt.store {
+				t.Errorf("ExtractHTML() store = %q, want %q", got.StoreName, tt.store)
+			}
+			if !reflect.DeepEqual(got.Items, tt.items) || got.TotalItems != len(tt.items) {
+				t.Errorf("ExtractHTML() items = %+v, want %+v", got.Items, tt.items)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package extraction
+
+import (
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// StitchPages combines the extraction results of the pages of one receipt, in page order, into
+// a single receipt. Header fields come from the first page that has them and totals from the
+// last, since receipts print the store at the top and the totals at the bottom. Photos of a long
+// receipt usually overlap, so lines repeated at the top of a page from the bottom of the page
+// before are dropped.
+func StitchPages(pages []domain.CreateReceiptRequest) domain.CreateReceiptRequest {
+	var stitched domain.CreateReceiptRequest
+	var rawText []string
+
+	for _, page := range pages {
+		if stitched.StoreName == "" {
+			stitched.StoreName = page.StoreName
+		}
+		if stitched.Address == "" {
+			stitched.Address = page.Address
+		}
+		if stitched.Phone == nil {
+			stitched.Phone = page.Phone
+		}
+		if stitched.Date == nil {
+			stitched.Date = page.Date
+		}
+		if stitched.Currency == "" {
+			stitched.Currency = page.Currency
+		}
+		if stitched.PaymentMethod == "" {
+			stitched.PaymentMethod = page.PaymentMethod
+		}
+		if stitched.CardBrand == "" {
+			stitched.CardBrand = page.CardBrand
+		}
+		if stitched.CardLast4 == "" {
+			stitched.CardLast4 = page.CardLast4
+		}
+		if stitched.Notes == nil {
+			stitched.Notes = page.Notes
+		}
+
+		if page.TotalItems != 0 {
+			stitched.TotalItems = page.TotalItems
+		}
+		if !decimalIsZero(page.TotalSpending) {
+			stitched.TotalSpending = page.TotalSpending
+		}
+		if !decimalIsZero(page.TotalDiscount) {
+			stitched.TotalDiscount = page.TotalDiscount
+		}
+
+		if text := strings.TrimSpace(page.RawText); text != "" {
+			rawText = append(rawText, text)
+		}
+
+		skip := overlap(len(stitched.Items), len(page.Items), func(i, j int) bool {
+			return sameItem(stitched.Items[i], page.Items[j])
+		})
+		stitched.Items = append(stitched.Items, page.Items[skip:]...)
+
+		skip = overlap(len(stitched.Adjustments), len(page.Adjustments), func(i, j int) bool {
+			return sameAdjustment(stitched.Adjustments[i], page.Adjustments[j])
+		})
+		stitched.Adjustments = append(stitched.Adjustments, page.Adjustments[skip:]...)
+	}
+
+	stitched.RawText = strings.Join(rawText, "\n")
+	return stitched
+}
+
+// overlap returns the length of the longest run of lines that ends the previous lines and
+// starts the next page, where equal(i, j) compares previous line i with next line j
+func overlap(previous, next int, equal func(i, j int) bool) int {
+	longest := previous
+	if next < longest {
+		longest = next
+	}
+
+	for n := longest; n > 0; n-- {
+		matches := true
+		for k := 0; k < n; k++ {
+			if !equal(previous-n+k, k) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// sameItem reports whether two extracted lines are the same item line
+func sameItem(a, b domain.CreateItemRequest) bool {
+	return strings.EqualFold(strings.TrimSpace(a.Name), strings.TrimSpace(b.Name)) &&
+		a.Quantity == b.Quantity && sameDecimal(a.Total, b.Total)
+}
+
+// sameAdjustment reports whether two extracted lines are the same adjustment line
+func sameAdjustment(a, b domain.CreateAdjustmentRequest) bool {
+	return a.Type == b.Type && sameDecimal(a.Amount, b.Amount)
+}
+
+// sameDecimal compares two decimals by value, so "12.50" equals "12.5"
+func sameDecimal(a, b domain.Decimal) bool {
+	x, okX := a.Rat()
+	y, okY := b.Rat()
+	if !okX || !okY {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// decimalIsZero reports whether a decimal is empty or zero
+func decimalIsZero(d domain.Decimal) bool {
+	value, ok := d.Rat()
+	return !ok || value.Sign() == 0
+}
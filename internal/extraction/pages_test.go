@@ -0,0 +1,93 @@
+package extraction
+
+import (
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func item(name string, total domain.Decimal) domain.CreateItemRequest {
+	return domain.CreateItemRequest{Name: name, UnitPrice: total, Quantity: 1, Price: total, Total: total}
+}
+
+func itemNames(items []domain.CreateItemRequest) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+func TestStitchPages(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []domain.CreateReceiptRequest
+		want  []string
+	}{
+		{
+			name: "two pages keep the items of both",
+			pages: []domain.CreateReceiptRequest{
+				{Items: []domain.CreateItemRequest{item("Milk", "18000"), item("Bread", "15000")}},
+				{Items: []domain.CreateItemRequest{item("Eggs", "28000"), item("Rice", "65000")}},
+			},
+			want: []string{"Milk", "Bread", "Eggs", "Rice"},
+		},
+		{
+			name: "overlapping lines are dropped",
+			pages: []domain.CreateReceiptRequest{
+				{Items: []domain.CreateItemRequest{item("Milk", "18000"), item("Bread", "15000"), item("Eggs", "28000")}},
+				{Items: []domain.CreateItemRequest{item("bread", "15000.00"), item("Eggs", "28000"), item("Rice", "65000")}},
+			},
+			want: []string{"Milk", "Bread", "Eggs", "Rice"},
+		},
+		{
+			name: "a repeated item that does not start the page is kept",
+			pages: []domain.CreateReceiptRequest{
+				{Items: []domain.CreateItemRequest{item("Milk", "18000"), item("Bread", "15000")}},
+				{Items: []domain.CreateItemRequest{item("Eggs", "28000"), item("Bread", "15000")}},
+			},
+			want: []string{"Milk", "Bread", "Eggs", "Bread"},
+		},
+		{
+			name: "a page without items",
+			pages: []domain.CreateReceiptRequest{
+				{Items: []domain.CreateItemRequest{item("Milk", "18000")}},
+				{},
+			},
+			want: []string{"Milk"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := itemNames(StitchPages(tt.pages).Items)
+			if len(got) != len(tt.want) {
+				t.Fatalf("StitchPages() items = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("StitchPages() items = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStitchPagesHeaderAndTotals(t *testing.T) {
+	phone := int64(2150001234)
+	pages := []domain.CreateReceiptRequest{
+		{StoreName: "Indomaret", Phone: &phone, TotalSpending: "50000", RawText: "INDOMARET\nMilk 18.000"},
+		{StoreName: "Thank you", TotalItems: 4, TotalSpending: "126000", TotalDiscount: "0", RawText: "Rice 65.000\nTOTAL 126.000"},
+	}
+
+	got := StitchPages(pages)
+	if got.StoreName != "Indomaret" || got.Phone == nil || *got.Phone != phone {
+		t.Errorf("StitchPages() header = %q %v, want the first page's", got.StoreName, got.Phone)
+	}
+	if got.TotalItems != 4 || got.TotalSpending != "126000" || got.TotalDiscount != "" {
+		t.Errorf("StitchPages() totals = %d %s %s, want the last page's", got.TotalItems, got.TotalSpending, got.TotalDiscount)
+	}
+	if got.RawText != "INDOMARET\nMilk 18.000\nRice 65.000\nTOTAL 126.000" {
+		t.Errorf("StitchPages() raw text = %q", got.RawText)
+	}
+}
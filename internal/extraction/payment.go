@@ -0,0 +1,98 @@
+package extraction
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Payment is the payment information printed on a receipt; empty fields are unknown
+type Payment struct {
+	Method domain.PaymentMethod
+	Brand  string
+	Last4  string
+}
+
+// cardBrands recognize card networks, in English and Indonesian spelling
+var cardBrands = []struct {
+	pattern *regexp.Regexp
+	brand   string
+}{
+	{regexp.MustCompile(`(?i)\bvisa\b`), "VISA"},
+	{regexp.MustCompile(`(?i)\bmaster\s*card\b`), "MASTERCARD"},
+	{regexp.MustCompile(`(?i)\b(amex|american\s+express)\b`), "AMEX"},
+	{regexp.MustCompile(`(?i)\bjcb\b`), "JCB"},
+	{regexp.MustCompile(`(?i)\bunion\s*pay\b`), "UNIONPAY"},
+	{regexp.MustCompile(`(?i)\bgpn\b`), "GPN"},
+}
+
+// paymentPatterns recognize payment methods by keyword, checked in order so that a card
+// payment is not mistaken for cash because the receipt also prints a "cash" line
+var paymentPatterns = []struct {
+	pattern *regexp.Regexp
+	method  domain.PaymentMethod
+}{
+	{regexp.MustCompile(`(?i)\b(credit|kredit|cc)\b`), domain.PaymentCreditCard},
+	{regexp.MustCompile(`(?i)\b(debit|debet)\b`), domain.PaymentDebitCard},
+	{regexp.MustCompile(`(?i)\bqris\b`), domain.PaymentQRIS},
+	{regexp.MustCompile(`(?i)\b(gopay|ovo|dana|shopee\s*pay|linkaja|e-?wallet)\b`), domain.PaymentEWallet},
+	{regexp.MustCompile(`(?i)\b(transfer|bank\s+transfer|virtual\s+account)\b`), domain.PaymentBankTransfer},
+	{regexp.MustCompile(`(?i)\b(cash|tunai)\b`), domain.PaymentCash},
+}
+
+// maskedCardPattern matches a masked card number such as "**** **** **** 1234" or "XXXX-1234"
+var maskedCardPattern = regexp.MustCompile(`(?i)(?:[*x•]{2,}[\s-]*)+(\d{4})\b`)
+
+// endingPattern matches the last four digits introduced by words, e.g. "ending in 1234"
+var endingPattern = regexp.MustCompile(`(?i)\b(?:ending\s+(?:in|with)|akhiran)\s*:?\s*(\d{4})\b`)
+
+// DetectPayment finds the payment method, card brand and the last four digits of the card
+// number in receipt text. A card brand or masked number without a debit or credit keyword
+// is taken to be a credit card, unless the receipt was paid another way.
+func DetectPayment(text string) Payment {
+	var payment Payment
+
+	for _, b := range cardBrands {
+		if b.pattern.MatchString(text) {
+			payment.Brand = b.brand
+			break
+		}
+	}
+
+	if match := maskedCardPattern.FindStringSubmatch(text); match != nil {
+		payment.Last4 = match[1]
+	} else if match := endingPattern.FindStringSubmatch(text); match != nil {
+		payment.Last4 = match[1]
+	}
+
+	for _, p := range paymentPatterns {
+		if p.pattern.MatchString(text) {
+			payment.Method = p.method
+			break
+		}
+	}
+
+	switch {
+	case payment.Brand == "" && payment.Last4 == "", payment.Method.IsCard():
+		// Nothing to reconcile
+	case payment.Method == "" || payment.Method == domain.PaymentCash:
+		payment.Method = domain.PaymentCreditCard
+	default:
+		// Masked digits on e-wallet, QRIS and transfer receipts are account or phone numbers
+		payment.Brand, payment.Last4 = "", ""
+	}
+
+	return payment
+}
+
+// NormalizeCardBrand returns the canonical upper case name of a card brand
+func NormalizeCardBrand(brand string) string {
+	brand = strings.TrimSpace(brand)
+	for _, b := range cardBrands {
+		if b.pattern.MatchString(brand) {
+			return b.brand
+		}
+	}
+	return strings.ToUpper(brand)
+}
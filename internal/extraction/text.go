@@ -0,0 +1,383 @@
+package extraction
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// totalPatterns recognize total lines by their label, strongest first, so that "Grand Total"
+// wins over a plain "Total" printed above it
+var totalPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^\W*(grand\s*total|amount\s+due|total\s+(due|bayar|pembayaran|tagihan)|jumlah\s+(bayar|tagihan))\b`),
+	regexp.MustCompile(`(?i)^\W*(total|jumlah|tagihan)\b`),
+}
+
+// subtotalPattern matches subtotal lines, which are neither items nor the total
+var subtotalPattern = regexp.MustCompile(`(?i)^\W*sub\s*-?\s*total\b`)
+
+// discountPattern matches discount lines, whose amounts are summed into the receipt discount
+var discountPattern = regexp.MustCompile(`(?i)\b(discount|diskon|disc|potongan|promo|voucher|hemat|savings?)\b`)
+
+// nonItemPattern matches lines about the payment, the document or the store's address rather
+// than an item
+var nonItemPattern = regexp.MustCompile(`(?i)\b(jl|jln|jalan|street|road|avenue|cash|tunai|change|kembali(an)?|paid|bayar|payment|pembayaran|balance|saldo|invoice|faktur|receipt|struk|order|pesanan|telp?|phone|npwp|date|tanggal|time|jam|kasir|cashier|member|poin|points?|total\s+items?|qty)\b`)
+
+// documentTitlePattern matches generic document titles that are not the store name
+var documentTitlePattern = regexp.MustCompile(`(?i)^\W*(tax\s+)?(e-?receipt|receipt|invoice|faktur(\s+pajak)?|(struk|nota)(\s+(belanja|pembelian|penjualan))?|kwitansi|bukti\s+pembayaran|order\s+confirmation)\W*$`)
+
+// receiptFromPattern matches titles naming the store, e.g. "Your receipt from Kopi Kenangan"
+var receiptFromPattern = regexp.MustCompile(`(?i)^\W*(?:your\s+)?(?:e-?receipt|receipt|invoice|order)\s+(?:from|for)\s+(.+?)\W*$`)
+
+// quantityPattern matches a quantity times a unit price, e.g. "2 x 3.500" or "3 @ 12,000"
+var quantityPattern = regexp.MustCompile(`(?i)\b(\d{1,4})\s*(?:pcs\s*)?[x@×]\s*(?:rp\.?\s*|\$\s*)?(\d[\d.,]*)`)
+
+// numberTokenPattern matches a field that is an amount, optionally negative or with a currency
+var numberTokenPattern = regexp.MustCompile(`(?i)^\(?(-)?(?:rp\.?|idr|usd|\$|€|£)?(\d[\d.,]*)\)?$`)
+
+// currencyTokenPattern matches a currency printed as a separate field before an amount
+var currencyTokenPattern = regexp.MustCompile(`(?i)^(rp\.?|idr|usd|sgd|eur|\$|€|£)$`)
+
+// maxNameLength is the longest store or item name stored
+const maxNameLength = 255
+
+// letterPattern matches text with at least two letters in a row
+var letterPattern = regexp.MustCompile(`\pL{2,}`)
+
+// Date patterns, tried in order: ISO dates, day/month/year with numbers, day month year with
+// an English or Indonesian month name, and month day, year
+var (
+	isoDatePattern      = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	numericDatePattern  = regexp.MustCompile(`\b(\d{1,2})[/.-](\d{1,2})[/.-](\d{4}|\d{2})\b`)
+	dayMonthDatePattern = regexp.MustCompile(`(?i)\b(\d{1,2})\s+(jan|feb|mar|apr|mei|may|jun|jul|agu|agt|aug|sep|okt|oct|nov|des|dec)[a-z]*\.?,?\s+(\d{4})\b`)
+	monthDayDatePattern = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2}),?\s+(\d{4})\b`)
+)
+
+// monthNumbers maps English and Indonesian month abbreviations to month numbers
+var monthNumbers = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "mei": time.May, "jun": time.June, "jul": time.July,
+	"aug": time.August, "agu": time.August, "agt": time.August, "sep": time.September,
+	"oct": time.October, "okt": time.October, "nov": time.November, "dec": time.December,
+	"des": time.December,
+}
+
+// ParseText extracts a receipt from the text of a digital receipt, one printed line per line.
+// The store is the first line naming something other than the document, the total the
+// strongest total line, and items the lines above the total ending in amounts. The printed
+// total is taken to be the amount paid, so discounts are added back to the spending. Currency,
+// payment and adjustments are detected from RawText when the receipt is created.
+func ParseText(text string) domain.CreateReceiptRequest {
+	req := domain.CreateReceiptRequest{RawText: text}
+	lines := strings.Split(text, "\n")
+
+	req.StoreName = storeName(lines)
+	if date := findDate(text); date != "" {
+		req.Date = &date
+	}
+
+	total, totalLine := findTotal(lines)
+	end := len(lines)
+	if totalLine >= 0 {
+		end = totalLine
+	}
+
+	discount := new(big.Rat)
+	pendingName := ""
+	for _, line := range lines[:end] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if discountPattern.MatchString(line) {
+			if amount, ok := lastAmount(line); ok {
+				discount.Add(discount, new(big.Rat).Abs(amount))
+			}
+			pendingName = ""
+			continue
+		}
+
+		if subtotalPattern.MatchString(line) || nonItemPattern.MatchString(line) || summaryPattern.MatchString(line) || findDate(line) != "" {
+			pendingName = ""
+			continue
+		}
+		if _, ok := adjustmentType(line); ok {
+			pendingName = ""
+			continue
+		}
+
+		item, ok := parseItemLine(line)
+		switch {
+		case ok && item.Name == "" && pendingName != "":
+			// The quantity and price are printed on the line below the name
+			item.Name = clipName(pendingName)
+			req.Items = append(req.Items, item)
+			pendingName = ""
+		case ok && item.Name != "":
+			req.Items = append(req.Items, item)
+			pendingName = ""
+		case !ok && letterPattern.MatchString(line):
+			pendingName = line
+		default:
+			pendingName = ""
+		}
+	}
+
+	if total != nil {
+		req.TotalSpending = ratDecimal(new(big.Rat).Add(total, discount))
+	}
+	if discount.Sign() > 0 {
+		req.TotalDiscount = ratDecimal(discount)
+	}
+	req.TotalItems = len(req.Items)
+
+	return req
+}
+
+// storeName returns the first line that names the store rather than the document
+func storeName(lines []string) string {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || !letterPattern.MatchString(line) {
+			continue
+		}
+		if match := receiptFromPattern.FindStringSubmatch(line); match != nil {
+			return clipName(match[1])
+		}
+		if documentTitlePattern.MatchString(line) || findDate(line) != "" {
+			continue
+		}
+		return clipName(line)
+	}
+	return ""
+}
+
+// findTotal returns the amount of the strongest total line and its index, the last one when
+// several lines have the same strength, or -1 when there is none
+func findTotal(lines []string) (*big.Rat, int) {
+	for _, pattern := range totalPatterns {
+		var total *big.Rat
+		index := -1
+		for i, line := range lines {
+			if subtotalPattern.MatchString(line) || !pattern.MatchString(line) {
+				continue
+			}
+			if amount, ok := lastAmount(line); ok {
+				total, index = amount, i
+			}
+		}
+		if total != nil {
+			return total, firstTotalLine(lines, index)
+		}
+	}
+	return nil, -1
+}
+
+// firstTotalLine returns the first line of the block of totals ending at index, so that
+// subtotal, tax and discount lines above the total are not read as items
+func firstTotalLine(lines []string, index int) int {
+	for index > 0 {
+		previous := strings.TrimSpace(lines[index-1])
+		if !subtotalPattern.MatchString(previous) && !isTotalLine(previous) {
+			break
+		}
+		index--
+	}
+	return index
+}
+
+// isTotalLine reports whether a line is labelled as any kind of total
+func isTotalLine(line string) bool {
+	for _, pattern := range totalPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseItemLine reads an item from a line ending in amounts. The name is empty when the line
+// holds only a quantity and prices.
+func parseItemLine(line string) (domain.CreateItemRequest, bool) {
+	fields := strings.Fields(line)
+	var amounts []*big.Rat
+	nameEnd := len(fields)
+	for nameEnd > 0 {
+		field := fields[nameEnd-1]
+		if currencyTokenPattern.MatchString(field) && len(amounts) > 0 {
+			nameEnd--
+			continue
+		}
+		amount, ok := parseAmountField(field)
+		if !ok {
+			break
+		}
+		amounts = append([]*big.Rat{amount}, amounts...)
+		nameEnd--
+	}
+	if len(amounts) == 0 {
+		return domain.CreateItemRequest{}, false
+	}
+
+	name := strings.Join(fields[:nameEnd], " ")
+	total := amounts[len(amounts)-1]
+	quantity := int64(1)
+	unit := total
+
+	if match := quantityPattern.FindStringSubmatchIndex(line); match != nil {
+		quantity, _ = strconv.ParseInt(line[match[2]:match[3]], 10, 64)
+		if price, ok := parseAmountField(line[match[4]:match[5]]); ok {
+			unit = price
+		}
+		if match[0] < len(name) {
+			name = line[:match[0]]
+		}
+		// Without a separate line total the amount after the quantity is the unit price
+		if len(amounts) == 1 && quantity > 1 && total.Cmp(unit) == 0 {
+			total = new(big.Rat).Mul(unit, big.NewRat(quantity, 1))
+		}
+	} else {
+		switch {
+		case len(amounts) >= 3 && amounts[len(amounts)-3].IsInt():
+			quantity = amounts[len(amounts)-3].Num().Int64()
+			unit = amounts[len(amounts)-2]
+		case len(amounts) == 2 && amounts[0].IsInt() && amounts[0].Num().Int64() < 1000 && amounts[0].Sign() > 0:
+			quantity, unit = unitPrice(total, amounts[0].Num().Int64())
+		case len(amounts) == 2 && amounts[0].Sign() > 0:
+			unit = amounts[0]
+			if count := new(big.Rat).Quo(total, unit); count.IsInt() && count.Sign() > 0 {
+				quantity = count.Num().Int64()
+			}
+		}
+	}
+
+	name = strings.Trim(strings.TrimSpace(name), ".:-")
+	if quantity <= 0 || total.Sign() <= 0 || (name != "" && !letterPattern.MatchString(name)) {
+		return domain.CreateItemRequest{}, false
+	}
+
+	return domain.CreateItemRequest{
+		Name:      clipName(name),
+		UnitPrice: ratDecimal(unit),
+		Quantity:  int(quantity),
+		Price:     ratDecimal(total),
+		Total:     ratDecimal(total),
+	}, true
+}
+
+// unitPrice divides a line total by its quantity. When the unit price cannot be written with
+// the precision of the total, the line is taken as a single unit instead.
+func unitPrice(total *big.Rat, quantity int64) (int64, *big.Rat) {
+	unit := new(big.Rat).Quo(total, big.NewRat(quantity, 1))
+	scale := big.NewRat(100, 1)
+	if total.IsInt() {
+		scale = big.NewRat(1, 1)
+	}
+	if !new(big.Rat).Mul(unit, scale).IsInt() {
+		return 1, total
+	}
+	return quantity, unit
+}
+
+// lastAmount returns the last amount printed on a line
+func lastAmount(line string) (*big.Rat, bool) {
+	fields := strings.Fields(line)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if amount, ok := parseAmountField(fields[i]); ok {
+			return amount, true
+		}
+		if !currencyTokenPattern.MatchString(fields[i]) && i < len(fields)-1 {
+			break
+		}
+	}
+	return nil, false
+}
+
+// parseAmountField parses a field that is a whole amount, such as "12.500", "Rp12.500" or "(3.00)"
+func parseAmountField(field string) (*big.Rat, bool) {
+	match := numberTokenPattern.FindStringSubmatch(field)
+	if match == nil {
+		return nil, false
+	}
+
+	value, ok := parseNumber(match[2]).Rat()
+	if !ok {
+		return nil, false
+	}
+	if match[1] != "" || strings.HasPrefix(field, "(") {
+		value.Neg(value)
+	}
+	return value, true
+}
+
+// findDate returns the first date in text as YYYY-MM-DD, or "" when there is none. Numeric
+// dates are read day first.
+func findDate(text string) string {
+	if match := isoDatePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := makeDate(match[1], monthNumber(match[2]), match[3]); ok {
+			return date
+		}
+	}
+	if match := numericDatePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := makeDate(match[3], monthNumber(match[2]), match[1]); ok {
+			return date
+		}
+	}
+	if match := dayMonthDatePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := makeDate(match[3], monthNumbers[strings.ToLower(match[2])], match[1]); ok {
+			return date
+		}
+	}
+	if match := monthDayDatePattern.FindStringSubmatch(text); match != nil {
+		if date, ok := makeDate(match[3], monthNumbers[strings.ToLower(match[1])], match[2]); ok {
+			return date
+		}
+	}
+	return ""
+}
+
+// monthNumber parses a numeric month, returning 0 when it is not one
+func monthNumber(text string) time.Month {
+	month, _ := strconv.Atoi(text)
+	return time.Month(month)
+}
+
+// makeDate formats a date as YYYY-MM-DD, rejecting dates that do not exist. Two digit years
+// are taken to be in this century.
+func makeDate(yearText string, month time.Month, dayText string) (string, bool) {
+	year, err1 := strconv.Atoi(yearText)
+	day, err2 := strconv.Atoi(dayText)
+	if err1 != nil || err2 != nil || month < time.January || month > time.December {
+		return "", false
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || date.Month() != month {
+		return "", false
+	}
+	return date.Format("2006-01-02"), true
+}
+
+// clipName shortens a name to maxNameLength characters
+func clipName(name string) string {
+	if runes := []rune(name); len(runes) > maxNameLength {
+		return strings.TrimSpace(string(runes[:maxNameLength]))
+	}
+	return name
+}
+
+// ratDecimal formats an amount as a decimal with at most two fraction digits
+func ratDecimal(value *big.Rat) domain.Decimal {
+	if value.IsInt() {
+		return domain.Decimal(value.Num().String())
+	}
+	return domain.Decimal(strings.TrimRight(strings.TrimRight(value.FloatString(2), "0"), "."))
+}
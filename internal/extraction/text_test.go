@@ -0,0 +1,70 @@
+package extraction
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		store    string
+		date     string
+		total    domain.Decimal
+		discount domain.Decimal
+		items    []domain.CreateItemRequest
+	}{
+		{
+			name: "minimarket receipt",
+			text: "INDOMARET\nJl. Melati 5\n12/03/2026 14:30\nMilk 1L 18.000\nRoti Tawar\n2 x 7.500 15.000\n" +
+				"Diskon Member -3.000\nSubtotal 33.000\nPPN 11% 3.300\nTOTAL 33.300\nTunai 50.000\nKembali 16.700",
+			store:    "INDOMARET",
+			date:     "2026-03-12",
+			total:    "36300",
+			discount: "3000",
+			items: []domain.CreateItemRequest{
+				{Name: "Milk 1L", UnitPrice: "18000", Quantity: 1, Price: "18000", Total: "18000"},
+				{Name: "Roti Tawar", UnitPrice: "7500", Quantity: 2, Price: "15000", Total: "15000"},
+			},
+		},
+		{
+			name:  "document title before the store",
+			text:  "Receipt\nKopi Kenangan\nMar 12, 2026\nLatte 25,000\nTotal 25,000",
+			store: "Kopi Kenangan",
+			date:  "2026-03-12",
+			total: "25000",
+			items: []domain.CreateItemRequest{
+				{Name: "Latte", UnitPrice: "25000", Quantity: 1, Price: "25000", Total: "25000"},
+			},
+		},
+		{
+			name: "empty text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseText(tt.text)
+
+			date := ""
+			if got.Date != nil {
+				date = *got.Date
+			}
+			if got.StoreName != tt.store || date != tt.date {
+				t.Errorf("ParseText() store and date = %q %q, want %q %q", got.StoreName, date, tt.store, tt.date)
+			}
+			if got.TotalSpending != tt.total || got.TotalDiscount != tt.discount {
+				t.Errorf("ParseText() total and discount = %q %q, want %q %q", got.TotalSpending, got.TotalDiscount, tt.total, tt.discount)
+			}
+			if !reflect.DeepEqual(got.Items, tt.items) || got.TotalItems != len(tt.items) {
+				t.Errorf("ParseText() items = %+v, want %+v", got.Items, tt.items)
+			}
+			if got.RawText != tt.text {
+				t.Errorf("ParseText() raw text = %q, want the input", got.RawText)
+			}
+		})
+	}
+}
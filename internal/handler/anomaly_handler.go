@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type AnomalyHandler struct {
+	anomalyService service.AnomalyService
+}
+
+// NewAnomalyHandler creates a new anomaly handler
+func NewAnomalyHandler(anomalyService service.AnomalyService) *AnomalyHandler {
+	return &AnomalyHandler{anomalyService: anomalyService}
+}
+
+// GetAnomalies returns the workspace's anomaly feed, optionally filtered by status
+func (h *AnomalyHandler) GetAnomalies(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	page, limit := parsePagination(c)
+
+	anomalies, total, err := h.anomalyService.GetAnomalies(middleware.GetUserID(c), workspaceID, domain.AnomalyStatus(c.QueryParam("status")), page, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.PaginatedSuccessResponse(c, http.StatusOK, anomalies, utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// DetectAnomalies runs anomaly detection over the workspace's receipts now, instead of waiting
+// for the scheduled job
+func (h *AnomalyHandler) DetectAnomalies(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	result, err := h.anomalyService.DetectAnomalies(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Anomaly detection completed", result)
+}
+
+// UpdateAnomaly marks an anomaly as seen or dismisses it
+func (h *AnomalyHandler) UpdateAnomaly(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid anomaly id")
+	}
+
+	var req domain.UpdateAnomalyRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	anomaly, err := h.anomalyService.UpdateAnomaly(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Anomaly updated", anomaly)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type AttachmentHandler struct {
+	attachmentService service.AttachmentService
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(attachmentService service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService}
+}
+
+// GetAttachments lists the pages and supporting documents of a receipt
+func (h *AttachmentHandler) GetAttachments(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Attachments retrieved", attachments)
+}
+
+// UploadAttachment adds an uploaded file to a receipt as its next page, or as a supporting
+// document with kind=document
+func (h *AttachmentHandler) UploadAttachment(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Missing attachment file")
+	}
+
+	if fileHeader.Size > service.MaxAttachmentSize {
+		return utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Attachment must be at most %d MB", service.MaxAttachmentSize>>20))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read attachment file")
+	}
+	defer file.Close()
+
+	kind := domain.AttachmentKind(c.FormValue("kind"))
+	attachment, err := h.attachmentService.UploadAttachment(id, middleware.GetUserID(c), kind, fileHeader.Filename, file)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Attachment uploaded", attachment)
+}
+
+// DownloadAttachment streams the file of an attachment
+func (h *AttachmentHandler) DownloadAttachment(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	attachmentID, ok := paramID(c, "attachmentId")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid attachment id")
+	}
+
+	attachment, file, err := h.attachmentService.OpenAttachment(id, attachmentID, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+	defer file.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename}))
+	return c.Stream(http.StatusOK, attachment.ContentType, file)
+}
+
+// DeleteAttachment deletes an attachment of a receipt
+func (h *AttachmentHandler) DeleteAttachment(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	attachmentID, ok := paramID(c, "attachmentId")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid attachment id")
+	}
+
+	if err := h.attachmentService.DeleteAttachment(id, attachmentID, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Attachment deleted", nil)
+}
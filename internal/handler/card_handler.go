@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type CardHandler struct {
+	cardService service.CardService
+}
+
+// NewCardHandler creates a new card handler
+func NewCardHandler(cardService service.CardService) *CardHandler {
+	return &CardHandler{cardService: cardService}
+}
+
+// GetCards lists the user's registered cards
+func (h *CardHandler) GetCards(c echo.Context) error {
+	cards, err := h.cardService.GetCards(middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Cards retrieved", cards)
+}
+
+// CreateCard registers a card and links existing receipts paid with it
+func (h *CardHandler) CreateCard(c echo.Context) error {
+	var req domain.CreateCardRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	card, linked, err := h.cardService.CreateCard(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Card registered", map[string]interface{}{
+		"card":            card,
+		"linked_receipts": linked,
+	})
+}
+
+// DeleteCard deletes a registered card
+func (h *CardHandler) DeleteCard(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid card id")
+	}
+
+	if err := h.cardService.DeleteCard(id, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Card deleted", nil)
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type DocumentHandler struct {
+	documentService service.DocumentService
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(documentService service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{documentService: documentService}
+}
+
+// IngestDocument creates a receipt from an uploaded receipt photo or PDF or HTML e-receipt
+func (h *DocumentHandler) IngestDocument(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Missing document file")
+	}
+
+	if fileHeader.Size > service.MaxAttachmentSize {
+		return utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Document must be at most %d MB", service.MaxAttachmentSize>>20))
+	}
+
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read document file")
+	}
+	defer file.Close()
+
+	receipt, err := h.documentService.IngestDocument(middleware.GetUserID(c), workspaceID, fileHeader.Filename, file)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Receipt created from document", receipt)
+}
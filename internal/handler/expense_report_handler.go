@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ExpenseReportHandler struct {
+	expenseService service.ExpenseReportService
+}
+
+// NewExpenseReportHandler creates a new expense report handler
+func NewExpenseReportHandler(expenseService service.ExpenseReportService) *ExpenseReportHandler {
+	return &ExpenseReportHandler{expenseService: expenseService}
+}
+
+// GetReports lists the workspace's expense reports the user submitted or approves,
+// optionally filtered by status
+func (h *ExpenseReportHandler) GetReports(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	status := domain.ExpenseReportStatus(c.QueryParam("status"))
+	switch status {
+	case "", domain.ExpenseDraft, domain.ExpenseSubmitted, domain.ExpenseApproved, domain.ExpenseRejected, domain.ExpensePaid:
+	default:
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status")
+	}
+
+	reports, err := h.expenseService.GetReports(middleware.GetUserID(c), workspaceID, status)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense reports retrieved", reports)
+}
+
+// CreateReport creates a draft expense report
+func (h *ExpenseReportHandler) CreateReport(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	var req domain.CreateExpenseReportRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := h.expenseService.CreateReport(middleware.GetUserID(c), workspaceID, req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Expense report created", report)
+}
+
+// GetReport returns an expense report with its receipts and comments
+func (h *ExpenseReportHandler) GetReport(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	report, err := h.expenseService.GetReport(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report retrieved", report)
+}
+
+// UpdateReport changes the title and description of an expense report
+func (h *ExpenseReportHandler) UpdateReport(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	var req domain.CreateExpenseReportRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := h.expenseService.UpdateReport(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report updated", report)
+}
+
+// DeleteReport deletes a draft or rejected expense report
+func (h *ExpenseReportHandler) DeleteReport(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	if err := h.expenseService.DeleteReport(id, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report deleted", nil)
+}
+
+// AddReceipts adds receipts to an expense report
+func (h *ExpenseReportHandler) AddReceipts(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	var req domain.ExpenseReceiptsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := h.expenseService.AddReceipts(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipts added", report)
+}
+
+// RemoveReceipt removes a receipt from an expense report
+func (h *ExpenseReportHandler) RemoveReceipt(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	receiptID, ok := paramID(c, "receiptId")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	if err := h.expenseService.RemoveReceipt(id, receiptID, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipt removed", nil)
+}
+
+// SetApprovers replaces the approvers of an expense report
+func (h *ExpenseReportHandler) SetApprovers(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	var req domain.ExpenseApproversRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := h.expenseService.SetApprovers(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Approvers updated", report)
+}
+
+// SubmitReport submits an expense report for approval
+func (h *ExpenseReportHandler) SubmitReport(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	report, err := h.expenseService.SubmitReport(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report submitted", report)
+}
+
+// ApproveReport records the user's approval of an expense report
+func (h *ExpenseReportHandler) ApproveReport(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	var req domain.ReviewExpenseReportRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := h.expenseService.ApproveReport(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report approval recorded", report)
+}
+
+// RejectReport rejects an expense report
+func (h *ExpenseReportHandler) RejectReport(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	var req domain.ReviewExpenseReportRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := h.expenseService.RejectReport(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report rejected", report)
+}
+
+// MarkPaid marks an approved expense report as reimbursed
+func (h *ExpenseReportHandler) MarkPaid(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	report, err := h.expenseService.MarkPaid(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report paid", report)
+}
+
+// AddComment comments on an expense report
+func (h *ExpenseReportHandler) AddComment(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	var req domain.CreateExpenseCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	comment, err := h.expenseService.AddComment(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Comment added", comment)
+}
+
+// GetSummary returns the reimbursement summary of an expense report
+func (h *ExpenseReportHandler) GetSummary(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	summary, err := h.expenseService.GetSummary(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Expense report summary retrieved", summary)
+}
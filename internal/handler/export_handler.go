@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/export"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportReceipts streams the workspace's receipts as a spreadsheet (one row per item)
+// or in a personal-finance format (one transaction per receipt)
+func (h *ExportHandler) ExportReceipts(c echo.Context) error {
+	format := export.Format(c.QueryParam("format"))
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if !export.IsSupported(format) {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Unsupported export format")
+	}
+
+	filter, err := parseReceiptFilter(c)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	filename := fmt.Sprintf("receipts-%s.%s", time.Now().Format("20060102"), format)
+	c.Response().Header().Set(echo.HeaderContentType, export.ContentType(format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The status is sent with the first row, so errors before it, such as a workspace the user
+	// cannot read, get an error response; once the body is streaming failures can only be logged
+	if err := h.exportService.ExportReceipts(middleware.GetUserID(c), workspaceID, filter, format, c.Response()); err != nil {
+		if !c.Response().Committed {
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+			return utils.ErrorResponse(c, errorStatus(err), err.Error())
+		}
+		log.Printf("Failed to export receipts: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// errorStatus maps a service error to an HTTP status code
+func errorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(msg, "unauthorized"):
+		return http.StatusForbidden
+	case strings.HasPrefix(msg, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// paramID parses a positive integer path parameter
+func paramID(c echo.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// workspaceParam reads the workspace a request acts on from the X-Workspace-ID header or the
+// workspace_id query parameter, returning 0 for the user's personal workspace when neither is set
+func workspaceParam(c echo.Context) (int, bool) {
+	value := c.Request().Header.Get("X-Workspace-ID")
+	if value == "" {
+		value = c.QueryParam("workspace_id")
+	}
+	if value == "" {
+		return 0, true
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePagination reads page and limit query parameters with defaults of 1 and 20
+func parsePagination(c echo.Context) (int, int) {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	return page, limit
+}
+
+// parseReceiptFilter reads a receipt filter and sort order from the query string
+func parseReceiptFilter(c echo.Context) (domain.ReceiptFilter, error) {
+	filter := domain.ReceiptFilter{
+		StoreName: c.QueryParam("store"),
+		Status:    domain.ReceiptStatus(c.QueryParam("status")),
+		Category:  c.QueryParam("category"),
+		Tag:       domain.NormalizeTagName(c.QueryParam("tag")),
+		SortBy:    domain.ReceiptSortField(c.QueryParam("sort")),
+		SortOrder: domain.SortOrder(strings.ToLower(c.QueryParam("order"))),
+	}
+
+	for name, dest := range map[string]**time.Time{"date_from": &filter.DateFrom, "date_to": &filter.DateTo} {
+		value := c.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s, expected YYYY-MM-DD", name)
+		}
+		*dest = &date
+	}
+
+	for name, dest := range map[string]**domain.Decimal{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		value := c.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		amount, err := domain.ParseDecimal(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s", name)
+		}
+		*dest = &amount
+	}
+
+	filter.PaymentMethod = domain.PaymentMethod(c.QueryParam("payment_method"))
+	switch filter.PaymentMethod {
+	case "", domain.PaymentCash, domain.PaymentDebitCard, domain.PaymentCreditCard, domain.PaymentEWallet,
+		domain.PaymentQRIS, domain.PaymentBankTransfer, domain.PaymentOther:
+	default:
+		return filter, fmt.Errorf("invalid payment_method")
+	}
+
+	if value := c.QueryParam("merchant_id"); value != "" {
+		merchantID, err := strconv.Atoi(value)
+		if err != nil || merchantID <= 0 {
+			return filter, fmt.Errorf("invalid merchant_id")
+		}
+		filter.MerchantID = merchantID
+	}
+
+	if value := c.QueryParam("card_id"); value != "" {
+		cardID, err := strconv.Atoi(value)
+		if err != nil || cardID <= 0 {
+			return filter, fmt.Errorf("invalid card_id")
+		}
+		filter.CardID = cardID
+	}
+
+	if value := c.QueryParam("has_discount"); value != "" {
+		hasDiscount, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid has_discount")
+		}
+		filter.HasDiscount = &hasDiscount
+	}
+
+	switch filter.SortBy {
+	case "", domain.SortByUploadDate, domain.SortByDate, domain.SortByTotal, domain.SortByStore:
+	default:
+		return filter, fmt.Errorf("invalid sort, expected one of upload_date, date, total, store")
+	}
+
+	switch filter.SortOrder {
+	case "", domain.SortAsc, domain.SortDesc:
+	default:
+		return filter, fmt.Errorf("invalid order, expected asc or desc")
+	}
+
+	return filter, nil
+}
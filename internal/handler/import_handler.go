@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/importer"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// ImportReceipts imports receipts from an uploaded CSV or JSON file
+func (h *ImportHandler) ImportReceipts(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Missing import file")
+	}
+
+	format := importer.Format(c.QueryParam("format"))
+	if format == "" {
+		format = importer.Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), "."))
+	}
+
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+	batchSize, _ := strconv.Atoi(c.QueryParam("batch_size"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read import file")
+	}
+	defer file.Close()
+
+	records, err := importer.ParseReceipts(format, file)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	report, err := h.importService.ImportReceipts(middleware.GetUserID(c), workspaceID, records, dryRun, batchSize)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	message := "Receipts imported"
+	if dryRun {
+		message = "Import dry run completed"
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, message, report)
+}
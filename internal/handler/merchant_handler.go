@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type MerchantHandler struct {
+	merchantService service.MerchantService
+}
+
+// NewMerchantHandler creates a new merchant handler
+func NewMerchantHandler(merchantService service.MerchantService) *MerchantHandler {
+	return &MerchantHandler{merchantService: merchantService}
+}
+
+// GetMerchants lists the workspace's merchants with their aliases and receipt counts
+func (h *MerchantHandler) GetMerchants(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	merchants, err := h.merchantService.GetMerchants(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Merchants retrieved", merchants)
+}
+
+// GetMerchant returns one merchant of a workspace the user is a member of
+func (h *MerchantHandler) GetMerchant(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid merchant id")
+	}
+
+	merchant, err := h.merchantService.GetMerchantByID(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Merchant retrieved", merchant)
+}
+
+// UpdateMerchant renames a merchant or corrects its address and phone
+func (h *MerchantHandler) UpdateMerchant(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid merchant id")
+	}
+
+	var req domain.UpdateMerchantRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	merchant, err := h.merchantService.UpdateMerchant(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Merchant updated", merchant)
+}
+
+// MergeMerchants merges the workspace's source merchants into the target merchant
+func (h *MerchantHandler) MergeMerchants(c echo.Context) error {
+	var req domain.MergeMerchantsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	merchant, err := h.merchantService.MergeMerchants(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Merchants merged", merchant)
+}
+
+// MatchReceipts assigns merchants to the workspace's receipts that have none
+func (h *MerchantHandler) MatchReceipts(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	matched, err := h.merchantService.MatchReceipts(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipts matched to merchants", map[string]int{
+		"matched_receipts": matched,
+	})
+}
+
+// AdminGetMerchants lists the merchants of every workspace, or of the workspace_id query parameter
+func (h *MerchantHandler) AdminGetMerchants(c echo.Context) error {
+	workspaceID := 0
+	if value := c.QueryParam("workspace_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace_id")
+		}
+		workspaceID = id
+	}
+
+	merchants, err := h.merchantService.GetAllMerchants(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Merchants retrieved", merchants)
+}
+
+// AdminMergeMerchants merges merchants of any workspace
+func (h *MerchantHandler) AdminMergeMerchants(c echo.Context) error {
+	var req domain.MergeMerchantsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	merchant, err := h.merchantService.AdminMergeMerchants(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Merchants merged", merchant)
+}
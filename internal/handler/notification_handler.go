@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetNotifications lists the user's notifications; unread=true lists only unread ones
+func (h *NotificationHandler) GetNotifications(c echo.Context) error {
+	page, limit := parsePagination(c)
+	unreadOnly, _ := strconv.ParseBool(c.QueryParam("unread"))
+
+	notifications, total, err := h.notificationService.GetNotifications(middleware.GetUserID(c), unreadOnly, page, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.PaginatedSuccessResponse(c, http.StatusOK, notifications, utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// MarkRead marks a notification as read
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notification id")
+	}
+
+	if err := h.notificationService.MarkRead(id, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	count, err := h.notificationService.MarkAllRead(middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Notifications marked as read", map[string]int64{"updated": count})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ProductHandler struct {
+	productService service.ProductService
+}
+
+// NewProductHandler creates a new product handler
+func NewProductHandler(productService service.ProductService) *ProductHandler {
+	return &ProductHandler{productService: productService}
+}
+
+// GetProducts lists the workspace's products with their aliases and purchase counts
+func (h *ProductHandler) GetProducts(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	products, err := h.productService.GetProducts(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Products retrieved", products)
+}
+
+// GetProduct returns one product of a workspace the user is a member of
+func (h *ProductHandler) GetProduct(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product id")
+	}
+
+	product, err := h.productService.GetProductByID(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Product retrieved", product)
+}
+
+// UpdateProduct renames a product or sets its unit, barcode and SKU
+func (h *ProductHandler) UpdateProduct(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product id")
+	}
+
+	var req domain.UpdateProductRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	product, err := h.productService.UpdateProduct(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Product updated", product)
+}
+
+// MergeProducts merges the workspace's source products into the target product
+func (h *ProductHandler) MergeProducts(c echo.Context) error {
+	var req domain.MergeProductsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	product, err := h.productService.MergeProducts(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Products merged", product)
+}
+
+// MatchItems assigns products to the workspace's items that have none
+func (h *ProductHandler) MatchItems(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	matched, err := h.productService.MatchItems(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Items matched to products", map[string]int{
+		"matched_items": matched,
+	})
+}
+
+// GetPriceHistory lists what the workspace paid for a product on each receipt
+func (h *ProductHandler) GetPriceHistory(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product id")
+	}
+
+	prices, err := h.productService.GetPriceHistory(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Price history retrieved", prices)
+}
+
+// GetTopProducts ranks the workspace's products by spending on receipts matching the filter
+func (h *ProductHandler) GetTopProducts(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	filter, err := parseReceiptFilter(c)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	_, limit := parsePagination(c)
+
+	top, err := h.productService.GetTopProducts(middleware.GetUserID(c), workspaceID, filter, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Top products retrieved", top)
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ReceiptHandler struct {
+	receiptService service.ReceiptService
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(receiptService service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+// GetReceipts lists receipts with filtering, sorting and pagination.
+// Passing cursor (or pagination=cursor for the first page) switches from page numbers
+// to keyset pagination ordered by upload date.
+func (h *ReceiptHandler) GetReceipts(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	filter, err := parseReceiptFilter(c)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	page, limit := parsePagination(c)
+
+	cursor := c.QueryParam("cursor")
+	if cursor != "" || c.QueryParam("pagination") == "cursor" {
+		if filter.SortBy != "" && filter.SortBy != domain.SortByUploadDate {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Cursor pagination only supports sorting by upload_date")
+		}
+
+		receipts, err := h.receiptService.GetReceiptsByCursor(middleware.GetUserID(c), workspaceID, filter, cursor, limit)
+		if err != nil {
+			return utils.ErrorResponse(c, errorStatus(err), err.Error())
+		}
+
+		return utils.PaginatedSuccessResponse(c, http.StatusOK, receipts.Receipts, utils.PaginationMeta{
+			Limit:      limit,
+			NextCursor: receipts.NextCursor,
+			PrevCursor: receipts.PrevCursor,
+		})
+	}
+
+	receipts, total, err := h.receiptService.GetReceipts(middleware.GetUserID(c), workspaceID, filter, page, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.PaginatedSuccessResponse(c, http.StatusOK, receipts, utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// GetStats returns spending statistics for receipts matching the filter
+func (h *ReceiptHandler) GetStats(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	filter, err := parseReceiptFilter(c)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	stats, err := h.receiptService.GetStats(middleware.GetUserID(c), workspaceID, filter)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Stats retrieved", stats)
+}
+
+// SearchReceipts searches receipts by store, address and item names
+func (h *ReceiptHandler) SearchReceipts(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	query := c.QueryParam("q")
+	if query == "" {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Missing search query")
+	}
+
+	page, limit := parsePagination(c)
+	results, err := h.receiptService.SearchReceipts(middleware.GetUserID(c), workspaceID, query, page, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipts found", results)
+}
+
+// GetDuplicates lists receipts awaiting duplicate review
+func (h *ReceiptHandler) GetDuplicates(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	receipts, err := h.receiptService.GetSuspectedDuplicates(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Suspected duplicates retrieved", receipts)
+}
+
+// ConfirmDuplicate confirms a receipt is a duplicate of its original
+func (h *ReceiptHandler) ConfirmDuplicate(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	receipt, err := h.receiptService.ConfirmDuplicate(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Duplicate confirmed", receipt)
+}
+
+// DismissDuplicate marks a suspected duplicate as a distinct receipt
+func (h *ReceiptHandler) DismissDuplicate(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	receipt, err := h.receiptService.DismissDuplicate(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Duplicate dismissed", receipt)
+}
+
+// ApplyPageExtraction updates a receipt from the extraction results of its pages
+func (h *ReceiptHandler) ApplyPageExtraction(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	var req domain.PageExtractionRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	receipt, err := h.receiptService.ApplyPageExtraction(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipt pages stitched", receipt)
+}
+
+// UpdateReceiptNotes replaces the notes of a receipt
+func (h *ReceiptHandler) UpdateReceiptNotes(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	var req domain.UpdateNotesRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	receipt, err := h.receiptService.UpdateReceiptNotes(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipt notes updated", receipt)
+}
+
+// UpdateItemNotes replaces the notes of an item
+func (h *ReceiptHandler) UpdateItemNotes(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+	}
+
+	var req domain.UpdateNotesRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	item, err := h.receiptService.UpdateItemNotes(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Item notes updated", item)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/importer"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(reconciliationService service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationService: reconciliationService}
+}
+
+// ImportStatement imports an uploaded CSV or OFX bank statement. CSV columns are mapped with
+// the *_column parameters, which default to the lower case field names.
+func (h *ReconciliationHandler) ImportStatement(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Missing statement file")
+	}
+
+	format := importer.Format(c.QueryParam("format"))
+	if format == "" {
+		format = importer.Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), "."))
+	}
+	if format == "qfx" {
+		format = importer.FormatOFX
+	}
+
+	columns, ok := parseStatementColumns(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid delimiter, expected a single character")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read statement file")
+	}
+	defer file.Close()
+
+	statement, err := importer.ParseStatement(format, file, columns)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	report, err := h.reconciliationService.ImportStatement(middleware.GetUserID(c), c.QueryParam("account"), statement)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Statement imported", report)
+}
+
+// parseStatementColumns reads the CSV column mapping from the query string
+func parseStatementColumns(c echo.Context) (importer.StatementColumns, bool) {
+	columns := importer.DefaultStatementColumns()
+
+	for name, dest := range map[string]*string{
+		"date_column":        &columns.Date,
+		"description_column": &columns.Description,
+		"amount_column":      &columns.Amount,
+		"debit_column":       &columns.Debit,
+		"credit_column":      &columns.Credit,
+		"currency_column":    &columns.Currency,
+		"id_column":          &columns.ID,
+		"date_format":        &columns.DateFormat,
+	} {
+		if value := c.QueryParam(name); value != "" {
+			*dest = value
+		}
+	}
+
+	columns.DecimalComma, _ = strconv.ParseBool(c.QueryParam("decimal_comma"))
+
+	if delimiter := c.QueryParam("delimiter"); delimiter != "" {
+		if delimiter == `\t` {
+			delimiter = "\t"
+		}
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return columns, false
+		}
+		columns.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	}
+
+	return columns, true
+}
+
+// GetReport lists matched pairs, unmatched receipts of the workspace and unmatched bank transactions
+func (h *ReconciliationHandler) GetReport(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	report, err := h.reconciliationService.GetReport(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Reconciliation retrieved", report)
+}
+
+// Reconcile matches unmatched bank transactions with receipts of the workspace
+func (h *ReconciliationHandler) Reconcile(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	report, err := h.reconciliationService.Reconcile(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Reconciliation completed", report)
+}
+
+// MatchTransaction matches a bank transaction with a receipt by hand
+func (h *ReconciliationHandler) MatchTransaction(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid bank transaction id")
+	}
+
+	var req domain.MatchTransactionRequest
+	if err := c.Bind(&req); err != nil || req.ReceiptID <= 0 {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	match, err := h.reconciliationService.MatchTransaction(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Bank transaction matched", match)
+}
+
+// UnmatchTransaction removes the receipt match of a bank transaction
+func (h *ReconciliationHandler) UnmatchTransaction(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid bank transaction id")
+	}
+
+	transaction, err := h.reconciliationService.UnmatchTransaction(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Bank transaction unmatched", transaction)
+}
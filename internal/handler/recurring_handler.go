@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type RecurringHandler struct {
+	recurringService service.RecurringService
+}
+
+// NewRecurringHandler creates a new recurring purchase handler
+func NewRecurringHandler(recurringService service.RecurringService) *RecurringHandler {
+	return &RecurringHandler{recurringService: recurringService}
+}
+
+// GetRecurring lists the detected recurring purchases; dismissed=true includes dismissed ones
+func (h *RecurringHandler) GetRecurring(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	includeDismissed, _ := strconv.ParseBool(c.QueryParam("dismissed"))
+
+	items, err := h.recurringService.GetRecurring(middleware.GetUserID(c), workspaceID, includeDismissed)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Recurring purchases retrieved", items)
+}
+
+// DetectRecurring runs recurring purchase detection over the workspace's receipts
+func (h *RecurringHandler) DetectRecurring(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	items, err := h.recurringService.DetectRecurring(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Recurring purchases detected", items)
+}
+
+// UpdateRecurring dismisses or restores a recurring purchase
+func (h *RecurringHandler) UpdateRecurring(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid recurring purchase id")
+	}
+
+	var req domain.UpdateRecurringRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	item, err := h.recurringService.UpdateRecurring(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Recurring purchase updated", item)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// MonthlyReport renders the workspace's spending in a month (month=YYYY-MM, default the current
+// month) as a PDF, with receipt image thumbnails when images=true
+func (h *ReportHandler) MonthlyReport(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	month := time.Now()
+	if value := c.QueryParam("month"); value != "" {
+		parsed, err := time.Parse("2006-01", value)
+		if err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid month, expected YYYY-MM")
+		}
+		month = parsed
+	}
+
+	withImages, _ := strconv.ParseBool(c.QueryParam("images"))
+
+	return writePDF(c, fmt.Sprintf("spending-%s.pdf", month.Format("2006-01")), func() error {
+		return h.reportService.WriteMonthlyReport(middleware.GetUserID(c), workspaceID, month, withImages, c.Response())
+	})
+}
+
+// ExpenseReportPDF renders an expense report as a PDF, with receipt image thumbnails when images=true
+func (h *ReportHandler) ExpenseReportPDF(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid expense report id")
+	}
+
+	withImages, _ := strconv.ParseBool(c.QueryParam("images"))
+
+	return writePDF(c, fmt.Sprintf("expense-report-%d.pdf", id), func() error {
+		return h.reportService.WriteExpenseReport(id, middleware.GetUserID(c), withImages, c.Response())
+	})
+}
+
+// writePDF sends the PDF written by render as a download. The document is only written once it
+// is complete, so errors such as a report the user cannot see get an error response.
+func writePDF(c echo.Context, filename string, render func() error) error {
+	c.Response().Header().Set(echo.HeaderContentType, "application/pdf")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := render(); err != nil {
+		if !c.Response().Committed {
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+			return utils.ErrorResponse(c, errorStatus(err), err.Error())
+		}
+		log.Printf("Failed to write PDF report: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type SplitHandler struct {
+	splitService service.SplitService
+}
+
+// NewSplitHandler creates a new split handler
+func NewSplitHandler(splitService service.SplitService) *SplitHandler {
+	return &SplitHandler{splitService: splitService}
+}
+
+// SplitReceipt divides a receipt between workspace members, replacing any earlier split
+func (h *SplitHandler) SplitReceipt(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	var req domain.SplitRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	split, err := h.splitService.SplitReceipt(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipt split", split)
+}
+
+// GetSplit returns the split of a receipt
+func (h *SplitHandler) GetSplit(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	split, err := h.splitService.GetSplit(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Split retrieved", split)
+}
+
+// DeleteSplit removes the split of a receipt
+func (h *SplitHandler) DeleteSplit(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	if err := h.splitService.DeleteSplit(id, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Split deleted", nil)
+}
+
+// CreateSettlement records a payment settling split debts between two workspace members
+func (h *SplitHandler) CreateSettlement(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	var req domain.CreateSettlementRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	settlement, err := h.splitService.CreateSettlement(middleware.GetUserID(c), workspaceID, req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Settlement recorded", settlement)
+}
+
+// GetLedger lists the split shares and settlements of a workspace over time
+func (h *SplitHandler) GetLedger(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	entries, err := h.splitService.GetLedger(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Ledger retrieved", entries)
+}
+
+// GetBalances returns who owes whom in a workspace, per currency
+func (h *SplitHandler) GetBalances(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	balances, err := h.splitService.GetBalances(middleware.GetUserID(c), workspaceID)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Balances retrieved", balances)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type TagHandler struct {
+	tagService service.TagService
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(tagService service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// GetTags lists the user's tags with their receipt counts
+func (h *TagHandler) GetTags(c echo.Context) error {
+	tags, err := h.tagService.GetTags(middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Tags retrieved", tags)
+}
+
+// CreateTag creates a tag
+func (h *TagHandler) CreateTag(c echo.Context) error {
+	var req domain.TagRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	tag, err := h.tagService.CreateTag(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Tag created", tag)
+}
+
+// RenameTag renames a tag
+func (h *TagHandler) RenameTag(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tag id")
+	}
+
+	var req domain.TagRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	tag, err := h.tagService.RenameTag(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Tag renamed", tag)
+}
+
+// DeleteTag deletes a tag and removes it from every receipt
+func (h *TagHandler) DeleteTag(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tag id")
+	}
+
+	if err := h.tagService.DeleteTag(id, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Tag deleted", nil)
+}
+
+// GetReceiptTags lists the user's tags on a receipt
+func (h *TagHandler) GetReceiptTags(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	tags, err := h.tagService.GetReceiptTags(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipt tags retrieved", tags)
+}
+
+// SetReceiptTags replaces the user's tags on a receipt
+func (h *TagHandler) SetReceiptTags(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid receipt id")
+	}
+
+	var req domain.SetReceiptTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	tags, err := h.tagService.SetReceiptTags(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Receipt tags updated", tags)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type WarrantyHandler struct {
+	warrantyService service.WarrantyService
+}
+
+// NewWarrantyHandler creates a new warranty handler
+func NewWarrantyHandler(warrantyService service.WarrantyService) *WarrantyHandler {
+	return &WarrantyHandler{warrantyService: warrantyService}
+}
+
+// GetWarranty returns the warranty and return window of an item with their expiry dates
+func (h *WarrantyHandler) GetWarranty(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+	}
+
+	warranty, err := h.warrantyService.GetWarranty(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Warranty retrieved", warranty)
+}
+
+// SetWarranty sets the warranty length and return window of an item
+func (h *WarrantyHandler) SetWarranty(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+	}
+
+	var req domain.SetWarrantyRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	warranty, err := h.warrantyService.SetWarranty(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Warranty saved", warranty)
+}
+
+// DeleteWarranty stops tracking the warranty of an item
+func (h *WarrantyHandler) DeleteWarranty(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+	}
+
+	if err := h.warrantyService.DeleteWarranty(id, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Warranty deleted", nil)
+}
+
+// GetUpcoming lists warranties and return windows of the workspace expiring within the next
+// days (30 by default); kind=warranty or kind=return lists one kind
+func (h *WarrantyHandler) GetUpcoming(c echo.Context) error {
+	workspaceID, ok := workspaceParam(c)
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	days := 0
+	if value := c.QueryParam("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid days")
+		}
+		days = parsed
+	}
+
+	expirations, err := h.warrantyService.GetUpcoming(middleware.GetUserID(c), workspaceID, domain.ExpirationKind(c.QueryParam("kind")), days)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Upcoming expirations retrieved", expirations)
+}
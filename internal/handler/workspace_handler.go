@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/middleware"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/service"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+}
+
+// NewWorkspaceHandler creates a new workspace handler
+func NewWorkspaceHandler(workspaceService service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+// GetWorkspaces lists the workspaces the user is a member of
+func (h *WorkspaceHandler) GetWorkspaces(c echo.Context) error {
+	workspaces, err := h.workspaceService.GetWorkspaces(middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Workspaces retrieved", workspaces)
+}
+
+// CreateWorkspace creates a shared workspace owned by the user
+func (h *WorkspaceHandler) CreateWorkspace(c echo.Context) error {
+	var req domain.CreateWorkspaceRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Workspace created", workspace)
+}
+
+// GetWorkspace returns a workspace of the user with its members
+func (h *WorkspaceHandler) GetWorkspace(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Workspace retrieved", workspace)
+}
+
+// UpdateWorkspace renames a workspace
+func (h *WorkspaceHandler) UpdateWorkspace(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	var req domain.CreateWorkspaceRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	workspace, err := h.workspaceService.UpdateWorkspace(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Workspace updated", workspace)
+}
+
+// InviteMember invites an email address to a workspace. The response carries the acceptance
+// token, which the owner passes on to the invitee.
+func (h *WorkspaceHandler) InviteMember(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	var req domain.InviteMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	invitation, err := h.workspaceService.InviteMember(id, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Invitation created", invitation)
+}
+
+// GetPendingInvitations lists the open invitations of a workspace
+func (h *WorkspaceHandler) GetPendingInvitations(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	invitations, err := h.workspaceService.GetPendingInvitations(id, middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Invitations retrieved", invitations)
+}
+
+// RevokeInvitation deletes an invitation of a workspace
+func (h *WorkspaceHandler) RevokeInvitation(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	invitationID, ok := paramID(c, "invitationId")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invitation id")
+	}
+
+	if err := h.workspaceService.RevokeInvitation(id, invitationID, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Invitation revoked", nil)
+}
+
+// GetMyInvitations lists the open invitations sent to the user
+func (h *WorkspaceHandler) GetMyInvitations(c echo.Context) error {
+	invitations, err := h.workspaceService.GetMyInvitations(middleware.GetUserID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Invitations retrieved", invitations)
+}
+
+// AcceptInvitation joins a workspace with an invitation token
+func (h *WorkspaceHandler) AcceptInvitation(c echo.Context) error {
+	var req domain.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	workspace, err := h.workspaceService.AcceptInvitation(middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Invitation accepted", workspace)
+}
+
+// UpdateMember changes the role of a workspace member
+func (h *WorkspaceHandler) UpdateMember(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	memberID, ok := paramID(c, "userId")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user id")
+	}
+
+	var req domain.UpdateMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	member, err := h.workspaceService.UpdateMember(id, memberID, middleware.GetUserID(c), req)
+	if err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Member updated", member)
+}
+
+// RemoveMember removes a member from a workspace, or lets a member leave it
+func (h *WorkspaceHandler) RemoveMember(c echo.Context) error {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid workspace id")
+	}
+
+	memberID, ok := paramID(c, "userId")
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user id")
+	}
+
+	if err := h.workspaceService.RemoveMember(id, memberID, middleware.GetUserID(c)); err != nil {
+		return utils.ErrorResponse(c, errorStatus(err), err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Member removed", nil)
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Exchange rate file formats
+const (
+	FormatRatesCSV Format = "csv"
+	FormatECB      Format = "ecb"
+)
+
+// ecbBaseCurrency is the base of every rate in the ECB reference rate feed
+const ecbBaseCurrency = "EUR"
+
+// ParseRates reads exchange rates in the given format
+func ParseRates(format Format, r io.Reader) ([]domain.ExchangeRate, error) {
+	switch format {
+	case FormatRatesCSV:
+		return ParseRatesCSV(r)
+	case FormatECB:
+		return ParseECB(r)
+	default:
+		return nil, fmt.Errorf("unsupported rates format: %s", format)
+	}
+}
+
+// ParseRatesCSV reads rates from a CSV file with the columns date, base, quote and rate,
+// where 1 base = rate quote. Dates use the 2006-01-02 layout.
+func ParseRatesCSV(r io.Reader) ([]domain.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing csv column: %s", name)
+		}
+	}
+
+	var rates []domain.ExchangeRate
+	line := 1
+
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid csv row: %w", line, err)
+		}
+
+		row := csvRow{index: index, fields: fields}
+
+		date, err := time.Parse("2006-01-02", row.get("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date: %s", line, row.get("date"))
+		}
+
+		rate, err := newExchangeRate(row.get("base"), row.get("quote"), row.get("rate"), date, "csv")
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// ecbEnvelope is the layout of the ECB euro foreign exchange reference rates XML
+// (eurofxref-daily.xml / eurofxref-hist.xml)
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB reads the ECB reference rates XML, where every rate is quoted against EUR
+func ParseECB(r io.Reader) ([]domain.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("invalid ecb xml: %w", err)
+	}
+
+	var rates []domain.ExchangeRate
+	for _, day := range envelope.Days {
+		date, err := time.Parse("2006-01-02", day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ecb date: %s", day.Time)
+		}
+
+		for _, cube := range day.Rates {
+			rate, err := newExchangeRate(ecbBaseCurrency, cube.Currency, cube.Rate, date, "ecb")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", day.Time, err)
+			}
+			rates = append(rates, rate)
+		}
+	}
+
+	return rates, nil
+}
+
+// newExchangeRate validates and builds one rate
+func newExchangeRate(base, quote, value string, date time.Time, source string) (domain.ExchangeRate, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+
+	if len(base) != 3 || len(quote) != 3 {
+		return domain.ExchangeRate{}, fmt.Errorf("invalid currency pair: %s/%s", base, quote)
+	}
+
+	rate, err := domain.ParseDecimal(value)
+	if err != nil {
+		return domain.ExchangeRate{}, fmt.Errorf("invalid rate for %s/%s: %s", base, quote, value)
+	}
+	if exact, _ := rate.Rat(); exact.Sign() <= 0 {
+		return domain.ExchangeRate{}, fmt.Errorf("invalid rate for %s/%s: %s", base, quote, value)
+	}
+
+	return domain.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate,
+		RateDate:      date,
+		Source:        source,
+	}, nil
+}
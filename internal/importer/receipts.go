@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Format is an import file format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// Record is one receipt read from an import file.
+// Row is the 1-based position in the source (CSV line or JSON array index),
+// and Err is set when the record could not be parsed.
+type Record struct {
+	Row     int
+	Request domain.CreateReceiptRequest
+	Err     error
+}
+
+// ParseReceipts reads receipt records in the given format
+func ParseReceipts(format Format, r io.Reader) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatJSON:
+		return ParseJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+}
+
+// ParseJSON reads a JSON array of CreateReceiptRequest objects
+func ParseJSON(r io.Reader) ([]Record, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid json: expected an array of receipts: %w", err)
+	}
+
+	records := make([]Record, len(raw))
+	for i, msg := range raw {
+		records[i].Row = i + 1
+		if err := json.Unmarshal(msg, &records[i].Request); err != nil {
+			records[i].Err = fmt.Errorf("invalid receipt: %w", err)
+		}
+	}
+
+	return records, nil
+}
+
+// ParseCSV reads receipts from a CSV file with one row per item.
+// Column names follow the CreateReceiptRequest JSON fields, with item fields prefixed by "item_"
+// (item_name, item_unit_price, ...) and one amount column per adjustment type (tax, service, tip,
+// rounding, delivery_fee). Consecutive rows sharing a receipt_ref are grouped into
+// one receipt; without a receipt_ref column every row is its own receipt.
+func ParseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var records []Record
+	var currentRef string
+	current := -1
+	line := 1
+
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			records = append(records, Record{Row: line, Err: fmt.Errorf("invalid csv row: %w", err)})
+			current = -1
+			continue
+		}
+
+		row := csvRow{index: index, fields: fields}
+		ref := row.get("receipt_ref")
+
+		if current < 0 || ref == "" || ref != currentRef {
+			records = append(records, Record{Row: line})
+			current = len(records) - 1
+			currentRef = ref
+			records[current].Err = row.fillReceipt(&records[current].Request)
+		}
+
+		record := &records[current]
+		if record.Err != nil {
+			continue
+		}
+
+		if row.get("item_name") != "" {
+			item, err := row.item()
+			if err != nil {
+				record.Err = fmt.Errorf("line %d: %w", line, err)
+				continue
+			}
+			record.Request.Items = append(record.Request.Items, item)
+		}
+	}
+
+	return records, nil
+}
+
+// csvRow gives access to a CSV record by column name
+type csvRow struct {
+	index  map[string]int
+	fields []string
+}
+
+// get returns the trimmed value of a column, or "" if the column is absent
+func (r csvRow) get(name string) string {
+	i, ok := r.index[name]
+	if !ok || i >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+// fillReceipt sets the receipt header fields of req
+func (r csvRow) fillReceipt(req *domain.CreateReceiptRequest) error {
+	req.StoreName = r.get("store_name")
+	req.Address = r.get("address")
+
+	if phone := r.get("phone"); phone != "" {
+		value, err := strconv.ParseInt(phone, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid phone: %s", phone)
+		}
+		req.Phone = &value
+	}
+
+	if date := r.get("date"); date != "" {
+		req.Date = &date
+	}
+	req.Currency = strings.ToUpper(r.get("currency"))
+	req.PaymentMethod = domain.PaymentMethod(strings.ToLower(r.get("payment_method")))
+	req.CardBrand = r.get("card_brand")
+	req.CardLast4 = r.get("card_last4")
+
+	var err error
+	if req.TotalItems, err = r.int("total_items"); err != nil {
+		return err
+	}
+	if req.TotalSpending, err = r.decimal("total_spending"); err != nil {
+		return err
+	}
+	if req.TotalDiscount, err = r.decimal("total_discount"); err != nil {
+		return err
+	}
+
+	for _, adjustmentType := range domain.AdjustmentTypes {
+		amount, err := r.decimal(string(adjustmentType))
+		if err != nil {
+			return err
+		}
+		if amount != "" {
+			req.Adjustments = append(req.Adjustments, domain.CreateAdjustmentRequest{Type: adjustmentType, Amount: amount})
+		}
+	}
+
+	return nil
+}
+
+// item reads the item columns of the row
+func (r csvRow) item() (domain.CreateItemRequest, error) {
+	item := domain.CreateItemRequest{
+		Name:     r.get("item_name"),
+		Category: r.get("item_category"),
+	}
+
+	var err error
+	if item.UnitPrice, err = r.decimal("item_unit_price"); err != nil {
+		return item, err
+	}
+	if item.Quantity, err = r.int("item_quantity"); err != nil {
+		return item, err
+	}
+	if item.Price, err = r.decimal("item_price"); err != nil {
+		return item, err
+	}
+	if item.Total, err = r.decimal("item_total"); err != nil {
+		return item, err
+	}
+
+	return item, nil
+}
+
+// int parses an integer column, treating an empty value as zero
+func (r csvRow) int(name string) (int, error) {
+	value := r.get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return n, nil
+}
+
+// decimal reads an exact decimal column, leaving an empty value empty
+func (r csvRow) decimal(name string) (domain.Decimal, error) {
+	value := r.get(name)
+	if value == "" {
+		return "", nil
+	}
+	n, err := domain.ParseDecimal(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// parsedRecord is what a test checks of a record
+type parsedRecord struct {
+	Row   int
+	Store string
+	Items []string
+	Err   string
+}
+
+func summarize(records []Record) []parsedRecord {
+	summary := make([]parsedRecord, len(records))
+	for i, record := range records {
+		summary[i] = parsedRecord{Row: record.Row, Store: record.Request.StoreName}
+		for _, item := range record.Request.Items {
+			summary[i].Items = append(summary[i].Items, item.Name)
+		}
+		if record.Err != nil {
+			summary[i].Err = record.Err.Error()
+		}
+	}
+	return summary
+}
+
+func TestParseCSVGrouping(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []parsedRecord
+	}{
+		{
+			name: "consecutive rows with a reference",
+			csv: "receipt_ref,store_name,item_name,item_quantity\n" +
+				"a,Indomaret,Milk,1\n" +
+				"a,,Bread,2\n" +
+				"b,Alfamart,Eggs,1\n",
+			want: []parsedRecord{
+				{Row: 2, Store: "Indomaret", Items: []string{"Milk", "Bread"}},
+				{Row: 4, Store: "Alfamart", Items: []string{"Eggs"}},
+			},
+		},
+		{
+			name: "without a reference column",
+			csv: "store_name,item_name\n" +
+				"Indomaret,Milk\n" +
+				"Indomaret,Bread\n",
+			want: []parsedRecord{
+				{Row: 2, Store: "Indomaret", Items: []string{"Milk"}},
+				{Row: 3, Store: "Indomaret", Items: []string{"Bread"}},
+			},
+		},
+		{
+			name: "empty references",
+			csv: "receipt_ref,store_name,item_name\n" +
+				",Indomaret,Milk\n" +
+				",Alfamart,Bread\n",
+			want: []parsedRecord{
+				{Row: 2, Store: "Indomaret", Items: []string{"Milk"}},
+				{Row: 3, Store: "Alfamart", Items: []string{"Bread"}},
+			},
+		},
+		{
+			name: "reference repeated later",
+			csv: "receipt_ref,store_name,item_name\n" +
+				"a,Indomaret,Milk\n" +
+				"b,Alfamart,Eggs\n" +
+				"a,Indomaret,Bread\n",
+			want: []parsedRecord{
+				{Row: 2, Store: "Indomaret", Items: []string{"Milk"}},
+				{Row: 3, Store: "Alfamart", Items: []string{"Eggs"}},
+				{Row: 4, Store: "Indomaret", Items: []string{"Bread"}},
+			},
+		},
+		{
+			name: "receipt without items",
+			csv: "Receipt_Ref, Store_Name ,item_name\n" +
+				"a,Indomaret,\n",
+			want: []parsedRecord{{Row: 2, Store: "Indomaret"}},
+		},
+		{
+			name: "invalid item fails the whole receipt",
+			csv: "receipt_ref,store_name,item_name,item_quantity\n" +
+				"a,Indomaret,Milk,1\n" +
+				"a,,Bread,two\n" +
+				"a,,Eggs,1\n" +
+				"b,Alfamart,Eggs,1\n",
+			want: []parsedRecord{
+				{Row: 2, Store: "Indomaret", Items: []string{"Milk"}, Err: "line 3: invalid item_quantity: two"},
+				{Row: 5, Store: "Alfamart", Items: []string{"Eggs"}},
+			},
+		},
+		{
+			name: "invalid header fields",
+			csv: "receipt_ref,store_name,phone,item_name\n" +
+				"a,Indomaret,call me,Milk\n" +
+				"a,,,Bread\n",
+			want: []parsedRecord{{Row: 2, Store: "Indomaret", Err: "invalid phone: call me"}},
+		},
+		{
+			name: "malformed row starts a new receipt",
+			csv: "receipt_ref,store_name,item_name\n" +
+				"a,Indomaret,Milk\n" +
+				"a,Indo\"maret,Bread\n" +
+				"a,,Eggs\n",
+			want: []parsedRecord{
+				{Row: 2, Store: "Indomaret", Items: []string{"Milk"}},
+				{Row: 3, Err: "invalid csv row: parse error on line 3, column 7: bare \" in non-quoted-field"},
+				{Row: 4, Items: []string{"Eggs"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ParseCSV(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatalf("ParseCSV() error = %v", err)
+			}
+			if got := summarize(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVFields(t *testing.T) {
+	csv := "receipt_ref,store_name,date,currency,payment_method,card_last4,total_items,total_spending,total_discount,tax,tip,item_name,item_unit_price,item_quantity,item_price,item_total,item_category\n" +
+		"a,Starbucks,2026-03-14,usd,Credit_Card,4242,2,12.50,1.00,1.10,,Latte,5.20,2,10.40,10.40,coffee\n"
+
+	records, err := ParseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Err != nil {
+		t.Fatalf("ParseCSV() = %+v, want one valid record", records)
+	}
+
+	req := records[0].Request
+	if req.Date == nil || *req.Date != "2026-03-14" || req.Currency != "USD" || req.PaymentMethod != domain.PaymentMethod("credit_card") || req.CardLast4 != "4242" {
+		t.Errorf("receipt fields = %+v", req)
+	}
+	if req.TotalItems != 2 || req.TotalSpending != "12.50" || req.TotalDiscount != "1.00" {
+		t.Errorf("totals = %d %s %s, want 2 12.50 1.00", req.TotalItems, req.TotalSpending, req.TotalDiscount)
+	}
+	wantAdjustments := []domain.CreateAdjustmentRequest{{Type: domain.AdjustmentTax, Amount: "1.10"}}
+	if !reflect.DeepEqual(req.Adjustments, wantAdjustments) {
+		t.Errorf("adjustments = %+v, want %+v", req.Adjustments, wantAdjustments)
+	}
+	wantItem := domain.CreateItemRequest{Name: "Latte", UnitPrice: "5.20", Quantity: 2, Price: "10.40", Total: "10.40", Category: "coffee"}
+	if len(req.Items) != 1 || !reflect.DeepEqual(req.Items[0], wantItem) {
+		t.Errorf("items = %+v, want %+v", req.Items, wantItem)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	records, err := ParseJSON(strings.NewReader(`[
+		{"store_name": "Indomaret", "items": [{"name": "Milk", "quantity": 1}]},
+		{"store_name": 42},
+		{"store_name": "Alfamart"}
+	]`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	got := summarize(records)
+	want := []parsedRecord{
+		{Row: 1, Store: "Indomaret", Items: []string{"Milk"}},
+		{Row: 2, Err: "invalid receipt"},
+		{Row: 3, Store: "Alfamart"},
+	}
+	for i := range got {
+		got[i].Err, _, _ = strings.Cut(got[i].Err, ":")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJSON() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseJSON(strings.NewReader(`{"store_name": "Indomaret"}`)); err == nil {
+		t.Error("ParseJSON() of an object succeeded, want an error")
+	}
+}
+
+func TestParseReceiptsUnsupportedFormat(t *testing.T) {
+	if _, err := ParseReceipts("xml", strings.NewReader("")); err == nil || err.Error() != "unsupported import format: xml" {
+		t.Errorf("ParseReceipts(xml) error = %v", err)
+	}
+}
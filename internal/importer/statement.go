@@ -0,0 +1,295 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// Bank statement file formats
+const (
+	FormatStatementCSV Format = "csv"
+	FormatOFX          Format = "ofx"
+)
+
+// Statement is a parsed bank statement. Account and Currency are empty when the file does not say.
+type Statement struct {
+	Account  string
+	Currency string
+	Lines    []StatementLine
+}
+
+// StatementLine is one transaction read from a statement.
+// Row is the 1-based position in the source, and Err is set when the line could not be parsed.
+type StatementLine struct {
+	Row         int
+	ExternalID  string
+	Date        time.Time
+	Description string
+	Amount      domain.Decimal
+	Currency    string
+	Err         error
+}
+
+// StatementColumns maps CSV statement columns to transaction fields. Either Amount (signed,
+// negative for money leaving the account) or Debit and Credit must be present.
+// DateFormat is a Go time layout; DecimalComma reads "1.234,56" style amounts, and Delimiter
+// defaults to a comma.
+type StatementColumns struct {
+	Date         string
+	Description  string
+	Amount       string
+	Debit        string
+	Credit       string
+	Currency     string
+	ID           string
+	DateFormat   string
+	DecimalComma bool
+	Delimiter    rune
+}
+
+// DefaultStatementColumns returns the column mapping used when none is given
+func DefaultStatementColumns() StatementColumns {
+	return StatementColumns{
+		Date:        "date",
+		Description: "description",
+		Amount:      "amount",
+		Debit:       "debit",
+		Credit:      "credit",
+		Currency:    "currency",
+		ID:          "id",
+		DateFormat:  "2006-01-02",
+	}
+}
+
+// ParseStatement reads a bank statement in the given format. The columns are only used for CSV.
+func ParseStatement(format Format, r io.Reader, columns StatementColumns) (*Statement, error) {
+	switch format {
+	case FormatStatementCSV:
+		return ParseStatementCSV(r, columns)
+	case FormatOFX:
+		return ParseOFX(r)
+	default:
+		return nil, fmt.Errorf("unsupported statement format: %s", format)
+	}
+}
+
+// ParseStatementCSV reads a CSV bank statement using the column mapping
+func ParseStatementCSV(r io.Reader, columns StatementColumns) (*Statement, error) {
+	defaults := DefaultStatementColumns()
+	if columns.DateFormat == "" {
+		columns.DateFormat = defaults.DateFormat
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if columns.Delimiter != 0 {
+		reader.Comma = columns.Delimiter
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columnName := func(name string) string {
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	has := func(name string) bool {
+		_, ok := index[columnName(name)]
+		return name != "" && ok
+	}
+
+	if !has(columns.Date) {
+		return nil, fmt.Errorf("missing csv column: %s", columns.Date)
+	}
+	signed := has(columns.Amount)
+	if !signed && !has(columns.Debit) && !has(columns.Credit) {
+		return nil, fmt.Errorf("missing csv column: %s, or %s and %s", columns.Amount, columns.Debit, columns.Credit)
+	}
+
+	statement := &Statement{}
+	line := 1
+
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			statement.Lines = append(statement.Lines, StatementLine{Row: line, Err: fmt.Errorf("invalid csv row: %w", err)})
+			continue
+		}
+
+		row := csvRow{index: index, fields: fields}
+		get := func(name string) string {
+			if name == "" {
+				return ""
+			}
+			return row.get(columnName(name))
+		}
+
+		entry := StatementLine{
+			Row:         line,
+			ExternalID:  get(columns.ID),
+			Description: get(columns.Description),
+			Currency:    strings.ToUpper(get(columns.Currency)),
+		}
+
+		if entry.Date, err = time.Parse(columns.DateFormat, get(columns.Date)); err != nil {
+			entry.Err = fmt.Errorf("invalid date: %s", get(columns.Date))
+		} else if signed {
+			entry.Amount, entry.Err = parseStatementAmount(get(columns.Amount), columns.DecimalComma)
+		} else {
+			entry.Amount, entry.Err = debitCreditAmount(get(columns.Debit), get(columns.Credit), columns.DecimalComma)
+		}
+
+		statement.Lines = append(statement.Lines, entry)
+	}
+
+	return statement, nil
+}
+
+// debitCreditAmount combines separate debit and credit columns into a signed amount
+func debitCreditAmount(debit, credit string, decimalComma bool) (domain.Decimal, error) {
+	if debit != "" {
+		amount, err := parseStatementAmount(debit, decimalComma)
+		if err != nil || amount == "" {
+			return amount, err
+		}
+		if strings.HasPrefix(string(amount), "-") {
+			return amount[1:], nil
+		}
+		return "-" + amount, nil
+	}
+	if credit != "" {
+		return parseStatementAmount(credit, decimalComma)
+	}
+	return "", fmt.Errorf("missing amount")
+}
+
+// statementAmountPattern matches the number of a printed amount with its optional sign
+var statementAmountPattern = regexp.MustCompile(`^(-|\()?\s*[^\d\s-]*\s*([\d.,]+)\s*(\))?\s*(CR|DB|DR)?$`)
+
+// parseStatementAmount reads a signed amount such as "-1,234.56", "(12.00)" or "1.234,56 DB".
+// A trailing DB or DR marks money leaving the account.
+func parseStatementAmount(value string, decimalComma bool) (domain.Decimal, error) {
+	match := statementAmountPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(value)))
+	if match == nil {
+		return "", fmt.Errorf("invalid amount: %s", value)
+	}
+
+	number := match[2]
+	if decimalComma {
+		number = strings.ReplaceAll(strings.ReplaceAll(number, ".", ""), ",", ".")
+	} else {
+		number = strings.ReplaceAll(number, ",", "")
+	}
+
+	amount, err := domain.ParseDecimal(number)
+	if err != nil {
+		return "", fmt.Errorf("invalid amount: %s", value)
+	}
+	if match[1] != "" || match[4] == "DB" || match[4] == "DR" {
+		amount = "-" + amount
+	}
+
+	return amount, nil
+}
+
+// ofxTransactionStart and ofxTransactionListEnd delimit the transactions of a statement
+var (
+	ofxTransactionStart   = regexp.MustCompile(`(?i)<STMTTRN>`)
+	ofxTransactionListEnd = regexp.MustCompile(`(?i)</BANKTRANLIST>`)
+)
+
+// ofxElementPattern matches an element and its value, with or without a closing tag
+var ofxElementPattern = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+
+// ParseOFX reads an OFX 1.x (SGML) or 2.x (XML) bank or credit card statement
+func ParseOFX(r io.Reader) (*Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ofx: %w", err)
+	}
+	text := string(data)
+
+	if !strings.Contains(strings.ToUpper(text), "<OFX>") {
+		return nil, fmt.Errorf("invalid ofx: no OFX element")
+	}
+
+	starts := ofxTransactionStart.FindAllStringIndex(text, -1)
+	header := text
+	if len(starts) > 0 {
+		header = text[:starts[0][0]]
+	}
+
+	statement := &Statement{}
+	for _, element := range ofxElementPattern.FindAllStringSubmatch(header, -1) {
+		switch strings.ToUpper(element[1]) {
+		case "CURDEF":
+			statement.Currency = strings.ToUpper(strings.TrimSpace(element[2]))
+		case "ACCTID":
+			statement.Account = strings.TrimSpace(element[2])
+		}
+	}
+
+	// Closing tags are optional in OFX 1.x, so a transaction runs until the next one starts
+	for i, start := range starts {
+		body := text[start[1]:]
+		if i+1 < len(starts) {
+			body = text[start[1]:starts[i+1][0]]
+		}
+		if end := ofxTransactionListEnd.FindStringIndex(body); end != nil {
+			body = body[:end[0]]
+		}
+
+		entry := StatementLine{Row: i + 1}
+
+		var name, memo, date, amount string
+		for _, element := range ofxElementPattern.FindAllStringSubmatch(body, -1) {
+			value := strings.TrimSpace(element[2])
+			switch strings.ToUpper(element[1]) {
+			case "FITID":
+				entry.ExternalID = value
+			case "DTPOSTED":
+				date = value
+			case "TRNAMT":
+				amount = value
+			case "NAME", "PAYEE":
+				name = value
+			case "MEMO":
+				memo = value
+			}
+		}
+
+		entry.Description = strings.TrimSpace(name + " " + memo)
+		if len(date) < 8 {
+			entry.Err = fmt.Errorf("invalid DTPOSTED: %s", date)
+		} else if entry.Date, err = time.Parse("20060102", date[:8]); err != nil {
+			entry.Err = fmt.Errorf("invalid DTPOSTED: %s", date)
+		} else {
+			// OFX amounts always use a period, but some banks write a comma
+			if !strings.Contains(amount, ".") {
+				amount = strings.ReplaceAll(amount, ",", ".")
+			}
+			entry.Amount, entry.Err = parseStatementAmount(amount, false)
+		}
+
+		statement.Lines = append(statement.Lines, entry)
+	}
+
+	return statement, nil
+}
@@ -0,0 +1,77 @@
+package merchant
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// MinSimilarity is the lowest name similarity at which a store name resolves to an existing merchant
+const MinSimilarity = 0.85
+
+// legalSuffixes are company designations dropped from store names, e.g. "PT" or "Tbk"
+var legalSuffixes = map[string]bool{
+	"pt": true, "tbk": true, "cv": true, "ud": true, "persero": true,
+	"ltd": true, "inc": true, "co": true, "corp": true, "llc": true, "bhd": true, "sdn": true,
+}
+
+// branchMarkers start the branch part of a store name, which is dropped with everything after it
+var branchMarkers = map[string]bool{
+	"cabang": true, "cab": true, "branch": true, "outlet": true,
+}
+
+// Key normalizes a store name for matching: lower case words without punctuation, legal
+// suffixes, branch names or numbers, so "INDOMARET", "Indomaret Pt." and
+// "indomaret 123" share the key "indomaret". Names made only of such words keep their
+// plain normalized form.
+func Key(name string) string {
+	normalized := utils.NormalizeText(name)
+
+	var words []string
+	for _, word := range strings.Fields(normalized) {
+		if branchMarkers[word] {
+			break
+		}
+		// Numbers after the name are branch or terminal numbers; "7 eleven" keeps its 7
+		if legalSuffixes[word] || (len(words) > 0 && strings.IndexFunc(word, unicode.IsDigit) >= 0) {
+			continue
+		}
+		words = append(words, word)
+	}
+
+	if len(words) == 0 {
+		return normalized
+	}
+	return strings.Join(words, " ")
+}
+
+// DisplayName returns the canonical name of a new merchant: its key with every word capitalized
+func DisplayName(name string) string {
+	words := strings.Fields(Key(name))
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// Closest returns the merchant whose name or an alias is most similar to the key, or nil
+// when none reaches MinSimilarity
+func Closest(merchants []domain.Merchant, key string) *domain.Merchant {
+	var best *domain.Merchant
+	bestScore := MinSimilarity
+
+	for i := range merchants {
+		candidates := append([]string{Key(merchants[i].Name)}, merchants[i].Aliases...)
+		for _, candidate := range candidates {
+			if score := utils.Similarity(key, candidate); score >= bestScore {
+				best, bestScore = &merchants[i], score
+			}
+		}
+	}
+
+	return best
+}
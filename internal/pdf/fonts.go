@@ -0,0 +1,82 @@
+package pdf
+
+// Font is one of the standard PDF fonts, which viewers provide so nothing is embedded
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// baseFonts are the PostScript names of the fonts, by Font
+var baseFonts = []string{"Helvetica", "Helvetica-Bold"}
+
+// defaultWidth is the advance width used for characters outside printable ASCII
+const defaultWidth = 556
+
+// widths are the advance widths in 1/1000 em of the printable ASCII characters (32-126) of each font,
+// from the Adobe font metrics
+var widths = [][95]int{
+	{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// TextWidth returns the width in points of text set in the font at size
+func TextWidth(text string, font Font, size float64) float64 {
+	total := 0
+	for _, b := range encode(text) {
+		if b >= 32 && b <= 126 {
+			total += widths[font][b-32]
+		} else {
+			total += defaultWidth
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Truncate shortens text with an ellipsis so it fits within width points
+func Truncate(text string, font Font, size float64, width float64) string {
+	if TextWidth(text, font, size) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := string(runes) + "..."; TextWidth(candidate, font, size) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// encode converts text to the WinAnsi encoding of the standard fonts. Latin-1 characters map to
+// themselves; anything else becomes a question mark.
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			encoded = append(encoded, ' ')
+		case r < 32 || (r >= 127 && r < 160) || r > 255:
+			encoded = append(encoded, '?')
+		default:
+			encoded = append(encoded, byte(r))
+		}
+	}
+	return encoded
+}
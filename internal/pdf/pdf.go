@@ -0,0 +1,228 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica fonts, lines, filled
+// rectangles and JPEG images on A4 pages. Coordinates are in points from the top left corner.
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"math"
+	"strconv"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// jpegQuality is the quality images are re-encoded at
+const jpegQuality = 80
+
+// Document is a PDF document built page by page
+type Document struct {
+	pages  []*bytes.Buffer
+	images []pdfImage
+	title  string
+}
+
+// pdfImage is an image embedded as a JPEG
+type pdfImage struct {
+	data          []byte
+	width, height int
+}
+
+// New creates an empty document with the given title in its metadata
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// AddPage starts a new page; drawing goes to the last page added
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount returns the number of pages added
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// content returns the content stream of the current page, adding the first page if needed
+func (d *Document) content() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws text with its baseline at y
+func (d *Document) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(d.content(), "BT /F%d %s Tf %s %s Td %s Tj ET\n", font+1, num(size), num(x), num(PageHeight-y), literal(encode(text)))
+}
+
+// TextRight draws text ending at x
+func (d *Document) TextRight(x, y float64, font Font, size float64, text string) {
+	d.Text(x-TextWidth(text, font, size), y, font, size, text)
+}
+
+// Line draws a line of the given width in a gray level from 0 (black) to 1 (white)
+func (d *Document) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(d.content(), "q %s G %s w %s %s m %s %s l S Q\n",
+		num(gray), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect fills a rectangle with its top left corner at x, y in a gray level
+func (d *Document) Rect(x, y, width, height, gray float64) {
+	fmt.Fprintf(d.content(), "q %s g %s %s %s %s re f Q\n",
+		num(gray), num(x), num(PageHeight-y-height), num(width), num(height))
+}
+
+// Image draws an image scaled into width by height points with its top left corner at x, y.
+// Transparent areas are drawn white.
+func (d *Document) Image(img image.Image, x, y, width, height float64) error {
+	// The image is flattened to RGB so the JPEG always has three components
+	bounds := img.Bounds()
+	rgb := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgb, rgb.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgb, rgb.Bounds(), img, bounds.Min, draw.Over)
+
+	var data bytes.Buffer
+	if err := jpeg.Encode(&data, rgb, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	d.images = append(d.images, pdfImage{data: data.Bytes(), width: bounds.Dx(), height: bounds.Dy()})
+
+	fmt.Fprintf(d.content(), "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+		num(width), num(height), num(x), num(PageHeight-y-height), len(d.images))
+	return nil
+}
+
+// WriteTo writes the document. Objects are numbered: 1 catalog, 2 page tree, 3 info,
+// 4-5 fonts, then each image, then a page and its content stream per page.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	out := &countingWriter{w: bufio.NewWriter(w)}
+	var offsets []int64
+	object := func(body func()) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n", len(offsets))
+		body()
+		fmt.Fprint(out, "\nendobj\n")
+	}
+
+	fmt.Fprint(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	firstImage := 6
+	firstPage := firstImage + len(d.images)
+
+	object(func() { fmt.Fprint(out, "<< /Type /Catalog /Pages 2 0 R >>") })
+	object(func() {
+		fmt.Fprint(out, "<< /Type /Pages /Kids [")
+		for i := range d.pages {
+			fmt.Fprintf(out, " %d 0 R", firstPage+2*i)
+		}
+		fmt.Fprintf(out, " ] /Count %d >>", len(d.pages))
+	})
+	object(func() {
+		fmt.Fprintf(out, "<< /Title %s /Producer (receipt-extraction-backend) >>", literal(encode(d.title)))
+	})
+	for _, name := range baseFonts {
+		object(func() {
+			fmt.Fprintf(out, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)
+		})
+	}
+	for _, img := range d.images {
+		object(func() {
+			fmt.Fprintf(out, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n",
+				img.width, img.height, len(img.data))
+			out.Write(img.data)
+			fmt.Fprint(out, "\nendstream")
+		})
+	}
+
+	var resources bytes.Buffer
+	resources.WriteString("<< /Font << /F1 4 0 R /F2 5 0 R >>")
+	if len(d.images) > 0 {
+		resources.WriteString(" /XObject <<")
+		for i := range d.images {
+			fmt.Fprintf(&resources, " /Im%d %d 0 R", i+1, firstImage+i)
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+
+	for i, page := range d.pages {
+		object(func() {
+			fmt.Fprintf(out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+				num(PageWidth), num(PageHeight), resources.String(), firstPage+2*i+1)
+		})
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(page.Bytes())
+		zw.Close()
+		object(func() {
+			fmt.Fprintf(out, "<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+			out.Write(compressed.Bytes())
+			fmt.Fprint(out, "\nendstream")
+		})
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if out.err != nil {
+		return out.n, out.err
+	}
+	return out.n, out.w.(*bufio.Writer).Flush()
+}
+
+// countingWriter counts the bytes written, for the cross-reference table, and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// num formats a number with at most two decimals
+func num(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// literal formats bytes as a PDF literal string, escaping delimiters
+func literal(text []byte) string {
+	var b bytes.Buffer
+	b.WriteByte('(')
+	for _, c := range text {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "", want: "()"},
+		{text: "Receipts", want: "(Receipts)"},
+		{text: "Total (incl. tax)", want: `(Total \(incl. tax\))`},
+		{text: `C:\scans`, want: `(C:\\scans)`},
+		{text: `\)`, want: `(\\\))`},
+		{text: "Caf\xe9", want: "(Caf\xe9)"},
+	}
+
+	for _, tt := range tests {
+		if got := literal([]byte(tt.text)); got != tt.want {
+			t.Errorf("literal(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Receipts", want: "Receipts"},
+		{text: "Café", want: "Caf\xe9"},
+		{text: "a\tb\nc", want: "a b c"},
+		{text: "Rp 10.000 ☕", want: "Rp 10.000 ?"},
+		{text: "\x00\u0085", want: "??"},
+	}
+
+	for _, tt := range tests {
+		if got := string(encode(tt.text)); got != tt.want {
+			t.Errorf("encode(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		want  string
+	}{
+		{name: "fits", text: "Erafone", width: 100, want: "Erafone"},
+		{name: "shortened", text: "Erafone Megastore Grand Indonesia", width: 60, want: "Erafone M..."},
+		{name: "nothing fits", text: "Erafone", width: 5, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.text, Helvetica, 10, tt.width)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %v) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+			if width := TextWidth(got, Helvetica, 10); width > tt.width {
+				t.Errorf("Truncate(%q, %v) is %v wide", tt.text, tt.width, width)
+			}
+		})
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	doc := New("Spending (March) \\ 2026")
+	doc.Text(48, 60, HelveticaBold, 20, "Total (incl. tax)")
+	doc.Line(48, 70, 500, 70, 1, 0)
+	img := image.NewRGBA(image.Rect(0, 0, 4, 6))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(1, 1, color.RGBA{A: 0})
+	if err := doc.Image(img, 48, 100, 40, 60); err != nil {
+		t.Fatalf("Image() error = %v", err)
+	}
+	doc.AddPage()
+	doc.TextRight(500, 60, Helvetica, 9, "Page 2")
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	data := buf.Bytes()
+	if n != int64(len(data)) {
+		t.Errorf("WriteTo() = %d, wrote %d bytes", n, len(data))
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("WriteTo() wrote no PDF header or trailer:\n%s", data)
+	}
+	if !bytes.Contains(data, []byte(`/Title (Spending \(March\) \\ 2026)`)) {
+		t.Errorf("WriteTo() did not escape the title")
+	}
+
+	// startxref points at the cross-reference table
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("WriteTo() wrote no startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d points at %q", xref, data[xref:min(xref+20, len(data))])
+	}
+
+	// Every entry of the table points at its object: catalog, pages, info, two fonts, one image
+	// and a page and its content per page
+	lines := strings.Split(string(data[xref:]), "\n")
+	if lines[1] != "0 11" {
+		t.Fatalf("xref subsection = %q, want %q", lines[1], "0 11")
+	}
+	for object := 1; object <= 10; object++ {
+		entry := lines[2+object]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("xref entry %d = %q", object, entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if header := fmt.Sprintf("%d 0 obj\n", object); !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Errorf("xref entry %d points at %q, want %q", object, data[offset:min(offset+12, len(data))], header)
+		}
+	}
+	if !strings.Contains(string(data), "/Size 11 /Root 1 0 R") {
+		t.Errorf("trailer does not give the object count")
+	}
+}
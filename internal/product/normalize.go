@@ -0,0 +1,145 @@
+package product
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// MinSimilarity is the lowest name similarity at which an item name resolves to an existing product
+const MinSimilarity = 0.85
+
+// sizePattern matches a pack size such as "600ML", "1,5 L" or "250gr"
+var sizePattern = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(ml|ltr|lt|liter|litre|l|gram|gr|g|kg|mg|oz|lb)\b`)
+
+// sizeUnits maps the spellings of a size unit to its canonical form
+var sizeUnits = map[string]string{
+	"ml": "ml", "l": "l", "lt": "l", "ltr": "l", "liter": "l", "litre": "l",
+	"g": "g", "gr": "g", "gram": "g", "kg": "kg", "mg": "mg", "oz": "oz", "lb": "lb",
+}
+
+// abbreviations expands words commonly shortened on thermal receipts
+var abbreviations = map[string]string{
+	"mnrl": "mineral", "grg": "goreng", "spc": "special",
+	"coklat": "cokelat", "ckl": "cokelat", "choc": "chocolate", "orig": "original", "ori": "original",
+	"strwb": "strawberry", "van": "vanilla", "fc": "full cream", "lf": "low fat",
+}
+
+// packagingWords name the packaging rather than the product and are left out of keys
+var packagingWords = map[string]bool{
+	"btl": true, "botol": true, "bks": true, "bungkus": true, "klg": true, "kaleng": true,
+	"pck": true, "pack": true, "pk": true, "ktk": true, "kotak": true, "sct": true, "sachet": true,
+	"pcs": true, "pc": true, "bottle": true, "can": true, "box": true,
+}
+
+// Parse normalizes a raw item name for matching and returns it with the pack size printed in
+// the name ("600ml"), or "" when there is none. The key is lower case without punctuation, PLU
+// codes or packaging words, with common abbreviations expanded and the size last, so
+// "AQUA BTL 600ML" and "Aqua 600 ml" share the key "aqua 600ml".
+func Parse(name string) (key string, size string) {
+	if match := sizePattern.FindStringSubmatchIndex(name); match != nil {
+		amount := strings.ReplaceAll(name[match[2]:match[3]], ",", ".")
+		if strings.Contains(amount, ".") {
+			amount = strings.TrimRight(strings.TrimRight(amount, "0"), ".")
+		}
+		size = amount + sizeUnits[strings.ToLower(name[match[4]:match[5]])]
+		name = name[:match[0]] + " " + name[match[1]:]
+	}
+
+	var words []string
+	for _, word := range strings.Fields(utils.NormalizeText(name)) {
+		if packagingWords[word] || isNumber(word) {
+			continue
+		}
+		if expanded, ok := abbreviations[word]; ok {
+			word = expanded
+		}
+		words = append(words, word)
+	}
+	if size != "" {
+		words = append(words, size)
+	}
+
+	if len(words) == 0 {
+		return utils.NormalizeText(name), size
+	}
+	return strings.Join(words, " "), size
+}
+
+// isNumber reports whether a word is made only of digits, like a PLU code
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// DisplayName returns the canonical name of a new product: its key with every word capitalized
+func DisplayName(name string) string {
+	key, size := Parse(name)
+	words := strings.Fields(key)
+	for i, word := range words {
+		if word == size {
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// Similarity scores between 0 and 1 how alike two product keys are. Thermal printers truncate
+// long names, so words of three or more letters that are prefixes of the word at the same
+// position count as equal.
+func Similarity(a, b string) float64 {
+	score := utils.Similarity(a, b)
+
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	if len(wordsA) == 0 || len(wordsA) != len(wordsB) {
+		return score
+	}
+
+	matched := 0
+	for i := range wordsA {
+		short, long := wordsA[i], wordsB[i]
+		if len(short) > len(long) {
+			short, long = long, short
+		}
+		if short == long || (len(short) >= 3 && strings.HasPrefix(long, short)) {
+			matched++
+		}
+	}
+	if matched == len(wordsA) && score < MinSimilarity {
+		return MinSimilarity
+	}
+
+	return score
+}
+
+// Closest returns the product whose name or an alias is most similar to the key and has the
+// same pack size, or nil when none reaches MinSimilarity
+func Closest(products []domain.Product, key, size string) *domain.Product {
+	var best *domain.Product
+	bestScore := MinSimilarity
+
+	for i := range products {
+		candidates := append([]string{products[i].Name}, products[i].Aliases...)
+		for _, candidate := range candidates {
+			candidateKey, candidateSize := Parse(candidate)
+			if candidateSize != size {
+				continue
+			}
+			if score := Similarity(key, candidateKey); score >= bestScore {
+				best, bestScore = &products[i], score
+			}
+		}
+	}
+
+	return best
+}
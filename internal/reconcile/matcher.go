@@ -0,0 +1,138 @@
+package reconcile
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// Options tune the matcher.
+// AmountTolerance is the difference allowed between amounts as a divisor of the receipt total
+// (100 allows 1%), DateWindow the number of days a transaction may post before or after the
+// purchase, and MinScore the lowest score accepted as a match.
+type Options struct {
+	AmountTolerance int64
+	DateWindow      int
+	MinScore        float64
+}
+
+// DefaultOptions returns the matcher options used by the API
+func DefaultOptions() Options {
+	return Options{
+		AmountTolerance: 100,
+		DateWindow:      3,
+		MinScore:        0.5,
+	}
+}
+
+// Score weights of the amount, date and merchant name
+const (
+	amountWeight   = 0.5
+	dateWeight     = 0.2
+	merchantWeight = 0.3
+)
+
+// Pair is a pairing of a transaction and a receipt, by index into the matcher input
+type Pair struct {
+	Transaction int
+	Receipt     int
+	Score       float64
+}
+
+// Match pairs outgoing transactions with receipts in the same currency whose net total is within
+// the amount tolerance and whose date is within the date window. Each candidate pair is scored
+// from the amount difference, the number of days apart and the similarity of the receipt's store
+// name to the transaction description; pairs are then taken best first, so every transaction
+// and receipt is used at most once.
+func Match(transactions []domain.BankTransaction, receipts []domain.Receipt, opts Options) []Pair {
+	var candidates []Pair
+
+	for i := range transactions {
+		transaction := &transactions[i]
+		if !transaction.Amount.IsNegative() {
+			continue
+		}
+		paid := transaction.Amount.Neg()
+
+		for j := range receipts {
+			receipt := &receipts[j]
+			// The bank is charged the total after discounts
+			net := receipt.TotalSpending.Sub(receipt.TotalDiscount)
+			if receipt.Currency != transaction.Currency || net.IsZero() {
+				continue
+			}
+
+			tolerance := net.Div(opts.AmountTolerance).Amount
+			diff := paid.Sub(net).Amount
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > tolerance {
+				continue
+			}
+
+			days := math.Abs(utils.StartOfDay(transaction.Date).Sub(utils.ReceiptDay(*receipt)).Hours() / 24)
+			if days > float64(opts.DateWindow) {
+				continue
+			}
+
+			amountScore := 1.0
+			if tolerance > 0 {
+				amountScore = 1 - float64(diff)/float64(tolerance+1)
+			}
+			dateScore := 1 - days/float64(opts.DateWindow+1)
+			merchantScore := MerchantSimilarity(receipt.StoreName.String, transaction.Description)
+
+			score := amountWeight*amountScore + dateWeight*dateScore + merchantWeight*merchantScore
+			if score >= opts.MinScore {
+				candidates = append(candidates, Pair{Transaction: i, Receipt: j, Score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Score > candidates[b].Score
+	})
+
+	var matches []Pair
+	usedTransactions := map[int]bool{}
+	usedReceipts := map[int]bool{}
+	for _, candidate := range candidates {
+		if usedTransactions[candidate.Transaction] || usedReceipts[candidate.Receipt] {
+			continue
+		}
+		usedTransactions[candidate.Transaction] = true
+		usedReceipts[candidate.Receipt] = true
+		matches = append(matches, candidate)
+	}
+
+	return matches
+}
+
+// MerchantSimilarity scores between 0 and 1 how well a store name matches a bank transaction
+// description. Descriptions usually wrap the merchant in terminal ids and locations
+// ("POS 1234 INDOMARET JKT"), so the share of store name words found in the description counts
+// as much as the edit distance of the whole strings.
+func MerchantSimilarity(storeName, description string) float64 {
+	storeWords := strings.Fields(utils.NormalizeText(storeName))
+	if len(storeWords) == 0 {
+		return 0
+	}
+
+	descriptionWords := map[string]bool{}
+	for _, word := range strings.Fields(utils.NormalizeText(description)) {
+		descriptionWords[word] = true
+	}
+
+	found := 0
+	for _, word := range storeWords {
+		if descriptionWords[word] {
+			found++
+		}
+	}
+
+	return math.Max(float64(found)/float64(len(storeWords)), utils.Similarity(storeName, description))
+}
@@ -0,0 +1,128 @@
+package reconcile
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func date(day int) time.Time {
+	return time.Date(2026, 3, day, 14, 30, 0, 0, time.UTC)
+}
+
+func matchReceipt(store string, day int, total, discount int64) domain.Receipt {
+	return domain.Receipt{
+		StoreName:     sql.NullString{String: store, Valid: true},
+		Date:          sql.NullTime{Time: date(day), Valid: true},
+		Currency:      "IDR",
+		TotalSpending: domain.NewMoney(total, "IDR"),
+		TotalDiscount: domain.NewMoney(discount, "IDR"),
+	}
+}
+
+func transaction(description string, day int, amount int64) domain.BankTransaction {
+	return domain.BankTransaction{
+		Date:        date(day),
+		Description: description,
+		Amount:      domain.NewMoney(amount, "IDR"),
+		Currency:    "IDR",
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []domain.BankTransaction
+		receipts     []domain.Receipt
+		want         []Pair
+	}{
+		{
+			name:         "exact amount on the same day",
+			transactions: []domain.BankTransaction{transaction("POS 1234 INDOMARET JKT", 10, -50000)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 50000, 0)},
+			want:         []Pair{{Transaction: 0, Receipt: 0}},
+		},
+		{
+			name:         "charged the total after discounts",
+			transactions: []domain.BankTransaction{transaction("POS 1234 INDOMARET JKT", 10, -45000)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 50000, 5000)},
+			want:         []Pair{{Transaction: 0, Receipt: 0}},
+		},
+		{
+			name:         "not charged the total before discounts",
+			transactions: []domain.BankTransaction{transaction("POS 1234 INDOMARET JKT", 10, -50000)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 50000, 5000)},
+		},
+		{
+			name:         "fully discounted receipt",
+			transactions: []domain.BankTransaction{transaction("POS 1234 INDOMARET JKT", 10, -1)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 50000, 50000)},
+		},
+		{
+			name:         "within the amount tolerance",
+			transactions: []domain.BankTransaction{transaction("INDOMARET", 10, -100500)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 100000, 0)},
+			want:         []Pair{{Transaction: 0, Receipt: 0}},
+		},
+		{
+			name:         "outside the amount tolerance",
+			transactions: []domain.BankTransaction{transaction("INDOMARET", 10, -102000)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 100000, 0)},
+		},
+		{
+			name:         "outside the date window",
+			transactions: []domain.BankTransaction{transaction("INDOMARET", 15, -50000)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 50000, 0)},
+		},
+		{
+			name:         "refund",
+			transactions: []domain.BankTransaction{transaction("INDOMARET", 10, 50000)},
+			receipts:     []domain.Receipt{matchReceipt("Indomaret", 10, 50000, 0)},
+		},
+		{
+			name: "each receipt used once, best first",
+			transactions: []domain.BankTransaction{
+				transaction("TRANSFER", 12, -50000),
+				transaction("POS ALFAMART BDG", 10, -50000),
+			},
+			receipts: []domain.Receipt{matchReceipt("Alfamart", 10, 50000, 0)},
+			want:     []Pair{{Transaction: 1, Receipt: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Match(tt.transactions, tt.receipts, DefaultOptions())
+			if len(got) != len(tt.want) {
+				t.Fatalf("Match() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Transaction != tt.want[i].Transaction || got[i].Receipt != tt.want[i].Receipt {
+					t.Errorf("Match()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMerchantSimilarity(t *testing.T) {
+	tests := []struct {
+		store, description string
+		min, max           float64
+	}{
+		{"Indomaret", "POS 1234 INDOMARET JKT", 1, 1},
+		{"Kopi Kenangan", "QRIS KOPI KENANGAN SENAYAN", 1, 1},
+		{"Kopi Kenangan", "QRIS KOPI JANJI JIWA", 0.5, 0.5},
+		{"Indomaret", "TRANSFER BCA", 0, 0.4},
+		{"", "INDOMARET", 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := MerchantSimilarity(tt.store, tt.description)
+		if got < tt.min || got > tt.max {
+			t.Errorf("MerchantSimilarity(%q, %q) = %.2f, want between %.2f and %.2f", tt.store, tt.description, got, tt.min, tt.max)
+		}
+	}
+}
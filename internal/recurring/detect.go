@@ -0,0 +1,337 @@
+package recurring
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/merchant"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// AmountTolerance is how far, as a fraction of the smallest amount, purchases may differ and
+// still count as the same recurrence. Occurrences costing more than the typical amount by this
+// fraction are flagged as unusually large.
+const AmountTolerance = 0.2
+
+// cadence describes the expected interval of a cadence in days and how many days an occurrence
+// may be early or late. MinOccurrences is the fewest purchases that establish the pattern.
+type cadence struct {
+	name           domain.Cadence
+	days           int
+	tolerance      int
+	minOccurrences int
+}
+
+var cadences = []cadence{
+	{name: domain.CadenceWeekly, days: 7, tolerance: 2, minOccurrences: 3},
+	{name: domain.CadenceMonthly, days: 30, tolerance: 5, minOccurrences: 3},
+	{name: domain.CadenceYearly, days: 365, tolerance: 20, minOccurrences: 2},
+}
+
+// endedPeriods is the number of missed periods after which a recurrence is considered ended
+const endedPeriods = 2
+
+// purchase is a receipt reduced to what detection needs
+type purchase struct {
+	receiptID int
+	day       time.Time
+	amount    int64
+}
+
+// group is the purchases at one merchant in one currency
+type group struct {
+	key        string
+	merchantID int64
+	storeName  string
+	currency   string
+	purchases  []purchase
+}
+
+// Detect finds recurring purchases in a workspace's receipts: purchases at the same merchant, in the
+// same currency and for a similar amount, repeating weekly, monthly or yearly. A cadence is
+// accepted when the median interval between purchases matches it and at least half of the
+// intervals are within its tolerance. The next occurrence is predicted from the latest one,
+// and the status is computed as of now. Receipts without a store name or purchase date are
+// ignored.
+func Detect(receipts []domain.Receipt, now time.Time) []domain.RecurringPurchase {
+	var detected []domain.RecurringPurchase
+	for _, g := range groupReceipts(receipts) {
+		detected = append(detected, detectGroup(g, now)...)
+	}
+
+	sort.SliceStable(detected, func(a, b int) bool {
+		return detected[a].NextDate.Before(detected[b].NextDate)
+	})
+	return detected
+}
+
+// Status returns the state of a recurrence as of now: missing once the predicted next
+// occurrence is later than the cadence tolerance, and ended after several missed periods
+func Status(c domain.Cadence, next, now time.Time) domain.RecurringStatus {
+	spec, ok := cadenceSpec(c)
+	if !ok {
+		return domain.RecurringActive
+	}
+
+	overdue := utils.StartOfDay(now).Sub(next).Hours() / 24
+	switch {
+	case overdue > float64(endedPeriods*spec.days+spec.tolerance):
+		return domain.RecurringEnded
+	case overdue > float64(spec.tolerance):
+		return domain.RecurringMissing
+	default:
+		return domain.RecurringActive
+	}
+}
+
+// groupReceipts groups receipts by merchant, or by normalized store name for receipts not yet
+// matched to a merchant, and currency
+func groupReceipts(receipts []domain.Receipt) []*group {
+	groups := map[string]*group{}
+	var order []string
+
+	for i := range receipts {
+		receipt := &receipts[i]
+		if !receipt.Date.Valid || !receipt.StoreName.Valid {
+			continue
+		}
+		paid := receipt.TotalSpending.Sub(receipt.TotalDiscount).Amount
+		if paid <= 0 {
+			continue
+		}
+
+		var key string
+		if receipt.MerchantID.Valid {
+			key = fmt.Sprintf("merchant:%d", receipt.MerchantID.Int64)
+		} else {
+			name := merchant.Key(receipt.StoreName.String)
+			if name == "" {
+				continue
+			}
+			key = "store:" + name
+		}
+
+		groupKey := key + "/" + receipt.Currency
+		g, ok := groups[groupKey]
+		if !ok {
+			g = &group{key: key, merchantID: receipt.MerchantID.Int64, storeName: receipt.StoreName.String, currency: receipt.Currency}
+			groups[groupKey] = g
+			order = append(order, groupKey)
+		}
+		g.purchases = append(g.purchases, purchase{receiptID: receipt.ID, day: utils.StartOfDay(receipt.Date.Time), amount: paid})
+	}
+
+	result := make([]*group, 0, len(order))
+	for _, key := range order {
+		result = append(result, groups[key])
+	}
+	return result
+}
+
+// detectGroup finds the recurrences among the purchases at one merchant. Purchases are clustered
+// by amount so that, say, a monthly subscription and occasional larger purchases at the same
+// store are told apart.
+func detectGroup(g *group, now time.Time) []domain.RecurringPurchase {
+	type pattern struct {
+		spec        cadence
+		occurrences []purchase
+	}
+
+	// Every recurring cluster claims its purchases first, so that a second, more expensive
+	// subscription at the same merchant is not taken for an unusual charge of the cheaper one
+	var patterns []pattern
+	used := map[int]bool{}
+	for _, cluster := range amountClusters(g.purchases) {
+		occurrences := byDay(cluster)
+		spec, ok := matchCadence(occurrences)
+		if !ok {
+			continue
+		}
+		for _, occurrence := range occurrences {
+			used[occurrence.receiptID] = true
+		}
+		patterns = append(patterns, pattern{spec: spec, occurrences: occurrences})
+	}
+
+	detected := make([]domain.RecurringPurchase, 0, len(patterns))
+	for _, p := range patterns {
+		spec, occurrences := p.spec, p.occurrences
+
+		amounts := make([]int64, len(occurrences))
+		for i, occurrence := range occurrences {
+			amounts[i] = occurrence.amount
+		}
+		typical := median(amounts)
+
+		// A larger purchase falling on the predicted date is the same recurrence charged more,
+		// e.g. after a price rise or with extras; it continues the pattern and is flagged
+		var unusual *purchase
+		for {
+			next := nextDate(spec, occurrences[len(occurrences)-1].day)
+			candidate := findUnusual(g.purchases, used, next, spec.tolerance, typical)
+			if candidate == nil {
+				break
+			}
+			used[candidate.receiptID] = true
+			occurrences = append(occurrences, *candidate)
+			unusual = candidate
+		}
+
+		last := occurrences[len(occurrences)-1]
+		next := nextDate(spec, last.day)
+
+		recurrence := domain.RecurringPurchase{
+			MerchantKey: g.key,
+			StoreName:   g.storeName,
+			Cadence:     spec.name,
+			Amount:      domain.NewMoney(typical, g.currency),
+			Currency:    g.currency,
+			Occurrences: len(occurrences),
+			FirstDate:   occurrences[0].day,
+			LastDate:    last.day,
+			NextDate:    next,
+			Status:      Status(spec.name, next, now),
+		}
+		if g.merchantID != 0 {
+			recurrence.MerchantID.Int64, recurrence.MerchantID.Valid = g.merchantID, true
+		}
+		if unusual != nil {
+			amount := domain.NewMoney(unusual.amount, g.currency)
+			recurrence.UnusualAmount = &amount
+			recurrence.UnusualReceiptID.Int64, recurrence.UnusualReceiptID.Valid = int64(unusual.receiptID), true
+		}
+		for _, occurrence := range occurrences {
+			recurrence.ReceiptIDs = append(recurrence.ReceiptIDs, int64(occurrence.receiptID))
+		}
+
+		detected = append(detected, recurrence)
+	}
+
+	return detected
+}
+
+// amountClusters splits purchases into clusters whose amounts are within the amount tolerance
+// of the cluster's smallest amount
+func amountClusters(purchases []purchase) [][]purchase {
+	sorted := append([]purchase(nil), purchases...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].amount < sorted[b].amount
+	})
+
+	var clusters [][]purchase
+	start := 0
+	for i := 1; i <= len(sorted); i++ {
+		if i == len(sorted) || float64(sorted[i].amount) > float64(sorted[start].amount)*(1+AmountTolerance) {
+			clusters = append(clusters, sorted[start:i])
+			start = i
+		}
+	}
+	return clusters
+}
+
+// byDay orders purchases by date, keeping one purchase per day
+func byDay(purchases []purchase) []purchase {
+	sorted := append([]purchase(nil), purchases...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].day.Before(sorted[b].day)
+	})
+
+	var result []purchase
+	for _, p := range sorted {
+		if len(result) > 0 && result[len(result)-1].day.Equal(p.day) {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// matchCadence returns the cadence of purchases ordered by date, if they are regular enough
+func matchCadence(occurrences []purchase) (cadence, bool) {
+	if len(occurrences) < 2 {
+		return cadence{}, false
+	}
+
+	intervals := make([]int64, len(occurrences)-1)
+	for i := 1; i < len(occurrences); i++ {
+		intervals[i-1] = int64(occurrences[i].day.Sub(occurrences[i-1].day).Hours() / 24)
+	}
+	typical := median(intervals)
+
+	for _, spec := range cadences {
+		if len(occurrences) < spec.minOccurrences || !withinDays(typical, spec) {
+			continue
+		}
+
+		regular := 0
+		for _, interval := range intervals {
+			if withinDays(interval, spec) {
+				regular++
+			}
+		}
+		if regular*2 >= len(intervals) {
+			return spec, true
+		}
+	}
+
+	return cadence{}, false
+}
+
+// findUnusual returns an unused purchase larger than the typical amount by more than the amount
+// tolerance and within the cadence tolerance of the expected date
+func findUnusual(purchases []purchase, used map[int]bool, expected time.Time, tolerance int, typical int64) *purchase {
+	var best *purchase
+	for i := range purchases {
+		p := &purchases[i]
+		if used[p.receiptID] || float64(p.amount) <= float64(typical)*(1+AmountTolerance) {
+			continue
+		}
+		days := p.day.Sub(expected).Hours() / 24
+		if days < -float64(tolerance) || days > float64(tolerance) {
+			continue
+		}
+		if best == nil || p.day.Before(best.day) {
+			best = p
+		}
+	}
+	return best
+}
+
+// nextDate predicts the occurrence after last. Monthly and yearly recurrences fall on the same
+// day of the month rather than a fixed number of days later, or on the last day of a shorter month.
+func nextDate(spec cadence, last time.Time) time.Time {
+	switch spec.name {
+	case domain.CadenceMonthly:
+		return utils.AddMonths(last, 1)
+	case domain.CadenceYearly:
+		return utils.AddMonths(last, 12)
+	default:
+		return last.AddDate(0, 0, spec.days)
+	}
+}
+
+// withinDays reports whether an interval matches the cadence within its tolerance
+func withinDays(interval int64, spec cadence) bool {
+	return interval >= int64(spec.days-spec.tolerance) && interval <= int64(spec.days+spec.tolerance)
+}
+
+// cadenceSpec looks up a cadence by name
+func cadenceSpec(name domain.Cadence) (cadence, bool) {
+	for _, spec := range cadences {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+	return cadence{}, false
+}
+
+// median returns the middle value, or the lower of the two middle values
+func median(values []int64) int64 {
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a] < sorted[b]
+	})
+	return sorted[(len(sorted)-1)/2]
+}
@@ -0,0 +1,200 @@
+package recurring
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// purchasesOn returns purchases of the same amount on the given days, with receipt IDs from 1
+func purchasesOn(days ...string) []purchase {
+	purchases := make([]purchase, len(days))
+	for i, d := range days {
+		purchases[i] = purchase{receiptID: i + 1, day: day(d), amount: 1000}
+	}
+	return purchases
+}
+
+func receiptIDs(purchases []purchase) []int {
+	ids := make([]int, len(purchases))
+	for i, p := range purchases {
+		ids[i] = p.receiptID
+	}
+	return ids
+}
+
+func TestAmountClusters(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []int64
+		want    [][]int
+	}{
+		{name: "no purchases", amounts: nil, want: nil},
+		{name: "one cluster", amounts: []int64{1000, 1100, 1200}, want: [][]int{{1, 2, 3}}},
+		{
+			name:    "tolerance from the smallest amount",
+			amounts: []int64{1000, 1100, 1250, 5000, 1200},
+			want:    [][]int{{1, 2, 5}, {3}, {4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purchases := make([]purchase, len(tt.amounts))
+			for i, amount := range tt.amounts {
+				purchases[i] = purchase{receiptID: i + 1, amount: amount}
+			}
+
+			var got [][]int
+			for _, cluster := range amountClusters(purchases) {
+				got = append(got, receiptIDs(cluster))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("amountClusters(%v) = %v, want %v", tt.amounts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchCadence(t *testing.T) {
+	tests := []struct {
+		name   string
+		days   []string
+		want   domain.Cadence
+		wantOK bool
+	}{
+		{name: "weekly", days: []string{"2026-01-01", "2026-01-08", "2026-01-15"}, want: domain.CadenceWeekly, wantOK: true},
+		{name: "too few for weekly", days: []string{"2026-01-01", "2026-01-08"}},
+		{name: "monthly across month ends", days: []string{"2026-01-31", "2026-02-28", "2026-03-31"}, want: domain.CadenceMonthly, wantOK: true},
+		{name: "monthly with a gap", days: []string{"2026-01-01", "2026-01-31", "2026-03-03", "2026-05-21"}, want: domain.CadenceMonthly, wantOK: true},
+		{name: "yearly", days: []string{"2024-03-01", "2025-03-01"}, want: domain.CadenceYearly, wantOK: true},
+		{name: "irregular", days: []string{"2026-01-01", "2026-01-08", "2026-03-20", "2026-06-01"}},
+		{name: "single purchase", days: []string{"2026-01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchCadence(purchasesOn(tt.days...))
+			if ok != tt.wantOK || got.name != tt.want {
+				t.Errorf("matchCadence(%v) = %q, %v, want %q, %v", tt.days, got.name, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFindUnusual(t *testing.T) {
+	purchases := []purchase{
+		{receiptID: 1, day: day("2026-01-31"), amount: 1000},
+		{receiptID: 2, day: day("2026-02-03"), amount: 1500},
+		{receiptID: 3, day: day("2026-02-01"), amount: 1300},
+		{receiptID: 4, day: day("2026-02-20"), amount: 2000},
+		{receiptID: 5, day: day("2026-02-01"), amount: 1100},
+	}
+
+	tests := []struct {
+		name string
+		used map[int]bool
+		want int
+	}{
+		{name: "earliest larger purchase near the date", used: map[int]bool{}, want: 3},
+		{name: "used purchases are skipped", used: map[int]bool{3: true}, want: 2},
+		{name: "none left within tolerance", used: map[int]bool{2: true, 3: true}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findUnusual(purchases, tt.used, day("2026-02-01"), 5, 1000)
+			gotID := 0
+			if got != nil {
+				gotID = got.receiptID
+			}
+			if gotID != tt.want {
+				t.Errorf("findUnusual() = receipt %d, want receipt %d", gotID, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	next := day("2026-03-01")
+
+	tests := []struct {
+		name    string
+		cadence domain.Cadence
+		now     time.Time
+		want    domain.RecurringStatus
+	}{
+		{name: "before the next date", cadence: domain.CadenceMonthly, now: day("2026-02-20"), want: domain.RecurringActive},
+		{name: "late within tolerance", cadence: domain.CadenceMonthly, now: day("2026-03-06").Add(23 * time.Hour), want: domain.RecurringActive},
+		{name: "missing", cadence: domain.CadenceMonthly, now: day("2026-03-07"), want: domain.RecurringMissing},
+		{name: "missing until two periods pass", cadence: domain.CadenceMonthly, now: day("2026-05-05"), want: domain.RecurringMissing},
+		{name: "ended", cadence: domain.CadenceMonthly, now: day("2026-05-06"), want: domain.RecurringEnded},
+		{name: "weekly missing", cadence: domain.CadenceWeekly, now: day("2026-03-04"), want: domain.RecurringMissing},
+		{name: "unknown cadence", cadence: "daily", now: day("2027-01-01"), want: domain.RecurringActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.cadence, next, tt.now); got != tt.want {
+				t.Errorf("Status(%q, %s, %s) = %q, want %q", tt.cadence, next.Format("2006-01-02"), tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDate(t *testing.T) {
+	tests := []struct {
+		cadence domain.Cadence
+		last    string
+		want    string
+	}{
+		{cadence: domain.CadenceMonthly, last: "2026-01-15", want: "2026-02-15"},
+		{cadence: domain.CadenceMonthly, last: "2026-01-31", want: "2026-02-28"},
+		{cadence: domain.CadenceMonthly, last: "2024-01-31", want: "2024-02-29"},
+		{cadence: domain.CadenceMonthly, last: "2026-03-31", want: "2026-04-30"},
+		{cadence: domain.CadenceMonthly, last: "2025-12-31", want: "2026-01-31"},
+		{cadence: domain.CadenceYearly, last: "2024-02-29", want: "2025-02-28"},
+		{cadence: domain.CadenceYearly, last: "2025-06-30", want: "2026-06-30"},
+		{cadence: domain.CadenceWeekly, last: "2025-12-28", want: "2026-01-04"},
+	}
+
+	for _, tt := range tests {
+		spec, _ := cadenceSpec(tt.cadence)
+		if got := nextDate(spec, day(tt.last)).Format("2006-01-02"); got != tt.want {
+			t.Errorf("nextDate(%q, %s) = %s, want %s", tt.cadence, tt.last, got, tt.want)
+		}
+	}
+}
+
+func TestDetectMonthEnd(t *testing.T) {
+	var receipts []domain.Receipt
+	for i, d := range []string{"2025-11-30", "2025-12-31", "2026-01-31"} {
+		var r domain.Receipt
+		r.ID = i + 1
+		r.StoreName = sql.NullString{String: "Netflix", Valid: true}
+		r.Date = sql.NullTime{Time: day(d), Valid: true}
+		r.Currency = "USD"
+		r.TotalSpending = domain.NewMoney(1599, "USD")
+		r.TotalDiscount = domain.NewMoney(0, "USD")
+		receipts = append(receipts, r)
+	}
+
+	got := Detect(receipts, day("2026-02-10"))
+	if len(got) != 1 {
+		t.Fatalf("Detect() = %+v, want one recurrence", got)
+	}
+	if got[0].Cadence != domain.CadenceMonthly || !got[0].NextDate.Equal(day("2026-02-28")) || got[0].Status != domain.RecurringActive {
+		t.Errorf("Detect() = %s %s %s, want monthly active on 2026-02-28", got[0].Cadence, got[0].NextDate.Format("2006-01-02"), got[0].Status)
+	}
+}
@@ -0,0 +1,312 @@
+// Package report renders spending reports as PDF documents
+package report
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/pdf"
+)
+
+// ThumbnailSize is the longest side in pixels of the receipt thumbnails to embed
+const ThumbnailSize = 480
+
+const (
+	margin     = 48.0
+	rowHeight  = 16.0
+	bodySize   = 9.0
+	headerSize = 12.0
+	dateLayout = "2006-01-02"
+)
+
+// receiptColumns are the receipt table columns: header, left edge and whether right-aligned
+var receiptColumns = []struct {
+	title string
+	x     float64
+	right bool
+}{
+	{"Date", margin, false},
+	{"Store", margin + 62, false},
+	{"Payment", margin + 262, false},
+	{"Amount", margin + 420, true},
+	{"Converted", pdf.PageWidth - margin, true},
+}
+
+// renderer lays out a report top to bottom, adding pages as they fill up
+type renderer struct {
+	doc    *pdf.Document
+	report *domain.SpendingReport
+	y      float64
+}
+
+// WritePDF writes a report as a PDF: a summary page with the totals, category breakdown and top
+// stores, a table of the receipts and, when thumbnails are given, the receipt images by receipt ID
+func WritePDF(w io.Writer, report *domain.SpendingReport, thumbnails map[int]image.Image) error {
+	r := &renderer{doc: pdf.New(report.Title), report: report}
+
+	r.newPage()
+	r.summary()
+	r.categories()
+	r.topStores()
+
+	r.newPage()
+	r.receipts()
+
+	if len(thumbnails) > 0 {
+		r.newPage()
+		if err := r.images(thumbnails); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.doc.WriteTo(w)
+	return err
+}
+
+// newPage starts a page with the report title and page number in its footer
+func (r *renderer) newPage() {
+	r.doc.AddPage()
+	r.y = margin
+
+	footerY := pdf.PageHeight - margin/2
+	r.doc.Text(margin, footerY, pdf.Helvetica, 8, r.report.Title)
+	r.doc.TextRight(pdf.PageWidth-margin, footerY, pdf.Helvetica, 8, fmt.Sprintf("Page %d", r.doc.PageCount()))
+}
+
+// ensureSpace starts a new page when height points do not fit above the footer, and reports
+// whether it did
+func (r *renderer) ensureSpace(height float64) bool {
+	if r.y+height <= pdf.PageHeight-margin {
+		return false
+	}
+	r.newPage()
+	return true
+}
+
+// heading draws a section heading with a rule under it
+func (r *renderer) heading(title string) {
+	r.ensureSpace(3 * rowHeight)
+	r.y += rowHeight
+	r.doc.Text(margin, r.y, pdf.HelveticaBold, headerSize, title)
+	r.y += 6
+	r.doc.Line(margin, r.y, pdf.PageWidth-margin, r.y, 0.75, 0)
+	r.y += rowHeight
+}
+
+// summary draws the title, period and totals
+func (r *renderer) summary() {
+	report := r.report
+
+	r.y += 12
+	r.doc.Text(margin, r.y, pdf.HelveticaBold, 20, report.Title)
+	r.y += 20
+	if report.Subtitle != "" {
+		r.doc.Text(margin, r.y, pdf.Helvetica, 11, report.Subtitle)
+		r.y += 16
+	}
+
+	period := "All dates"
+	if report.From != nil && report.To != nil {
+		period = report.From.Format(dateLayout) + " to " + report.To.Format(dateLayout)
+	}
+	r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, "Period: "+period)
+	r.y += 12
+	r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, "Generated: "+report.GeneratedAt.Format("2006-01-02 15:04"))
+	r.y += 12
+
+	r.heading("Summary")
+	rows := [][2]string{
+		{"Total spending", formatMoney(report.Total)},
+		{"Discounts", formatMoney(report.Discount)},
+		{"Receipts", fmt.Sprint(report.ReceiptCount)},
+	}
+	for _, unconverted := range report.Unconverted {
+		rows = append(rows, [2]string{"Not converted (no exchange rate)", formatMoney(unconverted)})
+	}
+	for _, row := range rows {
+		r.doc.Text(margin, r.y, pdf.Helvetica, bodySize+1, row[0])
+		r.doc.TextRight(margin+300, r.y, pdf.HelveticaBold, bodySize+1, row[1])
+		r.y += rowHeight
+	}
+}
+
+// categories draws the spending per category with a bar for its share of the total
+func (r *renderer) categories() {
+	r.heading("Spending by Category")
+	if len(r.report.Categories) == 0 {
+		r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, "No spending in this period.")
+		r.y += rowHeight
+		return
+	}
+
+	barX, barWidth := margin+260, 120.0
+	for _, category := range r.report.Categories {
+		r.ensureSpace(rowHeight)
+
+		share := 0.0
+		if r.report.Total.Amount > 0 {
+			share = float64(category.Total.Amount) / float64(r.report.Total.Amount)
+		}
+
+		r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, pdf.Truncate(category.Category, pdf.Helvetica, bodySize, 140))
+		r.doc.TextRight(margin+240, r.y, pdf.Helvetica, bodySize, formatMoney(category.Total))
+		r.doc.Rect(barX, r.y-8, barWidth, 9, 0.9)
+		if share > 0 {
+			r.doc.Rect(barX, r.y-8, barWidth*min(share, 1), 9, 0.35)
+		}
+		r.doc.Text(barX+barWidth+8, r.y, pdf.Helvetica, bodySize, fmt.Sprintf("%.1f%%", share*100))
+		r.y += rowHeight
+	}
+}
+
+// topStores draws the stores with the most spending
+func (r *renderer) topStores() {
+	r.heading("Top Stores")
+	if len(r.report.TopStores) == 0 {
+		r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, "No spending in this period.")
+		r.y += rowHeight
+		return
+	}
+
+	r.doc.Text(margin, r.y, pdf.HelveticaBold, bodySize, "Store")
+	r.doc.TextRight(margin+320, r.y, pdf.HelveticaBold, bodySize, "Receipts")
+	r.doc.TextRight(pdf.PageWidth-margin, r.y, pdf.HelveticaBold, bodySize, "Amount")
+	r.y += rowHeight
+
+	for _, store := range r.report.TopStores {
+		r.ensureSpace(rowHeight)
+		r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, pdf.Truncate(store.Name, pdf.Helvetica, bodySize, 250))
+		r.doc.TextRight(margin+320, r.y, pdf.Helvetica, bodySize, fmt.Sprint(store.Receipts))
+		r.doc.TextRight(pdf.PageWidth-margin, r.y, pdf.Helvetica, bodySize, formatMoney(store.Total))
+		r.y += rowHeight
+	}
+}
+
+// receipts draws the receipt table, repeating its header on every page
+func (r *renderer) receipts() {
+	r.heading("Receipts")
+	r.receiptHeader()
+
+	for i, receipt := range r.report.Receipts {
+		if r.ensureSpace(rowHeight) {
+			r.receiptHeader()
+		}
+		if i%2 == 1 {
+			r.doc.Rect(margin-4, r.y-11, pdf.PageWidth-2*margin+8, rowHeight, 0.95)
+		}
+
+		converted := "-"
+		if receipt.Converted != nil {
+			converted = formatMoney(*receipt.Converted)
+		}
+
+		values := []string{
+			receipt.Date.Format(dateLayout),
+			pdf.Truncate(receipt.StoreName, pdf.Helvetica, bodySize, 190),
+			pdf.Truncate(strings.ReplaceAll(receipt.PaymentMethod, "_", " "), pdf.Helvetica, bodySize, 80),
+			formatMoney(receipt.Amount),
+			converted,
+		}
+		r.receiptRow(pdf.Helvetica, values)
+	}
+
+	if len(r.report.Receipts) == 0 {
+		r.doc.Text(margin, r.y, pdf.Helvetica, bodySize, "No receipts in this period.")
+		r.y += rowHeight
+	}
+}
+
+// receiptHeader draws the header row of the receipt table
+func (r *renderer) receiptHeader() {
+	titles := make([]string, len(receiptColumns))
+	for i, column := range receiptColumns {
+		titles[i] = column.title
+	}
+	r.receiptRow(pdf.HelveticaBold, titles)
+	r.doc.Line(margin, r.y-11, pdf.PageWidth-margin, r.y-11, 0.5, 0.5)
+	r.y += 2
+}
+
+// receiptRow draws one row of the receipt table
+func (r *renderer) receiptRow(font pdf.Font, values []string) {
+	for i, column := range receiptColumns {
+		if column.right {
+			r.doc.TextRight(column.x, r.y, font, bodySize, values[i])
+		} else {
+			r.doc.Text(column.x, r.y, font, bodySize, values[i])
+		}
+	}
+	r.y += rowHeight
+}
+
+// images draws the receipt thumbnails in a grid, in receipt table order, each captioned with its
+// store and date
+func (r *renderer) images(thumbnails map[int]image.Image) error {
+	r.heading("Receipt Images")
+
+	const columns = 3
+	const gap = 12.0
+	cellWidth := (pdf.PageWidth - 2*margin - gap*(columns-1)) / columns
+	cellHeight := cellWidth * 1.5
+
+	column := 0
+	for _, receipt := range r.report.Receipts {
+		thumbnail, ok := thumbnails[receipt.ID]
+		if !ok {
+			continue
+		}
+
+		if column == 0 {
+			r.ensureSpace(cellHeight + 2*rowHeight)
+		}
+		x := margin + float64(column)*(cellWidth+gap)
+
+		// Fit the image in the cell keeping its aspect ratio
+		bounds := thumbnail.Bounds()
+		width, height := cellWidth, cellWidth*float64(bounds.Dy())/float64(bounds.Dx())
+		if height > cellHeight {
+			width, height = cellHeight*float64(bounds.Dx())/float64(bounds.Dy()), cellHeight
+		}
+		if err := r.doc.Image(thumbnail, x+(cellWidth-width)/2, r.y, width, height); err != nil {
+			return err
+		}
+
+		caption := receipt.Date.Format(dateLayout) + " " + receipt.StoreName
+		r.doc.Text(x, r.y+cellHeight+12, pdf.Helvetica, 8, pdf.Truncate(caption, pdf.Helvetica, 8, cellWidth))
+
+		column++
+		if column == columns {
+			column = 0
+			r.y += cellHeight + 2*rowHeight
+		}
+	}
+
+	return nil
+}
+
+// formatMoney formats an amount with its currency and thousands separators, e.g. "IDR 1,250,000.00"
+func formatMoney(m domain.Money) string {
+	value := m.String()
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+
+	whole, fraction := value, ""
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		whole, fraction = value[:dot], value[dot:]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return m.Currency + " " + sign + grouped.String() + fraction
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/document"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		money domain.Money
+		want  string
+	}{
+		{money: domain.NewMoney(125000000, "IDR"), want: "IDR 1,250,000.00"},
+		{money: domain.NewMoney(-123456, "USD"), want: "USD -1,234.56"},
+		{money: domain.NewMoney(5, "USD"), want: "USD 0.05"},
+		{money: domain.NewMoney(1000, "JPY"), want: "JPY 1,000"},
+		{money: domain.NewMoney(100, "JPY"), want: "JPY 100"},
+		{money: domain.NewMoney(1234567, "KWD"), want: "KWD 1,234.567"},
+	}
+
+	for _, tt := range tests {
+		if got := formatMoney(tt.money); got != tt.want {
+			t.Errorf("formatMoney(%+v) = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	converted := domain.NewMoney(1575000, "IDR")
+
+	report := &domain.SpendingReport{
+		Title:        "Bali (trip) \\ 2026",
+		From:         &from,
+		To:           &to,
+		HomeCurrency: "IDR",
+		ReceiptCount: 2,
+		Total:        domain.NewMoney(2575000, "IDR"),
+		Discount:     domain.NewMoney(0, "IDR"),
+		Unconverted:  []domain.Money{domain.NewMoney(1200, "EUR")},
+		Categories:   []domain.CategoryTotal{{Category: "Food", Total: domain.NewMoney(2575000, "IDR")}},
+		TopStores:    []domain.StoreTotal{{Name: "Warung (Bu Made)", Receipts: 2, Total: domain.NewMoney(2575000, "IDR")}},
+		Receipts: []domain.SpendingReportReceipt{
+			{ID: 1, Date: from, StoreName: "Warung (Bu Made)", PaymentMethod: "cash", Amount: domain.NewMoney(1000000, "IDR"), Converted: &domain.Money{Amount: 1000000, Currency: "IDR"}},
+			{ID: 2, Date: to, StoreName: "Café Lotus", PaymentMethod: "credit_card", Amount: domain.NewMoney(100, "USD"), Converted: &converted},
+		},
+		GeneratedAt: time.Date(2026, 4, 1, 9, 30, 0, 0, time.UTC),
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, 30, 60))
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, report, map[int]image.Image{2: thumbnail}); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+
+	pages, err := document.PDFText(buf.Bytes())
+	if err != nil {
+		t.Fatalf("PDFText() error = %v", err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("WritePDF() wrote %d pages, want 3", len(pages))
+	}
+
+	want := [][]string{
+		{"Bali (trip) \\ 2026", "Period: 2026-03-01 to 2026-03-31", "IDR 25,750.00", "EUR 12.00", "Warung (Bu Made)", "Page 1"},
+		{"2026-03-31", "Café Lotus", "credit card", "USD 1.00", "IDR 15,750.00", "Page 2"},
+		{"Receipt Images", "2026-03-31 Café Lotus", "Page 3"},
+	}
+	for i, texts := range want {
+		for _, text := range texts {
+			if !strings.Contains(pages[i], text) {
+				t.Errorf("page %d does not contain %q:\n%s", i+1, text, pages[i])
+			}
+		}
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSize       int
+		wantW, wantH  int
+	}{
+		{name: "landscape", width: 1000, height: 500, maxSize: 480, wantW: 480, wantH: 240},
+		{name: "portrait", width: 300, height: 900, maxSize: 480, wantW: 160, wantH: 480},
+		{name: "small images are kept", width: 200, height: 100, maxSize: 480, wantW: 200, wantH: 100},
+		{name: "thin images keep a pixel", width: 2000, height: 1, maxSize: 480, wantW: 480, wantH: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, tt.width, tt.height))); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Thumbnail(&buf, tt.maxSize)
+			if err != nil {
+				t.Fatalf("Thumbnail() error = %v", err)
+			}
+			if bounds := got.Bounds(); bounds.Dx() != tt.wantW || bounds.Dy() != tt.wantH {
+				t.Errorf("Thumbnail() is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestThumbnailAverages(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if (x+y)%2 == 0 {
+				src.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Thumbnail(&buf, 2)
+	if err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+	for x := 0; x < 2; x++ {
+		if r, g, b, a := got.At(x, 0).RGBA(); r>>8 != 127 || g>>8 != 127 || b>>8 != 127 || a>>8 != 255 {
+			t.Errorf("Thumbnail() pixel %d = %d %d %d %d, want mid gray", x, r>>8, g>>8, b>>8, a>>8)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	// Receipt images are uploaded as JPEG, PNG or GIF
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Thumbnail decodes an image and scales it down so its longest side is at most maxSize pixels,
+// averaging the source pixels covered by each thumbnail pixel
+func Thumbnail(r io.Reader, maxSize int) (image.Image, error) {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return src, nil
+	}
+
+	if width >= height {
+		width, height = maxSize, max(1, height*maxSize/width)
+	} else {
+		width, height = max(1, width*maxSize/height), maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+
+	return dst, nil
+}
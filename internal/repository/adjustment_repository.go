@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type AdjustmentRepository interface {
+	CreateBatch(adjustments []domain.Adjustment) error
+	FindByReceiptID(receiptID int) ([]domain.Adjustment, error)
+	ReplaceForReceipt(receiptID int, adjustments []domain.Adjustment) error
+	GetTotalsByCurrency(workspaceID int, filter domain.ReceiptFilter) ([]domain.AdjustmentTotal, error)
+}
+
+// insertAdjustmentQuery inserts one adjustment and returns its generated fields
+const insertAdjustmentQuery = `
+	INSERT INTO receipt_adjustments (receipt_id, type, label, rate, amount, created_at_unix)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, uuid, created_at
+`
+
+type adjustmentRepository struct {
+	db *sql.DB
+}
+
+// NewAdjustmentRepository creates a new adjustment repository
+func NewAdjustmentRepository(db *sql.DB) AdjustmentRepository {
+	return &adjustmentRepository{db: db}
+}
+
+// insertAdjustment inserts an adjustment using the given connection or transaction
+func insertAdjustment(q queryRower, adjustment *domain.Adjustment, now int64) error {
+	err := q.QueryRow(
+		insertAdjustmentQuery,
+		adjustment.ReceiptID,
+		adjustment.Type,
+		sql.NullString{String: adjustment.Label, Valid: adjustment.Label != ""},
+		sql.NullString{String: string(adjustment.Rate), Valid: adjustment.Rate != ""},
+		adjustment.Amount,
+		now,
+	).Scan(&adjustment.ID, &adjustment.UUID, &adjustment.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create adjustment: %w", err)
+	}
+
+	adjustment.CreatedAtUnix = now
+	return nil
+}
+
+// CreateBatch creates multiple adjustments in a single transaction
+func (r *adjustmentRepository) CreateBatch(adjustments []domain.Adjustment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	for i := range adjustments {
+		if err := insertAdjustment(tx, &adjustments[i], now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceForReceipt deletes the receipt's adjustments and inserts the given ones in a single transaction
+func (r *adjustmentRepository) ReplaceForReceipt(receiptID int, adjustments []domain.Adjustment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM receipt_adjustments WHERE receipt_id = $1`, receiptID); err != nil {
+		return fmt.Errorf("failed to delete adjustments: %w", err)
+	}
+
+	now := time.Now().Unix()
+	for i := range adjustments {
+		adjustments[i].ReceiptID = receiptID
+		if err := insertAdjustment(tx, &adjustments[i], now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindByReceiptID finds all adjustments of a receipt in the order they were printed
+func (r *adjustmentRepository) FindByReceiptID(receiptID int) ([]domain.Adjustment, error) {
+	query := `
+		SELECT a.id, a.uuid, a.receipt_id, a.type, COALESCE(a.label, ''), a.rate, a.amount,
+		       a.created_at, a.created_at_unix, r.currency
+		FROM receipt_adjustments a
+		JOIN receipts r ON r.id = a.receipt_id
+		WHERE a.receipt_id = $1
+		ORDER BY a.id ASC
+	`
+
+	rows, err := r.db.Query(query, receiptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	adjustments := []domain.Adjustment{}
+	for rows.Next() {
+		var adjustment domain.Adjustment
+		var rate sql.NullString
+
+		err := rows.Scan(
+			&adjustment.ID,
+			&adjustment.UUID,
+			&adjustment.ReceiptID,
+			&adjustment.Type,
+			&adjustment.Label,
+			&rate,
+			&adjustment.Amount,
+			&adjustment.CreatedAt,
+			&adjustment.CreatedAtUnix,
+			&adjustment.Amount.Currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan adjustment: %w", err)
+		}
+
+		adjustment.Rate = domain.Decimal(rate.String)
+		adjustments = append(adjustments, adjustment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate adjustments: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// GetTotalsByCurrency sums the adjustments of the workspace's receipts per currency, purchase day and
+// type, using the same receipt selection as ReceiptRepository.GetTotalsByCurrency
+func (r *adjustmentRepository) GetTotalsByCurrency(workspaceID int, filter domain.ReceiptFilter) ([]domain.AdjustmentTotal, error) {
+	if filter.Status == "" {
+		filter.Status = domain.StatusCompleted
+	}
+	where, args := receiptFilterClause(workspaceID, filter)
+
+	query := `
+		SELECT
+			r.currency,
+			COALESCE(r.date, r.upload_date::date) AS day,
+			a.type,
+			SUM(a.amount)::BIGINT AS amount
+		FROM receipts r
+		JOIN receipt_adjustments a ON a.receipt_id = r.id
+		WHERE ` + where + ` AND r.duplicate_of IS NULL
+		GROUP BY r.currency, day, a.type
+		ORDER BY day ASC
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adjustment stats: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.AdjustmentTotal
+	for rows.Next() {
+		var total domain.AdjustmentTotal
+		if err := rows.Scan(&total.Currency, &total.Day, &total.Type, &total.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan adjustment stats: %w", err)
+		}
+		total.Amount.Currency = total.Currency
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate adjustment stats: %w", err)
+	}
+
+	return totals, nil
+}
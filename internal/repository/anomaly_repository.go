@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type AnomalyRepository interface {
+	FindWorkspaceIDs() ([]int, error)
+	FindHistory(workspaceID int) ([]domain.ReceiptWithItems, error)
+	CreateBatch(anomalies []domain.Anomaly) (int, error)
+	FindByWorkspaceID(workspaceID int, status domain.AnomalyStatus, page, limit int) ([]domain.Anomaly, int64, error)
+	FindByID(id int) (*domain.Anomaly, error)
+	UpdateStatus(id int, status domain.AnomalyStatus) error
+}
+
+type anomalyRepository struct {
+	db *sql.DB
+}
+
+// NewAnomalyRepository creates a new anomaly repository
+func NewAnomalyRepository(db *sql.DB) AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+// anomalyColumns lists the columns of an anomaly SELECT joined with its receipt, in scanAnomaly order
+const anomalyColumns = `
+	a.id, a.uuid, a.workspace_id, a.receipt_id, a.item_id, a.kind, a.category, a.currency,
+	a.value, a.baseline, a.score, a.message, a.status, r.store_name, r.date,
+	a.created_at, a.updated_at, a.created_at_unix, a.updated_at_unix
+`
+
+// scanAnomaly scans a row of anomalyColumns
+func scanAnomaly(row rowScanner, anomaly *domain.Anomaly) error {
+	var value, baseline string
+
+	err := row.Scan(
+		&anomaly.ID,
+		&anomaly.UUID,
+		&anomaly.WorkspaceID,
+		&anomaly.ReceiptID,
+		&anomaly.ItemID,
+		&anomaly.Kind,
+		&anomaly.Category,
+		&anomaly.Currency,
+		&value,
+		&baseline,
+		&anomaly.Score,
+		&anomaly.Message,
+		&anomaly.Status,
+		&anomaly.StoreName,
+		&anomaly.ReceiptDate,
+		&anomaly.CreatedAt,
+		&anomaly.UpdatedAt,
+		&anomaly.CreatedAtUnix,
+		&anomaly.UpdatedAtUnix,
+	)
+	if err != nil {
+		return err
+	}
+
+	anomaly.Value = domain.Decimal(value)
+	anomaly.Baseline = domain.Decimal(baseline)
+	return nil
+}
+
+// FindWorkspaceIDs finds the workspaces that have receipts, for running detection over everyone
+func (r *anomalyRepository) FindWorkspaceIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT workspace_id FROM receipts ORDER BY workspace_id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var workspaceIDs []int
+	for rows.Next() {
+		var workspaceID int
+		if err := rows.Scan(&workspaceID); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaceIDs = append(workspaceIDs, workspaceID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate workspaces: %w", err)
+	}
+
+	return workspaceIDs, nil
+}
+
+// FindHistory finds the completed receipts of a workspace, with their items, as the history
+// anomalies are detected in. Receipts flagged as duplicates of another receipt are excluded.
+func (r *anomalyRepository) FindHistory(workspaceID int) ([]domain.ReceiptWithItems, error) {
+	query := `
+		SELECT ` + prefixedReceiptColumns("r") + `
+		FROM receipts r
+		WHERE r.workspace_id = $1 AND r.status = $2 AND r.duplicate_of IS NULL
+		ORDER BY r.id ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID, domain.StatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipts: %w", err)
+	}
+	defer rows.Close()
+
+	history := []domain.ReceiptWithItems{}
+	index := map[int]int{}
+	for rows.Next() {
+		var receipt domain.ReceiptWithItems
+		if err := scanReceipt(rows, &receipt.Receipt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		index[receipt.ID] = len(history)
+		history = append(history, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	itemQuery := `
+		SELECT i.id, i.uuid, i.receipt_id, i.product_id, i.name, i.unit_price, i.quantity, i.price, i.total,
+		       i.category, i.created_at, i.created_at_unix
+		FROM items i
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE r.workspace_id = $1 AND r.status = $2 AND r.duplicate_of IS NULL
+		ORDER BY i.receipt_id ASC, i.id ASC
+	`
+
+	itemRows, err := r.db.Query(itemQuery, workspaceID, domain.StatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item domain.Item
+		err := itemRows.Scan(
+			&item.ID,
+			&item.UUID,
+			&item.ReceiptID,
+			&item.ProductID,
+			&item.Name,
+			&item.UnitPrice,
+			&item.Quantity,
+			&item.Price,
+			&item.Total,
+			&item.Category,
+			&item.CreatedAt,
+			&item.CreatedAtUnix,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+
+		i, ok := index[item.ReceiptID]
+		if !ok {
+			continue
+		}
+		receipt := &history[i]
+		item.UnitPrice.Currency = receipt.Currency
+		item.Price.Currency = receipt.Currency
+		item.Total.Currency = receipt.Currency
+		receipt.Items = append(receipt.Items, item)
+	}
+
+	if err := itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate items: %w", err)
+	}
+
+	return history, nil
+}
+
+// CreateBatch stores detected anomalies, skipping those already recorded for the same receipt,
+// kind and item so that detection can run repeatedly without duplicating the feed or
+// resurfacing dismissed anomalies. It returns the number of anomalies created.
+func (r *anomalyRepository) CreateBatch(anomalies []domain.Anomaly) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO anomalies (
+			workspace_id, receipt_id, item_id, kind, category, currency, value, baseline, score, message,
+			status, created_at_unix, updated_at_unix
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (receipt_id, kind, (COALESCE(item_id, 0))) DO NOTHING
+	`
+
+	now := time.Now().Unix()
+	created := 0
+	for _, anomaly := range anomalies {
+		result, err := tx.Exec(
+			query,
+			anomaly.WorkspaceID,
+			anomaly.ReceiptID,
+			anomaly.ItemID,
+			anomaly.Kind,
+			anomaly.Category,
+			anomaly.Currency,
+			string(anomaly.Value),
+			string(anomaly.Baseline),
+			anomaly.Score,
+			anomaly.Message,
+			domain.AnomalyNew,
+			now,
+			now,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create anomaly: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		created += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// FindByWorkspaceID finds a page of a workspace's anomalies, newest first, optionally with one
+// status. Dismissed anomalies are only listed when asked for by status.
+func (r *anomalyRepository) FindByWorkspaceID(workspaceID int, status domain.AnomalyStatus, page, limit int) ([]domain.Anomaly, int64, error) {
+	where := `a.workspace_id = $1 AND a.status <> $2`
+	args := []interface{}{workspaceID, domain.AnomalyDismissed}
+	if status != "" {
+		where = `a.workspace_id = $1 AND a.status = $2`
+		args = []interface{}{workspaceID, status}
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM anomalies a WHERE ` + where
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count anomalies: %w", err)
+	}
+
+	query := `
+		SELECT ` + anomalyColumns + `
+		FROM anomalies a
+		JOIN receipts r ON r.id = a.receipt_id
+		WHERE ` + where + `
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	anomalies := []domain.Anomaly{}
+	for rows.Next() {
+		var anomaly domain.Anomaly
+		if err := scanAnomaly(rows, &anomaly); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan anomaly: %w", err)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate anomalies: %w", err)
+	}
+
+	return anomalies, total, nil
+}
+
+// FindByID finds an anomaly by ID
+func (r *anomalyRepository) FindByID(id int) (*domain.Anomaly, error) {
+	query := `
+		SELECT ` + anomalyColumns + `
+		FROM anomalies a
+		JOIN receipts r ON r.id = a.receipt_id
+		WHERE a.id = $1
+	`
+
+	anomaly := &domain.Anomaly{}
+	err := scanAnomaly(r.db.QueryRow(query, id), anomaly)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("anomaly not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find anomaly: %w", err)
+	}
+
+	return anomaly, nil
+}
+
+// UpdateStatus marks an anomaly as new, seen or dismissed
+func (r *anomalyRepository) UpdateStatus(id int, status domain.AnomalyStatus) error {
+	query := `
+		UPDATE anomalies
+		SET status = $1, updated_at = NOW(), updated_at_unix = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(query, status, time.Now().Unix(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update anomaly: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("anomaly not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *domain.Attachment) error
+	FindByID(id int) (*domain.Attachment, error)
+	FindByReceiptID(receiptID int) ([]domain.Attachment, error)
+	Delete(id int) error
+}
+
+// attachmentColumns lists the columns selected for an attachment, in scanAttachment order
+const attachmentColumns = `
+	id, uuid, receipt_id, user_id, kind, position, filename, content_type, size, checksum,
+	storage_key, created_at, created_at_unix
+`
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// scanAttachment scans a row selected with attachmentColumns into attachment
+func scanAttachment(row rowScanner, attachment *domain.Attachment) error {
+	return row.Scan(
+		&attachment.ID,
+		&attachment.UUID,
+		&attachment.ReceiptID,
+		&attachment.UserID,
+		&attachment.Kind,
+		&attachment.Position,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Checksum,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+		&attachment.CreatedAtUnix,
+	)
+}
+
+// Create creates a new attachment after the receipt's other attachments of the same kind
+func (r *attachmentRepository) Create(attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO receipt_attachments (
+			uuid, receipt_id, user_id, kind, position, filename, content_type, size, checksum,
+			storage_key, created_at_unix
+		)
+		SELECT $1, $2, $3, $4, COALESCE(MAX(position), 0) + 1, $5, $6, $7, $8, $9, $10
+		FROM receipt_attachments
+		WHERE receipt_id = $2 AND kind = $4
+		RETURNING id, position, created_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		attachment.UUID,
+		attachment.ReceiptID,
+		attachment.UserID,
+		attachment.Kind,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.StorageKey,
+		now,
+	).Scan(&attachment.ID, &attachment.Position, &attachment.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	attachment.CreatedAtUnix = now
+	return nil
+}
+
+// FindByID finds attachment by ID
+func (r *attachmentRepository) FindByID(id int) (*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM receipt_attachments WHERE id = $1`
+
+	attachment := &domain.Attachment{}
+	err := scanAttachment(r.db.QueryRow(query, id), attachment)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// FindByReceiptID finds the attachments of a receipt, pages first, in order
+func (r *attachmentRepository) FindByReceiptID(receiptID int) ([]domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM receipt_attachments
+		WHERE receipt_id = $1
+		ORDER BY kind = 'page' DESC, position ASC
+	`
+
+	rows, err := r.db.Query(query, receiptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []domain.Attachment{}
+	for rows.Next() {
+		var attachment domain.Attachment
+		if err := scanAttachment(rows, &attachment); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// Delete deletes an attachment
+func (r *attachmentRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM receipt_attachments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+
+	return nil
+}
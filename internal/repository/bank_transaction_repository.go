@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type BankTransactionRepository interface {
+	CreateBatch(transactions []domain.BankTransaction) (int, error)
+	FindByID(id int) (*domain.BankTransaction, error)
+	FindUnmatched(userID int) ([]domain.BankTransaction, error)
+	FindMatched(userID int) ([]domain.ReconciliationMatch, error)
+	FindUnmatchedReceipts(workspaceID int, from, to time.Time) ([]domain.Receipt, error)
+	FindByReceiptID(receiptID int) (*domain.BankTransaction, error)
+	SetMatch(id int, receiptID sql.NullInt64, score sql.NullFloat64) error
+}
+
+// bankTransactionColumnNames lists the columns selected for a bank transaction, in bankTransactionScanDest order
+var bankTransactionColumnNames = []string{
+	"id", "uuid", "user_id", "account", "external_id", "date", "description", "amount", "currency",
+	"receipt_id", "match_score", "created_at", "created_at_unix",
+}
+
+type bankTransactionRepository struct {
+	db *sql.DB
+}
+
+// NewBankTransactionRepository creates a new bank transaction repository
+func NewBankTransactionRepository(db *sql.DB) BankTransactionRepository {
+	return &bankTransactionRepository{db: db}
+}
+
+// prefixedBankTransactionColumns returns the bank transaction columns qualified with a table alias
+func prefixedBankTransactionColumns(alias string) string {
+	columns := make([]string, len(bankTransactionColumnNames))
+	for i, name := range bankTransactionColumnNames {
+		columns[i] = alias + "." + name
+	}
+	return strings.Join(columns, ", ")
+}
+
+// bankTransactionScanDest returns the scan destinations for the bank transaction columns
+func bankTransactionScanDest(transaction *domain.BankTransaction) []interface{} {
+	return []interface{}{
+		&transaction.ID,
+		&transaction.UUID,
+		&transaction.UserID,
+		&transaction.Account,
+		&transaction.ExternalID,
+		&transaction.Date,
+		&transaction.Description,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.ReceiptID,
+		&transaction.MatchScore,
+		&transaction.CreatedAt,
+		&transaction.CreatedAtUnix,
+	}
+}
+
+// queryBankTransactions runs a bank transaction query and scans every row
+func (r *bankTransactionRepository) queryBankTransactions(query string, args ...interface{}) ([]domain.BankTransaction, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bank transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []domain.BankTransaction{}
+	for rows.Next() {
+		var transaction domain.BankTransaction
+		if err := rows.Scan(bankTransactionScanDest(&transaction)...); err != nil {
+			return nil, fmt.Errorf("failed to scan bank transaction: %w", err)
+		}
+		transaction.Amount.Currency = transaction.Currency
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bank transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// CreateBatch inserts transactions in a single transaction, skipping those already imported
+// for the same account, and returns how many were inserted
+func (r *bankTransactionRepository) CreateBatch(transactions []domain.BankTransaction) (int, error) {
+	query := `
+		INSERT INTO bank_transactions (user_id, account, external_id, date, description, amount, currency, created_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, account, external_id) DO NOTHING
+		RETURNING id, uuid, created_at
+	`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	inserted := 0
+	for i := range transactions {
+		transaction := &transactions[i]
+		err := tx.QueryRow(
+			query,
+			transaction.UserID,
+			transaction.Account,
+			transaction.ExternalID,
+			transaction.Date,
+			transaction.Description,
+			transaction.Amount,
+			transaction.Currency,
+			now,
+		).Scan(&transaction.ID, &transaction.UUID, &transaction.CreatedAt)
+
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to create bank transaction: %w", err)
+		}
+
+		transaction.CreatedAtUnix = now
+		inserted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// FindByID finds bank transaction by ID
+func (r *bankTransactionRepository) FindByID(id int) (*domain.BankTransaction, error) {
+	query := `SELECT ` + prefixedBankTransactionColumns("t") + ` FROM bank_transactions t WHERE t.id = $1`
+
+	transactions, err := r.queryBankTransactions(query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("bank transaction not found")
+	}
+
+	return &transactions[0], nil
+}
+
+// FindByReceiptID finds the bank transaction matched with a receipt, or nil if there is none
+func (r *bankTransactionRepository) FindByReceiptID(receiptID int) (*domain.BankTransaction, error) {
+	query := `SELECT ` + prefixedBankTransactionColumns("t") + ` FROM bank_transactions t WHERE t.receipt_id = $1`
+
+	transactions, err := r.queryBankTransactions(query, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	return &transactions[0], nil
+}
+
+// FindUnmatched finds the user's bank transactions not matched with a receipt, oldest first
+func (r *bankTransactionRepository) FindUnmatched(userID int) ([]domain.BankTransaction, error) {
+	query := `
+		SELECT ` + prefixedBankTransactionColumns("t") + `
+		FROM bank_transactions t
+		WHERE t.user_id = $1 AND t.receipt_id IS NULL
+		ORDER BY t.date ASC, t.id ASC
+	`
+	return r.queryBankTransactions(query, userID)
+}
+
+// FindMatched finds the user's bank transactions matched with a receipt, with the receipt, oldest first
+func (r *bankTransactionRepository) FindMatched(userID int) ([]domain.ReconciliationMatch, error) {
+	query := `
+		SELECT ` + prefixedBankTransactionColumns("t") + `, ` + prefixedReceiptColumns("r") + `
+		FROM bank_transactions t
+		JOIN receipts r ON r.id = t.receipt_id
+		WHERE t.user_id = $1
+		ORDER BY t.date ASC, t.id ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []domain.ReconciliationMatch{}
+	for rows.Next() {
+		var match domain.ReconciliationMatch
+		dest := append(bankTransactionScanDest(&match.Transaction), receiptScanDest(&match.Receipt)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		match.Transaction.Amount.Currency = match.Transaction.Currency
+		setReceiptCurrency(&match.Receipt)
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// FindUnmatchedReceipts finds the workspace's completed receipts purchased (or, without a date,
+// uploaded) between from and to that no bank transaction is matched with. Receipts flagged as
+// duplicates of another receipt are excluded.
+func (r *bankTransactionRepository) FindUnmatchedReceipts(workspaceID int, from, to time.Time) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + prefixedReceiptColumns("r") + `
+		FROM receipts r
+		WHERE r.workspace_id = $1 AND r.status = $2 AND r.duplicate_of IS NULL
+		  AND COALESCE(r.date, r.upload_date::date) BETWEEN $3 AND $4
+		  AND NOT EXISTS (SELECT 1 FROM bank_transactions t WHERE t.receipt_id = r.id)
+		ORDER BY COALESCE(r.date, r.upload_date::date) ASC, r.id ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID, domain.StatusCompleted, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []domain.Receipt{}
+	for rows.Next() {
+		var receipt domain.Receipt
+		if err := scanReceipt(rows, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
+// SetMatch matches a bank transaction with a receipt, or unmatches it when receiptID is null
+func (r *bankTransactionRepository) SetMatch(id int, receiptID sql.NullInt64, score sql.NullFloat64) error {
+	query := `UPDATE bank_transactions SET receipt_id = $1, match_score = $2 WHERE id = $3`
+
+	result, err := r.db.Exec(query, receiptID, score, id)
+	if err != nil {
+		return fmt.Errorf("failed to update bank transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("bank transaction not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type CardRepository interface {
+	Create(card *domain.Card) error
+	FindByID(id int) (*domain.Card, error)
+	FindByUserID(userID int) ([]domain.Card, error)
+	FindMatching(userID int, last4, brand string) ([]domain.Card, error)
+	LinkReceipts(card *domain.Card) (int64, error)
+	Delete(id int) error
+}
+
+// cardColumns lists the columns selected for a card, in scanCard order
+const cardColumns = `id, uuid, user_id, COALESCE(nickname, ''), COALESCE(brand, ''), last4, created_at, created_at_unix`
+
+type cardRepository struct {
+	db *sql.DB
+}
+
+// NewCardRepository creates a new card repository
+func NewCardRepository(db *sql.DB) CardRepository {
+	return &cardRepository{db: db}
+}
+
+// scanCard scans a row selected with cardColumns into card
+func scanCard(row rowScanner, card *domain.Card) error {
+	return row.Scan(
+		&card.ID,
+		&card.UUID,
+		&card.UserID,
+		&card.Nickname,
+		&card.Brand,
+		&card.Last4,
+		&card.CreatedAt,
+		&card.CreatedAtUnix,
+	)
+}
+
+// queryCards runs a card query and scans every row
+func (r *cardRepository) queryCards(query string, args ...interface{}) ([]domain.Card, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := []domain.Card{}
+	for rows.Next() {
+		var card domain.Card
+		if err := scanCard(rows, &card); err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// Create creates a new card
+func (r *cardRepository) Create(card *domain.Card) error {
+	query := `
+		INSERT INTO cards (user_id, nickname, brand, last4, created_at_unix)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, uuid, created_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		card.UserID,
+		sql.NullString{String: card.Nickname, Valid: card.Nickname != ""},
+		sql.NullString{String: card.Brand, Valid: card.Brand != ""},
+		card.Last4,
+		now,
+	).Scan(&card.ID, &card.UUID, &card.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create card: %w", err)
+	}
+
+	card.CreatedAtUnix = now
+	return nil
+}
+
+// FindByID finds card by ID
+func (r *cardRepository) FindByID(id int) (*domain.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM cards WHERE id = $1`
+
+	card := &domain.Card{}
+	err := scanCard(r.db.QueryRow(query, id), card)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find card: %w", err)
+	}
+
+	return card, nil
+}
+
+// FindByUserID finds all cards of a user
+func (r *cardRepository) FindByUserID(userID int) ([]domain.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM cards WHERE user_id = $1 ORDER BY created_at ASC`
+	return r.queryCards(query, userID)
+}
+
+// FindMatching finds the user's cards with the given last four digits whose brand matches.
+// A card or receipt without a brand matches any brand.
+func (r *cardRepository) FindMatching(userID int, last4, brand string) ([]domain.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards
+		WHERE user_id = $1 AND last4 = $2
+		  AND ($3 = '' OR brand IS NULL OR UPPER(brand) = UPPER($3))
+		ORDER BY created_at ASC
+	`
+	return r.queryCards(query, userID, last4, brand)
+}
+
+// LinkReceipts links the user's receipts paid with the card's last four digits and brand that
+// are not linked to a card yet, and returns how many were linked
+func (r *cardRepository) LinkReceipts(card *domain.Card) (int64, error) {
+	query := `
+		UPDATE receipts
+		SET card_id = $1
+		WHERE user_id = $2 AND card_last4 = $3 AND card_id IS NULL
+		  AND ($4 = '' OR card_brand IS NULL OR UPPER(card_brand) = UPPER($4))
+	`
+
+	result, err := r.db.Exec(query, card.ID, card.UserID, card.Last4, card.Brand)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link receipts: %w", err)
+	}
+
+	linked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return linked, nil
+}
+
+// Delete deletes card by ID; receipts linked to it are unlinked
+func (r *cardRepository) Delete(id int) error {
+	query := `DELETE FROM cards WHERE id = $1`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete card: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("card not found")
+	}
+
+	return nil
+}
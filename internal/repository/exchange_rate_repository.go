@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type ExchangeRateRepository interface {
+	UpsertBatch(rates []domain.ExchangeRate) error
+	FindForCurrencies(currencies []string, until time.Time) ([]domain.ExchangeRate, error)
+}
+
+type exchangeRateRepository struct {
+	db *sql.DB
+}
+
+// NewExchangeRateRepository creates a new exchange rate repository
+func NewExchangeRateRepository(db *sql.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+// UpsertBatch inserts rates in a single transaction, replacing existing rates for the same pair and date
+func (r *exchangeRateRepository) UpsertBatch(rates []domain.ExchangeRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, rate_date, source, created_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (base_currency, quote_currency, rate_date)
+		DO UPDATE SET rate = EXCLUDED.rate, source = EXCLUDED.source
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+
+	for i := range rates {
+		err := stmt.QueryRow(
+			rates[i].BaseCurrency,
+			rates[i].QuoteCurrency,
+			rates[i].Rate,
+			rates[i].RateDate,
+			rates[i].Source,
+			now,
+		).Scan(&rates[i].ID, &rates[i].CreatedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to upsert exchange rate: %w", err)
+		}
+
+		rates[i].CreatedAtUnix = now
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindForCurrencies finds every rate up to the given day whose base or quote currency is one of
+// the currencies, which covers direct, inverse and cross rates between them
+func (r *exchangeRateRepository) FindForCurrencies(currencies []string, until time.Time) ([]domain.ExchangeRate, error) {
+	query := `
+		SELECT id, base_currency, quote_currency, rate, rate_date, COALESCE(source, ''), created_at, created_at_unix
+		FROM exchange_rates
+		WHERE (base_currency = ANY($1) OR quote_currency = ANY($1)) AND rate_date <= $2
+		ORDER BY rate_date ASC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(currencies), until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []domain.ExchangeRate
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		err := rows.Scan(
+			&rate.ID,
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.RateDate,
+			&rate.Source,
+			&rate.CreatedAt,
+			&rate.CreatedAtUnix,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type ExpenseReportRepository interface {
+	Create(report *domain.ExpenseReport) error
+	FindByID(id int) (*domain.ExpenseReport, error)
+	FindByWorkspaceID(workspaceID int, userID int, status domain.ExpenseReportStatus) ([]domain.ExpenseReport, error)
+	FindByReceiptID(receiptID int) (*domain.ExpenseReport, error)
+	Update(report *domain.ExpenseReport) error
+	Delete(id int) error
+	FindReceipts(reportID int) ([]domain.Receipt, error)
+	AddReceipts(reportID int, receiptIDs []int) error
+	RemoveReceipt(reportID int, receiptID int) error
+	SetApprovers(reportID int, userIDs []int) error
+	UpdateDecision(reportID int, userID int, decision domain.ApprovalDecision) error
+	ResetDecisions(reportID int) error
+	CreateComment(comment *domain.ExpenseComment) error
+	FindComments(reportID int) ([]domain.ExpenseComment, error)
+}
+
+// expenseReportColumns lists the columns selected for a report with its receipt count, in scanExpenseReport order
+const expenseReportColumns = `
+	e.id, e.uuid, e.workspace_id, e.user_id, e.title, COALESCE(e.description, ''), e.status,
+	(SELECT COUNT(*) FROM expense_report_receipts er WHERE er.report_id = e.id),
+	e.submitted_at, e.decided_at, e.paid_at, e.created_at, e.updated_at, e.created_at_unix, e.updated_at_unix
+`
+
+type expenseReportRepository struct {
+	db *sql.DB
+}
+
+// NewExpenseReportRepository creates a new expense report repository
+func NewExpenseReportRepository(db *sql.DB) ExpenseReportRepository {
+	return &expenseReportRepository{db: db}
+}
+
+// scanExpenseReport scans a row selected with expenseReportColumns into report
+func scanExpenseReport(row rowScanner, report *domain.ExpenseReport) error {
+	return row.Scan(
+		&report.ID,
+		&report.UUID,
+		&report.WorkspaceID,
+		&report.UserID,
+		&report.Title,
+		&report.Description,
+		&report.Status,
+		&report.ReceiptCount,
+		&report.SubmittedAt,
+		&report.DecidedAt,
+		&report.PaidAt,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+		&report.CreatedAtUnix,
+		&report.UpdatedAtUnix,
+	)
+}
+
+// Create creates a new draft expense report
+func (r *expenseReportRepository) Create(report *domain.ExpenseReport) error {
+	query := `
+		INSERT INTO expense_reports (workspace_id, user_id, title, description, status, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		report.WorkspaceID,
+		report.UserID,
+		report.Title,
+		sql.NullString{String: report.Description, Valid: report.Description != ""},
+		report.Status,
+		now,
+		now,
+	).Scan(&report.ID, &report.UUID, &report.CreatedAt, &report.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create expense report: %w", err)
+	}
+
+	report.CreatedAtUnix = now
+	report.UpdatedAtUnix = now
+	report.Approvers = []domain.ExpenseApprover{}
+
+	return nil
+}
+
+// FindByID finds expense report by ID with its approvers
+func (r *expenseReportRepository) FindByID(id int) (*domain.ExpenseReport, error) {
+	report := &domain.ExpenseReport{}
+	err := scanExpenseReport(r.db.QueryRow(`SELECT `+expenseReportColumns+` FROM expense_reports e WHERE e.id = $1`, id), report)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("expense report not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expense report: %w", err)
+	}
+
+	if report.Approvers, err = r.findApprovers(report.ID); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// FindByWorkspaceID finds the expense reports of a workspace that the user submitted or is
+// assigned to approve, or every report when userID is 0, newest first. An empty status matches
+// every status.
+func (r *expenseReportRepository) FindByWorkspaceID(workspaceID int, userID int, status domain.ExpenseReportStatus) ([]domain.ExpenseReport, error) {
+	query := `
+		SELECT ` + expenseReportColumns + `
+		FROM expense_reports e
+		WHERE e.workspace_id = $1
+		  AND ($2 = 0 OR e.user_id = $2 OR EXISTS (
+		      SELECT 1 FROM expense_report_approvers a WHERE a.report_id = e.id AND a.user_id = $2
+		  ))
+		  AND ($3 = '' OR e.status = $3)
+		ORDER BY e.created_at DESC, e.id DESC
+	`
+
+	rows, err := r.db.Query(query, workspaceID, userID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expense reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []domain.ExpenseReport{}
+	for rows.Next() {
+		var report domain.ExpenseReport
+		if err := scanExpenseReport(rows, &report); err != nil {
+			return nil, fmt.Errorf("failed to scan expense report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate expense reports: %w", err)
+	}
+
+	for i := range reports {
+		if reports[i].Approvers, err = r.findApprovers(reports[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return reports, nil
+}
+
+// FindByReceiptID finds the expense report a receipt is on, or nil if it is on none
+func (r *expenseReportRepository) FindByReceiptID(receiptID int) (*domain.ExpenseReport, error) {
+	query := `
+		SELECT ` + expenseReportColumns + `
+		FROM expense_reports e
+		JOIN expense_report_receipts er ON er.report_id = e.id
+		WHERE er.receipt_id = $1
+	`
+
+	report := &domain.ExpenseReport{}
+	err := scanExpenseReport(r.db.QueryRow(query, receiptID), report)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expense report: %w", err)
+	}
+
+	return report, nil
+}
+
+// findApprovers finds the approvers of a report with their names
+func (r *expenseReportRepository) findApprovers(reportID int) ([]domain.ExpenseApprover, error) {
+	query := `
+		SELECT a.user_id, u.full_name, a.decision, a.decided_at
+		FROM expense_report_approvers a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.report_id = $1
+		ORDER BY u.full_name ASC, a.user_id ASC
+	`
+
+	rows, err := r.db.Query(query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expense report approvers: %w", err)
+	}
+	defer rows.Close()
+
+	approvers := []domain.ExpenseApprover{}
+	for rows.Next() {
+		var approver domain.ExpenseApprover
+		if err := rows.Scan(&approver.UserID, &approver.FullName, &approver.Decision, &approver.DecidedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expense report approver: %w", err)
+		}
+		approvers = append(approvers, approver)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate expense report approvers: %w", err)
+	}
+
+	return approvers, nil
+}
+
+// Update updates expense report details, status and workflow timestamps
+func (r *expenseReportRepository) Update(report *domain.ExpenseReport) error {
+	query := `
+		UPDATE expense_reports
+		SET title = $1, description = $2, status = $3, submitted_at = $4, decided_at = $5, paid_at = $6,
+		    updated_at = NOW(), updated_at_unix = $7
+		WHERE id = $8
+		RETURNING updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		report.Title,
+		sql.NullString{String: report.Description, Valid: report.Description != ""},
+		report.Status,
+		report.SubmittedAt,
+		report.DecidedAt,
+		report.PaidAt,
+		now,
+		report.ID,
+	).Scan(&report.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("expense report not found")
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update expense report: %w", err)
+	}
+
+	report.UpdatedAtUnix = now
+	return nil
+}
+
+// Delete deletes expense report, releasing its receipts
+func (r *expenseReportRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM expense_reports WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete expense report: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("expense report not found")
+	}
+
+	return nil
+}
+
+// FindReceipts finds the receipts of a report ordered by purchase date
+func (r *expenseReportRepository) FindReceipts(reportID int) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + prefixedReceiptColumns("r") + `
+		FROM receipts r
+		JOIN expense_report_receipts er ON er.receipt_id = r.id
+		WHERE er.report_id = $1
+		ORDER BY COALESCE(r.date, r.upload_date) ASC, r.id ASC
+	`
+
+	rows, err := r.db.Query(query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expense report receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []domain.Receipt{}
+	for rows.Next() {
+		var receipt domain.Receipt
+		if err := scanReceipt(rows, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
+// AddReceipts adds receipts to a report, skipping those already on it
+func (r *expenseReportRepository) AddReceipts(reportID int, receiptIDs []int) error {
+	query := `
+		INSERT INTO expense_report_receipts (report_id, receipt_id)
+		SELECT $1, UNNEST($2::INTEGER[])
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.Exec(query, reportID, pq.Array(receiptIDs)); err != nil {
+		return fmt.Errorf("failed to add receipts to expense report: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveReceipt removes a receipt from a report
+func (r *expenseReportRepository) RemoveReceipt(reportID int, receiptID int) error {
+	result, err := r.db.Exec(`DELETE FROM expense_report_receipts WHERE report_id = $1 AND receipt_id = $2`, reportID, receiptID)
+	if err != nil {
+		return fmt.Errorf("failed to remove receipt from expense report: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("receipt not found on expense report")
+	}
+
+	return nil
+}
+
+// SetApprovers replaces the approvers of a report in a single transaction
+func (r *expenseReportRepository) SetApprovers(reportID int, userIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM expense_report_approvers WHERE report_id = $1`, reportID); err != nil {
+		return fmt.Errorf("failed to delete expense report approvers: %w", err)
+	}
+
+	query := `
+		INSERT INTO expense_report_approvers (report_id, user_id)
+		SELECT $1, UNNEST($2::INTEGER[])
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(query, reportID, pq.Array(userIDs)); err != nil {
+		return fmt.Errorf("failed to add expense report approvers: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDecision records an approver's decision on a report
+func (r *expenseReportRepository) UpdateDecision(reportID int, userID int, decision domain.ApprovalDecision) error {
+	query := `
+		UPDATE expense_report_approvers
+		SET decision = $1, decided_at = NOW()
+		WHERE report_id = $2 AND user_id = $3
+	`
+
+	if _, err := r.db.Exec(query, decision, reportID, userID); err != nil {
+		return fmt.Errorf("failed to update approval decision: %w", err)
+	}
+
+	return nil
+}
+
+// ResetDecisions sets every approver of a report back to pending for a new submission
+func (r *expenseReportRepository) ResetDecisions(reportID int) error {
+	query := `
+		UPDATE expense_report_approvers
+		SET decision = 'pending', decided_at = NULL
+		WHERE report_id = $1
+	`
+
+	if _, err := r.db.Exec(query, reportID); err != nil {
+		return fmt.Errorf("failed to reset approval decisions: %w", err)
+	}
+
+	return nil
+}
+
+// CreateComment creates a new comment on a report
+func (r *expenseReportRepository) CreateComment(comment *domain.ExpenseComment) error {
+	query := `
+		INSERT INTO expense_report_comments (report_id, user_id, body, created_at_unix)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, uuid, created_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(query, comment.ReportID, comment.UserID, comment.Body, now).
+		Scan(&comment.ID, &comment.UUID, &comment.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	comment.CreatedAtUnix = now
+	return nil
+}
+
+// FindComments finds the comments of a report with their authors, oldest first
+func (r *expenseReportRepository) FindComments(reportID int) ([]domain.ExpenseComment, error) {
+	query := `
+		SELECT c.id, c.uuid, c.report_id, c.user_id, u.full_name, c.body, c.created_at, c.created_at_unix
+		FROM expense_report_comments c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.report_id = $1
+		ORDER BY c.created_at ASC, c.id ASC
+	`
+
+	rows, err := r.db.Query(query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []domain.ExpenseComment{}
+	for rows.Next() {
+		var comment domain.ExpenseComment
+		if err := rows.Scan(
+			&comment.ID,
+			&comment.UUID,
+			&comment.ReportID,
+			&comment.UserID,
+			&comment.FullName,
+			&comment.Body,
+			&comment.CreatedAt,
+			&comment.CreatedAtUnix,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+
+	return comments, nil
+}
@@ -17,6 +17,13 @@ type ItemRepository interface {
 	Delete(id int) error
 }
 
+// insertItemQuery inserts one item and returns its generated fields
+const insertItemQuery = `
+	INSERT INTO items (receipt_id, product_id, name, unit_price, quantity, price, total, category, notes, created_at_unix)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING id, uuid, created_at
+`
+
 type itemRepository struct {
 	db *sql.DB
 }
@@ -28,21 +35,22 @@ func NewItemRepository(db *sql.DB) ItemRepository {
 
 // Create creates a new item
 func (r *itemRepository) Create(item *domain.Item) error {
-	query := `
-		INSERT INTO items (receipt_id, name, unit_price, quantity, price, total, created_at_unix)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, uuid, created_at
-	`
+	return insertItem(r.db, item, time.Now().Unix())
+}
 
-	now := time.Now().Unix()
-	err := r.db.QueryRow(
-		query,
+// insertItem inserts an item using the given connection or transaction
+func insertItem(q queryRower, item *domain.Item, now int64) error {
+	err := q.QueryRow(
+		insertItemQuery,
 		item.ReceiptID,
+		item.ProductID,
 		item.Name,
 		item.UnitPrice,
 		item.Quantity,
 		item.Price,
 		item.Total,
+		item.Category,
+		item.Notes,
 		now,
 	).Scan(&item.ID, &item.UUID, &item.CreatedAt)
 
@@ -62,11 +70,7 @@ func (r *itemRepository) CreateBatch(items []domain.Item) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO items (receipt_id, name, unit_price, quantity, price, total, created_at_unix)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, uuid, created_at
-	`)
+	stmt, err := tx.Prepare(insertItemQuery)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -77,11 +81,14 @@ func (r *itemRepository) CreateBatch(items []domain.Item) error {
 	for i := range items {
 		err := stmt.QueryRow(
 			items[i].ReceiptID,
+			items[i].ProductID,
 			items[i].Name,
 			items[i].UnitPrice,
 			items[i].Quantity,
 			items[i].Price,
 			items[i].Total,
+			items[i].Category,
+			items[i].Notes,
 			now,
 		).Scan(&items[i].ID, &items[i].UUID, &items[i].CreatedAt)
 
@@ -102,10 +109,12 @@ func (r *itemRepository) CreateBatch(items []domain.Item) error {
 // FindByReceiptID finds all items for a receipt
 func (r *itemRepository) FindByReceiptID(receiptID int) ([]domain.Item, error) {
 	query := `
-		SELECT id, uuid, receipt_id, name, unit_price, quantity, price, total, created_at, created_at_unix
-		FROM items
-		WHERE receipt_id = $1
-		ORDER BY id ASC
+		SELECT i.id, i.uuid, i.receipt_id, i.product_id, i.name, i.unit_price, i.quantity, i.price, i.total,
+		       i.category, i.notes, i.created_at, i.created_at_unix, r.currency
+		FROM items i
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE i.receipt_id = $1
+		ORDER BY i.id ASC
 	`
 
 	rows, err := r.db.Query(query, receiptID)
@@ -119,24 +128,30 @@ func (r *itemRepository) FindByReceiptID(receiptID int) ([]domain.Item, error) {
 	var items []domain.Item
 	for rows.Next() {
 		var item domain.Item
+		var currency string
 
 		err := rows.Scan(
 			&item.ID,
 			&item.UUID,
 			&item.ReceiptID,
+			&item.ProductID,
 			&item.Name,
 			&item.UnitPrice,
 			&item.Quantity,
 			&item.Price,
 			&item.Total,
+			&item.Category,
+			&item.Notes,
 			&item.CreatedAt,
 			&item.CreatedAtUnix,
+			&currency,
 		)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan item:  %w", err)
 		}
 
+		setItemCurrency(&item, currency)
 		items = append(items, item)
 	}
 
@@ -146,23 +161,30 @@ func (r *itemRepository) FindByReceiptID(receiptID int) ([]domain.Item, error) {
 // FindByID finds item by ID
 func (r *itemRepository) FindByID(id int) (*domain.Item, error) {
 	query := `
-		SELECT id, uuid, receipt_id, name, unit_price, quantity, price, total, created_at, created_at_unix
-		FROM items
-		WHERE id = $1
+		SELECT i.id, i.uuid, i.receipt_id, i.product_id, i.name, i.unit_price, i.quantity, i.price, i.total,
+		       i.category, i.notes, i.created_at, i.created_at_unix, r.currency
+		FROM items i
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE i.id = $1
 	`
 
 	item := &domain.Item{}
+	var currency string
 	err := r.db.QueryRow(query, id).Scan(
 		&item.ID,
 		&item.UUID,
 		&item.ReceiptID,
+		&item.ProductID,
 		&item.Name,
 		&item.UnitPrice,
 		&item.Quantity,
 		&item.Price,
 		&item.Total,
+		&item.Category,
+		&item.Notes,
 		&item.CreatedAt,
 		&item.CreatedAtUnix,
+		&currency,
 	)
 
 	if err == sql.ErrNoRows {
@@ -173,24 +195,40 @@ func (r *itemRepository) FindByID(id int) (*domain.Item, error) {
 		return nil, fmt.Errorf("failed to find item: %w", err)
 	}
 
+	setItemCurrency(item, currency)
 	return item, nil
 }
 
+// setItemCurrency tags the scanned amounts of an item with the currency of its receipt
+func setItemCurrency(item *domain.Item, currency string) {
+	item.UnitPrice.Currency = currency
+	item.Price.Currency = currency
+	item.Total.Currency = currency
+}
+
 // Update updates an item
 func (r *itemRepository) Update(item *domain.Item) error {
+	return updateItem(r.db, item)
+}
+
+// updateItem writes the editable fields of an item using the given connection or transaction
+func updateItem(e execer, item *domain.Item) error {
 	query := `
 		UPDATE items
-		SET name = $1, unit_price = $2, quantity = $3, price = $4, total = $5
-		WHERE id = $6
+		SET name = $1, unit_price = $2, quantity = $3, price = $4, total = $5, category = $6, product_id = $7, notes = $8
+		WHERE id = $9
 	`
 
-	result, err := r.db.Exec(
+	result, err := e.Exec(
 		query,
 		item.Name,
 		item.UnitPrice,
 		item.Quantity,
 		item.Price,
 		item.Total,
+		item.Category,
+		item.ProductID,
+		item.Notes,
 		item.ID,
 	)
 
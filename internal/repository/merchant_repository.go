@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type MerchantRepository interface {
+	Create(merchant *domain.Merchant) error
+	FindByID(id int) (*domain.Merchant, error)
+	FindByWorkspaceID(workspaceID int) ([]domain.Merchant, error)
+	FindAll() ([]domain.Merchant, error)
+	FindByAlias(workspaceID int, alias string) (*domain.Merchant, error)
+	FindByPhone(workspaceID int, phone int64) (*domain.Merchant, error)
+	FindUnassignedReceipts(workspaceID int) ([]domain.Receipt, error)
+	AddAlias(merchantID, workspaceID int, alias string) error
+	AssignReceipt(receiptID int, merchantID int) error
+	Update(merchant *domain.Merchant) error
+	Merge(targetID int, sourceIDs []int) error
+}
+
+// merchantColumns lists the columns selected for a merchant with its aliases and receipt count, in scanMerchant order
+const merchantColumns = `
+	m.id, m.uuid, m.user_id, m.workspace_id, m.name, m.address, m.phone, m.created_at, m.updated_at, m.created_at_unix, m.updated_at_unix,
+	ARRAY(SELECT a.alias FROM merchant_aliases a WHERE a.merchant_id = m.id ORDER BY a.alias),
+	(SELECT COUNT(*) FROM receipts r WHERE r.merchant_id = m.id)
+`
+
+type merchantRepository struct {
+	db *sql.DB
+}
+
+// NewMerchantRepository creates a new merchant repository
+func NewMerchantRepository(db *sql.DB) MerchantRepository {
+	return &merchantRepository{db: db}
+}
+
+// scanMerchant scans a row selected with merchantColumns into merchant
+func scanMerchant(row rowScanner, merchant *domain.Merchant) error {
+	var aliases pq.StringArray
+	err := row.Scan(
+		&merchant.ID,
+		&merchant.UUID,
+		&merchant.UserID,
+		&merchant.WorkspaceID,
+		&merchant.Name,
+		&merchant.Address,
+		&merchant.Phone,
+		&merchant.CreatedAt,
+		&merchant.UpdatedAt,
+		&merchant.CreatedAtUnix,
+		&merchant.UpdatedAtUnix,
+		&aliases,
+		&merchant.ReceiptCount,
+	)
+	merchant.Aliases = []string(aliases)
+	return err
+}
+
+// queryMerchants runs a merchant query and scans every row
+func (r *merchantRepository) queryMerchants(query string, args ...interface{}) ([]domain.Merchant, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchants: %w", err)
+	}
+	defer rows.Close()
+
+	merchants := []domain.Merchant{}
+	for rows.Next() {
+		var merchant domain.Merchant
+		if err := scanMerchant(rows, &merchant); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant: %w", err)
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate merchants: %w", err)
+	}
+
+	return merchants, nil
+}
+
+// findOne runs a merchant query expected to return at most one row, returning nil if there is none
+func (r *merchantRepository) findOne(query string, args ...interface{}) (*domain.Merchant, error) {
+	merchant := &domain.Merchant{}
+	err := scanMerchant(r.db.QueryRow(query, args...), merchant)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merchant: %w", err)
+	}
+
+	return merchant, nil
+}
+
+// Create creates a new merchant
+func (r *merchantRepository) Create(merchant *domain.Merchant) error {
+	query := `
+		INSERT INTO merchants (user_id, workspace_id, name, address, phone, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		merchant.UserID,
+		merchant.WorkspaceID,
+		merchant.Name,
+		merchant.Address,
+		merchant.Phone,
+		now,
+		now,
+	).Scan(&merchant.ID, &merchant.UUID, &merchant.CreatedAt, &merchant.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create merchant: %w", err)
+	}
+
+	merchant.CreatedAtUnix = now
+	merchant.UpdatedAtUnix = now
+
+	return nil
+}
+
+// FindByID finds merchant by ID
+func (r *merchantRepository) FindByID(id int) (*domain.Merchant, error) {
+	merchant, err := r.findOne(`SELECT `+merchantColumns+` FROM merchants m WHERE m.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if merchant == nil {
+		return nil, fmt.Errorf("merchant not found")
+	}
+	return merchant, nil
+}
+
+// FindByWorkspaceID finds all merchants of a workspace by name
+func (r *merchantRepository) FindByWorkspaceID(workspaceID int) ([]domain.Merchant, error) {
+	return r.queryMerchants(`SELECT `+merchantColumns+` FROM merchants m WHERE m.workspace_id = $1 ORDER BY m.name ASC, m.id ASC`, workspaceID)
+}
+
+// FindAll finds the merchants of every workspace
+func (r *merchantRepository) FindAll() ([]domain.Merchant, error) {
+	return r.queryMerchants(`SELECT ` + merchantColumns + ` FROM merchants m ORDER BY m.workspace_id ASC, m.name ASC, m.id ASC`)
+}
+
+// FindByAlias finds the workspace's merchant with the alias, or nil if there is none
+func (r *merchantRepository) FindByAlias(workspaceID int, alias string) (*domain.Merchant, error) {
+	query := `
+		SELECT ` + merchantColumns + `
+		FROM merchants m
+		JOIN merchant_aliases ma ON ma.merchant_id = m.id
+		WHERE ma.workspace_id = $1 AND ma.alias = $2
+	`
+	return r.findOne(query, workspaceID, alias)
+}
+
+// FindByPhone finds the workspace's oldest merchant with the phone number, or nil if there is none
+func (r *merchantRepository) FindByPhone(workspaceID int, phone int64) (*domain.Merchant, error) {
+	query := `
+		SELECT ` + merchantColumns + `
+		FROM merchants m
+		WHERE m.workspace_id = $1 AND m.phone = $2
+		ORDER BY m.id ASC
+		LIMIT 1
+	`
+	return r.findOne(query, workspaceID, phone)
+}
+
+// FindUnassignedReceipts finds the workspace's receipts with a store name but no merchant
+func (r *merchantRepository) FindUnassignedReceipts(workspaceID int) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + receiptColumns + `
+		FROM receipts
+		WHERE workspace_id = $1 AND merchant_id IS NULL AND COALESCE(store_name, '') <> ''
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var receipts []domain.Receipt
+	for rows.Next() {
+		var receipt domain.Receipt
+		if err := scanReceipt(rows, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
+// AddAlias adds an alias to a merchant; an alias the workspace already has is left where it is
+func (r *merchantRepository) AddAlias(merchantID, workspaceID int, alias string) error {
+	query := `
+		INSERT INTO merchant_aliases (merchant_id, workspace_id, alias)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, alias) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(query, merchantID, workspaceID, alias); err != nil {
+		return fmt.Errorf("failed to add merchant alias: %w", err)
+	}
+
+	return nil
+}
+
+// AssignReceipt sets the merchant of a receipt
+func (r *merchantRepository) AssignReceipt(receiptID int, merchantID int) error {
+	query := `UPDATE receipts SET merchant_id = $1 WHERE id = $2`
+
+	if _, err := r.db.Exec(query, merchantID, receiptID); err != nil {
+		return fmt.Errorf("failed to assign merchant: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates merchant
+func (r *merchantRepository) Update(merchant *domain.Merchant) error {
+	query := `
+		UPDATE merchants
+		SET name = $1, address = $2, phone = $3, updated_at = NOW(), updated_at_unix = $4
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(query, merchant.Name, merchant.Address, merchant.Phone, now, merchant.ID).Scan(&merchant.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	merchant.UpdatedAtUnix = now
+	return nil
+}
+
+// Merge moves the receipts and aliases of the source merchants to the target, fills in a
+// missing target address or phone from the sources, and deletes the sources, in a single transaction
+func (r *merchantRepository) Merge(targetID int, sourceIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sources := pq.Array(sourceIDs)
+
+	if _, err := tx.Exec(`UPDATE receipts SET merchant_id = $1 WHERE merchant_id = ANY($2)`, targetID, sources); err != nil {
+		return fmt.Errorf("failed to move receipts: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE merchant_aliases SET merchant_id = $1 WHERE merchant_id = ANY($2)`, targetID, sources); err != nil {
+		return fmt.Errorf("failed to move merchant aliases: %w", err)
+	}
+
+	query := `
+		UPDATE merchants t
+		SET address = COALESCE(t.address, (SELECT s.address FROM merchants s WHERE s.id = ANY($2) AND s.address IS NOT NULL ORDER BY s.id LIMIT 1)),
+		    phone = COALESCE(t.phone, (SELECT s.phone FROM merchants s WHERE s.id = ANY($2) AND s.phone IS NOT NULL ORDER BY s.id LIMIT 1)),
+		    updated_at = NOW(), updated_at_unix = $3
+		WHERE t.id = $1
+	`
+	if _, err := tx.Exec(query, targetID, sources, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM merchants WHERE id = ANY($1)`, sources); err != nil {
+		return fmt.Errorf("failed to delete merchants: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type NotificationRepository interface {
+	Create(notification *domain.Notification) error
+	FindByUserID(userID int, unreadOnly bool, page, limit int) ([]domain.Notification, int64, error)
+	MarkRead(id int, userID int) error
+	MarkAllRead(userID int) (int64, error)
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create creates a new notification
+func (r *notificationRepository) Create(notification *domain.Notification) error {
+	return insertNotification(r.db, notification, time.Now().Unix())
+}
+
+// insertNotification inserts a notification using the given connection or transaction
+func insertNotification(q queryRower, notification *domain.Notification, now int64) error {
+	query := `
+		INSERT INTO notifications (user_id, kind, title, message, receipt_id, item_id, created_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, uuid, created_at
+	`
+
+	err := q.QueryRow(
+		query,
+		notification.UserID,
+		notification.Kind,
+		notification.Title,
+		notification.Message,
+		notification.ReceiptID,
+		notification.ItemID,
+		now,
+	).Scan(&notification.ID, &notification.UUID, &notification.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	notification.CreatedAtUnix = now
+
+	return nil
+}
+
+// FindByUserID finds a page of a user's notifications, newest first
+func (r *notificationRepository) FindByUserID(userID int, unreadOnly bool, page, limit int) ([]domain.Notification, int64, error) {
+	where := `user_id = $1`
+	if unreadOnly {
+		where += ` AND read_at IS NULL`
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE `+where, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	query := `
+		SELECT id, uuid, user_id, kind, title, message, receipt_id, item_id, read_at, created_at, created_at_unix
+		FROM notifications
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		var notification domain.Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UUID,
+			&notification.UserID,
+			&notification.Kind,
+			&notification.Title,
+			&notification.Message,
+			&notification.ReceiptID,
+			&notification.ItemID,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+			&notification.CreatedAtUnix,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (r *notificationRepository) MarkRead(id int, userID int) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("notification not found")
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all of the user's unread notifications as read and returns how many
+func (r *notificationRepository) MarkAllRead(userID int) (int64, error) {
+	result, err := r.db.Exec(`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update notifications: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
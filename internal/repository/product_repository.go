@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type ProductRepository interface {
+	Create(product *domain.Product) error
+	FindByID(id int) (*domain.Product, error)
+	FindByWorkspaceID(workspaceID int) ([]domain.Product, error)
+	FindByAlias(workspaceID int, alias string) (*domain.Product, error)
+	FindByBarcode(workspaceID int, barcode string) (*domain.Product, error)
+	FindUnassignedItems(workspaceID int) ([]domain.Item, error)
+	FindPriceHistory(productID int) ([]domain.ProductPrice, error)
+	GetTotals(workspaceID int, filter domain.ReceiptFilter) ([]domain.ProductTotal, error)
+	AddAlias(productID, workspaceID int, alias string) error
+	AssignItem(itemID int, productID int) error
+	Update(product *domain.Product) error
+	Merge(targetID int, sourceIDs []int) error
+}
+
+// productColumns lists the columns selected for a product with its aliases and purchase count, in scanProduct order
+const productColumns = `
+	p.id, p.uuid, p.user_id, p.workspace_id, p.name, p.unit, p.barcode, p.sku, p.created_at, p.updated_at, p.created_at_unix, p.updated_at_unix,
+	ARRAY(SELECT a.alias FROM product_aliases a WHERE a.product_id = p.id ORDER BY a.alias),
+	(SELECT COUNT(*) FROM items i WHERE i.product_id = p.id)
+`
+
+type productRepository struct {
+	db *sql.DB
+}
+
+// NewProductRepository creates a new product repository
+func NewProductRepository(db *sql.DB) ProductRepository {
+	return &productRepository{db: db}
+}
+
+// scanProduct scans a row selected with productColumns into product
+func scanProduct(row rowScanner, product *domain.Product) error {
+	var aliases pq.StringArray
+	err := row.Scan(
+		&product.ID,
+		&product.UUID,
+		&product.UserID,
+		&product.WorkspaceID,
+		&product.Name,
+		&product.Unit,
+		&product.Barcode,
+		&product.SKU,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.CreatedAtUnix,
+		&product.UpdatedAtUnix,
+		&aliases,
+		&product.PurchaseCount,
+	)
+	product.Aliases = []string(aliases)
+	return err
+}
+
+// findOne runs a product query expected to return at most one row, returning nil if there is none
+func (r *productRepository) findOne(query string, args ...interface{}) (*domain.Product, error) {
+	product := &domain.Product{}
+	err := scanProduct(r.db.QueryRow(query, args...), product)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+
+	return product, nil
+}
+
+// Create creates a new product
+func (r *productRepository) Create(product *domain.Product) error {
+	query := `
+		INSERT INTO products (user_id, workspace_id, name, unit, barcode, sku, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		product.UserID,
+		product.WorkspaceID,
+		product.Name,
+		product.Unit,
+		product.Barcode,
+		product.SKU,
+		now,
+		now,
+	).Scan(&product.ID, &product.UUID, &product.CreatedAt, &product.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create product: %w", err)
+	}
+
+	product.CreatedAtUnix = now
+	product.UpdatedAtUnix = now
+
+	return nil
+}
+
+// FindByID finds product by ID
+func (r *productRepository) FindByID(id int) (*domain.Product, error) {
+	product, err := r.findOne(`SELECT `+productColumns+` FROM products p WHERE p.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	return product, nil
+}
+
+// FindByWorkspaceID finds all products of a workspace by name
+func (r *productRepository) FindByWorkspaceID(workspaceID int) ([]domain.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products p WHERE p.workspace_id = $1 ORDER BY p.name ASC, p.id ASC`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []domain.Product{}
+	for rows.Next() {
+		var product domain.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate products: %w", err)
+	}
+
+	return products, nil
+}
+
+// FindByAlias finds the workspace's product with the alias, or nil if there is none
+func (r *productRepository) FindByAlias(workspaceID int, alias string) (*domain.Product, error) {
+	query := `
+		SELECT ` + productColumns + `
+		FROM products p
+		JOIN product_aliases pa ON pa.product_id = p.id
+		WHERE pa.workspace_id = $1 AND pa.alias = $2
+	`
+	return r.findOne(query, workspaceID, alias)
+}
+
+// FindByBarcode finds the workspace's product with the barcode, or nil if there is none
+func (r *productRepository) FindByBarcode(workspaceID int, barcode string) (*domain.Product, error) {
+	return r.findOne(`SELECT `+productColumns+` FROM products p WHERE p.workspace_id = $1 AND p.barcode = $2`, workspaceID, barcode)
+}
+
+// FindUnassignedItems finds the items of the workspace's receipts that have no product
+func (r *productRepository) FindUnassignedItems(workspaceID int) ([]domain.Item, error) {
+	query := `
+		SELECT i.id, i.uuid, i.receipt_id, i.product_id, i.name, i.unit_price, i.quantity, i.price, i.total,
+		       i.category, i.notes, i.created_at, i.created_at_unix, r.currency
+		FROM items i
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE r.workspace_id = $1 AND i.product_id IS NULL AND COALESCE(i.name, '') <> ''
+		ORDER BY i.id ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []domain.Item
+	for rows.Next() {
+		var item domain.Item
+		var currency string
+
+		err := rows.Scan(
+			&item.ID,
+			&item.UUID,
+			&item.ReceiptID,
+			&item.ProductID,
+			&item.Name,
+			&item.UnitPrice,
+			&item.Quantity,
+			&item.Price,
+			&item.Total,
+			&item.Category,
+			&item.Notes,
+			&item.CreatedAt,
+			&item.CreatedAtUnix,
+			&currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+
+		setItemCurrency(&item, currency)
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate items: %w", err)
+	}
+
+	return items, nil
+}
+
+// FindPriceHistory finds every purchase of a product, oldest first, skipping duplicate receipts
+func (r *productRepository) FindPriceHistory(productID int) ([]domain.ProductPrice, error) {
+	query := `
+		SELECT r.id, i.id, COALESCE(r.date, r.upload_date::date), r.store_name, i.name, i.quantity, i.unit_price, r.currency
+		FROM items i
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE i.product_id = $1 AND r.duplicate_of IS NULL
+		ORDER BY COALESCE(r.date, r.upload_date::date) ASC, i.id ASC
+	`
+
+	rows, err := r.db.Query(query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price history: %w", err)
+	}
+	defer rows.Close()
+
+	prices := []domain.ProductPrice{}
+	for rows.Next() {
+		var price domain.ProductPrice
+		err := rows.Scan(
+			&price.ReceiptID,
+			&price.ItemID,
+			&price.Date,
+			&price.StoreName,
+			&price.Name,
+			&price.Quantity,
+			&price.UnitPrice,
+			&price.UnitPrice.Currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %w", err)
+		}
+		prices = append(prices, price)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate price history: %w", err)
+	}
+
+	return prices, nil
+}
+
+// GetTotals sums the items of the workspace's receipts per product, currency and purchase day,
+// using the same receipt selection as ReceiptRepository.GetTotalsByCurrency. Items without a
+// product are left out.
+func (r *productRepository) GetTotals(workspaceID int, filter domain.ReceiptFilter) ([]domain.ProductTotal, error) {
+	if filter.Status == "" {
+		filter.Status = domain.StatusCompleted
+	}
+	where, args := receiptFilterClause(workspaceID, filter)
+
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			p.unit,
+			r.currency,
+			COALESCE(r.date, r.upload_date::date) AS day,
+			SUM(i.quantity)::INTEGER,
+			COUNT(*),
+			SUM(i.total)::BIGINT
+		FROM receipts r
+		JOIN items i ON i.receipt_id = r.id
+		JOIN products p ON p.id = i.product_id
+		WHERE ` + where + ` AND r.duplicate_of IS NULL
+		GROUP BY p.id, p.name, p.unit, r.currency, day
+		ORDER BY day ASC
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product stats: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.ProductTotal
+	for rows.Next() {
+		var total domain.ProductTotal
+		err := rows.Scan(
+			&total.ProductID,
+			&total.Name,
+			&total.Unit,
+			&total.Currency,
+			&total.Day,
+			&total.Quantity,
+			&total.Purchases,
+			&total.Total,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product stats: %w", err)
+		}
+		total.Total.Currency = total.Currency
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate product stats: %w", err)
+	}
+
+	return totals, nil
+}
+
+// AddAlias adds an alias to a product; an alias the workspace already has is left where it is
+func (r *productRepository) AddAlias(productID, workspaceID int, alias string) error {
+	query := `
+		INSERT INTO product_aliases (product_id, workspace_id, alias)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, alias) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(query, productID, workspaceID, alias); err != nil {
+		return fmt.Errorf("failed to add product alias: %w", err)
+	}
+
+	return nil
+}
+
+// AssignItem sets the product of an item
+func (r *productRepository) AssignItem(itemID int, productID int) error {
+	query := `UPDATE items SET product_id = $1 WHERE id = $2`
+
+	if _, err := r.db.Exec(query, productID, itemID); err != nil {
+		return fmt.Errorf("failed to assign product: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates product
+func (r *productRepository) Update(product *domain.Product) error {
+	query := `
+		UPDATE products
+		SET name = $1, unit = $2, barcode = $3, sku = $4, updated_at = NOW(), updated_at_unix = $5
+		WHERE id = $6
+		RETURNING updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		product.Name,
+		product.Unit,
+		product.Barcode,
+		product.SKU,
+		now,
+		product.ID,
+	).Scan(&product.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	product.UpdatedAtUnix = now
+	return nil
+}
+
+// Merge moves the items and aliases of the source products to the target, fills in a missing
+// target unit, barcode or SKU from the sources, and deletes the sources, in a single transaction
+func (r *productRepository) Merge(targetID int, sourceIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sources := pq.Array(sourceIDs)
+
+	if _, err := tx.Exec(`UPDATE items SET product_id = $1 WHERE product_id = ANY($2)`, targetID, sources); err != nil {
+		return fmt.Errorf("failed to move items: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE product_aliases SET product_id = $1 WHERE product_id = ANY($2)`, targetID, sources); err != nil {
+		return fmt.Errorf("failed to move product aliases: %w", err)
+	}
+
+	// The source details are read before the sources are deleted, since a barcode is unique per user
+	var unit, barcode, sku sql.NullString
+	query := `
+		SELECT
+			(SELECT s.unit FROM products s WHERE s.id = ANY($1) AND s.unit IS NOT NULL ORDER BY s.id LIMIT 1),
+			(SELECT s.barcode FROM products s WHERE s.id = ANY($1) AND s.barcode IS NOT NULL ORDER BY s.id LIMIT 1),
+			(SELECT s.sku FROM products s WHERE s.id = ANY($1) AND s.sku IS NOT NULL ORDER BY s.id LIMIT 1)
+	`
+	if err := tx.QueryRow(query, sources).Scan(&unit, &barcode, &sku); err != nil {
+		return fmt.Errorf("failed to read merged products: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM products WHERE id = ANY($1)`, sources); err != nil {
+		return fmt.Errorf("failed to delete products: %w", err)
+	}
+
+	query = `
+		UPDATE products
+		SET unit = COALESCE(unit, $2), barcode = COALESCE(barcode, $3), sku = COALESCE(sku, $4),
+		    updated_at = NOW(), updated_at_unix = $5
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(query, targetID, unit, barcode, sku, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// receiptSortColumns maps sort fields to columns of the receipts table aliased as r
+var receiptSortColumns = map[domain.ReceiptSortField]string{
+	domain.SortByUploadDate: "r.upload_date",
+	domain.SortByDate:       "r.date",
+	domain.SortByTotal:      "r.total_spending / power(10::numeric, currency_exponent(r.currency))",
+	domain.SortByStore:      "LOWER(r.store_name)",
+}
+
+// receiptFilterClause builds the WHERE conditions of a receipt filter for the receipts table
+// aliased as r, scoped to the workspace. Placeholders are numbered from 1.
+func receiptFilterClause(workspaceID int, filter domain.ReceiptFilter) (string, []interface{}) {
+	conditions := []string{"r.workspace_id = $1"}
+	args := []interface{}{workspaceID}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.DateFrom != nil {
+		add("r.date >= $%d", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		add("r.date <= $%d", *filter.DateTo)
+	}
+	// Amounts are decimals in the receipt currency, scaled here to its minor units
+	if filter.MinAmount != nil {
+		add("r.total_spending >= $%d::numeric * power(10::numeric, currency_exponent(r.currency))", string(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		add("r.total_spending <= $%d::numeric * power(10::numeric, currency_exponent(r.currency))", string(*filter.MaxAmount))
+	}
+	if filter.StoreName != "" {
+		add(`r.store_name ILIKE '%%' || $%d || '%%' ESCAPE '\'`, escapeLike(filter.StoreName))
+	}
+	if filter.MerchantID != 0 {
+		add("r.merchant_id = $%d", filter.MerchantID)
+	}
+	if filter.Status != "" {
+		add("r.status = $%d", filter.Status)
+	}
+	if filter.Category != "" {
+		add("EXISTS (SELECT 1 FROM items fi WHERE fi.receipt_id = r.id AND fi.category = $%d)", filter.Category)
+	}
+	// Tags are per user, so only the requesting user's tag with the name matches
+	if filter.Tag != "" {
+		args = append(args, filter.Tag, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM receipt_tags frt JOIN tags ft ON ft.id = frt.tag_id WHERE frt.receipt_id = r.id AND ft.name = $%d AND ft.user_id = $%d)",
+			len(args)-1, len(args)))
+	}
+	if filter.PaymentMethod != "" {
+		add("r.payment_method = $%d", filter.PaymentMethod)
+	}
+	if filter.CardID != 0 {
+		add("r.card_id = $%d", filter.CardID)
+	}
+	if filter.HasDiscount != nil {
+		if *filter.HasDiscount {
+			conditions = append(conditions, "COALESCE(r.total_discount, 0) > 0")
+		} else {
+			conditions = append(conditions, "COALESCE(r.total_discount, 0) = 0")
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// receiptOrderClause builds the ORDER BY expression of a receipt filter, defaulting to the
+// newest upload first. The receipt id breaks ties so the order is stable across pages.
+func receiptOrderClause(filter domain.ReceiptFilter) string {
+	column, ok := receiptSortColumns[filter.SortBy]
+	if !ok {
+		column = receiptSortColumns[domain.SortByUploadDate]
+	}
+
+	direction := "DESC"
+	if filter.SortOrder == domain.SortAsc {
+		direction = "ASC"
+	}
+
+	return fmt.Sprintf("%s %s NULLS LAST, r.id %s", column, direction, direction)
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes user input match literally inside a LIKE pattern using ESCAPE '\'
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
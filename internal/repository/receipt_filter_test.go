@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Indomaret", "Indomaret"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`C:\shop`, `C:\\shop`},
+		{`%_\`, `\%\_\\`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.input); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestReceiptFilterClauseEscapesStoreName(t *testing.T) {
+	clause, args := receiptFilterClause(1, domain.ReceiptFilter{StoreName: "50%_off"})
+
+	if !strings.Contains(clause, `ESCAPE '\'`) {
+		t.Errorf("clause %q has no ESCAPE", clause)
+	}
+	if len(args) != 2 || args[1] != `50\%\_off` {
+		t.Errorf("args = %v, want the escaped store name", args)
+	}
+}
+
+func TestReceiptFilterClauseScopesTagToUser(t *testing.T) {
+	clause, args := receiptFilterClause(1, domain.ReceiptFilter{Tag: "travel", UserID: 7})
+
+	if !strings.Contains(clause, "ft.name = $2 AND ft.user_id = $3") {
+		t.Errorf("clause %q does not scope the tag to the user", clause)
+	}
+	if len(args) != 3 || args[1] != "travel" || args[2] != 7 {
+		t.Errorf("args = %v, want the tag name and user", args)
+	}
+}
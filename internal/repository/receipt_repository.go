@@ -3,26 +3,153 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type ReceiptRepository interface {
 	Create(receipt *domain.Receipt) error
+	CreateWithItems(receipt *domain.ReceiptWithItems) error
+	ImportBatch(receipts []domain.ReceiptWithItems, dryRun bool) ([]error, error)
 	FindByID(id int) (*domain.Receipt, error)
 	FindByUUID(uuid string) (*domain.Receipt, error)
-	FindByUserID(userID int, page, limit int) ([]domain.Receipt, int64, error)
+	FindByWorkspaceID(workspaceID int, filter domain.ReceiptFilter, page, limit int) ([]domain.Receipt, int64, error)
+	FindByWorkspaceIDCursor(workspaceID int, filter domain.ReceiptFilter, cursor *domain.ReceiptCursor, limit int) ([]domain.Receipt, bool, error)
+	FindByImageHash(workspaceID int, imageHash string) ([]domain.Receipt, error)
+	FindNearDuplicates(workspaceID int, perceptualHash int64, maxDistance int) ([]domain.Receipt, error)
+	FindDuplicateCandidates(receipt *domain.Receipt, tolerance domain.Money) ([]domain.Receipt, error)
+	FindSuspectedDuplicates(workspaceID int) ([]domain.Receipt, error)
 	Update(receipt *domain.Receipt) error
+	UpdateWithItems(receipt *domain.ReceiptWithItems) error
+	UpdateDuplicate(id int, duplicateOf sql.NullInt64, status domain.DuplicateStatus) error
 	Delete(id int) error
-	GetStatsByUserID(userID int) (map[string]interface{}, error)
+	GetTotalsByCurrency(workspaceID int, filter domain.ReceiptFilter) ([]domain.CurrencyTotal, error)
+	GetTotalsByTag(workspaceID int, filter domain.ReceiptFilter) ([]domain.TagTotal, error)
+	Search(workspaceID int, userID int, query string, page, limit int) ([]domain.ReceiptSearchResult, error)
+	StreamItemRows(workspaceID int, filter domain.ReceiptFilter, fn func(row *domain.ReceiptItemRow) error) error
 }
 
 type receiptRepository struct {
 	db *sql.DB
 }
 
+// receiptColumnNames lists the columns shared by every receipt SELECT, in scanReceipt order
+var receiptColumnNames = []string{
+	"id", "uuid", "user_id", "workspace_id", "store_name", "merchant_id", "address", "phone", "date", "image_url",
+	"original_filename", "file_size", "source_type", "upload_date", "status", "total_items",
+	"total_spending", "total_discount", "currency", "payment_method", "card_brand", "card_last4",
+	"card_id", "image_hash", "perceptual_hash", "duplicate_of",
+	"duplicate_status", "notes", "created_at", "updated_at", "created_at_unix", "updated_at_unix",
+}
+
+// receiptColumns is the comma separated receiptColumnNames
+var receiptColumns = strings.Join(receiptColumnNames, ", ")
+
+// prefixedReceiptColumns returns receiptColumns qualified with a table alias
+func prefixedReceiptColumns(alias string) string {
+	columns := make([]string, len(receiptColumnNames))
+	for i, name := range receiptColumnNames {
+		columns[i] = alias + "." + name
+	}
+	return strings.Join(columns, ", ")
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryRower is implemented by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// receiptScanDest returns the scan destinations for receiptColumns
+func receiptScanDest(receipt *domain.Receipt) []interface{} {
+	return []interface{}{
+		&receipt.ID,
+		&receipt.UUID,
+		&receipt.UserID,
+		&receipt.WorkspaceID,
+		&receipt.StoreName,
+		&receipt.MerchantID,
+		&receipt.Address,
+		&receipt.Phone,
+		&receipt.Date,
+		&receipt.ImageURL,
+		&receipt.OriginalFilename,
+		&receipt.FileSize,
+		&receipt.SourceType,
+		&receipt.UploadDate,
+		&receipt.Status,
+		&receipt.TotalItems,
+		&receipt.TotalSpending,
+		&receipt.TotalDiscount,
+		&receipt.Currency,
+		&receipt.PaymentMethod,
+		&receipt.CardBrand,
+		&receipt.CardLast4,
+		&receipt.CardID,
+		&receipt.ImageHash,
+		&receipt.PerceptualHash,
+		&receipt.DuplicateOf,
+		&receipt.DuplicateStatus,
+		&receipt.Notes,
+		&receipt.CreatedAt,
+		&receipt.UpdatedAt,
+		&receipt.CreatedAtUnix,
+		&receipt.UpdatedAtUnix,
+	}
+}
+
+// scanReceipt scans a row selected with receiptColumns into receipt
+func scanReceipt(row rowScanner, receipt *domain.Receipt) error {
+	if err := row.Scan(receiptScanDest(receipt)...); err != nil {
+		return err
+	}
+	setReceiptCurrency(receipt)
+	return nil
+}
+
+// setReceiptCurrency tags the scanned amounts of a receipt with its currency column
+func setReceiptCurrency(receipt *domain.Receipt) {
+	receipt.TotalSpending.Currency = receipt.Currency
+	receipt.TotalDiscount.Currency = receipt.Currency
+}
+
+// queryReceipts runs a receipt query and scans every row
+func (r *receiptRepository) queryReceipts(query string, args ...interface{}) ([]domain.Receipt, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var receipts []domain.Receipt
+	for rows.Next() {
+		var receipt domain.Receipt
+		if err := scanReceipt(rows, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
 // NewReceiptRepository creates a new receipt repository
 func NewReceiptRepository(db *sql.DB) ReceiptRepository {
 	return &receiptRepository{db: db}
@@ -30,31 +157,81 @@ func NewReceiptRepository(db *sql.DB) ReceiptRepository {
 
 // Create creates a new receipt
 func (r *receiptRepository) Create(receipt *domain.Receipt) error {
+	return insertReceipt(r.db, receipt)
+}
+
+// CreateWithItems creates a receipt with its items and adjustments in one transaction, so a
+// failure leaves no partial receipt behind
+func (r *receiptRepository) CreateWithItems(receipt *domain.ReceiptWithItems) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertReceiptWithItems(tx, receipt, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertReceipt inserts a receipt using the given connection or transaction
+func insertReceipt(q queryRower, receipt *domain.Receipt) error {
 	query := `
 		INSERT INTO receipts (
-			user_id, store_name, address, phone, date, image_url, original_filename, 
-			file_size, status, total_items, total_spending, total_discount, 
+			user_id, workspace_id, store_name, merchant_id, address, phone, date, image_url, original_filename,
+			file_size, source_type, status, total_items, total_spending, total_discount, currency,
+			payment_method, card_brand, card_last4, card_id,
+			image_hash, perceptual_hash, duplicate_of, duplicate_status, notes,
 			created_at_unix, updated_at_unix
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)
 		RETURNING id, uuid, upload_date, created_at, updated_at
 	`
 
+	if receipt.DuplicateStatus == "" {
+		receipt.DuplicateStatus = domain.DuplicateNone
+	}
+	if receipt.Currency == "" {
+		receipt.Currency = domain.DefaultCurrency
+	}
+	if receipt.SourceType == "" {
+		receipt.SourceType = domain.SourceImage
+	}
+
 	now := time.Now().Unix()
-	err := r.db.QueryRow(
+	err := q.QueryRow(
 		query,
 		receipt.UserID,
+		receipt.WorkspaceID,
 		receipt.StoreName,
+		receipt.MerchantID,
 		receipt.Address,
 		receipt.Phone,
 		receipt.Date,
 		receipt.ImageURL,
 		receipt.OriginalFilename,
 		receipt.FileSize,
+		receipt.SourceType,
 		receipt.Status,
 		receipt.TotalItems,
 		receipt.TotalSpending,
 		receipt.TotalDiscount,
+		receipt.Currency,
+		receipt.PaymentMethod,
+		receipt.CardBrand,
+		receipt.CardLast4,
+		receipt.CardID,
+		receipt.ImageHash,
+		receipt.PerceptualHash,
+		receipt.DuplicateOf,
+		receipt.DuplicateStatus,
+		receipt.Notes,
 		now,
 		now,
 	).Scan(&receipt.ID, &receipt.UUID, &receipt.UploadDate, &receipt.CreatedAt, &receipt.UpdatedAt)
@@ -69,39 +246,75 @@ func (r *receiptRepository) Create(receipt *domain.Receipt) error {
 	return nil
 }
 
+// ImportBatch inserts receipts with their items in a single transaction. Every receipt runs in
+// its own savepoint, so a failing receipt is rolled back alone and its error is returned at the
+// same index of the result. With dryRun the transaction is rolled back once all rows are checked.
+func (r *receiptRepository) ImportBatch(receipts []domain.ReceiptWithItems, dryRun bool) ([]error, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	rowErrors := make([]error, len(receipts))
+
+	for i := range receipts {
+		if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		rowErrors[i] = insertReceiptWithItems(tx, &receipts[i], now)
+
+		release := `RELEASE SAVEPOINT import_row`
+		if rowErrors[i] != nil {
+			release = `ROLLBACK TO SAVEPOINT import_row`
+		}
+		if _, err := tx.Exec(release); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if dryRun {
+		return rowErrors, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rowErrors, nil
+}
+
+// insertReceiptWithItems inserts a receipt followed by its items and adjustments
+func insertReceiptWithItems(tx *sql.Tx, receipt *domain.ReceiptWithItems, now int64) error {
+	if err := insertReceipt(tx, &receipt.Receipt); err != nil {
+		return err
+	}
+
+	for j := range receipt.Items {
+		receipt.Items[j].ReceiptID = receipt.ID
+		if err := insertItem(tx, &receipt.Items[j], now); err != nil {
+			return err
+		}
+	}
+
+	for j := range receipt.Adjustments {
+		receipt.Adjustments[j].ReceiptID = receipt.ID
+		if err := insertAdjustment(tx, &receipt.Adjustments[j], now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // FindByID finds receipt by ID
 func (r *receiptRepository) FindByID(id int) (*domain.Receipt, error) {
-	query := `
-		SELECT id, uuid, user_id, store_name, address, phone, date, image_url, 
-		       original_filename, file_size, upload_date, status, total_items, 
-		       total_spending, total_discount, created_at, updated_at, 
-		       created_at_unix, updated_at_unix
-		FROM receipts
-		WHERE id = $1
-	`
+	query := `SELECT ` + receiptColumns + ` FROM receipts WHERE id = $1`
 
 	receipt := &domain.Receipt{}
-	err := r.db.QueryRow(query, id).Scan(
-		&receipt.ID,
-		&receipt.UUID,
-		&receipt.UserID,
-		&receipt.StoreName,
-		&receipt.Address,
-		&receipt.Phone,
-		&receipt.Date,
-		&receipt.ImageURL,
-		&receipt.OriginalFilename,
-		&receipt.FileSize,
-		&receipt.UploadDate,
-		&receipt.Status,
-		&receipt.TotalItems,
-		&receipt.TotalSpending,
-		&receipt.TotalDiscount,
-		&receipt.CreatedAt,
-		&receipt.UpdatedAt,
-		&receipt.CreatedAtUnix,
-		&receipt.UpdatedAtUnix,
-	)
+	err := scanReceipt(r.db.QueryRow(query, id), receipt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("receipt not found")
@@ -116,14 +329,7 @@ func (r *receiptRepository) FindByID(id int) (*domain.Receipt, error) {
 
 // FindByUUID finds receipt by UUID
 func (r *receiptRepository) FindByUUID(uuidStr string) (*domain.Receipt, error) {
-	query := `
-		SELECT id, uuid, user_id, store_name, address, phone, date, image_url, 
-		       original_filename, file_size, upload_date, status, total_items, 
-		       total_spending, total_discount, created_at, updated_at, 
-		       created_at_unix, updated_at_unix
-		FROM receipts
-		WHERE uuid = $1
-	`
+	query := `SELECT ` + receiptColumns + ` FROM receipts WHERE uuid = $1`
 
 	uid, err := uuid.Parse(uuidStr)
 	if err != nil {
@@ -131,27 +337,7 @@ func (r *receiptRepository) FindByUUID(uuidStr string) (*domain.Receipt, error)
 	}
 
 	receipt := &domain.Receipt{}
-	err = r.db.QueryRow(query, uid).Scan(
-		&receipt.ID,
-		&receipt.UUID,
-		&receipt.UserID,
-		&receipt.StoreName,
-		&receipt.Address,
-		&receipt.Phone,
-		&receipt.Date,
-		&receipt.ImageURL,
-		&receipt.OriginalFilename,
-		&receipt.FileSize,
-		&receipt.UploadDate,
-		&receipt.Status,
-		&receipt.TotalItems,
-		&receipt.TotalSpending,
-		&receipt.TotalDiscount,
-		&receipt.CreatedAt,
-		&receipt.UpdatedAt,
-		&receipt.CreatedAtUnix,
-		&receipt.UpdatedAtUnix,
-	)
+	err = scanReceipt(r.db.QueryRow(query, uid), receipt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("receipt not found")
@@ -164,81 +350,235 @@ func (r *receiptRepository) FindByUUID(uuidStr string) (*domain.Receipt, error)
 	return receipt, nil
 }
 
-// FindByUserID finds receipts by user ID with pagination
-func (r *receiptRepository) FindByUserID(userID int, page, limit int) ([]domain.Receipt, int64, error) {
+// FindByWorkspaceID finds receipts of a workspace matching the filter, sorted and paginated
+func (r *receiptRepository) FindByWorkspaceID(workspaceID int, filter domain.ReceiptFilter, page, limit int) ([]domain.Receipt, int64, error) {
+	where, args := receiptFilterClause(workspaceID, filter)
+
 	// Count total
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM receipts WHERE user_id = $1`
-	err := r.db.QueryRow(countQuery, userID).Scan(&total)
+	countQuery := `SELECT COUNT(*) FROM receipts r WHERE ` + where
+	err := r.db.QueryRow(countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count receipts: %w", err)
 	}
 
 	// Get receipts
 	offset := (page - 1) * limit
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM receipts r
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, prefixedReceiptColumns("r"), where, receiptOrderClause(filter), len(args)+1, len(args)+2)
+
+	receipts, err := r.queryReceipts(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return receipts, total, nil
+}
+
+// FindByWorkspaceIDCursor finds a page of the workspace's receipts matching the filter using keyset
+// pagination on (upload_date, id), newest first. Without a cursor the first page is returned.
+// The boolean reports whether more receipts exist beyond the page in the cursor's direction.
+// Receipts are always returned newest first; the filter's sort order is ignored.
+func (r *receiptRepository) FindByWorkspaceIDCursor(workspaceID int, filter domain.ReceiptFilter, cursor *domain.ReceiptCursor, limit int) ([]domain.Receipt, bool, error) {
+	where, args := receiptFilterClause(workspaceID, filter)
+	order := "DESC"
+
+	if cursor != nil {
+		comparison := "<"
+		if cursor.Backward {
+			comparison = ">"
+			order = "ASC"
+		}
+		args = append(args, cursor.UploadDate, cursor.ID)
+		where += fmt.Sprintf(" AND (r.upload_date, r.id) %s ($%d, $%d)", comparison, len(args)-1, len(args))
+	}
+
+	// Fetch one extra row to know whether another page follows
+	args = append(args, limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM receipts r
+		WHERE %s
+		ORDER BY r.upload_date %s, r.id %s
+		LIMIT $%d
+	`, prefixedReceiptColumns("r"), where, order, order, len(args))
+
+	receipts, err := r.queryReceipts(query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(receipts) > limit
+	if hasMore {
+		receipts = receipts[:limit]
+	}
+
+	if order == "ASC" {
+		for i, j := 0, len(receipts)-1; i < j; i, j = i+1, j-1 {
+			receipts[i], receipts[j] = receipts[j], receipts[i]
+		}
+	}
+
+	return receipts, hasMore, nil
+}
+
+// FindByImageHash finds the workspace's receipts whose image has the given content hash
+func (r *receiptRepository) FindByImageHash(workspaceID int, imageHash string) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + receiptColumns + `
+		FROM receipts
+		WHERE workspace_id = $1 AND image_hash = $2
+		ORDER BY id ASC
+	`
+
+	return r.queryReceipts(query, workspaceID, imageHash)
+}
+
+// FindNearDuplicates finds the workspace's receipts whose perceptual hash is within maxDistance
+// bits of the given hash, closest first
+func (r *receiptRepository) FindNearDuplicates(workspaceID int, perceptualHash int64, maxDistance int) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + receiptColumns + `
+		FROM (
+			SELECT *, length(replace(((perceptual_hash # $2)::bit(64))::text, '0', '')) AS hamming_distance
+			FROM receipts
+			WHERE workspace_id = $1 AND perceptual_hash IS NOT NULL
+		) candidates
+		WHERE hamming_distance <= $3
+		ORDER BY hamming_distance ASC, id ASC
+	`
+
+	return r.queryReceipts(query, workspaceID, perceptualHash, maxDistance)
+}
+
+// FindDuplicateCandidates finds other receipts of the same workspace with the same date, currency
+// and a total within tolerance. Receipts already marked as duplicates are skipped
+// so that matches always point at an original.
+func (r *receiptRepository) FindDuplicateCandidates(receipt *domain.Receipt, tolerance domain.Money) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + receiptColumns + `
+		FROM receipts
+		WHERE workspace_id = $1
+		  AND id <> $2
+		  AND date = $3
+		  AND currency = $4
+		  AND ABS(COALESCE(total_spending, 0) - $5) <= $6
+		  AND duplicate_of IS NULL
+		ORDER BY id ASC
+	`
+
+	return r.queryReceipts(query, receipt.WorkspaceID, receipt.ID, receipt.Date, receipt.Currency, receipt.TotalSpending, tolerance)
+}
+
+// FindSuspectedDuplicates finds the workspace's receipts flagged as duplicates awaiting review
+func (r *receiptRepository) FindSuspectedDuplicates(workspaceID int) ([]domain.Receipt, error) {
 	query := `
-		SELECT id, uuid, user_id, store_name, address, phone, date, image_url, 
-		       original_filename, file_size, upload_date, status, total_items, 
-		       total_spending, total_discount, created_at, updated_at, 
-		       created_at_unix, updated_at_unix
+		SELECT ` + receiptColumns + `
 		FROM receipts
-		WHERE user_id = $1
+		WHERE workspace_id = $1 AND duplicate_status = $2
 		ORDER BY upload_date DESC
-		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	return r.queryReceipts(query, workspaceID, domain.DuplicateSuspected)
+}
+
+// Update updates receipt
+func (r *receiptRepository) Update(receipt *domain.Receipt) error {
+	return updateReceipt(r.db, receipt, time.Now().Unix())
+}
+
+// UpdateWithItems updates a receipt with its items and adjustments in one transaction, so a
+// failure leaves the receipt as it was. Items with an ID are updated in place and items without
+// one are inserted. The receipt's other items are deleted, unless a bill split, warranty or
+// anomaly refers to one of them, in which case nothing is changed. Adjustments are replaced.
+func (r *receiptRepository) UpdateWithItems(receipt *domain.ReceiptWithItems) error {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query receipts: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var receipts []domain.Receipt
-	for rows.Next() {
-		var receipt domain.Receipt
-		err := rows.Scan(
-			&receipt.ID,
-			&receipt.UUID,
-			&receipt.UserID,
-			&receipt.StoreName,
-			&receipt.Address,
-			&receipt.Phone,
-			&receipt.Date,
-			&receipt.ImageURL,
-			&receipt.OriginalFilename,
-			&receipt.FileSize,
-			&receipt.UploadDate,
-			&receipt.Status,
-			&receipt.TotalItems,
-			&receipt.TotalSpending,
-			&receipt.TotalDiscount,
-			&receipt.CreatedAt,
-			&receipt.UpdatedAt,
-			&receipt.CreatedAtUnix,
-			&receipt.UpdatedAtUnix,
+	now := time.Now().Unix()
+	if err := updateReceipt(tx, &receipt.Receipt, now); err != nil {
+		return err
+	}
+
+	kept := []int64{}
+	for _, item := range receipt.Items {
+		if item.ID != 0 {
+			kept = append(kept, int64(item.ID))
+		}
+	}
+
+	var referenced bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM items i
+			WHERE i.receipt_id = $1 AND NOT (i.id = ANY($2))
+			  AND (EXISTS (SELECT 1 FROM split_allocations sa WHERE sa.item_id = i.id)
+			       OR EXISTS (SELECT 1 FROM item_warranties iw WHERE iw.item_id = i.id)
+			       OR EXISTS (SELECT 1 FROM anomalies a WHERE a.item_id = i.id))
 		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan receipt: %w", err)
+	`, receipt.ID, pq.Array(kept)).Scan(&referenced)
+	if err != nil {
+		return fmt.Errorf("failed to check item references: %w", err)
+	}
+	if referenced {
+		return fmt.Errorf("items with a bill split, warranty or anomaly cannot be removed")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM items WHERE receipt_id = $1 AND NOT (id = ANY($2))`, receipt.ID, pq.Array(kept)); err != nil {
+		return fmt.Errorf("failed to delete items: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM receipt_adjustments WHERE receipt_id = $1`, receipt.ID); err != nil {
+		return fmt.Errorf("failed to delete adjustments: %w", err)
+	}
+
+	for i := range receipt.Items {
+		receipt.Items[i].ReceiptID = receipt.ID
+		if receipt.Items[i].ID != 0 {
+			if err := updateItem(tx, &receipt.Items[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := insertItem(tx, &receipt.Items[i], now); err != nil {
+			return err
 		}
-		receipts = append(receipts, receipt)
 	}
 
-	return receipts, total, nil
+	for i := range receipt.Adjustments {
+		receipt.Adjustments[i].ReceiptID = receipt.ID
+		if err := insertAdjustment(tx, &receipt.Adjustments[i], now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
 }
 
-// Update updates receipt
-func (r *receiptRepository) Update(receipt *domain.Receipt) error {
+// updateReceipt writes the editable fields of a receipt
+func updateReceipt(q queryRower, receipt *domain.Receipt, now int64) error {
 	query := `
 		UPDATE receipts
-		SET store_name = $1, address = $2, phone = $3, date = $4, status = $5, 
-		    total_items = $6, total_spending = $7, total_discount = $8, 
-		    updated_at = NOW(), updated_at_unix = $9
-		WHERE id = $10
+		SET store_name = $1, address = $2, phone = $3, date = $4, status = $5,
+		    total_items = $6, total_spending = $7, total_discount = $8, currency = $9,
+		    payment_method = $10, card_brand = $11, card_last4 = $12, card_id = $13,
+		    merchant_id = $14, notes = $15, updated_at = NOW(), updated_at_unix = $16
+		WHERE id = $17
 		RETURNING updated_at
 	`
 
-	now := time.Now().Unix()
-	err := r.db.QueryRow(
+	err := q.QueryRow(
 		query,
 		receipt.StoreName,
 		receipt.Address,
@@ -248,6 +588,13 @@ func (r *receiptRepository) Update(receipt *domain.Receipt) error {
 		receipt.TotalItems,
 		receipt.TotalSpending,
 		receipt.TotalDiscount,
+		receipt.Currency,
+		receipt.PaymentMethod,
+		receipt.CardBrand,
+		receipt.CardLast4,
+		receipt.CardID,
+		receipt.MerchantID,
+		receipt.Notes,
 		now,
 		receipt.ID,
 	).Scan(&receipt.UpdatedAt)
@@ -260,6 +607,32 @@ func (r *receiptRepository) Update(receipt *domain.Receipt) error {
 	return nil
 }
 
+// UpdateDuplicate sets the duplicate reference and review status of a receipt
+func (r *receiptRepository) UpdateDuplicate(id int, duplicateOf sql.NullInt64, status domain.DuplicateStatus) error {
+	query := `
+		UPDATE receipts
+		SET duplicate_of = $1, duplicate_status = $2, updated_at = NOW(), updated_at_unix = $3
+		WHERE id = $4
+	`
+
+	now := time.Now().Unix()
+	result, err := r.db.Exec(query, duplicateOf, status, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to update duplicate status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("receipt not found")
+	}
+
+	return nil
+}
+
 // Delete deletes receipt by ID
 func (r *receiptRepository) Delete(id int) error {
 	query := `DELETE FROM receipts WHERE id = $1`
@@ -307,39 +680,213 @@ func (r *receiptRepository) SoftDelete(id int) error {
 	return nil
 }
 
-// GetStatsByUserID gets spending statistics by user ID
-func (r *receiptRepository) GetStatsByUserID(userID int) (map[string]interface{}, error) {
+// GetTotalsByCurrency sums the workspace's spending per currency, payment method and purchase day
+// (falling back to the upload day) for receipts matching the filter, so callers can convert each group at the
+// rate of its day. Only completed receipts are counted unless the filter asks for another
+// status, and receipts flagged as duplicates of another receipt are excluded.
+func (r *receiptRepository) GetTotalsByCurrency(workspaceID int, filter domain.ReceiptFilter) ([]domain.CurrencyTotal, error) {
+	if filter.Status == "" {
+		filter.Status = domain.StatusCompleted
+	}
+	where, args := receiptFilterClause(workspaceID, filter)
+
 	query := `
-		SELECT 
-			COUNT(*) as total_receipts,
-			COALESCE(SUM(total_spending), 0) as total_spending,
-			COALESCE(SUM(total_discount), 0) as total_discount,
-			COALESCE(AVG(total_spending), 0) as avg_spending
-		FROM receipts
-		WHERE user_id = $1 AND status = 'completed'
+		SELECT
+			r.currency,
+			COALESCE(r.payment_method, '') AS payment_method,
+			COALESCE(r.date, r.upload_date::date) AS day,
+			COUNT(*) AS total_receipts,
+			COALESCE(SUM(r.total_spending), 0)::BIGINT AS total_spending,
+			COALESCE(SUM(r.total_discount), 0)::BIGINT AS total_discount
+		FROM receipts r
+		WHERE ` + where + ` AND r.duplicate_of IS NULL
+		GROUP BY r.currency, COALESCE(r.payment_method, ''), day
+		ORDER BY day ASC
 	`
 
-	var totalReceipts int
-	var totalSpending, totalDiscount, avgSpending float64
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.CurrencyTotal
+	for rows.Next() {
+		var total domain.CurrencyTotal
+		err := rows.Scan(
+			&total.Currency,
+			&total.PaymentMethod,
+			&total.Day,
+			&total.Receipts,
+			&total.TotalSpending,
+			&total.TotalDiscount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stats: %w", err)
+		}
+		total.TotalSpending.Currency = total.Currency
+		total.TotalDiscount.Currency = total.Currency
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stats: %w", err)
+	}
 
-	err := r.db.QueryRow(query, userID).Scan(
-		&totalReceipts,
-		&totalSpending,
-		&totalDiscount,
-		&avgSpending,
-	)
+	return totals, nil
+}
 
+// GetTotalsByTag sums the workspace's spending per tag of the requesting user, currency and
+// purchase day, using the same receipt selection as GetTotalsByCurrency. Tags are per user, so
+// other members' tags are left out. A receipt counts once under each tag it carries, so tag
+// totals can overlap.
+func (r *receiptRepository) GetTotalsByTag(workspaceID int, filter domain.ReceiptFilter) ([]domain.TagTotal, error) {
+	if filter.Status == "" {
+		filter.Status = domain.StatusCompleted
+	}
+	where, args := receiptFilterClause(workspaceID, filter)
+	args = append(args, filter.UserID)
+
+	query := fmt.Sprintf(`
+		SELECT
+			rt.name,
+			r.currency,
+			COALESCE(r.date, r.upload_date::date) AS day,
+			COUNT(*) AS total_receipts,
+			COALESCE(SUM(r.total_spending), 0)::BIGINT AS total_spending,
+			COALESCE(SUM(r.total_discount), 0)::BIGINT AS total_discount
+		FROM receipts r
+		JOIN (
+			SELECT DISTINCT receipt_tags.receipt_id, tags.name
+			FROM receipt_tags
+			JOIN tags ON tags.id = receipt_tags.tag_id
+			WHERE tags.user_id = $%d
+		) rt ON rt.receipt_id = r.id
+		WHERE %s AND r.duplicate_of IS NULL
+		GROUP BY rt.name, r.currency, day
+		ORDER BY day ASC
+	`, len(args), where)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stats: %w", err)
+		return nil, fmt.Errorf("failed to get tag stats: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []domain.TagTotal
+	for rows.Next() {
+		var total domain.TagTotal
+		err := rows.Scan(
+			&total.Tag,
+			&total.Currency,
+			&total.Day,
+			&total.Receipts,
+			&total.TotalSpending,
+			&total.TotalDiscount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag stats: %w", err)
+		}
+		total.TotalSpending.Currency = total.Currency
+		total.TotalDiscount.Currency = total.Currency
+		totals = append(totals, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tag stats: %w", err)
+	}
+
+	return totals, nil
+}
+
+// StreamItemRows streams the user's filtered receipts joined with their items, one row per
+// item, ordered by receipt. Each row also carries the receipt's adjustments. Rows are read from
+// the connection one at a time and handed to fn, so large exports never hold the whole result
+// set in memory.
+func (r *receiptRepository) StreamItemRows(workspaceID int, filter domain.ReceiptFilter, fn func(row *domain.ReceiptItemRow) error) error {
+	where, args := receiptFilterClause(workspaceID, filter)
+
+	itemJoin := "i.receipt_id = r.id"
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		itemJoin += fmt.Sprintf(" AND i.category = $%d", len(args))
 	}
 
-	stats := map[string]interface{}{
-		"total_receipts":   totalReceipts,
-		"total_spending":   totalSpending,
-		"total_discount":   totalDiscount,
-		"average_spending": avgSpending,
-		"net_spending":     totalSpending - totalDiscount,
+	query := `
+		SELECT ` + prefixedReceiptColumns("r") + `,
+		       i.id, i.uuid, COALESCE(i.name, ''), COALESCE(i.unit_price, 0),
+		       COALESCE(i.quantity, 0), COALESCE(i.price, 0), COALESCE(i.total, 0),
+		       i.category, i.created_at, COALESCE(i.created_at_unix, 0),
+		       ARRAY(SELECT a.type FROM receipt_adjustments a WHERE a.receipt_id = r.id ORDER BY a.id),
+		       ARRAY(SELECT COALESCE(a.label, '') FROM receipt_adjustments a WHERE a.receipt_id = r.id ORDER BY a.id),
+		       ARRAY(SELECT a.amount FROM receipt_adjustments a WHERE a.receipt_id = r.id ORDER BY a.id)
+		FROM receipts r
+		LEFT JOIN items i ON ` + itemJoin + `
+		WHERE ` + where + `
+		ORDER BY ` + receiptOrderClause(filter) + `, i.id ASC
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query receipt items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row domain.ReceiptItemRow
+		var item domain.Item
+		var itemID sql.NullInt64
+		var itemUUID uuid.NullUUID
+		var itemCreatedAt sql.NullTime
+		var adjustmentTypes, adjustmentLabels pq.StringArray
+		var adjustmentAmounts pq.Int64Array
+
+		dest := append(receiptScanDest(&row.Receipt),
+			&itemID,
+			&itemUUID,
+			&item.Name,
+			&item.UnitPrice,
+			&item.Quantity,
+			&item.Price,
+			&item.Total,
+			&item.Category,
+			&itemCreatedAt,
+			&item.CreatedAtUnix,
+			&adjustmentTypes,
+			&adjustmentLabels,
+			&adjustmentAmounts,
+		)
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan receipt item: %w", err)
+		}
+		setReceiptCurrency(&row.Receipt)
+
+		for j, adjustmentType := range adjustmentTypes {
+			row.Adjustments = append(row.Adjustments, domain.Adjustment{
+				ReceiptID: row.Receipt.ID,
+				Type:      domain.AdjustmentType(adjustmentType),
+				Label:     adjustmentLabels[j],
+				Amount:    domain.NewMoney(adjustmentAmounts[j], row.Receipt.Currency),
+			})
+		}
+
+		if itemID.Valid {
+			item.ID = int(itemID.Int64)
+			item.UUID = itemUUID.UUID
+			item.ReceiptID = row.Receipt.ID
+			item.CreatedAt = itemCreatedAt.Time
+			setItemCurrency(&item, row.Receipt.Currency)
+			row.Item = &item
+		}
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate receipt items: %w", err)
 	}
 
-	return stats, nil
+	return nil
 }
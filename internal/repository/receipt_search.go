@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+// searchQueryCTE binds the prefix tsquery ($2) and the raw search term ($3) used for trigram matching
+const searchQueryCTE = `
+	WITH q AS (SELECT to_tsquery('simple', $2) AS tsq, $3::text AS term)
+`
+
+// Search finds the workspace's receipts whose store name, address, notes or item names match the
+// query, using full-text search with prefix matching plus trigram similarity for typos. Receipts
+// the user tagged with the query as a tag name also match and rank first.
+// Results are ordered by rank and carry their matching items with highlighted names.
+func (r *receiptRepository) Search(workspaceID int, userID int, query string, page, limit int) ([]domain.ReceiptSearchResult, error) {
+	tsQuery := prefixTSQuery(query)
+	term := strings.TrimSpace(query)
+	if tsQuery == "" {
+		return []domain.ReceiptSearchResult{}, nil
+	}
+
+	receiptQuery := searchQueryCTE + `,
+		matched_items AS (
+			SELECT i.receipt_id,
+			       MAX(GREATEST(ts_rank(i.search_vector, q.tsq), similarity(i.name, q.term))) AS item_rank
+			FROM items i
+			JOIN receipts ir ON ir.id = i.receipt_id
+			CROSS JOIN q
+			WHERE ir.workspace_id = $1 AND (i.search_vector @@ q.tsq OR i.name % q.term)
+			GROUP BY i.receipt_id
+		),
+		tagged AS (
+			SELECT DISTINCT rt.receipt_id
+			FROM receipt_tags rt
+			JOIN tags t ON t.id = rt.tag_id
+			WHERE t.user_id = $7 AND t.name = $6
+		)
+		SELECT ` + prefixedReceiptColumns("r") + `,
+		       GREATEST(
+		           ts_rank(r.search_vector, q.tsq),
+		           similarity(COALESCE(r.store_name, ''), q.term),
+		           similarity(COALESCE(r.address, ''), q.term) * 0.5
+		       ) + COALESCE(mi.item_rank, 0) * 0.5
+		         + CASE WHEN tg.receipt_id IS NOT NULL THEN 1 ELSE 0 END AS rank
+		FROM receipts r
+		CROSS JOIN q
+		LEFT JOIN matched_items mi ON mi.receipt_id = r.id
+		LEFT JOIN tagged tg ON tg.receipt_id = r.id
+		WHERE r.workspace_id = $1
+		  AND (r.search_vector @@ q.tsq OR r.store_name % q.term OR r.address % q.term
+		       OR mi.receipt_id IS NOT NULL OR tg.receipt_id IS NOT NULL)
+		ORDER BY rank DESC, r.upload_date DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(receiptQuery, workspaceID, tsQuery, term, limit, offset, domain.NormalizeTagName(term), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search receipts: %w", err)
+	}
+	defer rows.Close()
+
+	results := []domain.ReceiptSearchResult{}
+	index := map[int]int{}
+	var receiptIDs []int64
+
+	for rows.Next() {
+		var result domain.ReceiptSearchResult
+		dest := append(receiptScanDest(&result.Receipt), &result.Rank)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		setReceiptCurrency(&result.Receipt)
+		result.MatchedItems = []domain.MatchedItem{}
+		index[result.ID] = len(results)
+		results = append(results, result)
+		receiptIDs = append(receiptIDs, int64(result.ID))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	if len(receiptIDs) == 0 {
+		return results, nil
+	}
+
+	itemQuery := searchQueryCTE + `
+		SELECT i.id, i.uuid, i.receipt_id, i.product_id, i.name, i.unit_price, i.quantity, i.price, i.total,
+		       i.category, i.notes, i.created_at, i.created_at_unix,
+		       ts_headline('simple', i.name, q.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+		FROM items i
+		CROSS JOIN q
+		WHERE i.receipt_id = ANY($1) AND (i.search_vector @@ q.tsq OR i.name % q.term)
+		ORDER BY i.receipt_id, i.id
+	`
+
+	itemRows, err := r.db.Query(itemQuery, pq.Array(receiptIDs), tsQuery, term)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item domain.MatchedItem
+		err := itemRows.Scan(
+			&item.ID,
+			&item.UUID,
+			&item.ReceiptID,
+			&item.ProductID,
+			&item.Name,
+			&item.UnitPrice,
+			&item.Quantity,
+			&item.Price,
+			&item.Total,
+			&item.Category,
+			&item.Notes,
+			&item.CreatedAt,
+			&item.CreatedAtUnix,
+			&item.Highlight,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+
+		i := index[item.ReceiptID]
+		setItemCurrency(&item.Item, results[i].Currency)
+		results[i].MatchedItems = append(results[i].MatchedItems, item)
+	}
+
+	if err := itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate items: %w", err)
+	}
+
+	return results, nil
+}
+
+// prefixTSQuery turns free text into a tsquery where every word must match as a prefix,
+// e.g. "indo mie" -> "indo:* & mie:*". Returns "" when the text has no searchable words.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type RecurringRepository interface {
+	FindPurchases(workspaceID int) ([]domain.Receipt, error)
+	ReplaceForWorkspace(workspaceID int, recurring []domain.RecurringPurchase) error
+	FindByWorkspaceID(workspaceID int, includeDismissed bool) ([]domain.RecurringPurchase, error)
+	FindByID(id int) (*domain.RecurringPurchase, error)
+	SetDismissed(id int, dismissed bool) error
+}
+
+type recurringRepository struct {
+	db *sql.DB
+}
+
+// NewRecurringRepository creates a new recurring purchase repository
+func NewRecurringRepository(db *sql.DB) RecurringRepository {
+	return &recurringRepository{db: db}
+}
+
+// recurringColumns lists the columns of a recurring purchase SELECT, in scanRecurring order
+const recurringColumns = `
+	id, uuid, workspace_id, merchant_id, merchant_key, store_name, cadence, amount, currency,
+	occurrences, first_date, last_date, next_date, status, unusual_amount, unusual_receipt_id,
+	receipt_ids, dismissed, created_at, updated_at, created_at_unix, updated_at_unix
+`
+
+// scanRecurring scans a row of recurringColumns
+func scanRecurring(row rowScanner, recurring *domain.RecurringPurchase) error {
+	var unusualAmount sql.NullInt64
+	var receiptIDs pq.Int64Array
+
+	err := row.Scan(
+		&recurring.ID,
+		&recurring.UUID,
+		&recurring.WorkspaceID,
+		&recurring.MerchantID,
+		&recurring.MerchantKey,
+		&recurring.StoreName,
+		&recurring.Cadence,
+		&recurring.Amount,
+		&recurring.Currency,
+		&recurring.Occurrences,
+		&recurring.FirstDate,
+		&recurring.LastDate,
+		&recurring.NextDate,
+		&recurring.Status,
+		&unusualAmount,
+		&recurring.UnusualReceiptID,
+		&receiptIDs,
+		&recurring.Dismissed,
+		&recurring.CreatedAt,
+		&recurring.UpdatedAt,
+		&recurring.CreatedAtUnix,
+		&recurring.UpdatedAtUnix,
+	)
+	if err != nil {
+		return err
+	}
+
+	recurring.Amount.Currency = recurring.Currency
+	if unusualAmount.Valid {
+		amount := domain.NewMoney(unusualAmount.Int64, recurring.Currency)
+		recurring.UnusualAmount = &amount
+	}
+	recurring.ReceiptIDs = []int64(receiptIDs)
+
+	return nil
+}
+
+// FindPurchases finds the dated receipts of a workspace, oldest first, for recurrence
+// detection. Receipts flagged as duplicates of another receipt are excluded.
+func (r *recurringRepository) FindPurchases(workspaceID int) ([]domain.Receipt, error) {
+	query := `
+		SELECT ` + prefixedReceiptColumns("r") + `
+		FROM receipts r
+		WHERE r.workspace_id = $1 AND r.date IS NOT NULL AND r.duplicate_of IS NULL
+		ORDER BY r.date ASC, r.id ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []domain.Receipt{}
+	for rows.Next() {
+		var receipt domain.Receipt
+		if err := scanReceipt(rows, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
+// ReplaceForWorkspace replaces a workspace's recurring purchases with a new detection in a
+// single transaction. Dismissed recurrences stay dismissed when detected again at the same
+// merchant, currency and cadence.
+func (r *recurringRepository) ReplaceForWorkspace(workspaceID int, recurring []domain.RecurringPurchase) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT merchant_key, currency, cadence
+		FROM recurring_purchases
+		WHERE workspace_id = $1 AND dismissed
+	`, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to query dismissed recurring purchases: %w", err)
+	}
+
+	dismissed := map[string]bool{}
+	for rows.Next() {
+		var merchantKey, currency, cadence string
+		if err := rows.Scan(&merchantKey, &currency, &cadence); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan dismissed recurring purchase: %w", err)
+		}
+		dismissed[merchantKey+"/"+currency+"/"+cadence] = true
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate dismissed recurring purchases: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM recurring_purchases WHERE workspace_id = $1`, workspaceID); err != nil {
+		return fmt.Errorf("failed to delete previous recurring purchases: %w", err)
+	}
+
+	query := `
+		INSERT INTO recurring_purchases (
+			workspace_id, merchant_id, merchant_key, store_name, cadence, amount, currency,
+			occurrences, first_date, last_date, next_date, status, unusual_amount, unusual_receipt_id,
+			receipt_ids, dismissed, created_at_unix, updated_at_unix
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	for i := range recurring {
+		item := &recurring[i]
+		item.WorkspaceID = workspaceID
+		item.Dismissed = dismissed[item.MerchantKey+"/"+item.Currency+"/"+string(item.Cadence)]
+
+		var unusualAmount sql.NullInt64
+		if item.UnusualAmount != nil {
+			unusualAmount = sql.NullInt64{Int64: item.UnusualAmount.Amount, Valid: true}
+		}
+
+		err := tx.QueryRow(
+			query,
+			item.WorkspaceID,
+			item.MerchantID,
+			item.MerchantKey,
+			item.StoreName,
+			item.Cadence,
+			item.Amount,
+			item.Currency,
+			item.Occurrences,
+			item.FirstDate,
+			item.LastDate,
+			item.NextDate,
+			item.Status,
+			unusualAmount,
+			item.UnusualReceiptID,
+			pq.Array(item.ReceiptIDs),
+			item.Dismissed,
+			now,
+			now,
+		).Scan(&item.ID, &item.UUID, &item.CreatedAt, &item.UpdatedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to create recurring purchase: %w", err)
+		}
+
+		item.CreatedAtUnix = now
+		item.UpdatedAtUnix = now
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindByWorkspaceID finds a workspace's recurring purchases ordered by the predicted next occurrence
+func (r *recurringRepository) FindByWorkspaceID(workspaceID int, includeDismissed bool) ([]domain.RecurringPurchase, error) {
+	query := `SELECT ` + recurringColumns + ` FROM recurring_purchases WHERE workspace_id = $1`
+	if !includeDismissed {
+		query += ` AND NOT dismissed`
+	}
+	query += ` ORDER BY next_date ASC, id ASC`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurring purchases: %w", err)
+	}
+	defer rows.Close()
+
+	recurring := []domain.RecurringPurchase{}
+	for rows.Next() {
+		var item domain.RecurringPurchase
+		if err := scanRecurring(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring purchase: %w", err)
+		}
+		recurring = append(recurring, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate recurring purchases: %w", err)
+	}
+
+	return recurring, nil
+}
+
+// FindByID finds a recurring purchase by ID
+func (r *recurringRepository) FindByID(id int) (*domain.RecurringPurchase, error) {
+	query := `SELECT ` + recurringColumns + ` FROM recurring_purchases WHERE id = $1`
+
+	recurring := &domain.RecurringPurchase{}
+	err := scanRecurring(r.db.QueryRow(query, id), recurring)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recurring purchase not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring purchase: %w", err)
+	}
+
+	return recurring, nil
+}
+
+// SetDismissed dismisses or restores a recurring purchase
+func (r *recurringRepository) SetDismissed(id int, dismissed bool) error {
+	query := `
+		UPDATE recurring_purchases
+		SET dismissed = $1, updated_at = NOW(), updated_at_unix = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(query, dismissed, time.Now().Unix(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring purchase: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("recurring purchase not found")
+	}
+
+	return nil
+}
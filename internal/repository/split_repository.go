@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type SplitRepository interface {
+	Save(split *domain.Split) error
+	FindByReceiptID(receiptID int) (*domain.Split, error)
+	DeleteByReceiptID(receiptID int) error
+	CreateSettlement(settlement *domain.Settlement) error
+	FindLedger(workspaceID int) ([]domain.LedgerEntry, error)
+}
+
+type splitRepository struct {
+	db *sql.DB
+}
+
+// NewSplitRepository creates a new split repository
+func NewSplitRepository(db *sql.DB) SplitRepository {
+	return &splitRepository{db: db}
+}
+
+// Save creates the split of a receipt, replacing any earlier split of the same receipt, with its
+// allocations and shares in a single transaction
+func (r *splitRepository) Save(split *domain.Split) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM receipt_splits WHERE receipt_id = $1`, split.ReceiptID); err != nil {
+		return fmt.Errorf("failed to delete previous split: %w", err)
+	}
+
+	query := `
+		INSERT INTO receipt_splits (receipt_id, workspace_id, payer_id, currency, created_by, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	err = tx.QueryRow(
+		query,
+		split.ReceiptID,
+		split.WorkspaceID,
+		split.PayerID,
+		split.Currency,
+		split.CreatedBy,
+		now,
+		now,
+	).Scan(&split.ID, &split.UUID, &split.CreatedAt, &split.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create split: %w", err)
+	}
+
+	for _, allocation := range split.Allocations {
+		query := `INSERT INTO split_allocations (split_id, item_id, user_id, weight) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(query, split.ID, allocation.ItemID, allocation.UserID, allocation.Weight); err != nil {
+			return fmt.Errorf("failed to create split allocation: %w", err)
+		}
+	}
+
+	for _, share := range split.Shares {
+		query := `INSERT INTO split_shares (split_id, user_id, items, adjustments, total) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := tx.Exec(query, split.ID, share.UserID, share.Items, share.Adjustments, share.Total); err != nil {
+			return fmt.Errorf("failed to create split share: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	split.CreatedAtUnix = now
+	split.UpdatedAtUnix = now
+
+	return nil
+}
+
+// FindByReceiptID finds the split of a receipt with its allocations and shares
+func (r *splitRepository) FindByReceiptID(receiptID int) (*domain.Split, error) {
+	query := `
+		SELECT id, uuid, receipt_id, workspace_id, payer_id, currency, created_by,
+		       created_at, updated_at, created_at_unix, updated_at_unix
+		FROM receipt_splits
+		WHERE receipt_id = $1
+	`
+
+	split := &domain.Split{}
+	err := r.db.QueryRow(query, receiptID).Scan(
+		&split.ID,
+		&split.UUID,
+		&split.ReceiptID,
+		&split.WorkspaceID,
+		&split.PayerID,
+		&split.Currency,
+		&split.CreatedBy,
+		&split.CreatedAt,
+		&split.UpdatedAt,
+		&split.CreatedAtUnix,
+		&split.UpdatedAtUnix,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("split not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find split: %w", err)
+	}
+
+	if split.Allocations, err = r.findAllocations(split.ID); err != nil {
+		return nil, err
+	}
+
+	if split.Shares, err = r.findShares(split.ID, split.Currency); err != nil {
+		return nil, err
+	}
+
+	return split, nil
+}
+
+// findAllocations finds the allocations of a split ordered by item and user
+func (r *splitRepository) findAllocations(splitID int) ([]domain.SplitAllocation, error) {
+	query := `
+		SELECT item_id, user_id, weight
+		FROM split_allocations
+		WHERE split_id = $1
+		ORDER BY item_id ASC, user_id ASC
+	`
+
+	rows, err := r.db.Query(query, splitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query split allocations: %w", err)
+	}
+	defer rows.Close()
+
+	allocations := []domain.SplitAllocation{}
+	for rows.Next() {
+		var allocation domain.SplitAllocation
+		if err := rows.Scan(&allocation.ItemID, &allocation.UserID, &allocation.Weight); err != nil {
+			return nil, fmt.Errorf("failed to scan split allocation: %w", err)
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate split allocations: %w", err)
+	}
+
+	return allocations, nil
+}
+
+// findShares finds the shares of a split ordered by user
+func (r *splitRepository) findShares(splitID int, currency string) ([]domain.SplitShare, error) {
+	query := `
+		SELECT user_id, items, adjustments, total
+		FROM split_shares
+		WHERE split_id = $1
+		ORDER BY user_id ASC
+	`
+
+	rows, err := r.db.Query(query, splitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query split shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := []domain.SplitShare{}
+	for rows.Next() {
+		var share domain.SplitShare
+		if err := rows.Scan(&share.UserID, &share.Items, &share.Adjustments, &share.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan split share: %w", err)
+		}
+		share.Items.Currency = currency
+		share.Adjustments.Currency = currency
+		share.Total.Currency = currency
+		shares = append(shares, share)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate split shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// DeleteByReceiptID deletes the split of a receipt
+func (r *splitRepository) DeleteByReceiptID(receiptID int) error {
+	result, err := r.db.Exec(`DELETE FROM receipt_splits WHERE receipt_id = $1`, receiptID)
+	if err != nil {
+		return fmt.Errorf("failed to delete split: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("split not found")
+	}
+
+	return nil
+}
+
+// CreateSettlement creates a new settlement
+func (r *splitRepository) CreateSettlement(settlement *domain.Settlement) error {
+	query := `
+		INSERT INTO settlements (workspace_id, from_user_id, to_user_id, amount, currency, note, created_by, settled_at, created_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, uuid, created_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		settlement.WorkspaceID,
+		settlement.FromUserID,
+		settlement.ToUserID,
+		settlement.Amount,
+		settlement.Currency,
+		sql.NullString{String: settlement.Note, Valid: settlement.Note != ""},
+		settlement.CreatedBy,
+		settlement.SettledAt,
+		now,
+	).Scan(&settlement.ID, &settlement.UUID, &settlement.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create settlement: %w", err)
+	}
+
+	settlement.CreatedAtUnix = now
+	return nil
+}
+
+// FindLedger finds every debt between members of a workspace, oldest first: a share of a split
+// receipt owed to its payer, or a settlement paid from one member to another
+func (r *splitRepository) FindLedger(workspaceID int) ([]domain.LedgerEntry, error) {
+	query := `
+		SELECT 'split', COALESCE(r.date, r.upload_date), s.receipt_id, COALESCE(r.store_name, ''),
+		       sh.user_id, s.payer_id, sh.total, s.currency, '', s.id
+		FROM receipt_splits s
+		JOIN receipts r ON r.id = s.receipt_id
+		JOIN split_shares sh ON sh.split_id = s.id
+		WHERE s.workspace_id = $1 AND sh.user_id <> s.payer_id AND sh.total <> 0
+		UNION ALL
+		SELECT 'settlement', st.settled_at, 0, '',
+		       st.from_user_id, st.to_user_id, st.amount, st.currency, COALESCE(st.note, ''), st.id
+		FROM settlements st
+		WHERE st.workspace_id = $1
+		ORDER BY 2 ASC, 10 ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.LedgerEntry{}
+	for rows.Next() {
+		var entry domain.LedgerEntry
+		var id int
+		if err := rows.Scan(
+			&entry.Type,
+			&entry.Date,
+			&entry.ReceiptID,
+			&entry.StoreName,
+			&entry.FromUserID,
+			&entry.ToUserID,
+			&entry.Amount,
+			&entry.Amount.Currency,
+			&entry.Note,
+			&id,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ledger: %w", err)
+	}
+
+	return entries, nil
+}
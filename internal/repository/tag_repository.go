@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type TagRepository interface {
+	Create(tag *domain.Tag) error
+	FindByID(id int) (*domain.Tag, error)
+	FindByName(userID int, name string) (*domain.Tag, error)
+	FindByUserID(userID int) ([]domain.Tag, error)
+	Rename(id int, name string) error
+	Delete(id int) error
+	FindByReceiptID(receiptID int, userID int) ([]domain.Tag, error)
+	SetReceiptTags(receiptID int, userID int, names []string) error
+}
+
+// tagColumns lists the columns selected for a tag, in scanTag order
+const tagColumns = `t.id, t.uuid, t.user_id, t.name, t.created_at, t.updated_at, t.created_at_unix, t.updated_at_unix`
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// scanTag scans a row selected with tagColumns, followed by extra destinations, into tag
+func scanTag(row rowScanner, tag *domain.Tag, extra ...interface{}) error {
+	dest := []interface{}{
+		&tag.ID,
+		&tag.UUID,
+		&tag.UserID,
+		&tag.Name,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+		&tag.CreatedAtUnix,
+		&tag.UpdatedAtUnix,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// Create creates a new tag
+func (r *tagRepository) Create(tag *domain.Tag) error {
+	query := `
+		INSERT INTO tags (user_id, name, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(query, tag.UserID, tag.Name, now, now).Scan(&tag.ID, &tag.UUID, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	tag.CreatedAtUnix = now
+	tag.UpdatedAtUnix = now
+	return nil
+}
+
+// FindByID finds tag by ID
+func (r *tagRepository) FindByID(id int) (*domain.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.id = $1`
+
+	tag := &domain.Tag{}
+	err := scanTag(r.db.QueryRow(query, id), tag)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tag not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// FindByName finds the user's tag with a normalized name, or nil if the user has none
+func (r *tagRepository) FindByName(userID int, name string) (*domain.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.user_id = $1 AND t.name = $2`
+
+	tag := &domain.Tag{}
+	err := scanTag(r.db.QueryRow(query, userID, name), tag)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// FindByUserID finds all tags of a user by name, with the number of receipts carrying each
+func (r *tagRepository) FindByUserID(userID int) ([]domain.Tag, error) {
+	query := `
+		SELECT ` + tagColumns + `, COUNT(rt.receipt_id)
+		FROM tags t
+		LEFT JOIN receipt_tags rt ON rt.tag_id = t.id
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY t.name ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []domain.Tag{}
+	for rows.Next() {
+		var tag domain.Tag
+		if err := scanTag(rows, &tag, &tag.ReceiptCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// Rename changes the name of a tag; receipts carrying it follow the new name
+func (r *tagRepository) Rename(id int, name string) error {
+	query := `
+		UPDATE tags
+		SET name = $1, updated_at = NOW(), updated_at_unix = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(query, name, time.Now().Unix(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a tag and removes it from every receipt
+func (r *tagRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// FindByReceiptID finds the user's tags on a receipt by name
+func (r *tagRepository) FindByReceiptID(receiptID int, userID int) ([]domain.Tag, error) {
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags t
+		JOIN receipt_tags rt ON rt.tag_id = t.id
+		WHERE rt.receipt_id = $1 AND t.user_id = $2
+		ORDER BY t.name ASC
+	`
+
+	rows, err := r.db.Query(query, receiptID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []domain.Tag{}
+	for rows.Next() {
+		var tag domain.Tag
+		if err := scanTag(rows, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// SetReceiptTags replaces the user's tags on a receipt with the tags of the given normalized
+// names, creating those the user does not have yet, in a single transaction. Tags other users
+// put on the receipt are left alone.
+func (r *tagRepository) SetReceiptTags(receiptID int, userID int, names []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	tagIDs := make([]int64, 0, len(names))
+	for _, name := range names {
+		var tagID int64
+		err := tx.QueryRow(`
+			INSERT INTO tags (user_id, name, created_at_unix, updated_at_unix)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, userID, name, now, now).Scan(&tagID)
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		tagIDs = append(tagIDs, tagID)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM receipt_tags rt
+		USING tags t
+		WHERE rt.tag_id = t.id AND rt.receipt_id = $1 AND t.user_id = $2 AND NOT (rt.tag_id = ANY($3))
+	`, receiptID, userID, pq.Int64Array(tagIDs))
+	if err != nil {
+		return fmt.Errorf("failed to remove receipt tags: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO receipt_tags (receipt_id, tag_id)
+		SELECT $1, UNNEST($2::int[])
+		ON CONFLICT DO NOTHING
+	`, receiptID, pq.Int64Array(tagIDs))
+	if err != nil {
+		return fmt.Errorf("failed to add receipt tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
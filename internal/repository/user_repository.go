@@ -29,9 +29,9 @@ func NewUserRepository(db *sql.DB) UserRepository {
 // Create creates a new user
 func (r *userRepository) Create(user *domain.User) error {
 	query := `
-		INSERT INTO users (email, password_hash, full_name, created_at_unix, updated_at_unix)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, uuid, created_at, updated_at
+		INSERT INTO users (email, password_hash, full_name, home_currency, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, uuid, role, created_at, updated_at
 	`
 
 	now := time.Now().Unix()
@@ -40,9 +40,10 @@ func (r *userRepository) Create(user *domain.User) error {
 		user.Email,
 		user.PasswordHash,
 		user.FullName,
+		user.HomeCurrency,
 		now,
 		now,
-	).Scan(&user.ID, &user.UUID, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.UUID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -57,7 +58,7 @@ func (r *userRepository) Create(user *domain.User) error {
 // FindByEmail finds user by email
 func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 	query := `
-		SELECT id, uuid, email, password_hash, full_name, created_at, updated_at, created_at_unix, updated_at_unix
+		SELECT id, uuid, email, password_hash, full_name, home_currency, role, created_at, updated_at, created_at_unix, updated_at_unix
 		FROM users
 		WHERE email = $1
 	`
@@ -69,6 +70,8 @@ func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.FullName,
+		&user.HomeCurrency,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.CreatedAtUnix,
@@ -89,7 +92,7 @@ func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 // FindByID finds user by ID
 func (r *userRepository) FindByID(id int) (*domain.User, error) {
 	query := `
-		SELECT id, uuid, email, password_hash, full_name, created_at, updated_at, created_at_unix, updated_at_unix
+		SELECT id, uuid, email, password_hash, full_name, home_currency, role, created_at, updated_at, created_at_unix, updated_at_unix
 		FROM users
 		WHERE id = $1
 	`
@@ -101,6 +104,8 @@ func (r *userRepository) FindByID(id int) (*domain.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.FullName,
+		&user.HomeCurrency,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.CreatedAtUnix,
@@ -121,7 +126,7 @@ func (r *userRepository) FindByID(id int) (*domain.User, error) {
 // FindByUUID finds user by UUID
 func (r *userRepository) FindByUUID(uuidStr string) (*domain.User, error) {
 	query := `
-		SELECT id, uuid, email, password_hash, full_name, created_at, updated_at, created_at_unix, updated_at_unix
+		SELECT id, uuid, email, password_hash, full_name, home_currency, role, created_at, updated_at, created_at_unix, updated_at_unix
 		FROM users
 		WHERE uuid = $1
 	`
@@ -138,6 +143,8 @@ func (r *userRepository) FindByUUID(uuidStr string) (*domain.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.FullName,
+		&user.HomeCurrency,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.CreatedAtUnix,
@@ -159,13 +166,13 @@ func (r *userRepository) FindByUUID(uuidStr string) (*domain.User, error) {
 func (r *userRepository) Update(user *domain.User) error {
 	query := `
 		UPDATE users
-		SET email = $1, full_name = $2, updated_at = NOW(), updated_at_unix = $3
-		WHERE id = $4
+		SET email = $1, full_name = $2, home_currency = $3, updated_at = NOW(), updated_at_unix = $4
+		WHERE id = $5
 		RETURNING updated_at
 	`
 
 	now := time.Now().Unix()
-	err := r.db.QueryRow(query, user.Email, user.FullName, now, user.ID).Scan(&user.UpdatedAt)
+	err := r.db.QueryRow(query, user.Email, user.FullName, user.HomeCurrency, now, user.ID).Scan(&user.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type WarrantyRepository interface {
+	Save(warranty *domain.ItemWarranty) error
+	FindByItemID(itemID int) (*domain.ItemWarranty, error)
+	Delete(itemID int) error
+	FindUpcoming(workspaceID int, kind domain.ExpirationKind, from, to time.Time) ([]domain.Expiration, error)
+	FindDueReturns(from, to time.Time) ([]domain.Expiration, error)
+	RemindReturn(notification *domain.Notification, at time.Time) error
+}
+
+type warrantyRepository struct {
+	db *sql.DB
+}
+
+// NewWarrantyRepository creates a new warranty repository
+func NewWarrantyRepository(db *sql.DB) WarrantyRepository {
+	return &warrantyRepository{db: db}
+}
+
+// Expiry dates are counted from the purchase date, or the upload date when it is unknown.
+// Months are added as calendar months, so a warranty bought on 31 January ends on the last
+// day of the following February rather than in March.
+const (
+	purchaseDateExpr   = `COALESCE(r.date, r.upload_date::date)`
+	warrantyExpiryExpr = `(` + purchaseDateExpr + ` + w.warranty_months * INTERVAL '1 month')::date`
+	returnExpiryExpr   = `(` + purchaseDateExpr + ` + w.return_days)`
+)
+
+// expirationsQuery selects the warranty and return window expirations of every tracked item,
+// with the columns scanned by scanExpiration, to be filtered by the caller. $1 is the date
+// days are counted from.
+const expirationsQuery = `
+	WITH expirations AS (
+		SELECT 'warranty' AS kind, ` + warrantyExpiryExpr + ` AS expires_on, w.return_reminded_at,
+		       i.id AS item_id, i.name AS item_name, r.id AS receipt_id, r.workspace_id, r.user_id,
+		       r.store_name, ` + purchaseDateExpr + ` AS purchase_date
+		FROM item_warranties w
+		JOIN items i ON i.id = w.item_id
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE w.warranty_months IS NOT NULL
+		UNION ALL
+		SELECT 'return' AS kind, ` + returnExpiryExpr + ` AS expires_on, w.return_reminded_at,
+		       i.id AS item_id, i.name AS item_name, r.id AS receipt_id, r.workspace_id, r.user_id,
+		       r.store_name, ` + purchaseDateExpr + ` AS purchase_date
+		FROM item_warranties w
+		JOIN items i ON i.id = w.item_id
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE w.return_days IS NOT NULL
+	)
+	SELECT kind, expires_on, expires_on - $1::date, item_id, item_name, receipt_id, workspace_id,
+	       user_id, store_name, purchase_date
+	FROM expirations
+`
+
+// Save creates or replaces the warranty of an item. Changing the return window allows its
+// reminder to be sent again.
+func (r *warrantyRepository) Save(warranty *domain.ItemWarranty) error {
+	query := `
+		INSERT INTO item_warranties (item_id, warranty_months, return_days, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (item_id) DO UPDATE SET
+			warranty_months = EXCLUDED.warranty_months,
+			return_days = EXCLUDED.return_days,
+			return_reminded_at = CASE
+				WHEN item_warranties.return_days IS DISTINCT FROM EXCLUDED.return_days THEN NULL
+				ELSE item_warranties.return_reminded_at
+			END,
+			updated_at = NOW(),
+			updated_at_unix = EXCLUDED.updated_at_unix
+	`
+
+	now := time.Now().Unix()
+	if _, err := r.db.Exec(query, warranty.ItemID, warranty.WarrantyMonths, warranty.ReturnDays, now, now); err != nil {
+		return fmt.Errorf("failed to save warranty: %w", err)
+	}
+
+	return nil
+}
+
+// FindByItemID finds the warranty of an item with its expiry dates
+func (r *warrantyRepository) FindByItemID(itemID int) (*domain.ItemWarranty, error) {
+	query := `
+		SELECT w.item_id, i.receipt_id, w.warranty_months, w.return_days,
+		       CASE WHEN w.warranty_months IS NOT NULL THEN ` + warrantyExpiryExpr + ` END,
+		       CASE WHEN w.return_days IS NOT NULL THEN ` + returnExpiryExpr + ` END,
+		       w.return_reminded_at, w.created_at, w.updated_at, w.created_at_unix, w.updated_at_unix
+		FROM item_warranties w
+		JOIN items i ON i.id = w.item_id
+		JOIN receipts r ON r.id = i.receipt_id
+		WHERE w.item_id = $1
+	`
+
+	warranty := &domain.ItemWarranty{}
+	err := r.db.QueryRow(query, itemID).Scan(
+		&warranty.ItemID,
+		&warranty.ReceiptID,
+		&warranty.WarrantyMonths,
+		&warranty.ReturnDays,
+		&warranty.WarrantyExpiresOn,
+		&warranty.ReturnExpiresOn,
+		&warranty.ReturnRemindedAt,
+		&warranty.CreatedAt,
+		&warranty.UpdatedAt,
+		&warranty.CreatedAtUnix,
+		&warranty.UpdatedAtUnix,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("warranty not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find warranty: %w", err)
+	}
+
+	return warranty, nil
+}
+
+// Delete stops tracking the warranty of an item
+func (r *warrantyRepository) Delete(itemID int) error {
+	result, err := r.db.Exec(`DELETE FROM item_warranties WHERE item_id = $1`, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete warranty: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("warranty not found")
+	}
+
+	return nil
+}
+
+// FindUpcoming finds the warranties and return windows of a workspace's items expiring between
+// from and to, soonest first, optionally of one kind
+func (r *warrantyRepository) FindUpcoming(workspaceID int, kind domain.ExpirationKind, from, to time.Time) ([]domain.Expiration, error) {
+	query := expirationsQuery + `
+		WHERE workspace_id = $2 AND expires_on BETWEEN $1::date AND $3::date AND ($4 = '' OR kind = $4)
+		ORDER BY expires_on ASC, item_id ASC, kind ASC
+	`
+
+	return r.queryExpirations(query, from, workspaceID, to, string(kind))
+}
+
+// FindDueReturns finds the return windows closing between from and to whose reminder has not
+// been sent, across all users
+func (r *warrantyRepository) FindDueReturns(from, to time.Time) ([]domain.Expiration, error) {
+	query := expirationsQuery + `
+		WHERE kind = 'return' AND return_reminded_at IS NULL AND expires_on BETWEEN $1::date AND $2::date
+		ORDER BY expires_on ASC, item_id ASC
+	`
+
+	return r.queryExpirations(query, from, to)
+}
+
+// queryExpirations runs a query built on expirationsQuery
+func (r *warrantyRepository) queryExpirations(query string, args ...interface{}) ([]domain.Expiration, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expirations: %w", err)
+	}
+	defer rows.Close()
+
+	expirations := []domain.Expiration{}
+	for rows.Next() {
+		var expiration domain.Expiration
+		err := rows.Scan(
+			&expiration.Kind,
+			&expiration.ExpiresOn,
+			&expiration.DaysLeft,
+			&expiration.ItemID,
+			&expiration.ItemName,
+			&expiration.ReceiptID,
+			&expiration.WorkspaceID,
+			&expiration.UserID,
+			&expiration.StoreName,
+			&expiration.PurchaseDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expiration: %w", err)
+		}
+		expirations = append(expirations, expiration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate expirations: %w", err)
+	}
+
+	return expirations, nil
+}
+
+// RemindReturn creates the reminder notification for an item's return window and records that
+// it was sent, in one transaction so a window is reminded of exactly once
+func (r *warrantyRepository) RemindReturn(notification *domain.Notification, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertNotification(tx, notification, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	query := `UPDATE item_warranties SET return_reminded_at = $1 WHERE item_id = $2`
+	if _, err := tx.Exec(query, at, notification.ItemID); err != nil {
+		return fmt.Errorf("failed to mark return reminded: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+type WorkspaceRepository interface {
+	Create(workspace *domain.Workspace) error
+	FindByID(id int) (*domain.Workspace, error)
+	FindByUserID(userID int) ([]domain.Workspace, error)
+	FindPersonal(userID int) (*domain.Workspace, error)
+	Update(workspace *domain.Workspace) error
+	FindMember(workspaceID, userID int) (*domain.WorkspaceMember, error)
+	FindMembers(workspaceID int) ([]domain.WorkspaceMember, error)
+	UpdateMemberRole(workspaceID, userID int, role domain.WorkspaceRole) error
+	RemoveMember(workspaceID, userID int) error
+	CreateInvitation(invitation *domain.WorkspaceInvitation) error
+	FindInvitationByID(id int) (*domain.WorkspaceInvitation, error)
+	FindInvitationByTokenHash(tokenHash string) (*domain.WorkspaceInvitation, error)
+	FindPendingInvitations(workspaceID int) ([]domain.WorkspaceInvitation, error)
+	FindInvitationsByEmail(email string) ([]domain.WorkspaceInvitation, error)
+	AcceptInvitation(invitation *domain.WorkspaceInvitation, userID int) error
+	DeleteInvitation(id int) error
+}
+
+// workspaceColumns lists the columns selected for a workspace, in scanWorkspace order
+const workspaceColumns = `w.id, w.uuid, w.name, w.owner_id, w.personal, w.created_at, w.updated_at, w.created_at_unix, w.updated_at_unix`
+
+// invitationColumns lists the columns selected for an invitation with its workspace name, in scanInvitation order
+const invitationColumns = `
+	i.id, i.uuid, i.workspace_id, w.name, i.email, i.role, i.token_hash, i.invited_by,
+	i.expires_at, i.accepted_at, i.created_at, i.created_at_unix
+`
+
+type workspaceRepository struct {
+	db *sql.DB
+}
+
+// NewWorkspaceRepository creates a new workspace repository
+func NewWorkspaceRepository(db *sql.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// scanWorkspace scans a row selected with workspaceColumns into workspace
+func scanWorkspace(row rowScanner, workspace *domain.Workspace, extra ...interface{}) error {
+	return row.Scan(append([]interface{}{
+		&workspace.ID,
+		&workspace.UUID,
+		&workspace.Name,
+		&workspace.OwnerID,
+		&workspace.Personal,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+		&workspace.CreatedAtUnix,
+		&workspace.UpdatedAtUnix,
+	}, extra...)...)
+}
+
+// scanInvitation scans a row selected with invitationColumns into invitation
+func scanInvitation(row rowScanner, invitation *domain.WorkspaceInvitation) error {
+	return row.Scan(
+		&invitation.ID,
+		&invitation.UUID,
+		&invitation.WorkspaceID,
+		&invitation.WorkspaceName,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+		&invitation.CreatedAtUnix,
+	)
+}
+
+// Create creates a new workspace with its owner as the first member, in a single transaction
+func (r *workspaceRepository) Create(workspace *domain.Workspace) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO workspaces (name, owner_id, personal, created_at_unix, updated_at_unix)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, uuid, created_at, updated_at
+	`
+
+	now := time.Now().Unix()
+	err = tx.QueryRow(
+		query,
+		workspace.Name,
+		workspace.OwnerID,
+		workspace.Personal,
+		now,
+		now,
+	).Scan(&workspace.ID, &workspace.UUID, &workspace.CreatedAt, &workspace.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	memberQuery := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at_unix)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(memberQuery, workspace.ID, workspace.OwnerID, domain.WorkspaceOwner, now); err != nil {
+		return fmt.Errorf("failed to add workspace owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	workspace.Role = domain.WorkspaceOwner
+	workspace.CreatedAtUnix = now
+	workspace.UpdatedAtUnix = now
+
+	return nil
+}
+
+// FindByID finds workspace by ID
+func (r *workspaceRepository) FindByID(id int) (*domain.Workspace, error) {
+	workspace := &domain.Workspace{}
+	err := scanWorkspace(r.db.QueryRow(`SELECT `+workspaceColumns+` FROM workspaces w WHERE w.id = $1`, id), workspace)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find workspace: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// FindByUserID finds the workspaces the user is a member of with the user's role, personal first
+func (r *workspaceRepository) FindByUserID(userID int) ([]domain.Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.personal DESC, w.name ASC, w.id ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []domain.Workspace{}
+	for rows.Next() {
+		var workspace domain.Workspace
+		if err := scanWorkspace(rows, &workspace, &workspace.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// FindPersonal finds the user's personal workspace, or nil if there is none
+func (r *workspaceRepository) FindPersonal(userID int) (*domain.Workspace, error) {
+	workspace := &domain.Workspace{}
+	err := scanWorkspace(r.db.QueryRow(`SELECT `+workspaceColumns+` FROM workspaces w WHERE w.owner_id = $1 AND w.personal`, userID), workspace)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find workspace: %w", err)
+	}
+
+	workspace.Role = domain.WorkspaceOwner
+	return workspace, nil
+}
+
+// Update updates workspace
+func (r *workspaceRepository) Update(workspace *domain.Workspace) error {
+	query := `
+		UPDATE workspaces
+		SET name = $1, updated_at = NOW(), updated_at_unix = $2
+		WHERE id = $3
+		RETURNING updated_at
+	`
+
+	now := time.Now().Unix()
+	if err := r.db.QueryRow(query, workspace.Name, now, workspace.ID).Scan(&workspace.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	workspace.UpdatedAtUnix = now
+	return nil
+}
+
+// memberQuery selects workspace members with their user details
+const memberQuery = `
+	SELECT m.workspace_id, m.user_id, u.email, u.full_name, m.role, m.created_at, m.created_at_unix
+	FROM workspace_members m
+	JOIN users u ON u.id = m.user_id
+`
+
+// scanMember scans a row selected with memberQuery into member
+func scanMember(row rowScanner, member *domain.WorkspaceMember) error {
+	return row.Scan(
+		&member.WorkspaceID,
+		&member.UserID,
+		&member.Email,
+		&member.FullName,
+		&member.Role,
+		&member.CreatedAt,
+		&member.CreatedAtUnix,
+	)
+}
+
+// FindMember finds the user's membership of a workspace, or nil if the user is not a member
+func (r *workspaceRepository) FindMember(workspaceID, userID int) (*domain.WorkspaceMember, error) {
+	member := &domain.WorkspaceMember{}
+	err := scanMember(r.db.QueryRow(memberQuery+` WHERE m.workspace_id = $1 AND m.user_id = $2`, workspaceID, userID), member)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find workspace member: %w", err)
+	}
+
+	return member, nil
+}
+
+// FindMembers finds the members of a workspace, owner first
+func (r *workspaceRepository) FindMembers(workspaceID int) ([]domain.WorkspaceMember, error) {
+	query := memberQuery + `
+		WHERE m.workspace_id = $1
+		ORDER BY m.role = 'owner' DESC, u.full_name ASC, m.user_id ASC
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.WorkspaceMember{}
+	for rows.Next() {
+		var member domain.WorkspaceMember
+		if err := scanMember(rows, &member); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate workspace members: %w", err)
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole changes the role of a workspace member
+func (r *workspaceRepository) UpdateMemberRole(workspaceID, userID int, role domain.WorkspaceRole) error {
+	query := `UPDATE workspace_members SET role = $1 WHERE workspace_id = $2 AND user_id = $3`
+
+	result, err := r.db.Exec(query, role, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update workspace member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace member not found")
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from a workspace; receipts they added stay in the workspace
+func (r *workspaceRepository) RemoveMember(workspaceID, userID int) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace member not found")
+	}
+
+	return nil
+}
+
+// CreateInvitation creates a new invitation
+func (r *workspaceRepository) CreateInvitation(invitation *domain.WorkspaceInvitation) error {
+	query := `
+		INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at, created_at_unix)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, uuid, created_at
+	`
+
+	now := time.Now().Unix()
+	err := r.db.QueryRow(
+		query,
+		invitation.WorkspaceID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+		now,
+	).Scan(&invitation.ID, &invitation.UUID, &invitation.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	invitation.CreatedAtUnix = now
+	return nil
+}
+
+// findInvitation runs an invitation query expected to return one row
+func (r *workspaceRepository) findInvitation(query string, args ...interface{}) (*domain.WorkspaceInvitation, error) {
+	invitation := &domain.WorkspaceInvitation{}
+	err := scanInvitation(r.db.QueryRow(query, args...), invitation)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invitation not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// queryInvitations runs an invitation query and scans every row
+func (r *workspaceRepository) queryInvitations(query string, args ...interface{}) ([]domain.WorkspaceInvitation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []domain.WorkspaceInvitation{}
+	for rows.Next() {
+		var invitation domain.WorkspaceInvitation
+		if err := scanInvitation(rows, &invitation); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// FindInvitationByID finds invitation by ID
+func (r *workspaceRepository) FindInvitationByID(id int) (*domain.WorkspaceInvitation, error) {
+	return r.findInvitation(`SELECT `+invitationColumns+` FROM workspace_invitations i JOIN workspaces w ON w.id = i.workspace_id WHERE i.id = $1`, id)
+}
+
+// FindInvitationByTokenHash finds the invitation with the token hash
+func (r *workspaceRepository) FindInvitationByTokenHash(tokenHash string) (*domain.WorkspaceInvitation, error) {
+	return r.findInvitation(`SELECT `+invitationColumns+` FROM workspace_invitations i JOIN workspaces w ON w.id = i.workspace_id WHERE i.token_hash = $1`, tokenHash)
+}
+
+// FindPendingInvitations finds the unaccepted, unexpired invitations of a workspace
+func (r *workspaceRepository) FindPendingInvitations(workspaceID int) ([]domain.WorkspaceInvitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM workspace_invitations i
+		JOIN workspaces w ON w.id = i.workspace_id
+		WHERE i.workspace_id = $1 AND i.accepted_at IS NULL AND i.expires_at > NOW()
+		ORDER BY i.created_at DESC
+	`
+	return r.queryInvitations(query, workspaceID)
+}
+
+// FindInvitationsByEmail finds the unaccepted, unexpired invitations sent to an email address
+func (r *workspaceRepository) FindInvitationsByEmail(email string) ([]domain.WorkspaceInvitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM workspace_invitations i
+		JOIN workspaces w ON w.id = i.workspace_id
+		WHERE LOWER(i.email) = LOWER($1) AND i.accepted_at IS NULL AND i.expires_at > NOW()
+		ORDER BY i.created_at DESC
+	`
+	return r.queryInvitations(query, email)
+}
+
+// AcceptInvitation marks an invitation accepted and adds the user to its workspace with the
+// invited role, in a single transaction. A user who is already a member keeps their role.
+func (r *workspaceRepository) AcceptInvitation(invitation *domain.WorkspaceInvitation, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`UPDATE workspace_invitations SET accepted_at = $1 WHERE id = $2 AND accepted_at IS NULL`, now, invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("invitation already accepted")
+	}
+
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at_unix)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`
+	if _, err := tx.Exec(query, invitation.WorkspaceID, userID, invitation.Role, now.Unix()); err != nil {
+		return fmt.Errorf("failed to add workspace member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	invitation.AcceptedAt = sql.NullTime{Time: now, Valid: true}
+	return nil
+}
+
+// DeleteInvitation deletes an invitation
+func (r *workspaceRepository) DeleteInvitation(id int) error {
+	result, err := r.db.Exec(`DELETE FROM workspace_invitations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("invitation not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/anomaly"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type AnomalyService interface {
+	DetectAnomalies(userID int, workspaceID int) (*domain.AnomalyDetectionResult, error)
+	DetectWorkspaceAnomalies(workspaceID int) (*domain.AnomalyDetectionResult, error)
+	GetAnomalies(userID int, workspaceID int, status domain.AnomalyStatus, page, limit int) ([]domain.Anomaly, int64, error)
+	UpdateAnomaly(id int, userID int, req domain.UpdateAnomalyRequest) (*domain.Anomaly, error)
+}
+
+type anomalyService struct {
+	anomalyRepo   repository.AnomalyRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewAnomalyService creates a new anomaly service
+func NewAnomalyService(anomalyRepo repository.AnomalyRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) AnomalyService {
+	return &anomalyService{
+		anomalyRepo:   anomalyRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// DetectAnomalies runs detection over the workspace, 0 being the user's personal workspace
+func (s *anomalyService) DetectAnomalies(userID int, workspaceID int) (*domain.AnomalyDetectionResult, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.DetectWorkspaceAnomalies(member.WorkspaceID)
+}
+
+// DetectWorkspaceAnomalies recomputes the workspace's baselines from its completed receipts and
+// records the outliers. Anomalies found by earlier runs are kept as they are, including their
+// status. It does not check access, for scheduled runs over every workspace.
+func (s *anomalyService) DetectWorkspaceAnomalies(workspaceID int) (*domain.AnomalyDetectionResult, error) {
+	history, err := s.anomalyRepo.FindHistory(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := anomaly.Detect(history)
+	created, err := s.anomalyRepo.CreateBatch(anomalies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AnomalyDetectionResult{
+		Checked: len(history),
+		Found:   len(anomalies),
+		Created: created,
+	}, nil
+}
+
+// GetAnomalies returns a page of the workspace's anomaly feed, newest first. Without a status it
+// lists everything not dismissed.
+func (s *anomalyService) GetAnomalies(userID int, workspaceID int, status domain.AnomalyStatus, page, limit int) ([]domain.Anomaly, int64, error) {
+	switch status {
+	case "", domain.AnomalyNew, domain.AnomalySeen, domain.AnomalyDismissed:
+	default:
+		return nil, 0, fmt.Errorf("invalid anomaly status: %s", status)
+	}
+
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.anomalyRepo.FindByWorkspaceID(member.WorkspaceID, status, page, limit)
+}
+
+// UpdateAnomaly marks an anomaly of a workspace the user can edit as seen or dismisses it
+func (s *anomalyService) UpdateAnomaly(id int, userID int, req domain.UpdateAnomalyRequest) (*domain.Anomaly, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	item, err := s.anomalyRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, item.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.anomalyRepo.UpdateStatus(item.ID, req.Status); err != nil {
+		return nil, err
+	}
+
+	item.Status = req.Status
+	return item, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/storage"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+type AttachmentService interface {
+	GetAttachments(receiptID int, userID int) ([]domain.Attachment, error)
+	UploadAttachment(receiptID int, userID int, kind domain.AttachmentKind, filename string, r io.Reader) (*domain.Attachment, error)
+	OpenAttachment(receiptID int, id int, userID int) (*domain.Attachment, io.ReadCloser, error)
+	DeleteAttachment(receiptID int, id int, userID int) error
+}
+
+const (
+	// MaxAttachmentSize is the largest attachment accepted, in bytes
+	MaxAttachmentSize = 20 << 20
+	// maxAttachments is the most attachments a receipt can have
+	maxAttachments = 20
+)
+
+// attachmentExtensions maps the accepted content types to the extension of their stored file.
+// Pages must be images; documents may also be PDFs.
+var attachmentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+type attachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	receiptRepo    repository.ReceiptRepository
+	expenseRepo    repository.ExpenseReportRepository
+	workspaceRepo  repository.WorkspaceRepository
+	fileStore      storage.FileStore
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(attachmentRepo repository.AttachmentRepository, receiptRepo repository.ReceiptRepository, expenseRepo repository.ExpenseReportRepository, workspaceRepo repository.WorkspaceRepository, fileStore storage.FileStore) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		receiptRepo:    receiptRepo,
+		expenseRepo:    expenseRepo,
+		workspaceRepo:  workspaceRepo,
+		fileStore:      fileStore,
+	}
+}
+
+// GetAttachments lists the attachments of a receipt the user can read, pages first
+func (s *attachmentService) GetAttachments(receiptID int, userID int) ([]domain.Attachment, error) {
+	if _, err := s.receiptAccess(receiptID, userID, false); err != nil {
+		return nil, err
+	}
+
+	return s.attachmentRepo.FindByReceiptID(receiptID)
+}
+
+// UploadAttachment stores a file as the next page or supporting document of a receipt, unless
+// the receipt is locked by a submitted expense report. The content type is detected from the
+// content rather than trusted from the upload, and a file already attached to the receipt, or
+// its own image, is rejected.
+func (s *attachmentService) UploadAttachment(receiptID int, userID int, kind domain.AttachmentKind, filename string, r io.Reader) (*domain.Attachment, error) {
+	if kind == "" {
+		kind = domain.AttachmentPage
+	}
+	if kind != domain.AttachmentPage && kind != domain.AttachmentDocument {
+		return nil, fmt.Errorf("invalid attachment kind %q", kind)
+	}
+
+	receipt, err := s.receiptAccess(receiptID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureUnlocked(s.expenseRepo, receiptID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("attachment is empty")
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, fmt.Errorf("attachment must be at most %d MB", MaxAttachmentSize>>20)
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := attachmentExtensions[contentType]
+	if !ok || (kind == domain.AttachmentPage && !strings.HasPrefix(contentType, "image/")) {
+		return nil, fmt.Errorf("unsupported %s content type %s", kind, contentType)
+	}
+
+	checksum, err := utils.HashContent(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt.ImageHash.Valid && receipt.ImageHash.String == checksum {
+		return nil, fmt.Errorf("attachment is the receipt image")
+	}
+
+	existing, err := s.attachmentRepo.FindByReceiptID(receiptID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAttachments {
+		return nil, fmt.Errorf("receipt already has the maximum of %d attachments", maxAttachments)
+	}
+	for _, other := range existing {
+		if other.Checksum == checksum {
+			return nil, fmt.Errorf("attachment already uploaded as %s %d", other.Kind, other.Position)
+		}
+	}
+
+	attachment := &domain.Attachment{
+		UUID:        uuid.New(),
+		ReceiptID:   receiptID,
+		UserID:      userID,
+		Kind:        kind,
+		Filename:    filepath.Base(filename),
+		ContentType: contentType,
+		Size:        len(data),
+		Checksum:    checksum,
+	}
+	attachment.StorageKey = fmt.Sprintf("receipts/%s/attachments/%s%s", receipt.UUID, attachment.UUID, extension)
+
+	if err := s.fileStore.Put(attachment.StorageKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		s.fileStore.Delete(attachment.StorageKey)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// OpenAttachment opens the file of an attachment of a receipt the user can read
+func (s *attachmentService) OpenAttachment(receiptID int, id int, userID int) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.getAttachment(receiptID, id, userID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.fileStore.Open(attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, file, nil
+}
+
+// DeleteAttachment deletes an attachment and its file, unless the receipt is locked by a
+// submitted expense report. Later attachments keep their positions.
+func (s *attachmentService) DeleteAttachment(receiptID int, id int, userID int) error {
+	attachment, err := s.getAttachment(receiptID, id, userID, true)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureUnlocked(s.expenseRepo, receiptID); err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.Delete(attachment.ID); err != nil {
+		return err
+	}
+
+	// The attachment is gone once its row is deleted; a file left behind is only wasted space
+	s.fileStore.Delete(attachment.StorageKey)
+	return nil
+}
+
+// getAttachment loads an attachment of a receipt the user can access, with edit rights if asked
+func (s *attachmentService) getAttachment(receiptID int, id int, userID int, edit bool) (*domain.Attachment, error) {
+	if _, err := s.receiptAccess(receiptID, userID, edit); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.ReceiptID != receiptID {
+		return nil, fmt.Errorf("attachment not found")
+	}
+
+	return attachment, nil
+}
+
+// receiptAccess loads a receipt and checks the user is a member of its workspace, with edit
+// rights if asked
+func (s *attachmentService) receiptAccess(receiptID int, userID int, edit bool) (*domain.Receipt, error) {
+	receipt, err := s.receiptRepo.FindByID(receiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, edit); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
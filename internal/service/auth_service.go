@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
@@ -44,11 +45,17 @@ func (s *authService) Register(req domain.CreateUserRequest) (*domain.User, erro
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	homeCurrency := strings.ToUpper(req.HomeCurrency)
+	if homeCurrency == "" {
+		homeCurrency = domain.DefaultCurrency
+	}
+
 	// Create user
 	user := &domain.User{
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
 		FullName:     req.FullName,
+		HomeCurrency: homeCurrency,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
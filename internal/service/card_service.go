@@ -0,0 +1,104 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/extraction"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type CardService interface {
+	CreateCard(userID int, req domain.CreateCardRequest) (*domain.Card, int64, error)
+	GetCards(userID int) ([]domain.Card, error)
+	DeleteCard(id int, userID int) error
+}
+
+type cardService struct {
+	cardRepo  repository.CardRepository
+	validator *utils.Validator
+}
+
+// NewCardService creates a new card service
+func NewCardService(cardRepo repository.CardRepository, validator *utils.Validator) CardService {
+	return &cardService{
+		cardRepo:  cardRepo,
+		validator: validator,
+	}
+}
+
+// CreateCard registers a card and links the user's existing receipts paid with it.
+// It returns the card and the number of receipts linked.
+func (s *cardService) CreateCard(userID int, req domain.CreateCardRequest) (*domain.Card, int64, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, 0, err
+	}
+
+	card := &domain.Card{
+		UserID:   userID,
+		Nickname: strings.TrimSpace(req.Nickname),
+		Brand:    extraction.NormalizeCardBrand(req.Brand),
+		Last4:    req.Last4,
+	}
+
+	existing, err := s.cardRepo.FindMatching(userID, card.Last4, card.Brand)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range existing {
+		if other.Brand == card.Brand {
+			return nil, 0, fmt.Errorf("card already registered")
+		}
+	}
+
+	if err := s.cardRepo.Create(card); err != nil {
+		return nil, 0, err
+	}
+
+	linked, err := s.cardRepo.LinkReceipts(card)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return card, linked, nil
+}
+
+// GetCards lists the user's cards
+func (s *cardService) GetCards(userID int) ([]domain.Card, error) {
+	return s.cardRepo.FindByUserID(userID)
+}
+
+// DeleteCard deletes a card; receipts paid with it keep their card details but are unlinked
+func (s *cardService) DeleteCard(id int, userID int) error {
+	card, err := s.cardRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if card.UserID != userID {
+		return fmt.Errorf("unauthorized access")
+	}
+
+	return s.cardRepo.Delete(id)
+}
+
+// linkCard links a card receipt to the user's registered card with the same last four digits
+// and brand. Receipts matching no card or several cards are left unlinked.
+func linkCard(cardRepo repository.CardRepository, receipt *domain.Receipt) error {
+	if !receipt.CardLast4.Valid {
+		return nil
+	}
+
+	cards, err := cardRepo.FindMatching(receipt.UserID, receipt.CardLast4.String, receipt.CardBrand.String)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 1 {
+		receipt.CardID = sql.NullInt64{Int64: int64(cards[0].ID), Valid: true}
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/document"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/extraction"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/storage"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+type DocumentService interface {
+	IngestDocument(userID int, workspaceID int, filename string, r io.Reader) (*domain.ReceiptWithItems, error)
+}
+
+// documentType is the receipt format of an accepted content type and the extension its
+// original is stored with
+type documentType struct {
+	source    domain.ReceiptSource
+	extension string
+}
+
+// documentTypes maps the content types of accepted receipt photos and e-receipts to their format
+var documentTypes = map[string]documentType{
+	"image/jpeg":               {domain.SourceImage, ".jpg"},
+	"image/png":                {domain.SourceImage, ".png"},
+	"image/webp":               {domain.SourceImage, ".webp"},
+	"application/pdf":          {domain.SourcePDF, ".pdf"},
+	"text/html; charset=utf-8": {domain.SourceHTML, ".html"},
+}
+
+type documentService struct {
+	receiptService    ReceiptService
+	attachmentService AttachmentService
+	receiptRepo       repository.ReceiptRepository
+	fileStore         storage.FileStore
+	rasterizer        document.Rasterizer
+}
+
+// NewDocumentService creates a new document service. Without a rasterizer, PDFs that have no
+// text layer are stored without page images.
+func NewDocumentService(receiptService ReceiptService, attachmentService AttachmentService, receiptRepo repository.ReceiptRepository, fileStore storage.FileStore, rasterizer document.Rasterizer) DocumentService {
+	return &documentService{
+		receiptService:    receiptService,
+		attachmentService: attachmentService,
+		receiptRepo:       receiptRepo,
+		fileStore:         fileStore,
+		rasterizer:        rasterizer,
+	}
+}
+
+// IngestDocument creates a receipt in the workspace from a receipt photo or a PDF or HTML
+// e-receipt, 0 being the user's personal workspace. The receipt is read from the PDF's text
+// layer or the HTML's text and item table, and the original file is stored as the receipt's
+// file; a file uploaded before is flagged as a duplicate by its content hash, and a copy of a
+// photo by its perceptual hash. Photos, and PDFs without text such as scans, are left pending
+// until their pages are extracted; the pages of such PDFs are rendered into page attachments.
+func (s *documentService) IngestDocument(userID int, workspaceID int, filename string, r io.Reader) (*domain.ReceiptWithItems, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, fmt.Errorf("document must be at most %d MB", MaxAttachmentSize>>20)
+	}
+
+	contentType, kind, err := documentKind(data, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	req, extracted, err := extractDocument(kind.source, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The content hash finds exact copies of a file uploaded before
+	checksum, err := utils.HashContent(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("receipts/documents/%s%s", uuid.New(), kind.extension)
+	if err := s.fileStore.Put(key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	image := domain.ReceiptImage{
+		URL:      s.fileStore.URL(key),
+		Filename: filepath.Base(filename),
+		Size:     len(data),
+		Hash:     checksum,
+		Source:   kind.source,
+	}
+
+	// The perceptual hash finds re-compressed or re-photographed copies of a photo. WebP photos
+	// cannot be decoded, so only their exact copies are found.
+	if kind.source == domain.SourceImage {
+		if hash, err := utils.PerceptualHash(bytes.NewReader(data)); err == nil {
+			image.PerceptualHash = sql.NullInt64{Int64: int64(hash), Valid: true}
+		}
+	}
+
+	receipt, err := s.receiptService.CreateReceipt(userID, workspaceID, req, image)
+	if err != nil {
+		s.fileStore.Delete(key)
+		return nil, err
+	}
+
+	if !extracted {
+		receipt.Status = domain.StatusPending
+		if err := s.receiptRepo.Update(&receipt.Receipt); err != nil {
+			return nil, err
+		}
+		if kind.source == domain.SourcePDF {
+			receipt.Warnings = append(receipt.Warnings, s.renderPages(receipt.ID, userID, filename, data)...)
+		}
+	}
+
+	return receipt, nil
+}
+
+// renderPages attaches images of the pages of a PDF without text to its receipt for extraction,
+// returning warnings for what could not be done
+func (s *documentService) renderPages(receiptID int, userID int, filename string, data []byte) []string {
+	warnings := []string{"document has no text layer; its pages need to be extracted"}
+	if s.rasterizer == nil {
+		return append(warnings, "pages were not rendered because no PDF rasterizer is installed")
+	}
+
+	pages, err := s.rasterizer.Rasterize(data, maxAttachments)
+	if err != nil {
+		return append(warnings, err.Error())
+	}
+
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	for i, page := range pages {
+		name := fmt.Sprintf("%s-page-%d.png", base, i+1)
+		if _, err := s.attachmentService.UploadAttachment(receiptID, userID, domain.AttachmentPage, name, bytes.NewReader(page)); err != nil {
+			warnings = append(warnings, fmt.Sprintf("page %d: %s", i+1, err))
+		}
+	}
+
+	return warnings
+}
+
+// documentKind detects the content type and format of an upload from its content. HTML
+// without the markup that identifies it, such as a fragment of an e-mail, is recognized by its
+// file extension.
+func documentKind(data []byte, filename string) (string, documentType, error) {
+	contentType := http.DetectContentType(data)
+	if strings.HasPrefix(contentType, "text/plain") {
+		if extension := strings.ToLower(filepath.Ext(filename)); extension == ".html" || extension == ".htm" {
+			contentType = "text/html; charset=utf-8"
+		}
+	}
+
+	kind, ok := documentTypes[contentType]
+	if !ok {
+		return "", documentType{}, fmt.Errorf("unsupported document content type %s", contentType)
+	}
+	return contentType, kind, nil
+}
+
+// extractDocument reads a receipt from a document with the extractor for its format. It
+// reports false for photos and PDFs without text, whose receipt only has the file's details.
+func extractDocument(source domain.ReceiptSource, data []byte) (domain.CreateReceiptRequest, bool, error) {
+	switch source {
+	case domain.SourceImage:
+		return domain.CreateReceiptRequest{}, false, nil
+	case domain.SourceHTML:
+		doc, err := document.ParseHTML(data)
+		if err != nil {
+			return domain.CreateReceiptRequest{}, false, err
+		}
+		return extraction.ExtractHTML(doc), true, nil
+	}
+
+	pages, err := document.PDFText(data)
+	if err != nil {
+		return domain.CreateReceiptRequest{}, false, err
+	}
+	req, ok := extraction.ExtractPDF(pages)
+	return req, ok, nil
+}
@@ -0,0 +1,539 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type ExpenseReportService interface {
+	CreateReport(userID int, workspaceID int, req domain.CreateExpenseReportRequest) (*domain.ExpenseReport, error)
+	GetReports(userID int, workspaceID int, status domain.ExpenseReportStatus) ([]domain.ExpenseReport, error)
+	GetReport(id int, userID int) (*domain.ExpenseReportWithReceipts, error)
+	UpdateReport(id int, userID int, req domain.CreateExpenseReportRequest) (*domain.ExpenseReport, error)
+	DeleteReport(id int, userID int) error
+	AddReceipts(id int, userID int, req domain.ExpenseReceiptsRequest) (*domain.ExpenseReportWithReceipts, error)
+	RemoveReceipt(id int, receiptID int, userID int) error
+	SetApprovers(id int, userID int, req domain.ExpenseApproversRequest) (*domain.ExpenseReport, error)
+	SubmitReport(id int, userID int) (*domain.ExpenseReport, error)
+	ApproveReport(id int, userID int, req domain.ReviewExpenseReportRequest) (*domain.ExpenseReport, error)
+	RejectReport(id int, userID int, req domain.ReviewExpenseReportRequest) (*domain.ExpenseReport, error)
+	MarkPaid(id int, userID int) (*domain.ExpenseReport, error)
+	AddComment(id int, userID int, req domain.CreateExpenseCommentRequest) (*domain.ExpenseComment, error)
+	GetSummary(id int, userID int) (*domain.ExpenseReportSummary, error)
+}
+
+type expenseReportService struct {
+	expenseRepo   repository.ExpenseReportRepository
+	receiptRepo   repository.ReceiptRepository
+	userRepo      repository.UserRepository
+	rateRepo      repository.ExchangeRateRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewExpenseReportService creates a new expense report service
+func NewExpenseReportService(expenseRepo repository.ExpenseReportRepository, receiptRepo repository.ReceiptRepository, userRepo repository.UserRepository, rateRepo repository.ExchangeRateRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) ExpenseReportService {
+	return &expenseReportService{
+		expenseRepo:   expenseRepo,
+		receiptRepo:   receiptRepo,
+		userRepo:      userRepo,
+		rateRepo:      rateRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// ensureUnlocked returns an error when a receipt is on an expense report that has been
+// submitted, approved or paid, which freezes the receipt until the report is rejected
+func ensureUnlocked(expenseRepo repository.ExpenseReportRepository, receiptID int) error {
+	report, err := expenseRepo.FindByReceiptID(receiptID)
+	if err != nil {
+		return err
+	}
+
+	if report != nil && !report.Status.Editable() {
+		return fmt.Errorf("receipt is locked by %s expense report %d", report.Status, report.ID)
+	}
+
+	return nil
+}
+
+// CreateReport creates a draft expense report in a workspace, 0 being the user's personal workspace
+func (s *expenseReportService) CreateReport(userID int, workspaceID int, req domain.CreateExpenseReportRequest) (*domain.ExpenseReport, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ExpenseReport{
+		WorkspaceID: member.WorkspaceID,
+		UserID:      userID,
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Status:      domain.ExpenseDraft,
+	}
+
+	if err := s.expenseRepo.Create(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetReports lists the workspace's expense reports the user submitted or approves, or every
+// report for the workspace owner, optionally only those with a status
+func (s *expenseReportService) GetReports(userID int, workspaceID int, status domain.ExpenseReportStatus) ([]domain.ExpenseReport, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	visibleTo := userID
+	if member.Role == domain.WorkspaceOwner {
+		visibleTo = 0
+	}
+
+	return s.expenseRepo.FindByWorkspaceID(member.WorkspaceID, visibleTo, status)
+}
+
+// GetReport gets an expense report with its receipts and comments
+func (s *expenseReportService) GetReport(id int, userID int) (*domain.ExpenseReportWithReceipts, error) {
+	report, _, err := s.getReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withReceipts(report)
+}
+
+// UpdateReport changes the title and description of a draft or rejected report
+func (s *expenseReportService) UpdateReport(id int, userID int, req domain.CreateExpenseReportRequest) (*domain.ExpenseReport, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	report, err := s.getEditableReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Title = strings.TrimSpace(req.Title)
+	report.Description = strings.TrimSpace(req.Description)
+
+	if err := s.expenseRepo.Update(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// DeleteReport deletes a draft or rejected report, releasing its receipts
+func (s *expenseReportService) DeleteReport(id int, userID int) error {
+	report, err := s.getEditableReport(id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.expenseRepo.Delete(report.ID)
+}
+
+// AddReceipts adds receipts the user added to the report's workspace. A receipt can be on one
+// report only.
+func (s *expenseReportService) AddReceipts(id int, userID int, req domain.ExpenseReceiptsRequest) (*domain.ExpenseReportWithReceipts, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	report, err := s.getEditableReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, receiptID := range req.ReceiptIDs {
+		receipt, err := s.receiptRepo.FindByID(receiptID)
+		if err != nil {
+			return nil, err
+		}
+		if receipt.WorkspaceID != report.WorkspaceID || receipt.UserID != userID {
+			return nil, fmt.Errorf("unauthorized access: receipt %d was not added by you to this workspace", receiptID)
+		}
+
+		existing, err := s.expenseRepo.FindByReceiptID(receiptID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != report.ID {
+			return nil, fmt.Errorf("receipt %d is already on expense report %d", receiptID, existing.ID)
+		}
+	}
+
+	if err := s.expenseRepo.AddReceipts(report.ID, req.ReceiptIDs); err != nil {
+		return nil, err
+	}
+
+	return s.withReceipts(report)
+}
+
+// RemoveReceipt removes a receipt from a draft or rejected report
+func (s *expenseReportService) RemoveReceipt(id int, receiptID int, userID int) error {
+	report, err := s.getEditableReport(id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.expenseRepo.RemoveReceipt(report.ID, receiptID)
+}
+
+// SetApprovers replaces who must approve a draft or rejected report. Approvers must be other
+// members of the workspace.
+func (s *expenseReportService) SetApprovers(id int, userID int, req domain.ExpenseApproversRequest) (*domain.ExpenseReport, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	report, err := s.getEditableReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, approverID := range req.UserIDs {
+		if approverID == userID {
+			return nil, fmt.Errorf("you cannot approve your own expense report")
+		}
+		member, err := s.workspaceRepo.FindMember(report.WorkspaceID, approverID)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil {
+			return nil, fmt.Errorf("approver %d is not a member of the workspace", approverID)
+		}
+	}
+
+	if err := s.expenseRepo.SetApprovers(report.ID, req.UserIDs); err != nil {
+		return nil, err
+	}
+
+	return s.expenseRepo.FindByID(report.ID)
+}
+
+// SubmitReport submits a draft or rejected report for approval, locking its receipts. Every
+// approver decides again on a resubmitted report.
+func (s *expenseReportService) SubmitReport(id int, userID int) (*domain.ExpenseReport, error) {
+	report, err := s.getEditableReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.ReceiptCount == 0 {
+		return nil, fmt.Errorf("expense report has no receipts")
+	}
+	if len(report.Approvers) == 0 {
+		return nil, fmt.Errorf("expense report has no approvers")
+	}
+
+	if err := s.expenseRepo.ResetDecisions(report.ID); err != nil {
+		return nil, err
+	}
+
+	report.Status = domain.ExpenseSubmitted
+	report.SubmittedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	report.DecidedAt = sql.NullTime{}
+
+	if err := s.expenseRepo.Update(report); err != nil {
+		return nil, err
+	}
+
+	return s.expenseRepo.FindByID(report.ID)
+}
+
+// ApproveReport records the user's approval of a submitted report, which is approved once every
+// approver has approved it
+func (s *expenseReportService) ApproveReport(id int, userID int, req domain.ReviewExpenseReportRequest) (*domain.ExpenseReport, error) {
+	report, err := s.review(id, userID, req, domain.ApprovalApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, approver := range report.Approvers {
+		if approver.Decision != domain.ApprovalApproved {
+			return report, nil
+		}
+	}
+
+	report.Status = domain.ExpenseApproved
+	report.DecidedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := s.expenseRepo.Update(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// RejectReport rejects a submitted report, unlocking its receipts so the submitter can fix and
+// resubmit it
+func (s *expenseReportService) RejectReport(id int, userID int, req domain.ReviewExpenseReportRequest) (*domain.ExpenseReport, error) {
+	report, err := s.review(id, userID, req, domain.ApprovalRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Status = domain.ExpenseRejected
+	report.DecidedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := s.expenseRepo.Update(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// review records an approver's decision on a submitted report with an optional comment and
+// returns the report with its updated approvers
+func (s *expenseReportService) review(id int, userID int, req domain.ReviewExpenseReportRequest, decision domain.ApprovalDecision) (*domain.ExpenseReport, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	report, _, err := s.getReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	approver := report.Approver(userID)
+	if approver == nil {
+		return nil, fmt.Errorf("unauthorized access: you are not an approver of this expense report")
+	}
+	if report.Status != domain.ExpenseSubmitted {
+		return nil, fmt.Errorf("expense report is %s, not submitted", report.Status)
+	}
+	if approver.Decision != domain.ApprovalPending {
+		return nil, fmt.Errorf("you already %s this expense report", approver.Decision)
+	}
+
+	if err := s.expenseRepo.UpdateDecision(report.ID, userID, decision); err != nil {
+		return nil, err
+	}
+
+	if comment := strings.TrimSpace(req.Comment); comment != "" {
+		if err := s.expenseRepo.CreateComment(&domain.ExpenseComment{ReportID: report.ID, UserID: userID, Body: comment}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.expenseRepo.FindByID(report.ID)
+}
+
+// MarkPaid records that an approved report has been reimbursed. Approvers and the workspace
+// owner may mark it paid.
+func (s *expenseReportService) MarkPaid(id int, userID int) (*domain.ExpenseReport, error) {
+	report, member, err := s.getReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Approver(userID) == nil && member.Role != domain.WorkspaceOwner {
+		return nil, fmt.Errorf("unauthorized access: only approvers and the workspace owner can mark a report paid")
+	}
+	if report.Status != domain.ExpenseApproved {
+		return nil, fmt.Errorf("expense report is %s, not approved", report.Status)
+	}
+
+	report.Status = domain.ExpensePaid
+	report.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := s.expenseRepo.Update(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// AddComment comments on a report the user can see
+func (s *expenseReportService) AddComment(id int, userID int, req domain.CreateExpenseCommentRequest) (*domain.ExpenseComment, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	report, _, err := s.getReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &domain.ExpenseComment{ReportID: report.ID, UserID: userID, Body: strings.TrimSpace(req.Body)}
+	if err := s.expenseRepo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+
+	if user, err := s.userRepo.FindByID(userID); err == nil {
+		comment.FullName = user.FullName
+	}
+
+	return comment, nil
+}
+
+// GetSummary totals a report for reimbursement in the submitter's home currency, converting each
+// receipt at the exchange rate of its purchase day
+func (s *expenseReportService) GetSummary(id int, userID int) (*domain.ExpenseReportSummary, error) {
+	report, _, err := s.getReport(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	submitter, err := s.userRepo.FindByID(report.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := s.expenseRepo.FindReceipts(report.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	home := submitter.HomeCurrency
+	rates, err := receiptRates(s.rateRepo, home, receipts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.ExpenseReportSummary{
+		ReportID:     report.ID,
+		Title:        report.Title,
+		Status:       report.Status,
+		Submitter:    submitter.FullName,
+		ReceiptCount: len(receipts),
+		ByCurrency:   []domain.Money{},
+		Total:        domain.NewMoney(0, home),
+		Unconverted:  []domain.Money{},
+		Lines:        []domain.ExpenseReportLine{},
+	}
+
+	byCurrency := map[string]domain.Money{}
+	unconverted := map[string]domain.Money{}
+	for _, receipt := range receipts {
+		day := utils.ReceiptDay(receipt)
+		if summary.From == nil || day.Before(*summary.From) {
+			from := day
+			summary.From = &from
+		}
+		if summary.To == nil || day.After(*summary.To) {
+			to := day
+			summary.To = &to
+		}
+
+		paid := receipt.TotalSpending.Sub(receipt.TotalDiscount)
+		byCurrency[receipt.Currency] = addMoney(byCurrency, paid)
+
+		line := domain.ExpenseReportLine{
+			ReceiptID: receipt.ID,
+			Date:      day,
+			StoreName: receipt.StoreName.String,
+			Amount:    paid,
+		}
+		if converted, ok := rates.Convert(paid, home, day); ok {
+			line.Converted = &converted
+			summary.Total = summary.Total.Add(converted)
+		} else {
+			unconverted[receipt.Currency] = addMoney(unconverted, paid)
+		}
+		summary.Lines = append(summary.Lines, line)
+	}
+
+	summary.ByCurrency = sortedMoney(byCurrency)
+	summary.Unconverted = sortedMoney(unconverted)
+
+	return summary, nil
+}
+
+// addMoney returns the total in totals for the amount's currency plus the amount
+func addMoney(totals map[string]domain.Money, amount domain.Money) domain.Money {
+	total, ok := totals[amount.Currency]
+	if !ok {
+		total = domain.NewMoney(0, amount.Currency)
+	}
+	return total.Add(amount)
+}
+
+// sortedMoney returns per-currency totals ordered by currency
+func sortedMoney(totals map[string]domain.Money) []domain.Money {
+	list := make([]domain.Money, 0, len(totals))
+	for _, total := range totals {
+		list = append(list, total)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Currency < list[j].Currency })
+	return list
+}
+
+// expenseReportAccess loads a report the user can see: the submitter, its approvers and the owner
+// of its workspace. It also returns the user's workspace membership.
+func expenseReportAccess(expenseRepo repository.ExpenseReportRepository, workspaceRepo repository.WorkspaceRepository, id int, userID int) (*domain.ExpenseReport, *domain.WorkspaceMember, error) {
+	report, err := expenseRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	member, err := workspaceAccess(workspaceRepo, userID, report.WorkspaceID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if report.UserID != userID && report.Approver(userID) == nil && member.Role != domain.WorkspaceOwner {
+		return nil, nil, fmt.Errorf("unauthorized access")
+	}
+
+	return report, member, nil
+}
+
+// getReport loads a report the user can see with the user's workspace membership
+func (s *expenseReportService) getReport(id int, userID int) (*domain.ExpenseReport, *domain.WorkspaceMember, error) {
+	return expenseReportAccess(s.expenseRepo, s.workspaceRepo, id, userID)
+}
+
+// getEditableReport loads a report of the user that is still a draft or was rejected
+func (s *expenseReportService) getEditableReport(id int, userID int) (*domain.ExpenseReport, error) {
+	report, err := s.expenseRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access")
+	}
+
+	if !report.Status.Editable() {
+		return nil, fmt.Errorf("expense report is %s and can no longer be changed", report.Status)
+	}
+
+	return report, nil
+}
+
+// withReceipts loads the receipts and comments of a report
+func (s *expenseReportService) withReceipts(report *domain.ExpenseReport) (*domain.ExpenseReportWithReceipts, error) {
+	receipts, err := s.expenseRepo.FindReceipts(report.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.expenseRepo.FindComments(report.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report.ReceiptCount = len(receipts)
+	return &domain.ExpenseReportWithReceipts{
+		ExpenseReport: *report,
+		Receipts:      receipts,
+		Comments:      comments,
+	}, nil
+}
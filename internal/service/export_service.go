@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/export"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+)
+
+type ExportService interface {
+	ExportReceipts(userID int, workspaceID int, filter domain.ReceiptFilter, format export.Format, w io.Writer) error
+}
+
+type exportService struct {
+	receiptRepo   repository.ReceiptRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+// NewExportService creates a new export service
+func NewExportService(receiptRepo repository.ReceiptRepository, workspaceRepo repository.WorkspaceRepository) ExportService {
+	return &exportService{receiptRepo: receiptRepo, workspaceRepo: workspaceRepo}
+}
+
+// ExportReceipts streams the workspace's filtered receipts to w in the given format.
+// Nothing is written when the user cannot read the workspace.
+func (s *exportService) ExportReceipts(userID int, workspaceID int, filter domain.ReceiptFilter, format export.Format, w io.Writer) error {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return err
+	}
+	filter.UserID = userID
+
+	writer, err := export.NewWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	err = s.receiptRepo.StreamItemRows(member.WorkspaceID, filter, func(row *domain.ReceiptItemRow) error {
+		return writer.WriteRow(row)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export receipts: %w", err)
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/importer"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// DefaultImportBatchSize is the number of receipts inserted per transaction
+const DefaultImportBatchSize = 100
+
+type ImportService interface {
+	ImportReceipts(userID int, workspaceID int, records []importer.Record, dryRun bool, batchSize int) (*domain.ImportReport, error)
+}
+
+type importService struct {
+	receiptRepo   repository.ReceiptRepository
+	userRepo      repository.UserRepository
+	cardRepo      repository.CardRepository
+	merchantRepo  repository.MerchantRepository
+	productRepo   repository.ProductRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewImportService creates a new import service
+func NewImportService(receiptRepo repository.ReceiptRepository, userRepo repository.UserRepository, cardRepo repository.CardRepository, merchantRepo repository.MerchantRepository, productRepo repository.ProductRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) ImportService {
+	return &importService{
+		receiptRepo:   receiptRepo,
+		userRepo:      userRepo,
+		cardRepo:      cardRepo,
+		merchantRepo:  merchantRepo,
+		productRepo:   productRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// ImportReceipts validates parsed records and inserts the valid ones in batches,
+// returning a per-row report. Receipts are added to the workspace, 0 being the user's personal
+// workspace. With dryRun nothing is persisted.
+func (s *importService) ImportReceipts(userID int, workspaceID int, records []importer.Record, dryRun bool, batchSize int) (*domain.ImportReport, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultImportBatchSize
+	}
+
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{
+		DryRun: dryRun,
+		Total:  len(records),
+		Rows:   make([]domain.ImportRowResult, len(records)),
+	}
+
+	// Validate and build receipts, remembering which report row each belongs to
+	var batch []domain.ReceiptWithItems
+	var batchRows []int
+
+	for i, record := range records {
+		report.Rows[i].Row = record.Row
+
+		receipt, err := s.buildReceipt(user, record)
+		if err != nil {
+			report.Rows[i].Error = err.Error()
+			continue
+		}
+		receipt.WorkspaceID = member.WorkspaceID
+
+		// Cards, merchants and products are only resolved for receipts that will be stored
+		if !dryRun {
+			if err := linkCard(s.cardRepo, &receipt.Receipt); err != nil {
+				return nil, err
+			}
+			if err := assignMerchant(s.merchantRepo, &receipt.Receipt); err != nil {
+				return nil, err
+			}
+			if err := assignProducts(s.productRepo, userID, member.WorkspaceID, receipt.Items); err != nil {
+				return nil, err
+			}
+		}
+
+		batch = append(batch, *receipt)
+		batchRows = append(batchRows, i)
+
+		if len(batch) == batchSize {
+			if err := s.insertBatch(report, batch, batchRows, dryRun); err != nil {
+				return nil, err
+			}
+			batch, batchRows = nil, nil
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := s.insertBatch(report, batch, batchRows, dryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range report.Rows {
+		if row.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// buildReceipt validates a record and converts it to a receipt with items owned by user
+func (s *importService) buildReceipt(user *domain.User, record importer.Record) (*domain.ReceiptWithItems, error) {
+	if record.Err != nil {
+		return nil, record.Err
+	}
+
+	if err := s.validator.Validate(record.Request); err != nil {
+		return nil, err
+	}
+
+	receipt, err := newReceipt(user.ID, record.Request, user.HomeCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := newItems(record.Request.Items, receipt.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments, err := newAdjustments(receiptAdjustments(record.Request), receipt.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ReceiptWithItems{
+		Receipt:     *receipt,
+		Items:       items,
+		Adjustments: adjustments,
+	}, nil
+}
+
+// insertBatch inserts one batch and records the outcome of each receipt in the report
+func (s *importService) insertBatch(report *domain.ImportReport, batch []domain.ReceiptWithItems, batchRows []int, dryRun bool) error {
+	rowErrors, err := s.receiptRepo.ImportBatch(batch, dryRun)
+	if err != nil {
+		return err
+	}
+
+	for j, i := range batchRows {
+		if rowErrors[j] != nil {
+			report.Rows[i].Error = rowErrors[j].Error()
+			continue
+		}
+
+		report.Rows[i].Success = true
+		if !dryRun {
+			report.Rows[i].ReceiptID = batch[j].ID
+			report.Rows[i].ReceiptUUID = batch[j].UUID.String()
+		}
+	}
+
+	return nil
+}
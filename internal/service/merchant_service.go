@@ -0,0 +1,253 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/merchant"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type MerchantService interface {
+	GetMerchants(userID int, workspaceID int) ([]domain.Merchant, error)
+	GetMerchantByID(id int, userID int) (*domain.Merchant, error)
+	UpdateMerchant(id int, userID int, req domain.UpdateMerchantRequest) (*domain.Merchant, error)
+	MergeMerchants(userID int, req domain.MergeMerchantsRequest) (*domain.Merchant, error)
+	MatchReceipts(userID int, workspaceID int) (int, error)
+	GetAllMerchants(adminID int, workspaceID int) ([]domain.Merchant, error)
+	AdminMergeMerchants(adminID int, req domain.MergeMerchantsRequest) (*domain.Merchant, error)
+}
+
+type merchantService struct {
+	merchantRepo  repository.MerchantRepository
+	userRepo      repository.UserRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewMerchantService creates a new merchant service
+func NewMerchantService(merchantRepo repository.MerchantRepository, userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) MerchantService {
+	return &merchantService{
+		merchantRepo:  merchantRepo,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// assignMerchant sets the merchant of a receipt from its store name, creating the merchant when
+// no existing one matches. A store name resolves to the workspace's merchant with the same normalized
+// alias, else the merchant with the receipt's phone number, else the merchant with the most similar
+// name. The normalized name is then recorded as an alias so the next receipt resolves directly.
+func assignMerchant(merchantRepo repository.MerchantRepository, receipt *domain.Receipt) error {
+	if !receipt.StoreName.Valid || strings.TrimSpace(receipt.StoreName.String) == "" {
+		receipt.MerchantID = sql.NullInt64{}
+		return nil
+	}
+
+	key := merchant.Key(receipt.StoreName.String)
+
+	found, err := merchantRepo.FindByAlias(receipt.WorkspaceID, key)
+	if err != nil {
+		return err
+	}
+
+	if found == nil && receipt.Phone.Valid {
+		if found, err = merchantRepo.FindByPhone(receipt.WorkspaceID, receipt.Phone.Int64); err != nil {
+			return err
+		}
+	}
+
+	if found == nil {
+		merchants, err := merchantRepo.FindByWorkspaceID(receipt.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		found = merchant.Closest(merchants, key)
+	}
+
+	if found == nil {
+		found = &domain.Merchant{
+			UserID:      receipt.UserID,
+			WorkspaceID: receipt.WorkspaceID,
+			Name:        merchant.DisplayName(receipt.StoreName.String),
+			Address:     receipt.Address,
+			Phone:       receipt.Phone,
+		}
+		if err := merchantRepo.Create(found); err != nil {
+			return err
+		}
+	}
+
+	if err := merchantRepo.AddAlias(found.ID, receipt.WorkspaceID, key); err != nil {
+		return err
+	}
+
+	receipt.MerchantID = sql.NullInt64{Int64: int64(found.ID), Valid: true}
+	return nil
+}
+
+// GetMerchants lists the merchants of the workspace, 0 being the user's personal workspace
+func (s *merchantService) GetMerchants(userID int, workspaceID int) ([]domain.Merchant, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.merchantRepo.FindByWorkspaceID(member.WorkspaceID)
+}
+
+// GetMerchantByID gets a merchant of a workspace the user is a member of
+func (s *merchantService) GetMerchantByID(id int, userID int) (*domain.Merchant, error) {
+	return s.merchantAccess(id, userID, false)
+}
+
+// merchantAccess loads a merchant and checks the user is a member of its workspace, with edit
+// rights if asked
+func (s *merchantService) merchantAccess(id int, userID int, edit bool) (*domain.Merchant, error) {
+	found, err := s.merchantRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, found.WorkspaceID, edit); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// UpdateMerchant renames a merchant or corrects its address and phone
+func (s *merchantService) UpdateMerchant(id int, userID int, req domain.UpdateMerchantRequest) (*domain.Merchant, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	found, err := s.merchantAccess(id, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	found.Name = strings.TrimSpace(req.Name)
+	found.Address = sql.NullString{String: req.Address, Valid: req.Address != ""}
+	found.Phone = nullInt64(req.Phone)
+
+	if err := s.merchantRepo.Update(found); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// MergeMerchants merges merchants of a workspace the user can edit into one
+func (s *merchantService) MergeMerchants(userID int, req domain.MergeMerchantsRequest) (*domain.Merchant, error) {
+	return s.merge(req, func(m *domain.Merchant) error {
+		_, err := workspaceAccess(s.workspaceRepo, userID, m.WorkspaceID, true)
+		return err
+	})
+}
+
+// MatchReceipts assigns merchants to the workspace's receipts that have a store name but no
+// merchant, such as receipts created before merchants existed, and returns how many were assigned
+func (s *merchantService) MatchReceipts(userID int, workspaceID int) (int, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	receipts, err := s.merchantRepo.FindUnassignedReceipts(member.WorkspaceID)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range receipts {
+		if err := assignMerchant(s.merchantRepo, &receipts[i]); err != nil {
+			return i, err
+		}
+		if err := s.merchantRepo.AssignReceipt(receipts[i].ID, int(receipts[i].MerchantID.Int64)); err != nil {
+			return i, err
+		}
+	}
+
+	return len(receipts), nil
+}
+
+// GetAllMerchants lists the merchants of one workspace, or of every workspace when workspaceID
+// is 0. Admin only.
+func (s *merchantService) GetAllMerchants(adminID int, workspaceID int) ([]domain.Merchant, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+
+	if workspaceID != 0 {
+		return s.merchantRepo.FindByWorkspaceID(workspaceID)
+	}
+	return s.merchantRepo.FindAll()
+}
+
+// AdminMergeMerchants merges merchants of any workspace into one. Admin only.
+func (s *merchantService) AdminMergeMerchants(adminID int, req domain.MergeMerchantsRequest) (*domain.Merchant, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+
+	return s.merge(req, func(*domain.Merchant) error { return nil })
+}
+
+// merge merges the source merchants into the target after checking the caller may access the
+// target. Sources must belong to the same workspace as the target.
+func (s *merchantService) merge(req domain.MergeMerchantsRequest, access func(*domain.Merchant) error) (*domain.Merchant, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	target, err := s.merchantRepo.FindByID(req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := access(target); err != nil {
+		return nil, err
+	}
+
+	var sourceIDs []int
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == target.ID {
+			continue
+		}
+		source, err := s.merchantRepo.FindByID(sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if source.WorkspaceID != target.WorkspaceID {
+			return nil, fmt.Errorf("unauthorized access: merchant %d belongs to another workspace", sourceID)
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("no merchants to merge")
+	}
+
+	if err := s.merchantRepo.Merge(target.ID, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	return s.merchantRepo.FindByID(target.ID)
+}
+
+// requireAdmin returns an error unless the user is an admin
+func (s *merchantService) requireAdmin(userID int) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return fmt.Errorf("unauthorized access: admin role required")
+	}
+
+	return nil
+}
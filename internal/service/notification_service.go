@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+)
+
+type NotificationService interface {
+	GetNotifications(userID int, unreadOnly bool, page, limit int) ([]domain.Notification, int64, error)
+	MarkRead(id int, userID int) error
+	MarkAllRead(userID int) (int64, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationService{notificationRepo: notificationRepo}
+}
+
+// GetNotifications returns a page of the user's notifications, newest first
+func (s *notificationService) GetNotifications(userID int, unreadOnly bool, page, limit int) ([]domain.Notification, int64, error) {
+	return s.notificationRepo.FindByUserID(userID, unreadOnly, page, limit)
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *notificationService) MarkRead(id int, userID int) error {
+	return s.notificationRepo.MarkRead(id, userID)
+}
+
+// MarkAllRead marks all of the user's notifications as read and returns how many were unread
+func (s *notificationService) MarkAllRead(userID int) (int64, error) {
+	return s.notificationRepo.MarkAllRead(userID)
+}
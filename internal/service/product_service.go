@@ -0,0 +1,328 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/product"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type ProductService interface {
+	GetProducts(userID int, workspaceID int) ([]domain.Product, error)
+	GetProductByID(id int, userID int) (*domain.Product, error)
+	UpdateProduct(id int, userID int, req domain.UpdateProductRequest) (*domain.Product, error)
+	MergeProducts(userID int, req domain.MergeProductsRequest) (*domain.Product, error)
+	MatchItems(userID int, workspaceID int) (int, error)
+	GetPriceHistory(id int, userID int) ([]domain.ProductPrice, error)
+	GetTopProducts(userID int, workspaceID int, filter domain.ReceiptFilter, limit int) ([]domain.TopProduct, error)
+}
+
+type productService struct {
+	productRepo   repository.ProductRepository
+	userRepo      repository.UserRepository
+	rateRepo      repository.ExchangeRateRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewProductService creates a new product service
+func NewProductService(productRepo repository.ProductRepository, userRepo repository.UserRepository, rateRepo repository.ExchangeRateRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) ProductService {
+	return &productService{
+		productRepo:   productRepo,
+		userRepo:      userRepo,
+		rateRepo:      rateRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// assignProducts sets the product of each item of the workspace from its name, creating products
+// for the user when no existing one matches. An item name resolves to the workspace's product
+// with the same normalized alias, else the product with the most similar name and the same pack
+// size. The normalized name is then recorded as an alias so the next item resolves directly.
+func assignProducts(productRepo repository.ProductRepository, userID int, workspaceID int, items []domain.Item) error {
+	var products []domain.Product
+	loaded := false
+
+	for i := range items {
+		if strings.TrimSpace(items[i].Name) == "" {
+			items[i].ProductID = sql.NullInt64{}
+			continue
+		}
+
+		key, size := product.Parse(items[i].Name)
+
+		found, err := productRepo.FindByAlias(workspaceID, key)
+		if err != nil {
+			return err
+		}
+
+		if found == nil {
+			if !loaded {
+				if products, err = productRepo.FindByWorkspaceID(workspaceID); err != nil {
+					return err
+				}
+				loaded = true
+			}
+			found = product.Closest(products, key, size)
+		}
+
+		if found == nil {
+			found = &domain.Product{
+				UserID:      userID,
+				WorkspaceID: workspaceID,
+				Name:        product.DisplayName(items[i].Name),
+				Unit:        sql.NullString{String: size, Valid: size != ""},
+			}
+			if err := productRepo.Create(found); err != nil {
+				return err
+			}
+			products = append(products, *found)
+		}
+
+		if err := productRepo.AddAlias(found.ID, workspaceID, key); err != nil {
+			return err
+		}
+
+		items[i].ProductID = sql.NullInt64{Int64: int64(found.ID), Valid: true}
+	}
+
+	return nil
+}
+
+// GetProducts lists the products of the workspace, 0 being the user's personal workspace
+func (s *productService) GetProducts(userID int, workspaceID int) ([]domain.Product, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.productRepo.FindByWorkspaceID(member.WorkspaceID)
+}
+
+// GetProductByID gets a product of a workspace the user is a member of
+func (s *productService) GetProductByID(id int, userID int) (*domain.Product, error) {
+	return s.productAccess(id, userID, false)
+}
+
+// productAccess loads a product and checks the user is a member of its workspace, with edit
+// rights if asked
+func (s *productService) productAccess(id int, userID int, edit bool) (*domain.Product, error) {
+	found, err := s.productRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, found.WorkspaceID, edit); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// UpdateProduct renames a product or sets its unit, barcode and SKU
+func (s *productService) UpdateProduct(id int, userID int, req domain.UpdateProductRequest) (*domain.Product, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	found, err := s.productAccess(id, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode := strings.TrimSpace(req.Barcode)
+	if barcode != "" {
+		other, err := s.productRepo.FindByBarcode(found.WorkspaceID, barcode)
+		if err != nil {
+			return nil, err
+		}
+		if other != nil && other.ID != found.ID {
+			return nil, fmt.Errorf("barcode already used by product %d", other.ID)
+		}
+	}
+
+	unit := strings.TrimSpace(req.Unit)
+	sku := strings.TrimSpace(req.SKU)
+
+	found.Name = strings.TrimSpace(req.Name)
+	found.Unit = sql.NullString{String: unit, Valid: unit != ""}
+	found.Barcode = sql.NullString{String: barcode, Valid: barcode != ""}
+	found.SKU = sql.NullString{String: sku, Valid: sku != ""}
+
+	if err := s.productRepo.Update(found); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// MergeProducts merges products of a workspace the user can edit into one
+func (s *productService) MergeProducts(userID int, req domain.MergeProductsRequest) (*domain.Product, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	target, err := s.productAccess(req.TargetID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceIDs []int
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == target.ID {
+			continue
+		}
+		source, err := s.productRepo.FindByID(sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if source.WorkspaceID != target.WorkspaceID {
+			return nil, fmt.Errorf("unauthorized access: product %d belongs to another workspace", sourceID)
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("no products to merge")
+	}
+
+	if err := s.productRepo.Merge(target.ID, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	return s.productRepo.FindByID(target.ID)
+}
+
+// MatchItems assigns products to the items of the workspace's receipts that have none, such as
+// items created before products existed, and returns how many were assigned
+func (s *productService) MatchItems(userID int, workspaceID int) (int, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	items, err := s.productRepo.FindUnassignedItems(member.WorkspaceID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := assignProducts(s.productRepo, userID, member.WorkspaceID, items); err != nil {
+		return 0, err
+	}
+
+	for i := range items {
+		if err := s.productRepo.AssignItem(items[i].ID, int(items[i].ProductID.Int64)); err != nil {
+			return i, err
+		}
+	}
+
+	return len(items), nil
+}
+
+// GetPriceHistory lists what the workspace paid for a product on each receipt, oldest first
+func (s *productService) GetPriceHistory(id int, userID int) ([]domain.ProductPrice, error) {
+	if _, err := s.GetProductByID(id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.productRepo.FindPriceHistory(id)
+}
+
+// GetTopProducts ranks the workspace's products by spending on receipts matching the filter,
+// converted to the user's home currency at the exchange rate of each purchase day
+func (s *productService) GetTopProducts(userID int, workspaceID int, filter domain.ReceiptFilter, limit int) ([]domain.TopProduct, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.productRepo.GetTotals(member.WorkspaceID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	home := user.HomeCurrency
+	currencies := []string{home}
+	seen := map[string]bool{home: true}
+	lastDay := time.Now()
+	for _, total := range totals {
+		if !seen[total.Currency] {
+			seen[total.Currency] = true
+			currencies = append(currencies, total.Currency)
+		}
+		if total.Day.After(lastDay) {
+			lastDay = total.Day
+		}
+	}
+
+	var rateList []domain.ExchangeRate
+	if len(currencies) > 1 {
+		if rateList, err = s.rateRepo.FindForCurrencies(currencies, lastDay); err != nil {
+			return nil, err
+		}
+	}
+	rates := domain.NewRateTable(rateList)
+
+	byProduct := map[int]*domain.TopProduct{}
+	for _, total := range totals {
+		entry, ok := byProduct[total.ProductID]
+		if !ok {
+			entry = &domain.TopProduct{
+				ProductID:     total.ProductID,
+				Name:          total.Name,
+				Unit:          total.Unit,
+				TotalSpending: domain.NewMoney(0, home),
+			}
+			byProduct[total.ProductID] = entry
+		}
+		entry.Quantity += total.Quantity
+		entry.Purchases += total.Purchases
+
+		spending, ok := rates.Convert(total.Total, home, total.Day)
+		if !ok {
+			if entry.Unconverted == nil {
+				entry.Unconverted = map[string]domain.Money{}
+			}
+			unconverted, ok := entry.Unconverted[total.Currency]
+			if !ok {
+				unconverted = domain.NewMoney(0, total.Currency)
+			}
+			entry.Unconverted[total.Currency] = unconverted.Add(total.Total)
+			continue
+		}
+		entry.TotalSpending = entry.TotalSpending.Add(spending)
+	}
+
+	top := make([]domain.TopProduct, 0, len(byProduct))
+	for _, entry := range byProduct {
+		top = append(top, *entry)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].TotalSpending.Amount != top[j].TotalSpending.Amount {
+			return top[i].TotalSpending.Amount > top[j].TotalSpending.Amount
+		}
+		if top[i].Quantity != top[j].Quantity {
+			return top[i].Quantity > top[j].Quantity
+		}
+		return top[i].ProductID < top[j].ProductID
+	})
+
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+
+	return top, nil
+}
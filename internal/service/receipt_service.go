@@ -3,86 +3,166 @@ package service
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/extraction"
 	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
 )
 
 type ReceiptService interface {
-	CreateReceipt(userID int, req domain.CreateReceiptRequest, imageURL string, filename string, fileSize int) (*domain.ReceiptWithItems, error)
+	CreateReceipt(userID int, workspaceID int, req domain.CreateReceiptRequest, image domain.ReceiptImage) (*domain.ReceiptWithItems, error)
 	GetReceiptByID(id int, userID int) (*domain.ReceiptWithItems, error)
-	GetReceiptsByUserID(userID int, page, limit int) ([]domain.Receipt, int64, error)
+	GetReceipts(userID int, workspaceID int, filter domain.ReceiptFilter, page, limit int) ([]domain.Receipt, int64, error)
+	GetReceiptsByCursor(userID int, workspaceID int, filter domain.ReceiptFilter, cursor string, limit int) (*domain.ReceiptPage, error)
+	SearchReceipts(userID int, workspaceID int, query string, page, limit int) ([]domain.ReceiptSearchResult, error)
 	UpdateReceipt(id int, userID int, req domain.CreateReceiptRequest) (*domain.ReceiptWithItems, error)
+	ApplyPageExtraction(id int, userID int, req domain.PageExtractionRequest) (*domain.ReceiptWithItems, error)
 	DeleteReceipt(id int, userID int) error
-	GetStatsByUserID(userID int) (map[string]interface{}, error)
+	GetStats(userID int, workspaceID int, filter domain.ReceiptFilter) (map[string]interface{}, error)
+	FindDuplicateImage(workspaceID int, imageHash string) (*domain.Receipt, error)
+	FindNearDuplicateImages(workspaceID int, perceptualHash int64) ([]domain.Receipt, error)
+	GetSuspectedDuplicates(userID int, workspaceID int) ([]domain.Receipt, error)
+	ConfirmDuplicate(id int, userID int) (*domain.Receipt, error)
+	DismissDuplicate(id int, userID int) (*domain.Receipt, error)
+	UpdateReceiptNotes(id int, userID int, req domain.UpdateNotesRequest) (*domain.Receipt, error)
+	UpdateItemNotes(itemID int, userID int, req domain.UpdateNotesRequest) (*domain.Item, error)
 }
 
+const (
+	// duplicateStoreSimilarity is the minimum store name similarity for a fuzzy duplicate
+	duplicateStoreSimilarity = 0.8
+	// duplicateItemSimilarity is the minimum Jaccard index of item names for a fuzzy duplicate
+	duplicateItemSimilarity = 0.7
+	// nearDuplicateMaxDistance is the maximum Hamming distance between perceptual hashes of near-duplicate images
+	nearDuplicateMaxDistance = 10
+	// duplicateTotalTolerance is the difference allowed between duplicate totals, as a divisor of the total (1%)
+	duplicateTotalTolerance = 100
+	// maxNotesLength is the longest note, in characters, on a receipt or item
+	maxNotesLength = 5000
+	// maxExtractedPages is the most pages a receipt extraction can be stitched from
+	maxExtractedPages = 20
+)
+
 type receiptService struct {
-	receiptRepo repository.ReceiptRepository
-	itemRepo    repository.ItemRepository
+	receiptRepo    repository.ReceiptRepository
+	itemRepo       repository.ItemRepository
+	adjustmentRepo repository.AdjustmentRepository
+	userRepo       repository.UserRepository
+	rateRepo       repository.ExchangeRateRepository
+	cardRepo       repository.CardRepository
+	merchantRepo   repository.MerchantRepository
+	productRepo    repository.ProductRepository
+	workspaceRepo  repository.WorkspaceRepository
+	expenseRepo    repository.ExpenseReportRepository
 }
 
 // NewReceiptService creates a new receipt service
-func NewReceiptService(receiptRepo repository.ReceiptRepository, itemRepo repository.ItemRepository) ReceiptService {
+func NewReceiptService(receiptRepo repository.ReceiptRepository, itemRepo repository.ItemRepository, adjustmentRepo repository.AdjustmentRepository, userRepo repository.UserRepository, rateRepo repository.ExchangeRateRepository, cardRepo repository.CardRepository, merchantRepo repository.MerchantRepository, productRepo repository.ProductRepository, workspaceRepo repository.WorkspaceRepository, expenseRepo repository.ExpenseReportRepository) ReceiptService {
 	return &receiptService{
-		receiptRepo: receiptRepo,
-		itemRepo:    itemRepo,
+		receiptRepo:    receiptRepo,
+		itemRepo:       itemRepo,
+		adjustmentRepo: adjustmentRepo,
+		userRepo:       userRepo,
+		rateRepo:       rateRepo,
+		cardRepo:       cardRepo,
+		merchantRepo:   merchantRepo,
+		productRepo:    productRepo,
+		workspaceRepo:  workspaceRepo,
+		expenseRepo:    expenseRepo,
 	}
 }
 
-// CreateReceipt creates a new receipt with items
-func (s *receiptService) CreateReceipt(userID int, req domain.CreateReceiptRequest, imageURL string, filename string, fileSize int) (*domain.ReceiptWithItems, error) {
-	// Parse date if provided
-	var date sql.NullTime
-	if req.Date != nil && *req.Date != "" {
-		// You can parse the date here if needed
-		// For now, we'll leave it as null
+// CreateReceipt creates a new receipt with items in a workspace, 0 being the user's personal workspace.
+// The receipt is flagged as a suspected duplicate when its image hash matches an
+// existing receipt of the workspace, or when its store, date, total and items match one after extraction.
+func (s *receiptService) CreateReceipt(userID int, workspaceID int, req domain.CreateReceiptRequest, image domain.ReceiptImage) (*domain.ReceiptWithItems, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create receipt
-	receipt := &domain.Receipt{
-		UserID:           userID,
-		StoreName:        sql.NullString{String: req.StoreName, Valid: req.StoreName != ""},
-		Address:          sql.NullString{String: req.Address, Valid: req.Address != ""},
-		Phone:            sql.NullInt64{Int64: *req.Phone, Valid: req.Phone != nil},
-		Date:             date,
-		ImageURL:         imageURL,
-		OriginalFilename: filename,
-		FileSize:         fileSize,
-		Status:           domain.StatusCompleted,
-		TotalItems:       req.TotalItems,
-		TotalSpending:    req.TotalSpending,
-		TotalDiscount:    req.TotalDiscount,
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.receiptRepo.Create(receipt); err != nil {
-		return nil, fmt.Errorf("failed to create receipt:  %w", err)
+	receipt, err := newReceipt(userID, req, user.HomeCurrency)
+	if err != nil {
+		return nil, err
 	}
+	receipt.WorkspaceID = member.WorkspaceID
 
-	// Create items
-	var items []domain.Item
-	for _, itemReq := range req.Items {
-		item := domain.Item{
-			ReceiptID: receipt.ID,
-			Name:      itemReq.Name,
-			UnitPrice: itemReq.UnitPrice,
-			Quantity:  itemReq.Quantity,
-			Price:     itemReq.Price,
-			Total:     itemReq.Total,
+	items, err := newItems(req.Items, receipt.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments, err := newAdjustments(receiptAdjustments(req), receipt.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt.ImageURL = image.URL
+	receipt.OriginalFilename = image.Filename
+	receipt.FileSize = image.Size
+	receipt.SourceType = image.Source
+	receipt.ImageHash = sql.NullString{String: image.Hash, Valid: image.Hash != ""}
+	receipt.PerceptualHash = image.PerceptualHash
+
+	if err := linkCard(s.cardRepo, receipt); err != nil {
+		return nil, err
+	}
+
+	if err := assignMerchant(s.merchantRepo, receipt); err != nil {
+		return nil, err
+	}
+
+	// Exact duplicate: the same image was uploaded before
+	original, err := s.FindDuplicateImage(receipt.WorkspaceID, image.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Near duplicate: a re-compressed or re-photographed copy of an uploaded image
+	if original == nil && image.PerceptualHash.Valid {
+		candidates, err := s.FindNearDuplicateImages(receipt.WorkspaceID, image.PerceptualHash.Int64)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			original = &candidates[0]
 		}
-		items = append(items, item)
 	}
 
 	if len(items) > 0 {
-		if err := s.itemRepo.CreateBatch(items); err != nil {
-			return nil, fmt.Errorf("failed to create items:  %w", err)
+		if err := assignProducts(s.productRepo, userID, receipt.WorkspaceID, items); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fuzzy duplicate: a different image of the same purchase
+	if original == nil {
+		if original, err = s.findFuzzyDuplicate(receipt, items); err != nil {
+			return nil, err
 		}
 	}
 
-	return &domain.ReceiptWithItems{
-		Receipt: *receipt,
-		Items:   items,
-	}, nil
+	if original != nil {
+		receipt.DuplicateOf = sql.NullInt64{Int64: int64(original.ID), Valid: true}
+		receipt.DuplicateStatus = domain.DuplicateSuspected
+	}
+
+	// The receipt, its items and its adjustments are created together or not at all
+	created := &domain.ReceiptWithItems{Receipt: *receipt, Items: items, Adjustments: adjustments}
+	if err := s.receiptRepo.CreateWithItems(created); err != nil {
+		return nil, fmt.Errorf("failed to create receipt: %w", err)
+	}
+
+	return withConsistency(created), nil
 }
 
 // GetReceiptByID gets receipt by ID with items
@@ -92,9 +172,9 @@ func (s *receiptService) GetReceiptByID(id int, userID int) (*domain.ReceiptWith
 		return nil, err
 	}
 
-	// Check ownership
-	if receipt.UserID != userID {
-		return nil, fmt.Errorf("unauthorized access")
+	// Any member of the receipt's workspace may read it
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, false); err != nil {
+		return nil, err
 	}
 
 	// Get items
@@ -103,71 +183,913 @@ func (s *receiptService) GetReceiptByID(id int, userID int) (*domain.ReceiptWith
 		return nil, fmt.Errorf("failed to get items: %w", err)
 	}
 
-	return &domain.ReceiptWithItems{
-		Receipt: *receipt,
-		Items:   items,
-	}, nil
+	adjustments, err := s.adjustmentRepo.FindByReceiptID(receipt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adjustments: %w", err)
+	}
+
+	return withConsistency(&domain.ReceiptWithItems{
+		Receipt:     *receipt,
+		Items:       items,
+		Adjustments: adjustments,
+	}), nil
+}
+
+// GetReceipts gets the workspace's receipts matching the filter with pagination
+func (s *receiptService) GetReceipts(userID int, workspaceID int, filter domain.ReceiptFilter, page, limit int) ([]domain.Receipt, int64, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.UserID = userID
+
+	return s.receiptRepo.FindByWorkspaceID(member.WorkspaceID, filter, page, limit)
+}
+
+// GetReceiptsByCursor gets a page of the workspace's receipts using an opaque keyset cursor,
+// with the cursors of the neighbouring pages. An empty cursor starts at the newest receipt.
+func (s *receiptService) GetReceiptsByCursor(userID int, workspaceID int, filter domain.ReceiptFilter, cursor string, limit int) (*domain.ReceiptPage, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
+	var position *domain.ReceiptCursor
+	if cursor != "" {
+		var err error
+		if position, err = domain.DecodeReceiptCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	receipts, hasMore, err := s.receiptRepo.FindByWorkspaceIDCursor(member.WorkspaceID, filter, position, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ReceiptPage{Receipts: receipts}
+	if len(receipts) == 0 {
+		return page, nil
+	}
+
+	backward := position != nil && position.Backward
+	first, last := receipts[0], receipts[len(receipts)-1]
+
+	// Newer receipts exist when paging backward found more, or when we came from a cursor going forward
+	if (backward && hasMore) || (!backward && position != nil) {
+		page.PrevCursor = domain.ReceiptCursor{UploadDate: first.UploadDate, ID: first.ID, Backward: true}.Encode()
+	}
+
+	// Older receipts exist when paging forward found more, or when we came from a cursor going backward
+	if (!backward && hasMore) || backward {
+		page.NextCursor = domain.ReceiptCursor{UploadDate: last.UploadDate, ID: last.ID}.Encode()
+	}
+
+	return page, nil
 }
 
-// GetReceiptsByUserID gets all receipts for a user with pagination
-func (s *receiptService) GetReceiptsByUserID(userID int, page, limit int) ([]domain.Receipt, int64, error) {
-	return s.receiptRepo.FindByUserID(userID, page, limit)
+// SearchReceipts searches the workspace's receipts by store, address and item names
+func (s *receiptService) SearchReceipts(userID int, workspaceID int, query string, page, limit int) ([]domain.ReceiptSearchResult, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.receiptRepo.Search(member.WorkspaceID, userID, query, page, limit)
 }
 
-// UpdateReceipt updates receipt and items
+// UpdateReceipt updates receipt and items, unless the receipt is locked by a submitted expense report
 func (s *receiptService) UpdateReceipt(id int, userID int, req domain.CreateReceiptRequest) (*domain.ReceiptWithItems, error) {
+	return s.updateReceipt(id, userID, req, false)
+}
+
+// updateReceipt updates a receipt from a request. Existing items are kept, rescaled to a new
+// currency, unless replaceItems is set, in which case the items and adjustments of the request
+// replace them. Items and adjustments that change are saved in one transaction along with the
+// receipt.
+func (s *receiptService) updateReceipt(id int, userID int, req domain.CreateReceiptRequest, replaceItems bool) (*domain.ReceiptWithItems, error) {
 	// Get existing receipt
 	receipt, err := s.receiptRepo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check ownership
-	if receipt.UserID != userID {
-		return nil, fmt.Errorf("unauthorized access")
+	// Owners and editors of the receipt's workspace may change it
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	if err := ensureUnlocked(s.expenseRepo, receipt.ID); err != nil {
+		return nil, err
+	}
+
+	date, err := parseReceiptDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notes are kept when the request has none
+	notes := receipt.Notes
+	if req.Notes != nil {
+		if notes, err = parseNotes(*req.Notes); err != nil {
+			return nil, err
+		}
+	}
+
+	currency := receiptCurrency(req)
+	if currency == "" {
+		currency = receipt.Currency
+	}
+
+	totalSpending, err := parseAmount("total_spending", req.TotalSpending, currency)
+	if err != nil {
+		return nil, err
+	}
+	totalDiscount, err := parseAmount("total_discount", req.TotalDiscount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []domain.Item
+	var adjustments []domain.Adjustment
+	saveItems := replaceItems
+	totalItems := req.TotalItems
+
+	if replaceItems {
+		if items, err = newItems(req.Items, currency); err != nil {
+			return nil, err
+		}
+		if adjustments, err = newAdjustments(receiptAdjustments(req), currency); err != nil {
+			return nil, err
+		}
+
+		existing, err := s.itemRepo.FindByReceiptID(receipt.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get items: %w", err)
+		}
+		matchItems(existing, items)
+
+		// Totals the request does not give are recomputed from the new items and adjustments
+		if totalItems == 0 {
+			totalItems = len(items)
+		}
+		if totalSpending.IsZero() {
+			for _, item := range items {
+				totalSpending = totalSpending.Add(item.Total)
+			}
+			for _, adjustment := range adjustments {
+				totalSpending = totalSpending.Add(adjustment.Amount)
+			}
+		}
+	} else {
+		replacements, err := newAdjustments(req.Adjustments, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		items, err = s.itemRepo.FindByReceiptID(receipt.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get items: %w", err)
+		}
+
+		adjustments, err = s.adjustmentRepo.FindByReceiptID(receipt.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get adjustments: %w", err)
+		}
+
+		// Item and adjustment amounts are in the receipt currency's minor units, so a currency
+		// with a different number of decimals needs them rescaled
+		rescale := domain.CurrencyExponent(currency) != domain.CurrencyExponent(receipt.Currency)
+		if rescale {
+			for i := range items {
+				items[i].UnitPrice = items[i].UnitPrice.WithCurrency(currency)
+				items[i].Price = items[i].Price.WithCurrency(currency)
+				items[i].Total = items[i].Total.WithCurrency(currency)
+			}
+			for i := range adjustments {
+				adjustments[i].Amount = adjustments[i].Amount.WithCurrency(currency)
+			}
+		}
+
+		// Adjustments are replaced when the request lists them
+		if req.Adjustments != nil {
+			adjustments = replacements
+		}
+
+		// Rescaled items and replaced adjustments are saved with the receipt in one transaction
+		saveItems = rescale || req.Adjustments != nil
 	}
 
 	// Update receipt
+	storeChanged := receipt.StoreName.String != req.StoreName
+	receipt.Date = date
 	receipt.StoreName = sql.NullString{String: req.StoreName, Valid: req.StoreName != ""}
 	receipt.Address = sql.NullString{String: req.Address, Valid: req.Address != ""}
-	receipt.Phone = sql.NullInt64{Int64: *req.Phone, Valid: req.Phone != nil}
-	receipt.TotalItems = req.TotalItems
-	receipt.TotalSpending = req.TotalSpending
-	receipt.TotalDiscount = req.TotalDiscount
+	receipt.Phone = nullInt64(req.Phone)
+	receipt.TotalItems = totalItems
+	receipt.TotalSpending = totalSpending
+	receipt.TotalDiscount = totalDiscount
+	receipt.Currency = currency
+	receipt.Notes = notes
+
+	// A receipt awaiting extraction, such as a scanned PDF, is complete once its fields are given
+	if receipt.Status == domain.StatusPending {
+		receipt.Status = domain.StatusCompleted
+	}
+
+	if storeChanged || !receipt.MerchantID.Valid {
+		if err := assignMerchant(s.merchantRepo, receipt); err != nil {
+			return nil, err
+		}
+	}
+
+	// Payment details are kept when the request has none
+	if payment := receiptPayment(req); payment != (extraction.Payment{}) {
+		setPayment(receipt, payment)
+		receipt.CardID = sql.NullInt64{}
+		if err := linkCard(s.cardRepo, receipt); err != nil {
+			return nil, err
+		}
+	}
+
+	if replaceItems && len(items) > 0 {
+		if err := assignProducts(s.productRepo, userID, receipt.WorkspaceID, items); err != nil {
+			return nil, err
+		}
+	}
+
+	if saveItems {
+		// The repository's errors are returned as they are, so refusing to remove an item
+		// that is still referenced is reported as a bad request
+		updated := &domain.ReceiptWithItems{Receipt: *receipt, Items: items, Adjustments: adjustments}
+		if err := s.receiptRepo.UpdateWithItems(updated); err != nil {
+			return nil, err
+		}
+		return withConsistency(updated), nil
+	}
 
 	if err := s.receiptRepo.Update(receipt); err != nil {
 		return nil, fmt.Errorf("failed to update receipt: %w", err)
 	}
 
-	// Get updated items
-	items, err := s.itemRepo.FindByReceiptID(receipt.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get items: %w", err)
+	return withConsistency(&domain.ReceiptWithItems{
+		Receipt:     *receipt,
+		Items:       items,
+		Adjustments: adjustments,
+	}), nil
+}
+
+// matchItems gives each new item the identity of an existing item with the same name, quantity
+// and total, so re-extracting a receipt updates its items in place and keeps their bill splits,
+// warranties and anomalies. Notes and categories are kept when the new item has none.
+func matchItems(existing []domain.Item, items []domain.Item) {
+	used := make([]bool, len(existing))
+	for i := range items {
+		for j, old := range existing {
+			if used[j] || old.Quantity != items[i].Quantity || old.Total.Amount != items[i].Total.Amount ||
+				!strings.EqualFold(strings.TrimSpace(old.Name), strings.TrimSpace(items[i].Name)) {
+				continue
+			}
+			used[j] = true
+			items[i].ID = old.ID
+			items[i].UUID = old.UUID
+			items[i].CreatedAt = old.CreatedAt
+			items[i].CreatedAtUnix = old.CreatedAtUnix
+			if !items[i].Notes.Valid {
+				items[i].Notes = old.Notes
+			}
+			if !items[i].Category.Valid {
+				items[i].Category = old.Category
+			}
+			break
+		}
+	}
+}
+
+// ApplyPageExtraction stitches the extraction results of a multi-page receipt, its image and
+// then its page attachments in order, into one receipt and updates the receipt with it. The
+// stitched items and adjustments replace the receipt's, and missing totals are recomputed.
+// Items matching an existing item are updated in place; an item that is gone is deleted,
+// unless a bill split, warranty or anomaly refers to it and the update is refused.
+func (s *receiptService) ApplyPageExtraction(id int, userID int, req domain.PageExtractionRequest) (*domain.ReceiptWithItems, error) {
+	if len(req.Pages) == 0 {
+		return nil, fmt.Errorf("at least one page is required")
+	}
+	if len(req.Pages) > maxExtractedPages {
+		return nil, fmt.Errorf("at most %d pages can be stitched", maxExtractedPages)
 	}
 
-	return &domain.ReceiptWithItems{
-		Receipt: *receipt,
-		Items:   items,
-	}, nil
+	return s.updateReceipt(id, userID, extraction.StitchPages(req.Pages), true)
 }
 
-// DeleteReceipt deletes receipt
+// DeleteReceipt deletes receipt, unless it is locked by a submitted expense report
 func (s *receiptService) DeleteReceipt(id int, userID int) error {
-	// Get receipt to check ownership
+	// Get receipt to check access
 	receipt, err := s.receiptRepo.FindByID(id)
 	if err != nil {
 		return err
 	}
 
-	// Check ownership
-	if receipt.UserID != userID {
-		return fmt.Errorf("unauthorized access")
+	// Owners and editors of the receipt's workspace may delete it
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return err
+	}
+
+	if err := ensureUnlocked(s.expenseRepo, receipt.ID); err != nil {
+		return err
 	}
 
 	return s.receiptRepo.Delete(id)
 }
 
-// GetStatsByUserID gets spending statistics
-func (s *receiptService) GetStatsByUserID(userID int) (map[string]interface{}, error) {
-	return s.receiptRepo.GetStatsByUserID(userID)
+// GetStats gets spending statistics for the workspace's receipts matching the filter, converted
+// to the user's home currency at the exchange rate of each purchase day. Amounts without a known rate
+// are reported per currency under "unconverted" instead of being added to the totals.
+// Tax, service charge and other adjustments are included in the spending and also broken
+// down by type under "adjustments", or under their currency in "unconverted" without a rate. Spending per payment method is under "by_payment_method",
+// with receipts of unknown payment method under "unknown", and spending per tag under "by_tag",
+// where a receipt with several tags counts under each of them.
+func (s *receiptService) GetStats(userID int, workspaceID int, filter domain.ReceiptFilter) (map[string]interface{}, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.receiptRepo.GetTotalsByCurrency(member.WorkspaceID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustmentTotals, err := s.adjustmentRepo.GetTotalsByCurrency(member.WorkspaceID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tagTotals, err := s.receiptRepo.GetTotalsByTag(member.WorkspaceID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	home := user.HomeCurrency
+	currencies := []string{home}
+	seen := map[string]bool{home: true}
+	lastDay := time.Now()
+	for _, total := range totals {
+		if !seen[total.Currency] {
+			seen[total.Currency] = true
+			currencies = append(currencies, total.Currency)
+		}
+		if total.Day.After(lastDay) {
+			lastDay = total.Day
+		}
+	}
+
+	var rateList []domain.ExchangeRate
+	if len(currencies) > 1 {
+		if rateList, err = s.rateRepo.FindForCurrencies(currencies, lastDay); err != nil {
+			return nil, err
+		}
+	}
+	rates := domain.NewRateTable(rateList)
+
+	var totalReceipts int
+	totalSpending := domain.NewMoney(0, home)
+	totalDiscount := domain.NewMoney(0, home)
+	byCurrency := map[string]*currencyStats{}
+	byPaymentMethod := map[string]*currencyStats{}
+	unconverted := map[string]*currencyStats{}
+
+	for _, total := range totals {
+		addCurrencyTotal(byCurrency, total.Currency, total)
+
+		spending, ok := rates.Convert(total.TotalSpending, home, total.Day)
+		if !ok {
+			addCurrencyTotal(unconverted, total.Currency, total)
+			continue
+		}
+		discount, _ := rates.Convert(total.TotalDiscount, home, total.Day)
+
+		totalReceipts += total.Receipts
+		totalSpending = totalSpending.Add(spending)
+		totalDiscount = totalDiscount.Add(discount)
+
+		method := total.PaymentMethod
+		if method == "" {
+			method = "unknown"
+		}
+		addCurrencyTotal(byPaymentMethod, method, domain.CurrencyTotal{
+			Currency:      home,
+			Receipts:      total.Receipts,
+			TotalSpending: spending,
+			TotalDiscount: discount,
+		})
+	}
+
+	adjustments := map[domain.AdjustmentType]domain.Money{}
+	for _, adjustmentType := range domain.AdjustmentTypes {
+		adjustments[adjustmentType] = domain.NewMoney(0, home)
+	}
+	for _, total := range adjustmentTotals {
+		amount, ok := rates.Convert(total.Amount, home, total.Day)
+		if !ok {
+			addAdjustmentTotal(unconverted, total)
+			continue
+		}
+		adjustments[total.Type] = adjustments[total.Type].Add(amount)
+	}
+
+	byTag := map[string]*tagStats{}
+	for _, total := range tagTotals {
+		entry, ok := byTag[total.Tag]
+		if !ok {
+			entry = &tagStats{currencyStats: currencyStats{
+				TotalSpending: domain.NewMoney(0, home),
+				TotalDiscount: domain.NewMoney(0, home),
+			}}
+			byTag[total.Tag] = entry
+		}
+
+		spending, ok := rates.Convert(total.TotalSpending, home, total.Day)
+		if !ok {
+			if entry.Unconverted == nil {
+				entry.Unconverted = map[string]*currencyStats{}
+			}
+			addCurrencyTotal(entry.Unconverted, total.Currency, total.CurrencyTotal)
+			continue
+		}
+		discount, _ := rates.Convert(total.TotalDiscount, home, total.Day)
+
+		entry.TotalReceipts += total.Receipts
+		entry.TotalSpending = entry.TotalSpending.Add(spending)
+		entry.TotalDiscount = entry.TotalDiscount.Add(discount)
+	}
+
+	stats := map[string]interface{}{
+		"home_currency":     home,
+		"total_receipts":    totalReceipts,
+		"total_spending":    totalSpending,
+		"total_discount":    totalDiscount,
+		"average_spending":  totalSpending.Div(int64(totalReceipts)),
+		"net_spending":      totalSpending.Sub(totalDiscount),
+		"adjustments":       adjustments,
+		"by_currency":       byCurrency,
+		"by_payment_method": byPaymentMethod,
+		"by_tag":            byTag,
+		"unconverted":       unconverted,
+	}
+
+	return stats, nil
+}
+
+// currencyStats is the unconverted spending in one currency, with the adjustments by type
+// when they could not be converted either
+type currencyStats struct {
+	TotalReceipts int                                    `json:"total_receipts"`
+	TotalSpending domain.Money                           `json:"total_spending"`
+	TotalDiscount domain.Money                           `json:"total_discount"`
+	Adjustments   map[domain.AdjustmentType]domain.Money `json:"adjustments,omitempty"`
+}
+
+// tagStats is the spending on the receipts with one tag in the home currency, with what could
+// not be converted listed per currency
+type tagStats struct {
+	currencyStats
+	Unconverted map[string]*currencyStats `json:"unconverted,omitempty"`
+}
+
+// addCurrencyTotal accumulates a day total into the summary entry with the given key
+func addCurrencyTotal(summary map[string]*currencyStats, key string, total domain.CurrencyTotal) {
+	entry, ok := summary[key]
+	if !ok {
+		entry = &currencyStats{
+			TotalSpending: domain.NewMoney(0, total.Currency),
+			TotalDiscount: domain.NewMoney(0, total.Currency),
+		}
+		summary[key] = entry
+	}
+	entry.TotalReceipts += total.Receipts
+	entry.TotalSpending = entry.TotalSpending.Add(total.TotalSpending)
+	entry.TotalDiscount = entry.TotalDiscount.Add(total.TotalDiscount)
+}
+
+// addAdjustmentTotal accumulates a day's adjustment total into the summary entry of its currency
+func addAdjustmentTotal(summary map[string]*currencyStats, total domain.AdjustmentTotal) {
+	addCurrencyTotal(summary, total.Currency, domain.CurrencyTotal{
+		Currency:      total.Currency,
+		TotalSpending: domain.NewMoney(0, total.Currency),
+		TotalDiscount: domain.NewMoney(0, total.Currency),
+	})
+
+	entry := summary[total.Currency]
+	if entry.Adjustments == nil {
+		entry.Adjustments = map[domain.AdjustmentType]domain.Money{}
+	}
+	if amount, ok := entry.Adjustments[total.Type]; ok {
+		entry.Adjustments[total.Type] = amount.Add(total.Amount)
+	} else {
+		entry.Adjustments[total.Type] = total.Amount
+	}
+}
+
+// FindDuplicateImage returns the workspace's original receipt with the same image hash, or nil if there is none
+func (s *receiptService) FindDuplicateImage(workspaceID int, imageHash string) (*domain.Receipt, error) {
+	if imageHash == "" {
+		return nil, nil
+	}
+
+	receipts, err := s.receiptRepo.FindByImageHash(workspaceID, imageHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check duplicate image: %w", err)
+	}
+
+	for i := range receipts {
+		if !receipts[i].DuplicateOf.Valid {
+			return &receipts[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindNearDuplicateImages returns the workspace's original receipts whose image is perceptually
+// close to the given hash, closest first
+func (s *receiptService) FindNearDuplicateImages(workspaceID int, perceptualHash int64) ([]domain.Receipt, error) {
+	receipts, err := s.receiptRepo.FindNearDuplicates(workspaceID, perceptualHash, nearDuplicateMaxDistance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check near duplicate images: %w", err)
+	}
+
+	var originals []domain.Receipt
+	for _, receipt := range receipts {
+		if !receipt.DuplicateOf.Valid {
+			originals = append(originals, receipt)
+		}
+	}
+
+	return originals, nil
+}
+
+// findFuzzyDuplicate looks for an existing receipt with a similar store name, the same date,
+// a matching total and a similar set of items
+func (s *receiptService) findFuzzyDuplicate(receipt *domain.Receipt, items []domain.Item) (*domain.Receipt, error) {
+	if !receipt.Date.Valid || !receipt.StoreName.Valid {
+		return nil, nil
+	}
+
+	tolerance := receipt.TotalSpending.Div(duplicateTotalTolerance)
+	candidates, err := s.receiptRepo.FindDuplicateCandidates(receipt, tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate candidates: %w", err)
+	}
+
+	itemNames := make([]string, len(items))
+	for i, item := range items {
+		itemNames[i] = item.Name
+	}
+
+	for i := range candidates {
+		candidate := &candidates[i]
+		if utils.Similarity(receipt.StoreName.String, candidate.StoreName.String) < duplicateStoreSimilarity {
+			continue
+		}
+
+		candidateItems, err := s.itemRepo.FindByReceiptID(candidate.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get items: %w", err)
+		}
+
+		candidateNames := make([]string, len(candidateItems))
+		for j, item := range candidateItems {
+			candidateNames[j] = item.Name
+		}
+
+		if utils.Jaccard(itemNames, candidateNames) >= duplicateItemSimilarity {
+			return candidate, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetSuspectedDuplicates gets the workspace's receipts awaiting duplicate review
+func (s *receiptService) GetSuspectedDuplicates(userID int, workspaceID int) ([]domain.Receipt, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.receiptRepo.FindSuspectedDuplicates(member.WorkspaceID)
+}
+
+// ConfirmDuplicate confirms a suspected duplicate, keeping it excluded from stats
+func (s *receiptService) ConfirmDuplicate(id int, userID int) (*domain.Receipt, error) {
+	receipt, err := s.getSuspectedDuplicate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.receiptRepo.UpdateDuplicate(receipt.ID, receipt.DuplicateOf, domain.DuplicateConfirmed); err != nil {
+		return nil, err
+	}
+
+	receipt.DuplicateStatus = domain.DuplicateConfirmed
+	return receipt, nil
+}
+
+// DismissDuplicate clears the duplicate reference so the receipt counts as an original again
+func (s *receiptService) DismissDuplicate(id int, userID int) (*domain.Receipt, error) {
+	receipt, err := s.getSuspectedDuplicate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.receiptRepo.UpdateDuplicate(receipt.ID, sql.NullInt64{}, domain.DuplicateDismissed); err != nil {
+		return nil, err
+	}
+
+	receipt.DuplicateOf = sql.NullInt64{}
+	receipt.DuplicateStatus = domain.DuplicateDismissed
+	return receipt, nil
+}
+
+// UpdateReceiptNotes replaces the notes of a receipt. Notes are the user's own annotations
+// rather than receipt content, so receipts locked by an expense report can still be annotated.
+func (s *receiptService) UpdateReceiptNotes(id int, userID int, req domain.UpdateNotesRequest) (*domain.Receipt, error) {
+	notes, err := parseNotes(req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.receiptRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	receipt.Notes = notes
+	if err := s.receiptRepo.Update(receipt); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// UpdateItemNotes replaces the notes of an item. Notes do not change amounts, so items of
+// receipts locked by an expense report can still be annotated.
+func (s *receiptService) UpdateItemNotes(itemID int, userID int, req domain.UpdateNotesRequest) (*domain.Item, error) {
+	notes, err := parseNotes(req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.itemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.receiptRepo.FindByID(item.ReceiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	item.Notes = notes
+	if err := s.itemRepo.Update(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// getSuspectedDuplicate loads a receipt the user may change that is awaiting duplicate review
+func (s *receiptService) getSuspectedDuplicate(id int, userID int) (*domain.Receipt, error) {
+	receipt, err := s.receiptRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	if receipt.DuplicateStatus != domain.DuplicateSuspected {
+		return nil, fmt.Errorf("receipt is not a suspected duplicate")
+	}
+
+	return receipt, nil
+}
+
+// receiptDateLayouts are the accepted formats of CreateReceiptRequest.Date
+var receiptDateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006", "2006/01/02", time.RFC3339}
+
+// parseReceiptDate parses an optional receipt date
+func parseReceiptDate(value *string) (sql.NullTime, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return sql.NullTime{}, nil
+	}
+
+	for _, layout := range receiptDateLayouts {
+		if date, err := time.Parse(layout, strings.TrimSpace(*value)); err == nil {
+			return sql.NullTime{Time: date, Valid: true}, nil
+		}
+	}
+
+	return sql.NullTime{}, fmt.Errorf("invalid date: %s", *value)
+}
+
+// parseNotes trims notes, rejecting notes over maxNotesLength characters. Empty notes are null.
+func parseNotes(value string) (sql.NullString, error) {
+	value = strings.TrimSpace(value)
+	if utf8.RuneCountInString(value) > maxNotesLength {
+		return sql.NullString{}, fmt.Errorf("notes must be at most %d characters", maxNotesLength)
+	}
+	return sql.NullString{String: value, Valid: value != ""}, nil
+}
+
+// nullInt64 converts an optional integer to sql.NullInt64
+func nullInt64(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+// newReceipt builds a completed receipt from a creation request. Amounts are in the request
+// currency, or in homeCurrency when the request has none.
+func newReceipt(userID int, req domain.CreateReceiptRequest, homeCurrency string) (*domain.Receipt, error) {
+	date, err := parseReceiptDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := receiptCurrency(req)
+	if currency == "" {
+		currency = homeCurrency
+	}
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	totalSpending, err := parseAmount("total_spending", req.TotalSpending, currency)
+	if err != nil {
+		return nil, err
+	}
+	totalDiscount, err := parseAmount("total_discount", req.TotalDiscount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &domain.Receipt{
+		UserID:          userID,
+		Currency:        currency,
+		StoreName:       sql.NullString{String: req.StoreName, Valid: req.StoreName != ""},
+		Address:         sql.NullString{String: req.Address, Valid: req.Address != ""},
+		Phone:           nullInt64(req.Phone),
+		Date:            date,
+		Status:          domain.StatusCompleted,
+		TotalItems:      req.TotalItems,
+		TotalSpending:   totalSpending,
+		TotalDiscount:   totalDiscount,
+		DuplicateStatus: domain.DuplicateNone,
+	}
+	setPayment(receipt, receiptPayment(req))
+
+	if req.Notes != nil {
+		if receipt.Notes, err = parseNotes(*req.Notes); err != nil {
+			return nil, err
+		}
+	}
+
+	return receipt, nil
+}
+
+// receiptPayment returns the payment details of a request, detecting them in the extracted text
+// when the request gives neither a payment method nor a card
+func receiptPayment(req domain.CreateReceiptRequest) extraction.Payment {
+	if req.PaymentMethod == "" && req.CardBrand == "" && req.CardLast4 == "" {
+		return extraction.DetectPayment(req.RawText)
+	}
+
+	payment := extraction.Payment{
+		Method: req.PaymentMethod,
+		Brand:  extraction.NormalizeCardBrand(req.CardBrand),
+		Last4:  req.CardLast4,
+	}
+	if payment.Method == "" && payment.Last4 != "" {
+		payment.Method = domain.PaymentCreditCard
+	}
+	return payment
+}
+
+// setPayment copies payment details onto a receipt
+func setPayment(receipt *domain.Receipt, payment extraction.Payment) {
+	receipt.PaymentMethod = sql.NullString{String: string(payment.Method), Valid: payment.Method != ""}
+	receipt.CardBrand = sql.NullString{String: payment.Brand, Valid: payment.Brand != ""}
+	receipt.CardLast4 = sql.NullString{String: payment.Last4, Valid: payment.Last4 != ""}
+}
+
+// receiptCurrency returns the currency of a request, detecting it from the extracted text when
+// it was not given explicitly. Returns "" when the currency is unknown.
+func receiptCurrency(req domain.CreateReceiptRequest) string {
+	if req.Currency != "" {
+		return strings.ToUpper(req.Currency)
+	}
+	return extraction.DetectCurrency(req.RawText)
+}
+
+// newItems builds the items of a receipt from creation requests, with amounts in the receipt currency
+func newItems(reqs []domain.CreateItemRequest, currency string) ([]domain.Item, error) {
+	var items []domain.Item
+	for i, itemReq := range reqs {
+		item := domain.Item{
+			Name:     itemReq.Name,
+			Quantity: itemReq.Quantity,
+			Category: sql.NullString{String: itemReq.Category, Valid: itemReq.Category != ""},
+		}
+
+		var err error
+		if item.Notes, err = parseNotes(itemReq.Notes); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if item.UnitPrice, err = parseAmount("unit_price", itemReq.UnitPrice, currency); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if item.Price, err = parseAmount("price", itemReq.Price, currency); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if item.Total, err = parseAmount("total", itemReq.Total, currency); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// receiptAdjustments returns the adjustments of a request, detecting them in the extracted text
+// when the request does not list any
+func receiptAdjustments(req domain.CreateReceiptRequest) []domain.CreateAdjustmentRequest {
+	if req.Adjustments != nil {
+		return req.Adjustments
+	}
+	return extraction.DetectAdjustments(req.RawText)
+}
+
+// newAdjustments builds receipt adjustments from creation requests, with amounts in the receipt
+// currency. Only rounding may be negative.
+func newAdjustments(reqs []domain.CreateAdjustmentRequest, currency string) ([]domain.Adjustment, error) {
+	adjustments := []domain.Adjustment{}
+	for i, adjustmentReq := range reqs {
+		amount, err := domain.ParseMoney(string(adjustmentReq.Amount), currency)
+		if err != nil {
+			return nil, fmt.Errorf("adjustment %d: invalid amount: %w", i+1, err)
+		}
+		if amount.IsNegative() && adjustmentReq.Type != domain.AdjustmentRounding {
+			return nil, fmt.Errorf("adjustment %d: invalid amount: %s must not be negative", i+1, adjustmentReq.Type)
+		}
+
+		adjustments = append(adjustments, domain.Adjustment{
+			Type:   adjustmentReq.Type,
+			Label:  adjustmentReq.Label,
+			Rate:   adjustmentReq.Rate,
+			Amount: amount,
+		})
+	}
+	return adjustments, nil
+}
+
+// withConsistency fills in the consistency warnings of a receipt
+func withConsistency(receipt *domain.ReceiptWithItems) *domain.ReceiptWithItems {
+	receipt.Warnings = receipt.Consistency()
+	return receipt
+}
+
+// parseAmount converts a non-negative request amount to money, treating an empty value as zero
+func parseAmount(field string, value domain.Decimal, currency string) (domain.Money, error) {
+	if value == "" {
+		return domain.NewMoney(0, currency), nil
+	}
+
+	amount, err := domain.ParseMoney(string(value), currency)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	if amount.IsNegative() {
+		return domain.Money{}, fmt.Errorf("invalid %s: must not be negative", field)
+	}
+
+	return amount, nil
 }
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/importer"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/reconcile"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type ReconciliationService interface {
+	ImportStatement(userID int, account string, statement *importer.Statement) (*domain.StatementImportReport, error)
+	Reconcile(userID int, workspaceID int) (*domain.ReconciliationReport, error)
+	GetReport(userID int, workspaceID int) (*domain.ReconciliationReport, error)
+	MatchTransaction(id int, userID int, req domain.MatchTransactionRequest) (*domain.ReconciliationMatch, error)
+	UnmatchTransaction(id int, userID int) (*domain.BankTransaction, error)
+}
+
+type reconciliationService struct {
+	transactionRepo repository.BankTransactionRepository
+	receiptRepo     repository.ReceiptRepository
+	userRepo        repository.UserRepository
+	workspaceRepo   repository.WorkspaceRepository
+	options         reconcile.Options
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(transactionRepo repository.BankTransactionRepository, receiptRepo repository.ReceiptRepository, userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository) ReconciliationService {
+	return &reconciliationService{
+		transactionRepo: transactionRepo,
+		receiptRepo:     receiptRepo,
+		userRepo:        userRepo,
+		workspaceRepo:   workspaceRepo,
+		options:         reconcile.DefaultOptions(),
+	}
+}
+
+// ImportStatement stores the transactions of a parsed statement under the account, or the
+// account named in the statement when none is given. Amounts are in the currency of the line,
+// the statement or else the user's home currency. Lines without a statement id are identified
+// by a hash of their contents, so importing the same file twice adds nothing.
+func (s *reconciliationService) ImportStatement(userID int, account string, statement *importer.Statement) (*domain.StatementImportReport, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == "" {
+		account = statement.Account
+	}
+
+	report := &domain.StatementImportReport{
+		Total: len(statement.Lines),
+		Rows:  make([]domain.ImportRowResult, len(statement.Lines)),
+	}
+
+	var transactions []domain.BankTransaction
+	occurrences := map[string]int{}
+
+	for i, line := range statement.Lines {
+		report.Rows[i].Row = line.Row
+
+		transaction, err := newBankTransaction(userID, account, line, statement.Currency, user.HomeCurrency)
+		if err != nil {
+			report.Rows[i].Error = err.Error()
+			report.Failed++
+			continue
+		}
+
+		if transaction.ExternalID == "" {
+			// Identical lines on one statement are distinct transactions, so count them
+			key := fmt.Sprintf("%s|%s|%s|%s", transaction.Date.Format("2006-01-02"), transaction.Amount, transaction.Currency, transaction.Description)
+			occurrences[key]++
+			hash, err := utils.HashContent(strings.NewReader(fmt.Sprintf("%s|%d", key, occurrences[key])))
+			if err != nil {
+				return nil, err
+			}
+			transaction.ExternalID = hash
+		}
+
+		report.Rows[i].Success = true
+		transactions = append(transactions, *transaction)
+	}
+
+	if len(transactions) > 0 {
+		imported, err := s.transactionRepo.CreateBatch(transactions)
+		if err != nil {
+			return nil, err
+		}
+		report.Imported = imported
+		report.Skipped = len(transactions) - imported
+	}
+
+	return report, nil
+}
+
+// newBankTransaction converts a statement line to a bank transaction
+func newBankTransaction(userID int, account string, line importer.StatementLine, statementCurrency, homeCurrency string) (*domain.BankTransaction, error) {
+	if line.Err != nil {
+		return nil, line.Err
+	}
+
+	currency := line.Currency
+	if currency == "" {
+		currency = statementCurrency
+	}
+	if currency == "" {
+		currency = homeCurrency
+	}
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	if len(currency) != 3 {
+		return nil, fmt.Errorf("invalid currency: %s", currency)
+	}
+
+	amount, err := domain.ParseMoney(string(line.Amount), currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	return &domain.BankTransaction{
+		UserID:      userID,
+		Account:     account,
+		ExternalID:  line.ExternalID,
+		Date:        line.Date,
+		Description: line.Description,
+		Amount:      amount,
+		Currency:    currency,
+	}, nil
+}
+
+// Reconcile matches the user's unmatched bank transactions with unmatched receipts of the
+// workspace, 0 being the user's personal workspace, and returns the resulting report. Existing
+// matches are kept.
+func (s *reconciliationService) Reconcile(userID int, workspaceID int) (*domain.ReconciliationReport, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.FindUnmatched(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if from, to, ok := s.period(transactions); ok {
+		receipts, err := s.transactionRepo.FindUnmatchedReceipts(member.WorkspaceID, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pair := range reconcile.Match(transactions, receipts, s.options) {
+			err := s.transactionRepo.SetMatch(
+				transactions[pair.Transaction].ID,
+				sql.NullInt64{Int64: int64(receipts[pair.Receipt].ID), Valid: true},
+				sql.NullFloat64{Float64: pair.Score, Valid: true},
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.GetReport(userID, workspaceID)
+}
+
+// GetReport lists the user's matched transactions, unmatched transactions, and the workspace's
+// receipts without a transaction from the period covered by the imported statements
+func (s *reconciliationService) GetReport(userID int, workspaceID int) (*domain.ReconciliationReport, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := s.transactionRepo.FindMatched(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unmatched, err := s.transactionRepo.FindUnmatched(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ReconciliationReport{
+		Matched:               matched,
+		UnmatchedReceipts:     []domain.Receipt{},
+		UnmatchedTransactions: unmatched,
+	}
+
+	all := append([]domain.BankTransaction{}, unmatched...)
+	for _, match := range matched {
+		all = append(all, match.Transaction)
+	}
+
+	if from, to, ok := s.period(all); ok {
+		if report.UnmatchedReceipts, err = s.transactionRepo.FindUnmatchedReceipts(member.WorkspaceID, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// period returns the dates spanned by the transactions, widened by the matcher's date window
+func (s *reconciliationService) period(transactions []domain.BankTransaction) (time.Time, time.Time, bool) {
+	if len(transactions) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, to := transactions[0].Date, transactions[0].Date
+	for _, transaction := range transactions[1:] {
+		if transaction.Date.Before(from) {
+			from = transaction.Date
+		}
+		if transaction.Date.After(to) {
+			to = transaction.Date
+		}
+	}
+
+	window := s.options.DateWindow
+	return from.AddDate(0, 0, -window), to.AddDate(0, 0, window), true
+}
+
+// MatchTransaction matches a bank transaction with a receipt by hand, replacing any previous match
+func (s *reconciliationService) MatchTransaction(id int, userID int, req domain.MatchTransactionRequest) (*domain.ReconciliationMatch, error) {
+	transaction, err := s.getTransaction(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.receiptRepo.FindByID(req.ReceiptID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.transactionRepo.FindByReceiptID(receipt.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != transaction.ID {
+		return nil, fmt.Errorf("receipt is already matched with another bank transaction")
+	}
+
+	transaction.ReceiptID = sql.NullInt64{Int64: int64(receipt.ID), Valid: true}
+	transaction.MatchScore = sql.NullFloat64{}
+	if err := s.transactionRepo.SetMatch(transaction.ID, transaction.ReceiptID, transaction.MatchScore); err != nil {
+		return nil, err
+	}
+
+	return &domain.ReconciliationMatch{Transaction: *transaction, Receipt: *receipt}, nil
+}
+
+// UnmatchTransaction removes the receipt match of a bank transaction
+func (s *reconciliationService) UnmatchTransaction(id int, userID int) (*domain.BankTransaction, error) {
+	transaction, err := s.getTransaction(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.ReceiptID = sql.NullInt64{}
+	transaction.MatchScore = sql.NullFloat64{}
+	if err := s.transactionRepo.SetMatch(transaction.ID, transaction.ReceiptID, transaction.MatchScore); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// getTransaction loads a bank transaction owned by the user
+func (s *reconciliationService) getTransaction(id int, userID int) (*domain.BankTransaction, error) {
+	transaction, err := s.transactionRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access")
+	}
+
+	return transaction, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/recurring"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+)
+
+type RecurringService interface {
+	DetectRecurring(userID int, workspaceID int) ([]domain.RecurringPurchase, error)
+	GetRecurring(userID int, workspaceID int, includeDismissed bool) ([]domain.RecurringPurchase, error)
+	UpdateRecurring(id int, userID int, req domain.UpdateRecurringRequest) (*domain.RecurringPurchase, error)
+}
+
+type recurringService struct {
+	recurringRepo repository.RecurringRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+// NewRecurringService creates a new recurring purchase service
+func NewRecurringService(recurringRepo repository.RecurringRepository, workspaceRepo repository.WorkspaceRepository) RecurringService {
+	return &recurringService{
+		recurringRepo: recurringRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+// DetectRecurring scans the workspace's receipts for recurring purchases, 0 being the user's
+// personal workspace, and stores them in place of the previous detection
+func (s *recurringService) DetectRecurring(userID int, workspaceID int) ([]domain.RecurringPurchase, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := s.recurringRepo.FindPurchases(member.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	detected := recurring.Detect(receipts, time.Now())
+	if err := s.recurringRepo.ReplaceForWorkspace(member.WorkspaceID, detected); err != nil {
+		return nil, err
+	}
+
+	if detected == nil {
+		detected = []domain.RecurringPurchase{}
+	}
+	return detected, nil
+}
+
+// GetRecurring lists the workspace's recurring purchases by the predicted next occurrence. The
+// status is recomputed as of today, so a recurrence becomes missing without running detection again.
+func (s *recurringService) GetRecurring(userID int, workspaceID int, includeDismissed bool) ([]domain.RecurringPurchase, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.recurringRepo.FindByWorkspaceID(member.WorkspaceID, includeDismissed)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range items {
+		items[i].Status = recurring.Status(items[i].Cadence, items[i].NextDate, now)
+	}
+
+	return items, nil
+}
+
+// UpdateRecurring dismisses or restores a recurring purchase of a workspace the user can edit
+func (s *recurringService) UpdateRecurring(id int, userID int, req domain.UpdateRecurringRequest) (*domain.RecurringPurchase, error) {
+	item, err := s.recurringRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, item.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.recurringRepo.SetDismissed(item.ID, req.Dismissed); err != nil {
+		return nil, err
+	}
+
+	item.Dismissed = req.Dismissed
+	item.Status = recurring.Status(item.Cadence, item.NextDate, time.Now())
+	return item, nil
+}
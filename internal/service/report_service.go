@@ -0,0 +1,344 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/report"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/split"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/storage"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+const (
+	// topStoreCount is the number of stores listed in a spending report
+	topStoreCount = 10
+	// maxReportImages is the most receipt thumbnails embedded in one report
+	maxReportImages = 200
+	// uncategorized names the spending on items without a category
+	uncategorized = "Uncategorized"
+)
+
+type ReportService interface {
+	WriteMonthlyReport(userID int, workspaceID int, month time.Time, withImages bool, w io.Writer) error
+	WriteExpenseReport(id int, userID int, withImages bool, w io.Writer) error
+}
+
+type reportService struct {
+	receiptRepo   repository.ReceiptRepository
+	itemRepo      repository.ItemRepository
+	expenseRepo   repository.ExpenseReportRepository
+	userRepo      repository.UserRepository
+	rateRepo      repository.ExchangeRateRepository
+	workspaceRepo repository.WorkspaceRepository
+	imageStore    storage.ImageStore
+}
+
+// NewReportService creates a new report service
+func NewReportService(receiptRepo repository.ReceiptRepository, itemRepo repository.ItemRepository, expenseRepo repository.ExpenseReportRepository, userRepo repository.UserRepository, rateRepo repository.ExchangeRateRepository, workspaceRepo repository.WorkspaceRepository, imageStore storage.ImageStore) ReportService {
+	return &reportService{
+		receiptRepo:   receiptRepo,
+		itemRepo:      itemRepo,
+		expenseRepo:   expenseRepo,
+		userRepo:      userRepo,
+		rateRepo:      rateRepo,
+		workspaceRepo: workspaceRepo,
+		imageStore:    imageStore,
+	}
+}
+
+// WriteMonthlyReport writes a PDF of the workspace's spending in the month of the given date,
+// converted to the user's home currency. Nothing is written when the user cannot read the workspace.
+func (s *reportService) WriteMonthlyReport(userID int, workspaceID int, month time.Time, withImages bool, w io.Writer) error {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(member.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	filter := domain.ReceiptFilter{DateFrom: &from, DateTo: &to, SortBy: domain.SortByDate, SortOrder: domain.SortAsc}
+
+	var receipts []domain.ReceiptWithItems
+	err = s.receiptRepo.StreamItemRows(member.WorkspaceID, filter, func(row *domain.ReceiptItemRow) error {
+		if len(receipts) == 0 || receipts[len(receipts)-1].ID != row.Receipt.ID {
+			receipts = append(receipts, domain.ReceiptWithItems{Receipt: row.Receipt})
+		}
+		if row.Item != nil {
+			last := &receipts[len(receipts)-1]
+			last.Items = append(last.Items, *row.Item)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load receipts: %w", err)
+	}
+
+	spending, err := s.buildReport(user.HomeCurrency, receipts)
+	if err != nil {
+		return err
+	}
+	spending.Title = "Spending Report " + from.Format("January 2006")
+	spending.Subtitle = workspace.Name
+	spending.From, spending.To = &from, &to
+
+	return report.WritePDF(w, spending, s.thumbnails(spending, withImages))
+}
+
+// WriteExpenseReport writes a PDF of an expense report the user can see, converted to the
+// submitter's home currency
+func (s *reportService) WriteExpenseReport(id int, userID int, withImages bool, w io.Writer) error {
+	expenseReport, _, err := expenseReportAccess(s.expenseRepo, s.workspaceRepo, id, userID)
+	if err != nil {
+		return err
+	}
+
+	submitter, err := s.userRepo.FindByID(expenseReport.UserID)
+	if err != nil {
+		return err
+	}
+
+	found, err := s.expenseRepo.FindReceipts(expenseReport.ID)
+	if err != nil {
+		return err
+	}
+
+	receipts := make([]domain.ReceiptWithItems, len(found))
+	for i, receipt := range found {
+		items, err := s.itemRepo.FindByReceiptID(receipt.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get items: %w", err)
+		}
+		receipts[i] = domain.ReceiptWithItems{Receipt: receipt, Items: items}
+	}
+
+	spending, err := s.buildReport(submitter.HomeCurrency, receipts)
+	if err != nil {
+		return err
+	}
+	spending.Title = "Expense Report: " + expenseReport.Title
+	spending.Subtitle = fmt.Sprintf("Submitted by %s - %s", submitter.FullName, expenseReport.Status)
+	if len(spending.Receipts) > 0 {
+		first, last := spending.Receipts[0].Date, spending.Receipts[len(spending.Receipts)-1].Date
+		spending.From, spending.To = &first, &last
+	}
+
+	return report.WritePDF(w, spending, s.thumbnails(spending, withImages))
+}
+
+// buildReport totals receipts in the home currency at the exchange rate of each purchase day.
+// Confirmed duplicates are left out. Each receipt's paid amount is shared between its item
+// categories in proportion to the item totals, so the categories add up to the total.
+func (s *reportService) buildReport(home string, receipts []domain.ReceiptWithItems) (*domain.SpendingReport, error) {
+	headers := make([]domain.Receipt, len(receipts))
+	for i := range receipts {
+		headers[i] = receipts[i].Receipt
+	}
+
+	rates, err := receiptRates(s.rateRepo, home, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	spending := &domain.SpendingReport{
+		HomeCurrency: home,
+		Total:        domain.NewMoney(0, home),
+		Discount:     domain.NewMoney(0, home),
+		Unconverted:  []domain.Money{},
+		Receipts:     []domain.SpendingReportReceipt{},
+		GeneratedAt:  time.Now(),
+	}
+
+	categories := map[string]int64{}
+	stores := map[string]*domain.StoreTotal{}
+	unconverted := map[string]domain.Money{}
+
+	for _, receipt := range receipts {
+		if receipt.DuplicateStatus == domain.DuplicateConfirmed {
+			continue
+		}
+
+		day := utils.ReceiptDay(receipt.Receipt)
+		paid := receipt.TotalSpending.Sub(receipt.TotalDiscount)
+		spending.ReceiptCount++
+
+		row := domain.SpendingReportReceipt{
+			ID:            receipt.ID,
+			Date:          day,
+			StoreName:     receipt.StoreName.String,
+			PaymentMethod: receipt.PaymentMethod.String,
+			Amount:        paid,
+		}
+		// Only photographed receipts have an image to show; PDF and HTML originals are not images
+		if receipt.SourceType == domain.SourceImage {
+			row.ImageURL = receipt.ImageURL
+		}
+
+		converted, ok := rates.Convert(paid, home, day)
+		if !ok {
+			unconverted[receipt.Currency] = addMoney(unconverted, paid)
+			spending.Receipts = append(spending.Receipts, row)
+			continue
+		}
+		row.Converted = &converted
+		spending.Receipts = append(spending.Receipts, row)
+
+		spending.Total = spending.Total.Add(converted)
+		if discount, ok := rates.Convert(receipt.TotalDiscount, home, day); ok {
+			spending.Discount = spending.Discount.Add(discount)
+		}
+
+		names, weights := itemCategories(receipt.Items)
+		for i, part := range split.Allocate(converted.Amount, weights) {
+			categories[names[i]] += part
+		}
+
+		key := storeKey(receipt.Receipt)
+		store, ok := stores[key]
+		if !ok {
+			name := strings.TrimSpace(receipt.StoreName.String)
+			if name == "" {
+				name = "Unknown store"
+			}
+			store = &domain.StoreTotal{Name: name, Total: domain.NewMoney(0, home)}
+			stores[key] = store
+		}
+		store.Receipts++
+		store.Total = store.Total.Add(converted)
+	}
+
+	for name, total := range categories {
+		spending.Categories = append(spending.Categories, domain.CategoryTotal{Category: name, Total: domain.NewMoney(total, home)})
+	}
+	sort.Slice(spending.Categories, func(i, j int) bool {
+		a, b := spending.Categories[i], spending.Categories[j]
+		if a.Total.Amount != b.Total.Amount {
+			return a.Total.Amount > b.Total.Amount
+		}
+		return a.Category < b.Category
+	})
+
+	for _, store := range stores {
+		spending.TopStores = append(spending.TopStores, *store)
+	}
+	sort.Slice(spending.TopStores, func(i, j int) bool {
+		a, b := spending.TopStores[i], spending.TopStores[j]
+		if a.Total.Amount != b.Total.Amount {
+			return a.Total.Amount > b.Total.Amount
+		}
+		return a.Name < b.Name
+	})
+	if len(spending.TopStores) > topStoreCount {
+		spending.TopStores = spending.TopStores[:topStoreCount]
+	}
+
+	spending.Unconverted = sortedMoney(unconverted)
+	return spending, nil
+}
+
+// itemCategories returns the categories of a receipt's items with their item totals, in order
+// of first appearance. A receipt without items is a single uncategorized weight.
+func itemCategories(items []domain.Item) ([]string, []int64) {
+	var names []string
+	var weights []int64
+	index := map[string]int{}
+
+	for _, item := range items {
+		name := uncategorized
+		if item.Category.Valid && strings.TrimSpace(item.Category.String) != "" {
+			name = strings.TrimSpace(item.Category.String)
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(names)
+			index[name] = i
+			names = append(names, name)
+			weights = append(weights, 0)
+		}
+		weights[i] += max(item.Total.Amount, 0)
+	}
+
+	if len(names) == 0 {
+		return []string{uncategorized}, []int64{1}
+	}
+	return names, weights
+}
+
+// storeKey groups receipts by merchant, or by store name for receipts without one
+func storeKey(receipt domain.Receipt) string {
+	if receipt.MerchantID.Valid {
+		return fmt.Sprintf("merchant:%d", receipt.MerchantID.Int64)
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(receipt.StoreName.String))
+}
+
+// thumbnails loads thumbnails of the report's receipt images when requested. Images that cannot
+// be read are left out rather than failing the report.
+func (s *reportService) thumbnails(spending *domain.SpendingReport, withImages bool) map[int]image.Image {
+	if !withImages {
+		return nil
+	}
+
+	thumbnails := map[int]image.Image{}
+	for _, receipt := range spending.Receipts {
+		if receipt.ImageURL == "" || len(thumbnails) == maxReportImages {
+			continue
+		}
+
+		file, err := s.imageStore.Open(receipt.ImageURL)
+		if err != nil {
+			continue
+		}
+		thumbnail, err := report.Thumbnail(file, report.ThumbnailSize)
+		file.Close()
+		if err != nil {
+			continue
+		}
+		thumbnails[receipt.ID] = thumbnail
+	}
+
+	return thumbnails
+}
+
+// receiptRates loads the exchange rates needed to convert receipts to the home currency up to
+// their latest purchase day
+func receiptRates(rateRepo repository.ExchangeRateRepository, home string, receipts []domain.Receipt) (*domain.RateTable, error) {
+	currencies := []string{home}
+	seen := map[string]bool{home: true}
+	lastDay := time.Now()
+	for _, receipt := range receipts {
+		if !seen[receipt.Currency] {
+			seen[receipt.Currency] = true
+			currencies = append(currencies, receipt.Currency)
+		}
+		if day := utils.ReceiptDay(receipt); day.After(lastDay) {
+			lastDay = day
+		}
+	}
+
+	var rateList []domain.ExchangeRate
+	if len(currencies) > 1 {
+		var err error
+		if rateList, err = rateRepo.FindForCurrencies(currencies, lastDay); err != nil {
+			return nil, err
+		}
+	}
+
+	return domain.NewRateTable(rateList), nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/split"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type SplitService interface {
+	SplitReceipt(receiptID int, userID int, req domain.SplitRequest) (*domain.Split, error)
+	GetSplit(receiptID int, userID int) (*domain.Split, error)
+	DeleteSplit(receiptID int, userID int) error
+	CreateSettlement(userID int, workspaceID int, req domain.CreateSettlementRequest) (*domain.Settlement, error)
+	GetLedger(userID int, workspaceID int) ([]domain.LedgerEntry, error)
+	GetBalances(userID int, workspaceID int) ([]domain.BalanceSummary, error)
+}
+
+type splitService struct {
+	splitRepo     repository.SplitRepository
+	receiptRepo   repository.ReceiptRepository
+	itemRepo      repository.ItemRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewSplitService creates a new split service
+func NewSplitService(splitRepo repository.SplitRepository, receiptRepo repository.ReceiptRepository, itemRepo repository.ItemRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) SplitService {
+	return &splitService{
+		splitRepo:     splitRepo,
+		receiptRepo:   receiptRepo,
+		itemRepo:      itemRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// SplitReceipt divides a receipt between members of its workspace, replacing any earlier split.
+// Each participant pays their part of the items they share plus a part of the rest of the amount
+// paid (adjustments less the discount) proportional to their items, rounded so the shares add up
+// to exactly what was paid.
+func (s *splitService) SplitReceipt(receiptID int, userID int, req domain.SplitRequest) (*domain.Split, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.receiptRepo.FindByID(receiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaceRepo.FindMembers(receipt.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	isMember := map[int]bool{}
+	for _, member := range members {
+		isMember[member.UserID] = true
+	}
+
+	payerID := req.PayerID
+	if payerID == 0 {
+		payerID = receipt.UserID
+	}
+	if !isMember[payerID] {
+		return nil, fmt.Errorf("payer %d is not a member of the workspace", payerID)
+	}
+	for _, participant := range req.Participants {
+		if !isMember[participant] {
+			return nil, fmt.Errorf("participant %d is not a member of the workspace", participant)
+		}
+	}
+
+	items, err := s.itemRepo.FindByReceiptID(receipt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+
+	// Weights of the participants of each item, by item ID
+	requested := map[int]map[int]int64{}
+	for _, item := range req.Items {
+		if _, ok := requested[item.ItemID]; ok {
+			return nil, fmt.Errorf("item %d is listed more than once", item.ItemID)
+		}
+		weights := map[int]int64{}
+		for _, share := range item.Shares {
+			if !isMember[share.UserID] {
+				return nil, fmt.Errorf("participant %d is not a member of the workspace", share.UserID)
+			}
+			weight := int64(share.Weight)
+			if weight == 0 {
+				weight = 1
+			}
+			weights[share.UserID] += weight
+		}
+		requested[item.ItemID] = weights
+	}
+
+	result := &domain.Split{
+		ReceiptID:   receipt.ID,
+		WorkspaceID: receipt.WorkspaceID,
+		PayerID:     payerID,
+		Currency:    receipt.Currency,
+		CreatedBy:   userID,
+		Allocations: []domain.SplitAllocation{},
+	}
+
+	paid := receipt.TotalSpending.Sub(receipt.TotalDiscount).Amount
+	var splitItems []split.Item
+	var subtotal int64
+	for _, item := range items {
+		weights, ok := requested[item.ID]
+		delete(requested, item.ID)
+		if !ok {
+			weights = evenWeights(req.Participants, payerID)
+		}
+
+		for _, participant := range sortedKeys(weights) {
+			result.Allocations = append(result.Allocations, domain.SplitAllocation{ItemID: item.ID, UserID: participant, Weight: int(weights[participant])})
+		}
+		splitItems = append(splitItems, split.Item{Total: item.Total.Amount, Weights: weights})
+		subtotal += item.Total.Amount
+	}
+
+	for itemID := range requested {
+		return nil, fmt.Errorf("item %d is not on the receipt", itemID)
+	}
+
+	// A receipt without items is divided evenly as a whole
+	if len(splitItems) == 0 {
+		splitItems = []split.Item{{Total: paid, Weights: evenWeights(req.Participants, payerID)}}
+		subtotal = paid
+	}
+
+	for _, share := range split.Shares(splitItems, paid-subtotal) {
+		result.Shares = append(result.Shares, domain.SplitShare{
+			UserID:      share.UserID,
+			Items:       domain.NewMoney(share.Items, receipt.Currency),
+			Adjustments: domain.NewMoney(share.Adjustments, receipt.Currency),
+			Total:       domain.NewMoney(share.Total(), receipt.Currency),
+		})
+	}
+
+	if err := s.splitRepo.Save(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// evenWeights gives each participant a weight of 1, or everything to the payer when there are none
+func evenWeights(participants []int, payerID int) map[int]int64 {
+	if len(participants) == 0 {
+		return map[int]int64{payerID: 1}
+	}
+
+	weights := map[int]int64{}
+	for _, participant := range participants {
+		weights[participant] = 1
+	}
+	return weights
+}
+
+// sortedKeys returns the user IDs of a weight map in ascending order
+func sortedKeys(weights map[int]int64) []int {
+	keys := make([]int, 0, len(weights))
+	for key := range weights {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// GetSplit gets the split of a receipt
+func (s *splitService) GetSplit(receiptID int, userID int) (*domain.Split, error) {
+	receipt, err := s.receiptRepo.FindByID(receiptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, false); err != nil {
+		return nil, err
+	}
+
+	return s.splitRepo.FindByReceiptID(receipt.ID)
+}
+
+// DeleteSplit removes the split of a receipt, cancelling the debts it created
+func (s *splitService) DeleteSplit(receiptID int, userID int) error {
+	receipt, err := s.receiptRepo.FindByID(receiptID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, true); err != nil {
+		return err
+	}
+
+	return s.splitRepo.DeleteByReceiptID(receipt.ID)
+}
+
+// CreateSettlement records a payment between two members of a workspace, 0 being the user's
+// personal workspace. The user must be the one paying or the one paid.
+func (s *splitService) CreateSettlement(userID int, workspaceID int, req domain.CreateSettlementRequest) (*domain.Settlement, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	fromUserID := req.FromUserID
+	if fromUserID == 0 {
+		fromUserID = userID
+	}
+	if fromUserID != userID && req.ToUserID != userID {
+		return nil, fmt.Errorf("unauthorized access: only the payer or the receiver can record a settlement")
+	}
+	if fromUserID == req.ToUserID {
+		return nil, fmt.Errorf("a settlement needs two different members")
+	}
+
+	for _, id := range []int{fromUserID, req.ToUserID} {
+		found, err := s.workspaceRepo.FindMember(member.WorkspaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			return nil, fmt.Errorf("user %d is not a member of the workspace", id)
+		}
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	amount, err := parseAmount("amount", req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		return nil, fmt.Errorf("invalid amount: must be positive")
+	}
+
+	settledAt, err := parseReceiptDate(req.SettledAt)
+	if err != nil {
+		return nil, err
+	}
+	if !settledAt.Valid {
+		settledAt.Time = time.Now()
+	}
+
+	settlement := &domain.Settlement{
+		WorkspaceID: member.WorkspaceID,
+		FromUserID:  fromUserID,
+		ToUserID:    req.ToUserID,
+		Amount:      amount,
+		Currency:    currency,
+		Note:        strings.TrimSpace(req.Note),
+		CreatedBy:   userID,
+		SettledAt:   settledAt.Time,
+	}
+
+	if err := s.splitRepo.CreateSettlement(settlement); err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+// GetLedger lists the split shares and settlements between members of a workspace, oldest first
+func (s *splitService) GetLedger(userID int, workspaceID int) ([]domain.LedgerEntry, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.splitRepo.FindLedger(member.WorkspaceID)
+}
+
+// GetBalances summarizes who owes whom in a workspace, per currency. Debts net what each pair of
+// members owe each other after settlements; transfers simplify them into as few payments as
+// possible that leave every member settled.
+func (s *splitService) GetBalances(userID int, workspaceID int) ([]domain.BalanceSummary, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.splitRepo.FindLedger(member.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaceRepo.FindMembers(member.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int]string{}
+	for _, m := range members {
+		names[m.UserID] = m.FullName
+	}
+
+	type pair struct{ from, to int }
+	balances := map[string]map[int]int64{}
+	debts := map[string]map[pair]int64{}
+	for _, entry := range entries {
+		currency := entry.Amount.Currency
+		if balances[currency] == nil {
+			balances[currency] = map[int]int64{}
+			debts[currency] = map[pair]int64{}
+		}
+
+		// A split share adds to what From owes To; a settlement pays it off
+		amount := entry.Amount.Amount
+		if entry.Type == domain.LedgerSettlement {
+			amount = -amount
+		}
+		balances[currency][entry.FromUserID] -= amount
+		balances[currency][entry.ToUserID] += amount
+
+		if entry.FromUserID < entry.ToUserID {
+			debts[currency][pair{entry.FromUserID, entry.ToUserID}] += amount
+		} else {
+			debts[currency][pair{entry.ToUserID, entry.FromUserID}] -= amount
+		}
+	}
+
+	currencies := make([]string, 0, len(balances))
+	for currency := range balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	summaries := []domain.BalanceSummary{}
+	for _, currency := range currencies {
+		summary := domain.BalanceSummary{
+			Currency:  currency,
+			Balances:  []domain.MemberBalance{},
+			Debts:     []domain.Debt{},
+			Transfers: []domain.Debt{},
+		}
+
+		for _, id := range sortedKeys(balances[currency]) {
+			summary.Balances = append(summary.Balances, domain.MemberBalance{
+				UserID:   id,
+				FullName: names[id],
+				Balance:  domain.NewMoney(balances[currency][id], currency),
+			})
+		}
+
+		for p, amount := range debts[currency] {
+			switch {
+			case amount > 0:
+				summary.Debts = append(summary.Debts, domain.Debt{FromUserID: p.from, ToUserID: p.to, Amount: domain.NewMoney(amount, currency)})
+			case amount < 0:
+				summary.Debts = append(summary.Debts, domain.Debt{FromUserID: p.to, ToUserID: p.from, Amount: domain.NewMoney(-amount, currency)})
+			}
+		}
+		sort.Slice(summary.Debts, func(i, j int) bool {
+			if summary.Debts[i].FromUserID != summary.Debts[j].FromUserID {
+				return summary.Debts[i].FromUserID < summary.Debts[j].FromUserID
+			}
+			return summary.Debts[i].ToUserID < summary.Debts[j].ToUserID
+		})
+
+		for _, transfer := range split.Simplify(balances[currency]) {
+			summary.Transfers = append(summary.Transfers, domain.Debt{
+				FromUserID: transfer.From,
+				ToUserID:   transfer.To,
+				Amount:     domain.NewMoney(transfer.Amount, currency),
+			})
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+type TagService interface {
+	GetTags(userID int) ([]domain.Tag, error)
+	CreateTag(userID int, req domain.TagRequest) (*domain.Tag, error)
+	RenameTag(id int, userID int, req domain.TagRequest) (*domain.Tag, error)
+	DeleteTag(id int, userID int) error
+	GetReceiptTags(receiptID int, userID int) ([]domain.Tag, error)
+	SetReceiptTags(receiptID int, userID int, req domain.SetReceiptTagsRequest) ([]domain.Tag, error)
+}
+
+// maxTagLength is the longest tag name, in characters, after normalization
+const maxTagLength = 50
+
+type tagService struct {
+	tagRepo       repository.TagRepository
+	receiptRepo   repository.ReceiptRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewTagService creates a new tag service
+func NewTagService(tagRepo repository.TagRepository, receiptRepo repository.ReceiptRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) TagService {
+	return &tagService{
+		tagRepo:       tagRepo,
+		receiptRepo:   receiptRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// tagName normalizes a tag name and checks it is not empty or too long
+func tagName(name string) (string, error) {
+	normalized := domain.NormalizeTagName(name)
+	if normalized == "" {
+		return "", fmt.Errorf("tag name is required")
+	}
+	if utf8.RuneCountInString(normalized) > maxTagLength {
+		return "", fmt.Errorf("tag name must be at most %d characters", maxTagLength)
+	}
+	return normalized, nil
+}
+
+// GetTags lists the user's tags with the number of receipts carrying each
+func (s *tagService) GetTags(userID int) ([]domain.Tag, error) {
+	return s.tagRepo.FindByUserID(userID)
+}
+
+// CreateTag creates a tag for the user
+func (s *tagService) CreateTag(userID int, req domain.TagRequest) (*domain.Tag, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	name, err := tagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tagRepo.FindByName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("tag %q already exists", name)
+	}
+
+	tag := &domain.Tag{UserID: userID, Name: name}
+	if err := s.tagRepo.Create(tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// RenameTag renames one of the user's tags
+func (s *tagService) RenameTag(id int, userID int, req domain.TagRequest) (*domain.Tag, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	name, err := tagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.getOwnTag(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == tag.Name {
+		return tag, nil
+	}
+
+	existing, err := s.tagRepo.FindByName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("tag %q already exists", name)
+	}
+
+	if err := s.tagRepo.Rename(id, name); err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.FindByID(id)
+}
+
+// DeleteTag deletes one of the user's tags and removes it from every receipt
+func (s *tagService) DeleteTag(id int, userID int) error {
+	if _, err := s.getOwnTag(id, userID); err != nil {
+		return err
+	}
+
+	return s.tagRepo.Delete(id)
+}
+
+// GetReceiptTags lists the user's tags on a receipt the user can read
+func (s *tagService) GetReceiptTags(receiptID int, userID int) ([]domain.Tag, error) {
+	if err := s.receiptAccess(receiptID, userID, false); err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.FindByReceiptID(receiptID, userID)
+}
+
+// SetReceiptTags replaces the user's tags on a receipt, creating tags the user does not have yet.
+// Tags are labels rather than receipt content, so receipts locked by an expense report can
+// still be tagged.
+func (s *tagService) SetReceiptTags(receiptID int, userID int, req domain.SetReceiptTagsRequest) ([]domain.Tag, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for _, raw := range req.Tags {
+		name, err := tagName(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if err := s.receiptAccess(receiptID, userID, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.SetReceiptTags(receiptID, userID, names); err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.FindByReceiptID(receiptID, userID)
+}
+
+// getOwnTag loads a tag and checks it belongs to the user
+func (s *tagService) getOwnTag(id int, userID int) (*domain.Tag, error) {
+	tag, err := s.tagRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access")
+	}
+
+	return tag, nil
+}
+
+// receiptAccess checks the user is a member of the receipt's workspace, with edit rights if asked
+func (s *tagService) receiptAccess(receiptID int, userID int, edit bool) error {
+	receipt, err := s.receiptRepo.FindByID(receiptID)
+	if err != nil {
+		return err
+	}
+
+	_, err = workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, edit)
+	return err
+}
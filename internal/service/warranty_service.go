@@ -0,0 +1,194 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// ReturnReminderDays is how many days before a return window closes the reminder is sent
+const ReturnReminderDays = 3
+
+// DefaultUpcomingDays is how far ahead upcoming expirations are listed by default
+const DefaultUpcomingDays = 30
+
+// maxNotificationTitle is the length of the notifications title column
+const maxNotificationTitle = 255
+
+type WarrantyService interface {
+	SetWarranty(itemID int, userID int, req domain.SetWarrantyRequest) (*domain.ItemWarranty, error)
+	GetWarranty(itemID int, userID int) (*domain.ItemWarranty, error)
+	DeleteWarranty(itemID int, userID int) error
+	GetUpcoming(userID int, workspaceID int, kind domain.ExpirationKind, days int) ([]domain.Expiration, error)
+	SendReturnReminders(now time.Time, days int) (int, error)
+}
+
+type warrantyService struct {
+	warrantyRepo  repository.WarrantyRepository
+	receiptRepo   repository.ReceiptRepository
+	itemRepo      repository.ItemRepository
+	workspaceRepo repository.WorkspaceRepository
+	validator     *utils.Validator
+}
+
+// NewWarrantyService creates a new warranty service
+func NewWarrantyService(warrantyRepo repository.WarrantyRepository, receiptRepo repository.ReceiptRepository, itemRepo repository.ItemRepository, workspaceRepo repository.WorkspaceRepository, validator *utils.Validator) WarrantyService {
+	return &warrantyService{
+		warrantyRepo:  warrantyRepo,
+		receiptRepo:   receiptRepo,
+		itemRepo:      itemRepo,
+		workspaceRepo: workspaceRepo,
+		validator:     validator,
+	}
+}
+
+// SetWarranty sets the warranty length and return window of an item, replacing earlier values
+func (s *warrantyService) SetWarranty(itemID int, userID int, req domain.SetWarrantyRequest) (*domain.ItemWarranty, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	if req.WarrantyMonths == nil && req.ReturnDays == nil {
+		return nil, fmt.Errorf("warranty_months or return_days is required")
+	}
+
+	if err := s.itemAccess(itemID, userID, true); err != nil {
+		return nil, err
+	}
+
+	warranty := &domain.ItemWarranty{ItemID: itemID}
+	if req.WarrantyMonths != nil {
+		warranty.WarrantyMonths = sql.NullInt64{Int64: int64(*req.WarrantyMonths), Valid: true}
+	}
+	if req.ReturnDays != nil {
+		warranty.ReturnDays = sql.NullInt64{Int64: int64(*req.ReturnDays), Valid: true}
+	}
+
+	if err := s.warrantyRepo.Save(warranty); err != nil {
+		return nil, err
+	}
+
+	return s.warrantyRepo.FindByItemID(itemID)
+}
+
+// GetWarranty returns the warranty of an item with its expiry dates
+func (s *warrantyService) GetWarranty(itemID int, userID int) (*domain.ItemWarranty, error) {
+	if err := s.itemAccess(itemID, userID, false); err != nil {
+		return nil, err
+	}
+
+	return s.warrantyRepo.FindByItemID(itemID)
+}
+
+// DeleteWarranty stops tracking the warranty of an item
+func (s *warrantyService) DeleteWarranty(itemID int, userID int) error {
+	if err := s.itemAccess(itemID, userID, true); err != nil {
+		return err
+	}
+
+	return s.warrantyRepo.Delete(itemID)
+}
+
+// GetUpcoming lists the warranties and return windows of the workspace's items expiring within
+// the next days, soonest first, optionally of one kind
+func (s *warrantyService) GetUpcoming(userID int, workspaceID int, kind domain.ExpirationKind, days int) ([]domain.Expiration, error) {
+	switch kind {
+	case "", domain.ExpirationWarranty, domain.ExpirationReturn:
+	default:
+		return nil, fmt.Errorf("invalid expiration kind: %s", kind)
+	}
+
+	if days <= 0 {
+		days = DefaultUpcomingDays
+	}
+
+	member, err := workspaceAccess(s.workspaceRepo, userID, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	today := utils.StartOfDay(time.Now())
+	return s.warrantyRepo.FindUpcoming(member.WorkspaceID, kind, today, today.AddDate(0, 0, days))
+}
+
+// SendReturnReminders notifies the users who added receipts of the return windows closing
+// within the next days, once per window, and returns the number of reminders sent
+func (s *warrantyService) SendReturnReminders(now time.Time, days int) (int, error) {
+	today := utils.StartOfDay(now)
+	due, err := s.warrantyRepo.FindDueReturns(today, today.AddDate(0, 0, days))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, expiration := range due {
+		notification := &domain.Notification{
+			UserID:    expiration.UserID,
+			Kind:      domain.NotificationReturnReminder,
+			Title:     returnReminderTitle(expiration.ItemName),
+			Message:   returnReminderMessage(expiration),
+			ReceiptID: sql.NullInt64{Int64: int64(expiration.ReceiptID), Valid: true},
+			ItemID:    sql.NullInt64{Int64: int64(expiration.ItemID), Valid: true},
+		}
+
+		if err := s.warrantyRepo.RemindReturn(notification, now); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// itemAccess checks that the user can read, or with edit change, the receipt of an item
+func (s *warrantyService) itemAccess(itemID int, userID int, edit bool) error {
+	item, err := s.itemRepo.FindByID(itemID)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := s.receiptRepo.FindByID(item.ReceiptID)
+	if err != nil {
+		return err
+	}
+
+	_, err = workspaceAccess(s.workspaceRepo, userID, receipt.WorkspaceID, edit)
+	return err
+}
+
+// returnReminderTitle names the item whose return window closes soon, shortening a long item
+// name so the title fits the notification's title column
+func returnReminderTitle(itemName string) string {
+	const format = "Return window for %s closes soon"
+	room := maxNotificationTitle - utf8.RuneCountInString(format) + len("%s")
+	if name := []rune(itemName); len(name) > room {
+		itemName = string(name[:room-1]) + "…"
+	}
+	return fmt.Sprintf(format, itemName)
+}
+
+// returnReminderMessage describes a closing return window, e.g. "You can return Headphones
+// bought at Erafone on 2026-09-01 until 2026-10-01 (in 3 days)."
+func returnReminderMessage(expiration domain.Expiration) string {
+	store := ""
+	if expiration.StoreName.Valid && expiration.StoreName.String != "" {
+		store = " at " + expiration.StoreName.String
+	}
+
+	remaining := fmt.Sprintf("in %d days", expiration.DaysLeft)
+	switch expiration.DaysLeft {
+	case 0:
+		remaining = "today"
+	case 1:
+		remaining = "tomorrow"
+	}
+
+	return fmt.Sprintf("You can return %s bought%s on %s until %s (%s).",
+		expiration.ItemName, store, expiration.PurchaseDate.Format("2006-01-02"),
+		expiration.ExpiresOn.Format("2006-01-02"), remaining)
+}
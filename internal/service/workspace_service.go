@@ -0,0 +1,363 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/repository"
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/utils"
+)
+
+// invitationTTL is how long an invitation can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+// personalWorkspaceName is the name of the workspace created for every user
+const personalWorkspaceName = "Personal"
+
+type WorkspaceService interface {
+	CreateWorkspace(userID int, req domain.CreateWorkspaceRequest) (*domain.Workspace, error)
+	GetWorkspaces(userID int) ([]domain.Workspace, error)
+	GetWorkspace(id int, userID int) (*domain.WorkspaceWithMembers, error)
+	UpdateWorkspace(id int, userID int, req domain.CreateWorkspaceRequest) (*domain.Workspace, error)
+	InviteMember(id int, userID int, req domain.InviteMemberRequest) (*domain.WorkspaceInvitation, error)
+	GetPendingInvitations(id int, userID int) ([]domain.WorkspaceInvitation, error)
+	RevokeInvitation(id int, invitationID int, userID int) error
+	GetMyInvitations(userID int) ([]domain.WorkspaceInvitation, error)
+	AcceptInvitation(userID int, req domain.AcceptInvitationRequest) (*domain.Workspace, error)
+	UpdateMember(id int, memberID int, userID int, req domain.UpdateMemberRequest) (*domain.WorkspaceMember, error)
+	RemoveMember(id int, memberID int, userID int) error
+}
+
+type workspaceService struct {
+	workspaceRepo repository.WorkspaceRepository
+	userRepo      repository.UserRepository
+	validator     *utils.Validator
+}
+
+// NewWorkspaceService creates a new workspace service
+func NewWorkspaceService(workspaceRepo repository.WorkspaceRepository, userRepo repository.UserRepository, validator *utils.Validator) WorkspaceService {
+	return &workspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		validator:     validator,
+	}
+}
+
+// personalWorkspace returns the user's personal workspace, creating it on first use
+func personalWorkspace(workspaceRepo repository.WorkspaceRepository, userID int) (*domain.Workspace, error) {
+	workspace, err := workspaceRepo.FindPersonal(userID)
+	if err != nil || workspace != nil {
+		return workspace, err
+	}
+
+	workspace = &domain.Workspace{Name: personalWorkspaceName, OwnerID: userID, Personal: true}
+	if err := workspaceRepo.Create(workspace); err != nil {
+		// A concurrent request may have created it first
+		if existing, findErr := workspaceRepo.FindPersonal(userID); findErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// workspaceAccess resolves the workspace a user acts on and returns their membership. Workspace
+// 0 is the user's personal workspace. With edit the member must be allowed to change receipts.
+func workspaceAccess(workspaceRepo repository.WorkspaceRepository, userID, workspaceID int, edit bool) (*domain.WorkspaceMember, error) {
+	if workspaceID == 0 {
+		workspace, err := personalWorkspace(workspaceRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userID, Role: domain.WorkspaceOwner}, nil
+	}
+
+	member, err := workspaceRepo.FindMember(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member == nil {
+		return nil, fmt.Errorf("unauthorized access")
+	}
+
+	if edit && !member.Role.CanEdit() {
+		return nil, fmt.Errorf("unauthorized access: %s role cannot change receipts", member.Role)
+	}
+
+	return member, nil
+}
+
+// CreateWorkspace creates a shared workspace owned by the user
+func (s *workspaceService) CreateWorkspace(userID int, req domain.CreateWorkspaceRequest) (*domain.Workspace, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	// Make sure the personal workspace exists before the first shared one
+	if _, err := personalWorkspace(s.workspaceRepo, userID); err != nil {
+		return nil, err
+	}
+
+	workspace := &domain.Workspace{Name: strings.TrimSpace(req.Name), OwnerID: userID}
+	if err := s.workspaceRepo.Create(workspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// GetWorkspaces lists the workspaces the user is a member of with their role
+func (s *workspaceService) GetWorkspaces(userID int) ([]domain.Workspace, error) {
+	if _, err := personalWorkspace(s.workspaceRepo, userID); err != nil {
+		return nil, err
+	}
+
+	return s.workspaceRepo.FindByUserID(userID)
+}
+
+// GetWorkspace gets a workspace of the user with its members
+func (s *workspaceService) GetWorkspace(id int, userID int) (*domain.WorkspaceWithMembers, error) {
+	member, err := workspaceAccess(s.workspaceRepo, userID, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(member.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	workspace.Role = member.Role
+
+	members, err := s.workspaceRepo.FindMembers(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.WorkspaceWithMembers{Workspace: *workspace, Members: members}, nil
+}
+
+// UpdateWorkspace renames a workspace. Owner only.
+func (s *workspaceService) UpdateWorkspace(id int, userID int, req domain.CreateWorkspaceRequest) (*domain.Workspace, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.ownedWorkspace(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace.Name = strings.TrimSpace(req.Name)
+	if err := s.workspaceRepo.Update(workspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// InviteMember invites an email address to a shared workspace and returns the invitation with
+// its acceptance token. Owner only.
+func (s *workspaceService) InviteMember(id int, userID int, req domain.InviteMemberRequest) (*domain.WorkspaceInvitation, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.ownedWorkspace(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspace.Personal {
+		return nil, fmt.Errorf("personal workspace cannot be shared")
+	}
+
+	email := strings.TrimSpace(req.Email)
+
+	// Inviting an existing member is a mistake rather than a role change
+	if user, err := s.userRepo.FindByEmail(email); err == nil {
+		member, err := s.workspaceRepo.FindMember(workspace.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, fmt.Errorf("user is already a member of the workspace")
+		}
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.WorkspaceInvitation{
+		WorkspaceID:   workspace.ID,
+		WorkspaceName: workspace.Name,
+		Email:         email,
+		Role:          req.Role,
+		Token:         token,
+		TokenHash:     utils.HashToken(token),
+		InvitedBy:     userID,
+		ExpiresAt:     time.Now().Add(invitationTTL),
+	}
+
+	if err := s.workspaceRepo.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// GetPendingInvitations lists the invitations of a workspace that are still open. Owner only.
+func (s *workspaceService) GetPendingInvitations(id int, userID int) ([]domain.WorkspaceInvitation, error) {
+	workspace, err := s.ownedWorkspace(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.workspaceRepo.FindPendingInvitations(workspace.ID)
+}
+
+// RevokeInvitation deletes an invitation of a workspace. Owner only.
+func (s *workspaceService) RevokeInvitation(id int, invitationID int, userID int) error {
+	workspace, err := s.ownedWorkspace(id, userID)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.workspaceRepo.FindInvitationByID(invitationID)
+	if err != nil {
+		return err
+	}
+
+	if invitation.WorkspaceID != workspace.ID {
+		return fmt.Errorf("invitation not found")
+	}
+
+	return s.workspaceRepo.DeleteInvitation(invitation.ID)
+}
+
+// GetMyInvitations lists the open invitations sent to the user's email address
+func (s *workspaceService) GetMyInvitations(userID int) ([]domain.WorkspaceInvitation, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.workspaceRepo.FindInvitationsByEmail(user.Email)
+}
+
+// AcceptInvitation joins the workspace of an invitation sent to the user's email address
+func (s *workspaceService) AcceptInvitation(userID int, req domain.AcceptInvitationRequest) (*domain.Workspace, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.workspaceRepo.FindInvitationByTokenHash(utils.HashToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, fmt.Errorf("unauthorized access: invitation was sent to another email address")
+	}
+
+	if invitation.AcceptedAt.Valid {
+		return nil, fmt.Errorf("invitation already accepted")
+	}
+
+	if invitation.IsExpired() {
+		return nil, fmt.Errorf("invitation expired")
+	}
+
+	if err := s.workspaceRepo.AcceptInvitation(invitation, userID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.workspaceRepo.FindMember(invitation.WorkspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(invitation.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil {
+		workspace.Role = member.Role
+	}
+
+	return workspace, nil
+}
+
+// UpdateMember changes the role of a member other than the owner. Owner only.
+func (s *workspaceService) UpdateMember(id int, memberID int, userID int, req domain.UpdateMemberRequest) (*domain.WorkspaceMember, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.ownedWorkspace(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if memberID == workspace.OwnerID {
+		return nil, fmt.Errorf("the owner's role cannot be changed")
+	}
+
+	if err := s.workspaceRepo.UpdateMemberRole(workspace.ID, memberID, req.Role); err != nil {
+		return nil, err
+	}
+
+	member, err := s.workspaceRepo.FindMember(workspace.ID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, fmt.Errorf("workspace member not found")
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes a member from a workspace. The owner can remove anyone but
+// themselves; other members can only remove themselves, leaving the workspace.
+func (s *workspaceService) RemoveMember(id int, memberID int, userID int) error {
+	workspace, err := s.workspaceRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if memberID == workspace.OwnerID {
+		return fmt.Errorf("the owner cannot leave the workspace")
+	}
+
+	if userID != workspace.OwnerID && userID != memberID {
+		return fmt.Errorf("unauthorized access")
+	}
+
+	return s.workspaceRepo.RemoveMember(workspace.ID, memberID)
+}
+
+// ownedWorkspace loads a workspace the user owns
+func (s *workspaceService) ownedWorkspace(id int, userID int) (*domain.Workspace, error) {
+	workspace, err := s.workspaceRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspace.OwnerID != userID {
+		return nil, fmt.Errorf("unauthorized access: only the workspace owner can do this")
+	}
+
+	workspace.Role = domain.WorkspaceOwner
+	return workspace, nil
+}
@@ -0,0 +1,168 @@
+package split
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Item is one receipt item's total with the weight of each participant sharing it
+type Item struct {
+	Total   int64
+	Weights map[int]int64
+}
+
+// Share is one participant's part of a receipt in minor units
+type Share struct {
+	UserID      int
+	Items       int64
+	Adjustments int64
+}
+
+// Total returns the participant's items and adjustments together
+func (s Share) Total() int64 {
+	return s.Items + s.Adjustments
+}
+
+// Transfer is a payment of Amount minor units from one user to another
+type Transfer struct {
+	From   int
+	To     int
+	Amount int64
+}
+
+// Allocate divides amount into parts proportional to weights. Parts are rounded toward zero and
+// the minor units left over go to the largest remainders, earlier parts first on ties, so the
+// parts always add up to amount. When every weight is zero the amount is divided evenly.
+func Allocate(amount int64, weights []int64) []int64 {
+	parts := make([]int64, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var totalWeight int64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		weights = make([]int64, len(parts))
+		for i := range weights {
+			weights[i] = 1
+		}
+		totalWeight = int64(len(weights))
+	}
+
+	sign := int64(1)
+	if amount < 0 {
+		sign, amount = -1, -amount
+	}
+
+	remainders := make([]int64, len(weights))
+	allocated := int64(0)
+	total := big.NewInt(totalWeight)
+	for i, weight := range weights {
+		quotient, remainder := new(big.Int).QuoRem(new(big.Int).Mul(big.NewInt(amount), big.NewInt(weight)), total, new(big.Int))
+		parts[i] = quotient.Int64()
+		remainders[i] = remainder.Int64()
+		allocated += parts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := int64(0); i < amount-allocated; i++ {
+		parts[order[i]]++
+	}
+
+	for i := range parts {
+		parts[i] *= sign
+	}
+	return parts
+}
+
+// Shares divides each item between its participants by weight, then divides the adjustments
+// (tax, service, tip and the like less any discount) in proportion to each participant's item
+// total. Shares are ordered by user ID and add up to the items plus the adjustments.
+func Shares(items []Item, adjustments int64) []Share {
+	itemTotals := map[int]int64{}
+	for _, item := range items {
+		users := sortedUsers(item.Weights)
+		weights := make([]int64, len(users))
+		for i, userID := range users {
+			weights[i] = item.Weights[userID]
+		}
+		for i, part := range Allocate(item.Total, weights) {
+			itemTotals[users[i]] += part
+		}
+	}
+
+	users := sortedUsers(itemTotals)
+	weights := make([]int64, len(users))
+	for i, userID := range users {
+		weights[i] = itemTotals[userID]
+		if weights[i] < 0 {
+			weights[i] = 0
+		}
+	}
+
+	shares := make([]Share, len(users))
+	for i, part := range Allocate(adjustments, weights) {
+		shares[i] = Share{UserID: users[i], Items: itemTotals[users[i]], Adjustments: part}
+	}
+	return shares
+}
+
+// Simplify returns payments that settle net balances, positive when a user is owed and negative
+// when they owe. The largest debtor repeatedly pays the largest creditor, which settles n users
+// with at most n-1 payments. Balances must add up to zero.
+func Simplify(balances map[int]int64) []Transfer {
+	remaining := map[int]int64{}
+	for userID, balance := range balances {
+		if balance != 0 {
+			remaining[userID] = balance
+		}
+	}
+
+	var transfers []Transfer
+	for {
+		debtor, creditor := 0, 0
+		for _, userID := range sortedUsers(remaining) {
+			balance := remaining[userID]
+			if balance < 0 && (debtor == 0 || balance < remaining[debtor]) {
+				debtor = userID
+			}
+			if balance > 0 && (creditor == 0 || balance > remaining[creditor]) {
+				creditor = userID
+			}
+		}
+		if debtor == 0 || creditor == 0 {
+			return transfers
+		}
+
+		amount := -remaining[debtor]
+		if remaining[creditor] < amount {
+			amount = remaining[creditor]
+		}
+		transfers = append(transfers, Transfer{From: debtor, To: creditor, Amount: amount})
+
+		remaining[debtor] += amount
+		remaining[creditor] -= amount
+		if remaining[debtor] == 0 {
+			delete(remaining, debtor)
+		}
+		if remaining[creditor] == 0 {
+			delete(remaining, creditor)
+		}
+	}
+}
+
+// sortedUsers returns the keys of a map of user IDs in ascending order
+func sortedUsers(values map[int]int64) []int {
+	users := make([]int, 0, len(values))
+	for userID := range values {
+		users = append(users, userID)
+	}
+	sort.Ints(users)
+	return users
+}
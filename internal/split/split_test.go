@@ -0,0 +1,98 @@
+package split
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{name: "no weights", amount: 700, weights: nil, want: []int64{}},
+		{name: "even thirds", amount: 100, weights: []int64{1, 1, 1}, want: []int64{34, 33, 33}},
+		{name: "negative amount", amount: -100, weights: []int64{1, 1, 1}, want: []int64{-34, -33, -33}},
+		{name: "largest remainder first", amount: 1000, weights: []int64{1, 2}, want: []int64{333, 667}},
+		{name: "zero weights split evenly", amount: 10, weights: []int64{0, 0}, want: []int64{5, 5}},
+		{name: "zero weight gets nothing", amount: 5, weights: []int64{0, 1}, want: []int64{0, 5}},
+		{name: "zero amount", amount: 0, weights: []int64{3, 4}, want: []int64{0, 0}},
+		{name: "no overflow", amount: 9000000000000000000, weights: []int64{3, 3, 3}, want: []int64{3000000000000000000, 3000000000000000000, 3000000000000000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Allocate(tt.amount, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShares(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []Item
+		adjustments int64
+		want        []Share
+	}{
+		{
+			name:        "adjustments follow item totals",
+			items:       []Item{{Total: 300, Weights: map[int]int64{1: 1, 2: 2}}},
+			adjustments: 30,
+			want:        []Share{{UserID: 1, Items: 100, Adjustments: 10}, {UserID: 2, Items: 200, Adjustments: 20}},
+		},
+		{
+			name:        "discount rounds to the largest share",
+			items:       []Item{{Total: 100, Weights: map[int]int64{1: 1, 2: 1, 3: 1}}},
+			adjustments: -10,
+			want: []Share{
+				{UserID: 1, Items: 34, Adjustments: -4},
+				{UserID: 2, Items: 33, Adjustments: -3},
+				{UserID: 3, Items: 33, Adjustments: -3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Shares(tt.items, tt.adjustments)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Shares() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[int]int64
+		want     []Transfer
+	}{
+		{name: "settled", balances: map[int]int64{1: 0, 2: 0}},
+		{name: "one payment", balances: map[int]int64{1: -50, 2: 50}, want: []Transfer{{From: 1, To: 2, Amount: 50}}},
+		{
+			name:     "largest debtor first",
+			balances: map[int]int64{1: 100, 2: -60, 3: -40},
+			want:     []Transfer{{From: 2, To: 1, Amount: 60}, {From: 3, To: 1, Amount: 40}},
+		},
+		{
+			name:     "at most n-1 payments",
+			balances: map[int]int64{1: -30, 2: -30, 3: 20, 4: 40},
+			want:     []Transfer{{From: 1, To: 4, Amount: 30}, {From: 2, To: 3, Amount: 20}, {From: 2, To: 4, Amount: 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Simplify(tt.balances)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Simplify(%v) = %+v, want %+v", tt.balances, got, tt.want)
+			}
+		})
+	}
+}
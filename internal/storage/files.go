@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileStore saves uploaded files under storage keys and reads them back. URL returns the image
+// URL of a stored file, as the image store reads it.
+type FileStore interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	URL(key string) string
+}
+
+// MinioConfig locates a MinIO or other S3 compatible bucket
+type MinioConfig struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	UseSSL    bool
+}
+
+// NewFileStore creates the file store for a storage type: "local" keeps files under root,
+// anything else in the MinIO bucket
+func NewFileStore(storageType string, root string, minio MinioConfig) FileStore {
+	if storageType == "local" {
+		return &localFileStore{root: root}
+	}
+	return &minioStore{config: minio, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+type localFileStore struct {
+	root string
+}
+
+// path resolves a key under the storage root, refusing keys that leave it
+func (s *localFileStore) path(key string) string {
+	return filepath.Join(s.root, filepath.Clean("/"+key))
+}
+
+// Put writes a file, creating its directories
+func (s *localFileStore) Put(key string, r io.Reader, size int64, contentType string) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// Open opens a stored file
+func (s *localFileStore) Open(key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes a stored file; a file that is already gone is not an error
+func (s *localFileStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// URL returns the key, which the local image store reads relative to the same root
+func (s *localFileStore) URL(key string) string {
+	return key
+}
+
+type minioStore struct {
+	config MinioConfig
+	client *http.Client
+}
+
+// objectURL returns the path-style URL of an object in the bucket
+func (s *minioStore) objectURL(key string) string {
+	scheme := "http"
+	if s.config.UseSSL {
+		scheme = "https"
+	}
+
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return scheme + "://" + s.config.Endpoint + "/" + url.PathEscape(s.config.Bucket) + "/" + strings.Join(segments, "/")
+}
+
+// do sends a signed request for an object and checks its status
+func (s *minioStore) do(method string, key string, body io.Reader, size int64, contentType string, ok ...int) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.objectURL(key), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", contentType)
+	}
+	signRequest(req, s.config.AccessKey, s.config.SecretKey, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range ok {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+
+	resp.Body.Close()
+	return nil, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// Put uploads an object
+func (s *minioStore) Put(key string, r io.Reader, size int64, contentType string) error {
+	resp, err := s.do(http.MethodPut, key, r, size, contentType, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open downloads an object
+func (s *minioStore) Open(key string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, key, nil, 0, "", http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object; S3 reports success for objects that do not exist
+func (s *minioStore) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, 0, "", http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// URL returns the object URL
+func (s *minioStore) URL(key string) string {
+	return s.objectURL(key)
+}
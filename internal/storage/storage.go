@@ -0,0 +1,74 @@
+// Package storage stores uploaded receipt files and reads receipt images back from where they are stored
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxImageSize is the largest image that is read, to bound memory use
+const maxImageSize = 20 << 20
+
+// ImageStore opens a stored receipt image by its image URL
+type ImageStore interface {
+	Open(imageURL string) (io.ReadCloser, error)
+}
+
+// NewImageStore creates the image store for a storage type: "local" reads image URLs as paths
+// under root, anything else fetches them over HTTP from MinIO or another object store
+func NewImageStore(storageType string, root string) ImageStore {
+	if storageType == "local" {
+		return &localStore{root: root}
+	}
+	return &httpStore{client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+type localStore struct {
+	root string
+}
+
+// Open opens an image path relative to the storage root, refusing paths that leave it
+func (s *localStore) Open(imageURL string) (io.ReadCloser, error) {
+	name := filepath.Clean("/" + strings.TrimPrefix(imageURL, "file://"))
+	file, err := os.Open(filepath.Join(s.root, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	return limitedReadCloser{Reader: io.LimitReader(file, maxImageSize), Closer: file}, nil
+}
+
+type httpStore struct {
+	client *http.Client
+}
+
+// Open downloads an image from an http or https URL
+func (s *httpStore) Open(imageURL string) (io.ReadCloser, error) {
+	parsed, err := url.Parse(imageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("unsupported image url: %s", imageURL)
+	}
+
+	resp, err := s.client.Get(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	return limitedReadCloser{Reader: io.LimitReader(resp.Body, maxImageSize), Closer: resp.Body}, nil
+}
+
+// limitedReadCloser reads at most maxImageSize bytes and closes the underlying source
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
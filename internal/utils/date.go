@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/dzulfiardev/receipt-extraction-backend/internal/domain"
+)
+
+// StartOfDay returns the start of the calendar day of t, in UTC
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ReceiptDay returns the day of a receipt's purchase, falling back to its upload date when the
+// purchase date is unknown
+func ReceiptDay(receipt domain.Receipt) time.Time {
+	if receipt.Date.Valid {
+		return StartOfDay(receipt.Date.Time)
+	}
+	return StartOfDay(receipt.UploadDate)
+}
+
+// AddMonths adds calendar months to t, clamping to the last day of the resulting month, so one
+// month after 31 January is the last day of February rather than early March, as in Postgres
+func AddMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
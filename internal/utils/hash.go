@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// HashContent returns the hex encoded SHA-256 digest of the reader's content
+func HashContent(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to hash content: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math/bits"
+)
+
+// dHash grid size: 9x8 samples give 8 horizontal gradients per row, 64 bits in total
+const (
+	dHashWidth  = 9
+	dHashHeight = 8
+)
+
+// PerceptualHash computes a 64-bit difference hash (dHash) of an image.
+// Re-compressed, resized or slightly re-photographed copies of the same image
+// produce hashes with a small Hamming distance.
+func PerceptualHash(r io.Reader) (uint64, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	grid := downscaleGray(img, dHashWidth, dHashHeight)
+
+	var hash uint64
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash, nil
+}
+
+// HammingDistance returns the number of differing bits between two perceptual hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// downscaleGray averages the luminance of img over a width x height grid of boxes
+func downscaleGray(img image.Image, width, height int) [][]float64 {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	grid := make([][]float64, height)
+	for gy := 0; gy < height; gy++ {
+		grid[gy] = make([]float64, width)
+
+		y0 := bounds.Min.Y + gy*srcH/height
+		y1 := bounds.Min.Y + (gy+1)*srcH/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for gx := 0; gx < width; gx++ {
+			x0 := bounds.Min.X + gx*srcW/width
+			x1 := bounds.Min.X + (gx+1)*srcW/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var sum float64
+			var count int
+			for y := y0; y < y1 && y < bounds.Max.Y; y++ {
+				for x := x0; x < x1 && x < bounds.Max.X; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					count++
+				}
+			}
+
+			if count > 0 {
+				grid[gy][gx] = sum / float64(count)
+			}
+		}
+	}
+
+	return grid
+}
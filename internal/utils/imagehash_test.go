@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// testPhoto draws a smooth grayscale pattern standing in for a receipt photo
+func testPhoto(width, height int, invert bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			u, v := float64(x)/float64(width), float64(y)/float64(height)
+			value := 128 + 60*math.Sin(u*7) + 50*math.Cos(v*5+u*3)
+			if invert {
+				value = 255 - value
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(value)})
+		}
+	}
+	return img
+}
+
+// gradient fills an image whose brightness changes only from left to right
+func gradient(width, height int, falling bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := x * 255 / (width - 1)
+			if falling {
+				value = 255 - value
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(value)})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image, quality int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func hashOf(t *testing.T, data []byte) uint64 {
+	t.Helper()
+	hash, err := PerceptualHash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PerceptualHash() error = %v", err)
+	}
+	return hash
+}
+
+func TestPerceptualHash(t *testing.T) {
+	tests := []struct {
+		name  string
+		image image.Image
+		want  uint64
+	}{
+		{name: "brighter to the left", image: gradient(90, 80, true), want: math.MaxUint64},
+		{name: "brighter to the right", image: gradient(90, 80, false), want: 0},
+		{name: "uniform", image: image.NewGray(image.Rect(0, 0, 50, 50)), want: 0},
+		// Each of the three columns is sampled three times, so only two of every eight
+		// neighbouring samples differ
+		{name: "smaller than the grid", image: gradient(3, 2, true), want: 0x2424242424242424},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashOf(t, encodePNG(t, tt.image)); got != tt.want {
+				t.Errorf("PerceptualHash() = %016x, want %016x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerceptualHashStability(t *testing.T) {
+	original := hashOf(t, encodePNG(t, testPhoto(400, 600, false)))
+
+	if again := hashOf(t, encodePNG(t, testPhoto(400, 600, false))); again != original {
+		t.Errorf("hashing the same image twice = %016x and %016x", original, again)
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		maxDistance int
+		minDistance int
+	}{
+		{name: "recompressed", data: encodeJPEG(t, testPhoto(400, 600, false), 60), maxDistance: 4},
+		{name: "resized", data: encodePNG(t, testPhoto(200, 300, false)), maxDistance: 4},
+		{name: "resized and recompressed", data: encodeJPEG(t, testPhoto(120, 180, false), 40), maxDistance: 10},
+		{name: "different image", data: encodePNG(t, testPhoto(400, 600, true)), minDistance: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := HammingDistance(original, hashOf(t, tt.data))
+			if tt.maxDistance > 0 && distance > tt.maxDistance {
+				t.Errorf("distance to the original = %d, want at most %d", distance, tt.maxDistance)
+			}
+			if distance < tt.minDistance {
+				t.Errorf("distance to the original = %d, want at least %d", distance, tt.minDistance)
+			}
+		})
+	}
+}
+
+func TestPerceptualHashInvalidImage(t *testing.T) {
+	if _, err := PerceptualHash(bytes.NewReader([]byte("not an image"))); err == nil {
+		t.Error("PerceptualHash() of text succeeded, want an error")
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{a: 0, b: 0, want: 0},
+		{a: 0b1011, b: 0b0001, want: 2},
+		{a: 0, b: math.MaxUint64, want: 64},
+		{a: 1 << 63, b: 1, want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := HammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HammingDistance(%x, %x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -29,12 +29,15 @@ func ErrorResponse(c echo.Context, statusCode int, message string) error {
 	})
 }
 
-// PaginationMeta represents pagination metadata
+// PaginationMeta represents pagination metadata.
+// Cursor pagination fills NextCursor/PrevCursor instead of page counts.
 type PaginationMeta struct {
-	Page       int   `json:"page"`
-	Limit      int   `json:"limit"`
-	TotalItems int64 `json:"total_items"`
-	TotalPages int   `json:"total_pages"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	TotalItems int64  `json:"total_items"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // PaginatedResponse returns paginated response
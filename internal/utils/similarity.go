@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeText lowercases text and collapses everything that is not a letter or digit into single spaces
+func NormalizeText(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+// Similarity returns a score between 0 and 1 based on the Levenshtein distance of the normalized strings
+func Similarity(a, b string) float64 {
+	ra := []rune(NormalizeText(a))
+	rb := []rune(NormalizeText(b))
+
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+// Jaccard returns the Jaccard index of two sets of normalized strings
+func Jaccard(a, b []string) float64 {
+	setA := make(map[string]bool, len(a))
+	for _, s := range a {
+		setA[NormalizeText(s)] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, s := range b {
+		setB[NormalizeText(s)] = true
+	}
+
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for s := range setA {
+		if setB[s] {
+			intersection++
+		}
+	}
+
+	union := len(setA) + len(setB) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// levenshtein computes the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipts_duplicate_of;
+DROP INDEX IF EXISTS idx_receipts_user_image_hash;
+
+-- Drop columns
+ALTER TABLE receipts
+    DROP COLUMN IF EXISTS duplicate_status,
+    DROP COLUMN IF EXISTS duplicate_of,
+    DROP COLUMN IF EXISTS image_hash;
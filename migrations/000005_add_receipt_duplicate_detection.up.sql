@@ -0,0 +1,14 @@
+-- Duplicate detection columns
+ALTER TABLE receipts
+    ADD COLUMN image_hash VARCHAR(64),
+    ADD COLUMN duplicate_of INTEGER REFERENCES receipts(id) ON DELETE SET NULL,
+    ADD COLUMN duplicate_status VARCHAR(20) NOT NULL DEFAULT 'none';
+
+-- Indexes
+CREATE INDEX idx_receipts_user_image_hash ON receipts(user_id, image_hash);
+CREATE INDEX idx_receipts_duplicate_of ON receipts(duplicate_of);
+
+-- Comments
+COMMENT ON COLUMN receipts.image_hash IS 'SHA-256 hex digest of the uploaded image';
+COMMENT ON COLUMN receipts.duplicate_of IS 'Original receipt this one duplicates, excluded from stats while set';
+COMMENT ON COLUMN receipts.duplicate_status IS 'Status: none, suspected, confirmed, dismissed';
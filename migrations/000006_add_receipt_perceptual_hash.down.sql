@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipts_user_perceptual_hash;
+
+-- Drop columns
+ALTER TABLE receipts DROP COLUMN IF EXISTS perceptual_hash;
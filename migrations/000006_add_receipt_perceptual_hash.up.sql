@@ -0,0 +1,8 @@
+-- Perceptual hash for near-duplicate detection
+ALTER TABLE receipts ADD COLUMN perceptual_hash BIGINT;
+
+-- Indexes
+CREATE INDEX idx_receipts_user_perceptual_hash ON receipts(user_id) WHERE perceptual_hash IS NOT NULL;
+
+-- Comments
+COMMENT ON COLUMN receipts.perceptual_hash IS '64-bit dHash of the uploaded image, compared by Hamming distance';
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_items_category;
+
+-- Drop columns
+ALTER TABLE items DROP COLUMN IF EXISTS category;
@@ -0,0 +1,8 @@
+-- Item category
+ALTER TABLE items ADD COLUMN category VARCHAR(100);
+
+-- Indexes
+CREATE INDEX idx_items_category ON items(category);
+
+-- Comments
+COMMENT ON COLUMN items.category IS 'Spending category of the item, e.g. groceries, dining';
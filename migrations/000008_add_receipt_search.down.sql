@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_items_name_trgm;
+DROP INDEX IF EXISTS idx_receipts_address_trgm;
+DROP INDEX IF EXISTS idx_receipts_store_name_trgm;
+DROP INDEX IF EXISTS idx_items_search_vector;
+DROP INDEX IF EXISTS idx_receipts_search_vector;
+
+-- Drop columns
+ALTER TABLE items DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE receipts DROP COLUMN IF EXISTS search_vector;
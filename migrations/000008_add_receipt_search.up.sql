@@ -0,0 +1,23 @@
+-- Enable trigram extension
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+-- Full-text search vectors
+ALTER TABLE receipts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(store_name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(address, '')), 'B')
+) STORED;
+
+ALTER TABLE items ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', COALESCE(name, ''))
+) STORED;
+
+-- Indexes
+CREATE INDEX idx_receipts_search_vector ON receipts USING GIN (search_vector);
+CREATE INDEX idx_items_search_vector ON items USING GIN (search_vector);
+CREATE INDEX idx_receipts_store_name_trgm ON receipts USING GIN (store_name gin_trgm_ops);
+CREATE INDEX idx_receipts_address_trgm ON receipts USING GIN (address gin_trgm_ops);
+CREATE INDEX idx_items_name_trgm ON items USING GIN (name gin_trgm_ops);
+
+-- Comments
+COMMENT ON COLUMN receipts.search_vector IS 'Full-text vector of store name (weight A) and address (weight B)';
+COMMENT ON COLUMN items.search_vector IS 'Full-text vector of the item name';
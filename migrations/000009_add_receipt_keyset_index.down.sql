@@ -0,0 +1,2 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipts_user_upload_date_id;
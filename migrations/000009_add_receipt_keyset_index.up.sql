@@ -0,0 +1,2 @@
+-- Keyset pagination index on (upload_date, id) per user
+CREATE INDEX idx_receipts_user_upload_date_id ON receipts(user_id, upload_date DESC, id DESC);
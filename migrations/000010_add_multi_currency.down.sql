@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_exchange_rates_quote_date;
+DROP INDEX IF EXISTS idx_receipts_currency;
+
+-- Drop table
+DROP TABLE IF EXISTS exchange_rates CASCADE;
+
+-- Drop columns
+ALTER TABLE users DROP COLUMN IF EXISTS home_currency;
+ALTER TABLE receipts DROP COLUMN IF EXISTS currency;
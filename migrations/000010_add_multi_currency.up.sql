@@ -0,0 +1,26 @@
+-- Currency columns
+ALTER TABLE receipts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE users ADD COLUMN home_currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+-- Exchange rates table
+CREATE TABLE exchange_rates (
+    id SERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(24, 10) NOT NULL,
+    rate_date DATE NOT NULL,
+    source VARCHAR(50),
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    UNIQUE (base_currency, quote_currency, rate_date)
+);
+
+-- Indexes
+CREATE INDEX idx_receipts_currency ON receipts(currency);
+CREATE INDEX idx_exchange_rates_quote_date ON exchange_rates(quote_currency, rate_date DESC);
+
+-- Comments
+COMMENT ON COLUMN receipts.currency IS 'ISO 4217 currency code of the receipt amounts';
+COMMENT ON COLUMN users.home_currency IS 'ISO 4217 currency code stats are converted to';
+COMMENT ON TABLE exchange_rates IS 'Daily exchange rates: 1 base_currency = rate quote_currency';
+COMMENT ON COLUMN exchange_rates.source IS 'Importer the rate came from, e.g. ecb, csv';
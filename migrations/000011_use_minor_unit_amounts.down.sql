@@ -0,0 +1,27 @@
+-- Restore item amounts in whole units
+UPDATE items i
+SET unit_price = ROUND(i.unit_price / power(10::numeric, currency_exponent(r.currency))),
+    price = ROUND(i.price / power(10::numeric, currency_exponent(r.currency))),
+    total = ROUND(i.total / power(10::numeric, currency_exponent(r.currency)))
+FROM receipts r
+WHERE r.id = i.receipt_id;
+
+ALTER TABLE items
+    ALTER COLUMN unit_price TYPE INTEGER,
+    ALTER COLUMN price TYPE INTEGER,
+    ALTER COLUMN total TYPE INTEGER;
+
+-- Restore decimal receipt totals
+ALTER TABLE receipts
+    ALTER COLUMN total_spending TYPE DECIMAL(15, 2)
+        USING total_spending / power(10::numeric, currency_exponent(currency)),
+    ALTER COLUMN total_discount TYPE DECIMAL(15, 2)
+        USING total_discount / power(10::numeric, currency_exponent(currency));
+
+-- Drop functions
+DROP FUNCTION IF EXISTS currency_exponent(CHAR(3));
+
+-- Comments
+COMMENT ON COLUMN items.unit_price IS 'Price per single unit';
+COMMENT ON COLUMN items.price IS 'Price shown on receipt (may differ from unit_price)';
+COMMENT ON COLUMN items.total IS 'Total price for this item (price * quantity)';
@@ -0,0 +1,41 @@
+-- Number of decimal places of a currency's minor unit (ISO 4217).
+-- Keep in sync with currencyExponents in internal/domain/money.go
+CREATE OR REPLACE FUNCTION currency_exponent(code CHAR(3)) RETURNS INTEGER AS $$
+    SELECT CASE code
+        WHEN 'BIF' THEN 0 WHEN 'CLP' THEN 0 WHEN 'DJF' THEN 0 WHEN 'GNF' THEN 0
+        WHEN 'ISK' THEN 0 WHEN 'JPY' THEN 0 WHEN 'KMF' THEN 0 WHEN 'KRW' THEN 0
+        WHEN 'PYG' THEN 0 WHEN 'RWF' THEN 0 WHEN 'UGX' THEN 0 WHEN 'UYI' THEN 0
+        WHEN 'VND' THEN 0 WHEN 'VUV' THEN 0 WHEN 'XAF' THEN 0 WHEN 'XOF' THEN 0
+        WHEN 'XPF' THEN 0
+        WHEN 'BHD' THEN 3 WHEN 'IQD' THEN 3 WHEN 'JOD' THEN 3 WHEN 'KWD' THEN 3
+        WHEN 'LYD' THEN 3 WHEN 'OMR' THEN 3 WHEN 'TND' THEN 3
+        ELSE 2
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Receipt totals in minor units of the receipt currency
+ALTER TABLE receipts
+    ALTER COLUMN total_spending TYPE BIGINT
+        USING ROUND(total_spending * power(10::numeric, currency_exponent(currency))),
+    ALTER COLUMN total_discount TYPE BIGINT
+        USING ROUND(total_discount * power(10::numeric, currency_exponent(currency)));
+
+-- Item amounts were whole units; convert them to minor units of their receipt's currency
+ALTER TABLE items
+    ALTER COLUMN unit_price TYPE BIGINT,
+    ALTER COLUMN price TYPE BIGINT,
+    ALTER COLUMN total TYPE BIGINT;
+
+UPDATE items i
+SET unit_price = i.unit_price * power(10, currency_exponent(r.currency))::BIGINT,
+    price = i.price * power(10, currency_exponent(r.currency))::BIGINT,
+    total = i.total * power(10, currency_exponent(r.currency))::BIGINT
+FROM receipts r
+WHERE r.id = i.receipt_id;
+
+-- Comments
+COMMENT ON COLUMN receipts.total_spending IS 'Total spending in minor units of the receipt currency';
+COMMENT ON COLUMN receipts.total_discount IS 'Total discount in minor units of the receipt currency';
+COMMENT ON COLUMN items.unit_price IS 'Price per single unit, in minor units of the receipt currency';
+COMMENT ON COLUMN items.price IS 'Price shown on receipt (may differ from unit_price), in minor units';
+COMMENT ON COLUMN items.total IS 'Total price for this item (price * quantity), in minor units';
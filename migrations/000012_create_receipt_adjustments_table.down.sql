@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipt_adjustments_type;
+DROP INDEX IF EXISTS idx_receipt_adjustments_receipt_id;
+
+-- Drop table
+DROP TABLE IF EXISTS receipt_adjustments CASCADE;
@@ -0,0 +1,23 @@
+-- Receipt adjustments table
+CREATE TABLE receipt_adjustments (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    receipt_id INTEGER NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    label VARCHAR(255),
+    rate NUMERIC(7, 4),
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    CONSTRAINT chk_receipt_adjustments_type CHECK (type IN ('tax', 'service', 'tip', 'rounding', 'delivery_fee'))
+);
+
+-- Indexes
+CREATE INDEX idx_receipt_adjustments_receipt_id ON receipt_adjustments(receipt_id);
+CREATE INDEX idx_receipt_adjustments_type ON receipt_adjustments(type);
+
+-- Comments
+COMMENT ON TABLE receipt_adjustments IS 'Receipt-level lines that are not items: tax, service charge, tip, rounding, delivery fee';
+COMMENT ON COLUMN receipt_adjustments.label IS 'Label printed on the receipt, e.g. PPN 11%';
+COMMENT ON COLUMN receipt_adjustments.rate IS 'Percentage printed on the receipt, if any';
+COMMENT ON COLUMN receipt_adjustments.amount IS 'Amount in minor units of the receipt currency; rounding may be negative';
@@ -0,0 +1,14 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipts_card_id;
+DROP INDEX IF EXISTS idx_receipts_card_last4;
+DROP INDEX IF EXISTS idx_receipts_payment_method;
+DROP INDEX IF EXISTS idx_cards_user_brand_last4;
+
+-- Drop columns
+ALTER TABLE receipts DROP COLUMN IF EXISTS card_id;
+ALTER TABLE receipts DROP COLUMN IF EXISTS card_last4;
+ALTER TABLE receipts DROP COLUMN IF EXISTS card_brand;
+ALTER TABLE receipts DROP COLUMN IF EXISTS payment_method;
+
+-- Drop table
+DROP TABLE IF EXISTS cards CASCADE;
@@ -0,0 +1,29 @@
+-- Cards table
+CREATE TABLE cards (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    nickname VARCHAR(100),
+    brand VARCHAR(30),
+    last4 CHAR(4) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL
+);
+
+-- Payment columns
+ALTER TABLE receipts ADD COLUMN payment_method VARCHAR(30);
+ALTER TABLE receipts ADD COLUMN card_brand VARCHAR(30);
+ALTER TABLE receipts ADD COLUMN card_last4 CHAR(4);
+ALTER TABLE receipts ADD COLUMN card_id INTEGER REFERENCES cards(id) ON DELETE SET NULL;
+
+-- Indexes
+CREATE UNIQUE INDEX idx_cards_user_brand_last4 ON cards(user_id, COALESCE(brand, ''), last4);
+CREATE INDEX idx_receipts_payment_method ON receipts(user_id, payment_method);
+CREATE INDEX idx_receipts_card_last4 ON receipts(user_id, card_last4) WHERE card_last4 IS NOT NULL;
+CREATE INDEX idx_receipts_card_id ON receipts(card_id);
+
+-- Comments
+COMMENT ON TABLE cards IS 'Payment cards registered by users, identified by brand and last four digits only';
+COMMENT ON COLUMN receipts.payment_method IS 'Payment method: cash, debit_card, credit_card, e_wallet, qris, bank_transfer, other';
+COMMENT ON COLUMN receipts.card_last4 IS 'Last four digits of the card printed on the receipt';
+COMMENT ON COLUMN receipts.card_id IS 'Registered card the receipt was paid with';
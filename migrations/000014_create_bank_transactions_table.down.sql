@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_bank_transactions_receipt_id;
+DROP INDEX IF EXISTS idx_bank_transactions_user_date;
+
+-- Drop table
+DROP TABLE IF EXISTS bank_transactions CASCADE;
@@ -0,0 +1,27 @@
+-- Bank transactions table
+CREATE TABLE bank_transactions (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account VARCHAR(100) NOT NULL DEFAULT '',
+    external_id VARCHAR(255) NOT NULL,
+    date DATE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    receipt_id INTEGER REFERENCES receipts(id) ON DELETE SET NULL,
+    match_score REAL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    UNIQUE (user_id, account, external_id)
+);
+
+-- Indexes
+CREATE INDEX idx_bank_transactions_user_date ON bank_transactions(user_id, date);
+CREATE UNIQUE INDEX idx_bank_transactions_receipt_id ON bank_transactions(receipt_id) WHERE receipt_id IS NOT NULL;
+
+-- Comments
+COMMENT ON TABLE bank_transactions IS 'Imported bank and card statement lines, reconciled against receipts';
+COMMENT ON COLUMN bank_transactions.external_id IS 'Statement transaction id (OFX FITID), or a hash of the line when the statement has none';
+COMMENT ON COLUMN bank_transactions.amount IS 'Signed amount in minor units of currency; money leaving the account is negative';
+COMMENT ON COLUMN bank_transactions.match_score IS 'Matcher confidence between 0 and 1; NULL for manual matches';
@@ -0,0 +1,14 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipts_merchant_id;
+DROP INDEX IF EXISTS idx_merchant_aliases_merchant_id;
+DROP INDEX IF EXISTS idx_merchants_user_phone;
+DROP INDEX IF EXISTS idx_merchants_user_id;
+
+-- Drop columns
+ALTER TABLE receipts DROP COLUMN IF EXISTS merchant_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS merchant_aliases CASCADE;
+DROP TABLE IF EXISTS merchants CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,42 @@
+-- User roles
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'admin'));
+
+-- Merchants table
+CREATE TABLE merchants (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(255),
+    phone BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Merchant aliases table
+CREATE TABLE merchant_aliases (
+    id SERIAL PRIMARY KEY,
+    merchant_id INTEGER NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, alias)
+);
+
+-- Receipt merchant
+ALTER TABLE receipts ADD COLUMN merchant_id INTEGER REFERENCES merchants(id) ON DELETE SET NULL;
+
+-- Indexes
+CREATE INDEX idx_merchants_user_id ON merchants(user_id);
+CREATE INDEX idx_merchants_user_phone ON merchants(user_id, phone) WHERE phone IS NOT NULL;
+CREATE INDEX idx_merchant_aliases_merchant_id ON merchant_aliases(merchant_id);
+CREATE INDEX idx_receipts_merchant_id ON receipts(merchant_id);
+
+-- Comments
+COMMENT ON COLUMN users.role IS 'Access role: user or admin';
+COMMENT ON TABLE merchants IS 'Canonical stores that receipt store names are normalized to';
+COMMENT ON COLUMN merchant_aliases.alias IS 'Normalized store name that resolves to the merchant';
+COMMENT ON COLUMN receipts.merchant_id IS 'Merchant matched from the store name after extraction';
@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_items_product_id;
+DROP INDEX IF EXISTS idx_product_aliases_product_id;
+DROP INDEX IF EXISTS idx_products_user_barcode;
+DROP INDEX IF EXISTS idx_products_user_id;
+
+-- Drop columns
+ALTER TABLE items DROP COLUMN IF EXISTS product_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS product_aliases CASCADE;
+DROP TABLE IF EXISTS products CASCADE;
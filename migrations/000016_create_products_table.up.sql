@@ -0,0 +1,39 @@
+-- Products table
+CREATE TABLE products (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    unit VARCHAR(20),
+    barcode VARCHAR(64),
+    sku VARCHAR(64),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Product aliases table
+CREATE TABLE product_aliases (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, alias)
+);
+
+-- Item product
+ALTER TABLE items ADD COLUMN product_id INTEGER REFERENCES products(id) ON DELETE SET NULL;
+
+-- Indexes
+CREATE INDEX idx_products_user_id ON products(user_id);
+CREATE UNIQUE INDEX idx_products_user_barcode ON products(user_id, barcode) WHERE barcode IS NOT NULL;
+CREATE INDEX idx_product_aliases_product_id ON product_aliases(product_id);
+CREATE INDEX idx_items_product_id ON items(product_id);
+
+-- Comments
+COMMENT ON TABLE products IS 'Canonical products that receipt item names are normalized to';
+COMMENT ON COLUMN products.unit IS 'Pack size or unit, e.g. 600ml or kg';
+COMMENT ON COLUMN product_aliases.alias IS 'Normalized item name that resolves to the product';
+COMMENT ON COLUMN items.product_id IS 'Product matched from the item name after extraction';
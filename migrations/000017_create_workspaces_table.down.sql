@@ -0,0 +1,15 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipts_workspace_image_hash;
+DROP INDEX IF EXISTS idx_receipts_workspace_upload_date_id;
+DROP INDEX IF EXISTS idx_workspace_invitations_email;
+DROP INDEX IF EXISTS idx_workspace_invitations_workspace_id;
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+DROP INDEX IF EXISTS idx_workspaces_personal_owner;
+
+-- Drop columns
+ALTER TABLE receipts DROP COLUMN IF EXISTS workspace_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS workspace_invitations CASCADE;
+DROP TABLE IF EXISTS workspace_members CASCADE;
+DROP TABLE IF EXISTS workspaces CASCADE;
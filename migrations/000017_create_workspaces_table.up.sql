@@ -0,0 +1,70 @@
+-- Workspaces table
+CREATE TABLE workspaces (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    personal BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Workspace members table
+CREATE TABLE workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- Workspace invitations table
+CREATE TABLE workspace_invitations (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    invited_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL
+);
+
+-- Personal workspaces for existing users, owning their receipts
+INSERT INTO workspaces (name, owner_id, personal, created_at_unix, updated_at_unix)
+SELECT 'Personal', id, TRUE, EXTRACT(EPOCH FROM NOW())::INTEGER, EXTRACT(EPOCH FROM NOW())::INTEGER
+FROM users;
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at_unix)
+SELECT id, owner_id, 'owner', created_at_unix
+FROM workspaces;
+
+ALTER TABLE receipts ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE receipts r
+SET workspace_id = w.id
+FROM workspaces w
+WHERE w.owner_id = r.user_id AND w.personal;
+
+ALTER TABLE receipts ALTER COLUMN workspace_id SET NOT NULL;
+
+-- Indexes
+CREATE UNIQUE INDEX idx_workspaces_personal_owner ON workspaces(owner_id) WHERE personal;
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+CREATE INDEX idx_workspace_invitations_workspace_id ON workspace_invitations(workspace_id);
+CREATE INDEX idx_workspace_invitations_email ON workspace_invitations(LOWER(email)) WHERE accepted_at IS NULL;
+CREATE INDEX idx_receipts_workspace_upload_date_id ON receipts(workspace_id, upload_date DESC, id DESC);
+CREATE INDEX idx_receipts_workspace_image_hash ON receipts(workspace_id, image_hash);
+
+-- Comments
+COMMENT ON TABLE workspaces IS 'Households or teams whose members share receipts';
+COMMENT ON COLUMN workspaces.personal IS 'The private workspace every user has, which cannot be shared';
+COMMENT ON COLUMN workspace_members.role IS 'Member role: owner, editor or viewer';
+COMMENT ON COLUMN workspace_invitations.token_hash IS 'SHA-256 hash of the acceptance token';
+COMMENT ON COLUMN receipts.workspace_id IS 'Workspace owning the receipt; user_id is the member who added it';
@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_settlements_workspace_id;
+DROP INDEX IF EXISTS idx_split_shares_user_id;
+DROP INDEX IF EXISTS idx_receipt_splits_workspace_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS settlements CASCADE;
+DROP TABLE IF EXISTS split_shares CASCADE;
+DROP TABLE IF EXISTS split_allocations CASCADE;
+DROP TABLE IF EXISTS receipt_splits CASCADE;
@@ -0,0 +1,63 @@
+-- Receipt splits table
+CREATE TABLE receipt_splits (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    receipt_id INTEGER UNIQUE NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    payer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Split allocations table
+CREATE TABLE split_allocations (
+    split_id INTEGER NOT NULL REFERENCES receipt_splits(id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weight INTEGER NOT NULL CHECK (weight >= 0),
+    PRIMARY KEY (split_id, item_id, user_id)
+);
+
+-- Split shares table
+CREATE TABLE split_shares (
+    split_id INTEGER NOT NULL REFERENCES receipt_splits(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    items BIGINT NOT NULL,
+    adjustments BIGINT NOT NULL,
+    total BIGINT NOT NULL,
+    PRIMARY KEY (split_id, user_id)
+);
+
+-- Settlements table
+CREATE TABLE settlements (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    from_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    note VARCHAR(255),
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    settled_at DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    CHECK (from_user_id <> to_user_id)
+);
+
+-- Indexes
+CREATE INDEX idx_receipt_splits_workspace_id ON receipt_splits(workspace_id);
+CREATE INDEX idx_split_shares_user_id ON split_shares(user_id);
+CREATE INDEX idx_settlements_workspace_id ON settlements(workspace_id, settled_at);
+
+-- Comments
+COMMENT ON TABLE receipt_splits IS 'Receipts divided between workspace members; the payer paid the whole receipt';
+COMMENT ON COLUMN split_allocations.weight IS 'Participant weight in the item, e.g. 1 and 2 for a third and two thirds';
+COMMENT ON COLUMN split_shares.items IS 'Participant part of the item totals in minor units of the split currency';
+COMMENT ON COLUMN split_shares.adjustments IS 'Participant part of the adjustments less the discount, proportional to items';
+COMMENT ON TABLE settlements IS 'Payments between workspace members settling split debts';
+COMMENT ON COLUMN settlements.amount IS 'Amount paid in minor units of currency';
@@ -0,0 +1,11 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_expense_report_comments_report_id;
+DROP INDEX IF EXISTS idx_expense_report_approvers_user_id;
+DROP INDEX IF EXISTS idx_expense_reports_user_id;
+DROP INDEX IF EXISTS idx_expense_reports_workspace_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS expense_report_comments CASCADE;
+DROP TABLE IF EXISTS expense_report_approvers CASCADE;
+DROP TABLE IF EXISTS expense_report_receipts CASCADE;
+DROP TABLE IF EXISTS expense_reports CASCADE;
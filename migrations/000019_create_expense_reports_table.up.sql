@@ -0,0 +1,57 @@
+-- Expense reports table
+CREATE TABLE expense_reports (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'approved', 'rejected', 'paid')),
+    submitted_at TIMESTAMP,
+    decided_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Expense report receipts table
+CREATE TABLE expense_report_receipts (
+    report_id INTEGER NOT NULL REFERENCES expense_reports(id) ON DELETE CASCADE,
+    receipt_id INTEGER UNIQUE NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+    PRIMARY KEY (report_id, receipt_id)
+);
+
+-- Expense report approvers table
+CREATE TABLE expense_report_approvers (
+    report_id INTEGER NOT NULL REFERENCES expense_reports(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    decision VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (decision IN ('pending', 'approved', 'rejected')),
+    decided_at TIMESTAMP,
+    PRIMARY KEY (report_id, user_id)
+);
+
+-- Expense report comments table
+CREATE TABLE expense_report_comments (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    report_id INTEGER NOT NULL REFERENCES expense_reports(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL
+);
+
+-- Indexes
+CREATE INDEX idx_expense_reports_workspace_id ON expense_reports(workspace_id, created_at DESC);
+CREATE INDEX idx_expense_reports_user_id ON expense_reports(user_id);
+CREATE INDEX idx_expense_report_approvers_user_id ON expense_report_approvers(user_id);
+CREATE INDEX idx_expense_report_comments_report_id ON expense_report_comments(report_id, created_at);
+
+-- Comments
+COMMENT ON TABLE expense_reports IS 'Business receipts grouped for reimbursement';
+COMMENT ON COLUMN expense_reports.status IS 'Workflow stage: draft, submitted, approved, rejected or paid';
+COMMENT ON COLUMN expense_reports.decided_at IS 'When the report was approved by every approver or rejected by one';
+COMMENT ON COLUMN expense_report_receipts.receipt_id IS 'A receipt can be on one expense report only';
+COMMENT ON COLUMN expense_report_approvers.decision IS 'Approver decision on the current submission: pending, approved or rejected';
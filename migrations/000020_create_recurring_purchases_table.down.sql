@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_recurring_purchases_user_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS recurring_purchases CASCADE;
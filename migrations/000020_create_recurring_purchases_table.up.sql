@@ -0,0 +1,36 @@
+-- Recurring purchases table
+CREATE TABLE recurring_purchases (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    merchant_id INTEGER REFERENCES merchants(id) ON DELETE SET NULL,
+    merchant_key VARCHAR(255) NOT NULL,
+    store_name VARCHAR(255) NOT NULL,
+    cadence VARCHAR(20) NOT NULL CHECK (cadence IN ('weekly', 'monthly', 'yearly')),
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    occurrences INTEGER NOT NULL,
+    first_date DATE NOT NULL,
+    last_date DATE NOT NULL,
+    next_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'missing', 'ended')),
+    unusual_amount BIGINT,
+    unusual_receipt_id INTEGER REFERENCES receipts(id) ON DELETE SET NULL,
+    receipt_ids INTEGER[] NOT NULL DEFAULT '{}',
+    dismissed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Indexes
+CREATE INDEX idx_recurring_purchases_user_id ON recurring_purchases(user_id, next_date);
+
+-- Comments
+COMMENT ON TABLE recurring_purchases IS 'Subscriptions and other purchases detected as repeating at a regular cadence';
+COMMENT ON COLUMN recurring_purchases.merchant_key IS 'Merchant id or normalized store name the purchases were grouped by';
+COMMENT ON COLUMN recurring_purchases.amount IS 'Typical (median) amount in minor units of the currency';
+COMMENT ON COLUMN recurring_purchases.next_date IS 'Predicted date of the next occurrence';
+COMMENT ON COLUMN recurring_purchases.unusual_amount IS 'Amount of the latest occurrence when unusually larger than the typical amount';
+COMMENT ON COLUMN recurring_purchases.dismissed IS 'Hidden by the user; kept when detection runs again';
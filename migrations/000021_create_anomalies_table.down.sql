@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_anomalies_user_id;
+DROP INDEX IF EXISTS idx_anomalies_receipt_kind_item;
+
+-- Drop tables
+DROP TABLE IF EXISTS anomalies CASCADE;
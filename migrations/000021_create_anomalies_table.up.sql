@@ -0,0 +1,30 @@
+-- Anomalies table
+CREATE TABLE anomalies (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    receipt_id INTEGER NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+    item_id INTEGER REFERENCES items(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('total', 'item_count', 'item_price')),
+    category VARCHAR(100),
+    currency CHAR(3),
+    value NUMERIC NOT NULL,
+    baseline NUMERIC NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'seen', 'dismissed')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL
+);
+
+-- Indexes
+CREATE UNIQUE INDEX idx_anomalies_receipt_kind_item ON anomalies(receipt_id, kind, (COALESCE(item_id, 0)));
+CREATE INDEX idx_anomalies_user_id ON anomalies(user_id, created_at DESC);
+
+-- Comments
+COMMENT ON TABLE anomalies IS 'Receipts and items whose values are outliers against the user''s history';
+COMMENT ON COLUMN anomalies.value IS 'Flagged amount in currency, or item count when currency is null';
+COMMENT ON COLUMN anomalies.baseline IS 'Median of the history the value was compared with';
+COMMENT ON COLUMN anomalies.score IS 'Robust z-score: deviations above the median, from the median absolute deviation';
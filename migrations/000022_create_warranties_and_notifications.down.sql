@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_notifications_user_id;
+DROP INDEX IF EXISTS idx_item_warranties_return_pending;
+
+-- Drop tables
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS item_warranties CASCADE;
@@ -0,0 +1,36 @@
+-- Item warranties table
+CREATE TABLE item_warranties (
+    item_id INTEGER PRIMARY KEY REFERENCES items(id) ON DELETE CASCADE,
+    warranty_months INTEGER CHECK (warranty_months > 0),
+    return_days INTEGER CHECK (return_days > 0),
+    return_reminded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL,
+    CHECK (warranty_months IS NOT NULL OR return_days IS NOT NULL)
+);
+
+-- Notifications table
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    receipt_id INTEGER REFERENCES receipts(id) ON DELETE CASCADE,
+    item_id INTEGER REFERENCES items(id) ON DELETE CASCADE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL
+);
+
+-- Indexes
+CREATE INDEX idx_item_warranties_return_pending ON item_warranties(item_id) WHERE return_reminded_at IS NULL AND return_days IS NOT NULL;
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at DESC);
+
+-- Comments
+COMMENT ON TABLE item_warranties IS 'Warranty length and return window of an item, counted from the receipt date';
+COMMENT ON COLUMN item_warranties.return_reminded_at IS 'When the reminder that the return window is closing was sent';
+COMMENT ON TABLE notifications IS 'In-app messages to users, such as return window reminders';
@@ -0,0 +1,21 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipt_tags_tag_id;
+DROP INDEX IF EXISTS idx_tags_name;
+DROP INDEX IF EXISTS idx_receipts_search_vector;
+
+-- Drop tables
+DROP TABLE IF EXISTS receipt_tags;
+DROP TABLE IF EXISTS tags;
+
+-- Restore the search vector without notes
+ALTER TABLE receipts DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE receipts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(store_name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(address, '')), 'B')
+) STORED;
+CREATE INDEX idx_receipts_search_vector ON receipts USING GIN (search_vector);
+COMMENT ON COLUMN receipts.search_vector IS 'Full-text vector of store name (weight A) and address (weight B)';
+
+-- Drop columns
+ALTER TABLE items DROP COLUMN IF EXISTS notes;
+ALTER TABLE receipts DROP COLUMN IF EXISTS notes;
@@ -0,0 +1,45 @@
+-- Notes
+ALTER TABLE receipts ADD COLUMN notes TEXT;
+ALTER TABLE items ADD COLUMN notes TEXT;
+
+-- Include receipt notes in full-text search
+DROP INDEX IF EXISTS idx_receipts_search_vector;
+ALTER TABLE receipts DROP COLUMN search_vector;
+ALTER TABLE receipts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(store_name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(address, '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(notes, '')), 'C')
+) STORED;
+
+-- Tags table
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    updated_at_unix INTEGER NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+-- Receipt tags table
+CREATE TABLE receipt_tags (
+    receipt_id INTEGER NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (receipt_id, tag_id)
+);
+
+-- Indexes
+CREATE INDEX idx_receipts_search_vector ON receipts USING GIN (search_vector);
+CREATE INDEX idx_tags_name ON tags(name);
+CREATE INDEX idx_receipt_tags_tag_id ON receipt_tags(tag_id);
+
+-- Comments
+COMMENT ON COLUMN receipts.notes IS 'Free-form notes written by the user';
+COMMENT ON COLUMN items.notes IS 'Free-form notes written by the user';
+COMMENT ON COLUMN receipts.search_vector IS 'Full-text vector of store name (weight A), address (weight B) and notes (weight C)';
+COMMENT ON TABLE tags IS 'Labels a user attaches to receipts, stored normalized to lowercase with dashes';
+COMMENT ON TABLE receipt_tags IS 'Tags attached to receipts';
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_receipt_attachments_checksum;
+
+-- Drop tables
+DROP TABLE IF EXISTS receipt_attachments;
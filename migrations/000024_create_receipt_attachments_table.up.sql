@@ -0,0 +1,26 @@
+-- Receipt attachments table
+CREATE TABLE receipt_attachments (
+    id SERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    receipt_id INTEGER NOT NULL REFERENCES receipts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('page', 'document')),
+    position INTEGER NOT NULL CHECK (position > 0),
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size INTEGER NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    created_at_unix INTEGER NOT NULL,
+    UNIQUE (receipt_id, kind, position)
+);
+
+-- Indexes
+CREATE INDEX idx_receipt_attachments_checksum ON receipt_attachments(receipt_id, checksum);
+
+-- Comments
+COMMENT ON TABLE receipt_attachments IS 'Additional pages and supporting documents of a receipt';
+COMMENT ON COLUMN receipt_attachments.position IS 'Order among attachments of the same kind; pages follow the receipt image';
+COMMENT ON COLUMN receipt_attachments.checksum IS 'Hex encoded SHA-256 of the file content';
+COMMENT ON COLUMN receipt_attachments.storage_key IS 'Key of the file in local storage or the object store';
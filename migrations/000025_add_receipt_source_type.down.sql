@@ -0,0 +1,2 @@
+-- Drop columns
+ALTER TABLE receipts DROP COLUMN IF EXISTS source_type;
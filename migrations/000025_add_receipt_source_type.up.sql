@@ -0,0 +1,6 @@
+-- Kind of file a receipt was extracted from; image_url points at the stored original
+ALTER TABLE receipts ADD COLUMN source_type VARCHAR(10) NOT NULL DEFAULT 'image'
+    CHECK (source_type IN ('image', 'pdf', 'html'));
+
+-- Comments
+COMMENT ON COLUMN receipts.source_type IS 'Format of the original file: a photographed image, a PDF or an HTML e-receipt';
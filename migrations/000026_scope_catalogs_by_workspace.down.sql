@@ -0,0 +1,76 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_anomalies_workspace_id;
+DROP INDEX IF EXISTS idx_recurring_purchases_workspace_id;
+DROP INDEX IF EXISTS idx_products_workspace_barcode;
+DROP INDEX IF EXISTS idx_products_workspace_id;
+DROP INDEX IF EXISTS idx_merchants_workspace_phone;
+DROP INDEX IF EXISTS idx_merchants_workspace_id;
+
+-- Anomalies and recurring purchases go back to the receipt's user and the workspace owner
+ALTER TABLE anomalies ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+UPDATE anomalies a
+SET user_id = r.user_id
+FROM receipts r
+WHERE r.id = a.receipt_id;
+
+ALTER TABLE anomalies ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE anomalies DROP COLUMN workspace_id;
+
+ALTER TABLE recurring_purchases ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+UPDATE recurring_purchases p
+SET user_id = w.owner_id
+FROM workspaces w
+WHERE w.id = p.workspace_id;
+
+ALTER TABLE recurring_purchases ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE recurring_purchases DROP COLUMN workspace_id;
+
+-- Aliases go back to the user who created the product or merchant, keeping one per alias
+ALTER TABLE product_aliases ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+UPDATE product_aliases a
+SET user_id = p.user_id
+FROM products p
+WHERE p.id = a.product_id;
+
+DELETE FROM product_aliases a
+USING product_aliases b
+WHERE a.user_id = b.user_id AND a.alias = b.alias AND a.id > b.id;
+
+ALTER TABLE product_aliases ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE product_aliases DROP COLUMN workspace_id;
+ALTER TABLE product_aliases ADD CONSTRAINT product_aliases_user_id_alias_key UNIQUE (user_id, alias);
+
+ALTER TABLE products DROP COLUMN workspace_id;
+
+UPDATE products p
+SET barcode = NULL
+FROM products q
+WHERE p.user_id = q.user_id AND p.barcode = q.barcode AND p.id > q.id;
+
+ALTER TABLE merchant_aliases ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+UPDATE merchant_aliases a
+SET user_id = m.user_id
+FROM merchants m
+WHERE m.id = a.merchant_id;
+
+DELETE FROM merchant_aliases a
+USING merchant_aliases b
+WHERE a.user_id = b.user_id AND a.alias = b.alias AND a.id > b.id;
+
+ALTER TABLE merchant_aliases ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE merchant_aliases DROP COLUMN workspace_id;
+ALTER TABLE merchant_aliases ADD CONSTRAINT merchant_aliases_user_id_alias_key UNIQUE (user_id, alias);
+
+ALTER TABLE merchants DROP COLUMN workspace_id;
+
+-- Restore user indexes
+CREATE INDEX idx_merchants_user_id ON merchants(user_id);
+CREATE INDEX idx_merchants_user_phone ON merchants(user_id, phone) WHERE phone IS NOT NULL;
+CREATE INDEX idx_products_user_id ON products(user_id);
+CREATE UNIQUE INDEX idx_products_user_barcode ON products(user_id, barcode) WHERE barcode IS NOT NULL;
+CREATE INDEX idx_recurring_purchases_user_id ON recurring_purchases(user_id, next_date);
+CREATE INDEX idx_anomalies_user_id ON anomalies(user_id, created_at DESC);
@@ -0,0 +1,93 @@
+-- Merchants, products, recurring purchases and anomalies belong to a workspace like the
+-- receipts they are built from. Existing rows move to their user's personal workspace.
+ALTER TABLE merchants ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE merchants m
+SET workspace_id = w.id
+FROM workspaces w
+WHERE w.owner_id = m.user_id AND w.personal;
+
+ALTER TABLE merchants ALTER COLUMN workspace_id SET NOT NULL;
+
+ALTER TABLE merchant_aliases ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE merchant_aliases a
+SET workspace_id = m.workspace_id
+FROM merchants m
+WHERE m.id = a.merchant_id;
+
+ALTER TABLE merchant_aliases ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE merchant_aliases DROP COLUMN user_id;
+ALTER TABLE merchant_aliases ADD CONSTRAINT merchant_aliases_workspace_id_alias_key UNIQUE (workspace_id, alias);
+
+ALTER TABLE products ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE products p
+SET workspace_id = w.id
+FROM workspaces w
+WHERE w.owner_id = p.user_id AND w.personal;
+
+ALTER TABLE products ALTER COLUMN workspace_id SET NOT NULL;
+
+ALTER TABLE product_aliases ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE product_aliases a
+SET workspace_id = p.workspace_id
+FROM products p
+WHERE p.id = a.product_id;
+
+ALTER TABLE product_aliases ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE product_aliases DROP COLUMN user_id;
+ALTER TABLE product_aliases ADD CONSTRAINT product_aliases_workspace_id_alias_key UNIQUE (workspace_id, alias);
+
+-- Receipts and items of shared workspaces were matched against their member's personal
+-- merchants and products; unlink them so matching again assigns the workspace's own
+UPDATE receipts r
+SET merchant_id = NULL
+FROM merchants m
+WHERE m.id = r.merchant_id AND m.workspace_id <> r.workspace_id;
+
+UPDATE items i
+SET product_id = NULL
+FROM products p, receipts r
+WHERE p.id = i.product_id AND r.id = i.receipt_id AND p.workspace_id <> r.workspace_id;
+
+-- Recurring purchases are detected again per workspace
+ALTER TABLE recurring_purchases ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE recurring_purchases p
+SET workspace_id = w.id
+FROM workspaces w
+WHERE w.owner_id = p.user_id AND w.personal;
+
+ALTER TABLE recurring_purchases ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE recurring_purchases DROP COLUMN user_id;
+
+ALTER TABLE anomalies ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE anomalies a
+SET workspace_id = r.workspace_id
+FROM receipts r
+WHERE r.id = a.receipt_id;
+
+ALTER TABLE anomalies ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE anomalies DROP COLUMN user_id;
+
+-- Indexes
+DROP INDEX IF EXISTS idx_merchants_user_id;
+DROP INDEX IF EXISTS idx_merchants_user_phone;
+DROP INDEX IF EXISTS idx_products_user_id;
+DROP INDEX IF EXISTS idx_products_user_barcode;
+DROP INDEX IF EXISTS idx_recurring_purchases_user_id;
+DROP INDEX IF EXISTS idx_anomalies_user_id;
+
+CREATE INDEX idx_merchants_workspace_id ON merchants(workspace_id);
+CREATE INDEX idx_merchants_workspace_phone ON merchants(workspace_id, phone) WHERE phone IS NOT NULL;
+CREATE INDEX idx_products_workspace_id ON products(workspace_id);
+CREATE UNIQUE INDEX idx_products_workspace_barcode ON products(workspace_id, barcode) WHERE barcode IS NOT NULL;
+CREATE INDEX idx_recurring_purchases_workspace_id ON recurring_purchases(workspace_id, next_date);
+CREATE INDEX idx_anomalies_workspace_id ON anomalies(workspace_id, created_at DESC);
+
+-- Comments
+COMMENT ON COLUMN merchants.workspace_id IS 'Workspace owning the merchant; user_id is the member whose receipt created it';
+COMMENT ON COLUMN products.workspace_id IS 'Workspace owning the product; user_id is the member whose item created it';